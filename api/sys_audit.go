@@ -55,6 +55,91 @@ func (c *Sys) AuditHashWithContext(ctx context.Context, path string, input strin
 	return hashStr, nil
 }
 
+// AuditHashes returns the hashes of the given inputs, in order, using the salt
+// of the audit device at the given path.
+func (c *Sys) AuditHashes(path string, inputs []string) ([]string, error) {
+	return c.AuditHashesWithContext(context.Background(), path, inputs)
+}
+
+func (c *Sys) AuditHashesWithContext(ctx context.Context, path string, inputs []string) ([]string, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	body := map[string]interface{}{
+		"inputs": inputs,
+	}
+
+	r := c.c.NewRequest(http.MethodPut, fmt.Sprintf("/v1/sys/audit-hash/%s", path))
+	if err := r.SetJSONBody(body); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result struct {
+		Hashes []string `mapstructure:"hashes"`
+	}
+	if err := mapstructure.Decode(secret.Data, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Hashes) != len(inputs) {
+		return nil, fmt.Errorf("expected %d hashes in response data, found %d", len(inputs), len(result.Hashes))
+	}
+
+	return result.Hashes, nil
+}
+
+// AuditChainHead returns the sequence number and chain of the last entry
+// written by the hash chained audit device at the given path. A nil head is
+// returned if the device has not yet written a chained entry.
+func (c *Sys) AuditChainHead(path string) (*AuditChainHead, error) {
+	return c.AuditChainHeadWithContext(context.Background(), path)
+}
+
+func (c *Sys) AuditChainHeadWithContext(ctx context.Context, path string) (*AuditChainHead, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodGet, fmt.Sprintf("/v1/sys/audit-chain/%s", path))
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var head AuditChainHead
+	err = mapstructure.WeakDecode(secret.Data, &head)
+	if err != nil {
+		return nil, err
+	}
+	if head.Sequence == 0 {
+		return nil, nil
+	}
+
+	return &head, nil
+}
+
 func (c *Sys) ListAudit() (map[string]*Audit, error) {
 	return c.ListAuditWithContext(context.Background())
 }
@@ -157,3 +242,8 @@ type Audit struct {
 	Local       bool              `json:"local" mapstructure:"local"`
 	Path        string            `json:"path" mapstructure:"path"`
 }
+
+type AuditChainHead struct {
+	Sequence uint64 `json:"sequence" mapstructure:"sequence"`
+	Chain    string `json:"chain" mapstructure:"chain"`
+}
@@ -0,0 +1,243 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEntryFilter_NewEntryFilter tests that we can create EntryFilter types correctly.
+func TestEntryFilter_NewEntryFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		Filter               string
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"empty-filter": {
+			Filter:               "",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.NewEntryFilter: cannot create new audit filter with empty filter expression: invalid parameter",
+		},
+		"spacey-filter": {
+			Filter:               "    ",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "audit.NewEntryFilter: cannot create new audit filter with empty filter expression: invalid parameter",
+		},
+		"bad-filter": {
+			Filter:          "____",
+			IsErrorExpected: true,
+		},
+		"good-filter": {
+			Filter:          "foo == bar",
+			IsErrorExpected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := NewEntryFilter(tc.Filter)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				if tc.ExpectedErrorMessage != "" {
+					require.EqualError(t, err, tc.ExpectedErrorMessage)
+				}
+				require.Nil(t, f)
+			default:
+				require.NoError(t, err)
+				require.NotNil(t, f)
+			}
+		})
+	}
+}
+
+// TestEntryFilter_Reopen ensures we can reopen the filter node.
+func TestEntryFilter_Reopen(t *testing.T) {
+	t.Parallel()
+
+	f := &EntryFilter{}
+	res := f.Reopen()
+	require.Nil(t, res)
+}
+
+// TestEntryFilter_Type ensures we always return the right type for this node.
+func TestEntryFilter_Type(t *testing.T) {
+	t.Parallel()
+
+	f := &EntryFilter{}
+	require.Equal(t, eventlogger.NodeTypeFilter, f.Type())
+}
+
+// TestEntryFilter_Process_ContextDone ensures that we stop processing the event
+// if the context was cancelled.
+func TestEntryFilter_Process_ContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Explicitly cancel the context
+	cancel()
+
+	l, err := NewEntryFilter("operation == foo")
+	require.NoError(t, err)
+
+	e := fakeEvent(t, RequestType, JSONFormat, &logical.LogInput{})
+
+	e2, err := l.Process(ctx, e)
+
+	require.Error(t, err)
+	require.ErrorContains(t, err, "context canceled")
+
+	// Ensure that the pipeline won't continue.
+	require.Nil(t, e2)
+}
+
+// TestEntryFilter_Process_NilEvent ensures we receive the right error when the
+// event we are trying to process is nil.
+func TestEntryFilter_Process_NilEvent(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewEntryFilter("operation == foo")
+	require.NoError(t, err)
+	e, err := l.Process(context.Background(), nil)
+	require.Error(t, err)
+	require.EqualError(t, err, "audit.(EntryFilter).Process: event is nil: invalid parameter")
+
+	// Ensure that the pipeline won't continue.
+	require.Nil(t, e)
+}
+
+// TestEntryFilter_Process_BadPayload ensures we receive the correct error when
+// attempting to process an event with a payload that cannot be parsed back to
+// an audit event.
+func TestEntryFilter_Process_BadPayload(t *testing.T) {
+	t.Parallel()
+
+	l, err := NewEntryFilter("operation == foo")
+	require.NoError(t, err)
+
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType.String()),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+		Payload:   nil,
+	}
+
+	e2, err := l.Process(context.Background(), e)
+	require.Error(t, err)
+	require.EqualError(t, err, "audit.(EntryFilter).Process: cannot parse event payload: invalid parameter")
+
+	// Ensure that the pipeline won't continue.
+	require.Nil(t, e2)
+}
+
+// TestEntryFilter_Process ensures that events are only passed along the
+// pipeline when they match the filter.
+func TestEntryFilter_Process(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		Filter        string
+		Request       *logical.Request
+		IsFilteredOut bool
+	}{
+		"no-request": {
+			Filter:        "operation == read",
+			IsFilteredOut: true,
+		},
+		"mount-type-match": {
+			Filter: `mount_type == "kv" and operation != "read"`,
+			Request: &logical.Request{
+				MountType: "kv",
+				Operation: logical.UpdateOperation,
+			},
+		},
+		"mount-type-read": {
+			Filter: `mount_type == "kv" and operation != "read"`,
+			Request: &logical.Request{
+				MountType: "kv",
+				Operation: logical.ReadOperation,
+			},
+			IsFilteredOut: true,
+		},
+		"path-match": {
+			Filter: `path matches "^secret/.*"`,
+			Request: &logical.Request{
+				Path: "secret/foo",
+			},
+		},
+		"namespace-match": {
+			Filter:  `namespace == ""`,
+			Request: &logical.Request{},
+		},
+		"mount-point-mismatch": {
+			Filter: `mount_point == "transit/"`,
+			Request: &logical.Request{
+				MountPoint: "secret/",
+			},
+			IsFilteredOut: true,
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l, err := NewEntryFilter(tc.Filter)
+			require.NoError(t, err)
+
+			e := fakeEvent(t, RequestType, JSONFormat, &logical.LogInput{Request: tc.Request})
+			ctx := namespace.ContextWithNamespace(context.Background(), namespace.RootNamespace)
+
+			e2, err := l.Process(ctx, e)
+			require.NoError(t, err)
+			switch {
+			case tc.IsFilteredOut:
+				require.Nil(t, e2)
+			default:
+				require.Equal(t, e, e2)
+			}
+		})
+	}
+}
+
+// TestEntryFilter_AddFilterNode tests that a filter node is only added to an
+// audit device's pipeline when a filter is configured, and that filters are
+// rejected when the event logger is disabled.
+func TestEntryFilter_AddFilterNode(t *testing.T) {
+	t.Parallel()
+
+	config := map[string]string{"filter": `mount_type == "kv"`}
+	require.EqualError(t, ValidateFilterConfig(config, false), "cannot configure a filter when the event logger is disabled")
+	require.NoError(t, ValidateFilterConfig(config, true))
+	require.NoError(t, ValidateFilterConfig(map[string]string{}, false))
+
+	var nodeIDs []eventlogger.NodeID
+	nodes := make(map[eventlogger.NodeID]eventlogger.Node)
+	require.NoError(t, AddFilterNode(map[string]string{}, &nodeIDs, nodes))
+	require.Empty(t, nodeIDs)
+
+	require.NoError(t, AddFilterNode(config, &nodeIDs, nodes))
+	require.Len(t, nodeIDs, 1)
+	require.Equal(t, eventlogger.NodeTypeFilter, nodes[nodeIDs[0]].Type())
+
+	err := AddFilterNode(map[string]string{"filter": "this is not valid"}, &nodeIDs, nodes)
+	require.ErrorContains(t, err, "error creating filter node")
+}
@@ -66,7 +66,7 @@ func (*EntryFormatter) Type() eventlogger.NodeType {
 }
 
 // Process will attempt to parse the incoming event data into a corresponding
-// audit Request/Response which is serialized to JSON/JSONx and stored within the event.
+// audit Request/Response which is serialized to JSON/JSONx/CEF/OCSF and stored within the event.
 func (f *EntryFormatter) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
 	const op = "audit.(EntryFormatter).Process"
 
@@ -112,7 +112,7 @@ func (f *EntryFormatter) Process(ctx context.Context, e *eventlogger.Event) (*ev
 			return nil, fmt.Errorf("%s: unable to parse request from audit event: %w", op, err)
 		}
 
-		result, err = jsonutil.EncodeJSON(entry)
+		result, err = encodeEntry(f.config.RequiredFormat, entry)
 		if err != nil {
 			return nil, fmt.Errorf("%s: unable to format request: %w", op, err)
 		}
@@ -122,7 +122,7 @@ func (f *EntryFormatter) Process(ctx context.Context, e *eventlogger.Event) (*ev
 			return nil, fmt.Errorf("%s: unable to parse response from audit event: %w", op, err)
 		}
 
-		result, err = jsonutil.EncodeJSON(entry)
+		result, err = encodeEntry(f.config.RequiredFormat, entry)
 		if err != nil {
 			return nil, fmt.Errorf("%s: unable to format response: %w", op, err)
 		}
@@ -497,6 +497,59 @@ func (f *EntryFormatter) FormatResponse(ctx context.Context, in *logical.LogInpu
 	return respEntry, nil
 }
 
+// encodeEntry serializes a RequestEntry or ResponseEntry into the required
+// format. JSONx is encoded as JSON, and left to the caller to convert.
+func encodeEntry(requiredFormat format, entry any) ([]byte, error) {
+	switch requiredFormat {
+	case CEFFormat:
+		return encodeCEF(entry)
+	case OCSFFormat:
+		return encodeOCSF(entry)
+	default:
+		return jsonutil.EncodeJSON(entry)
+	}
+}
+
+// newEntryView returns the fields of a RequestEntry or ResponseEntry which are
+// common to both.
+func newEntryView(entry any) (*entryView, error) {
+	switch e := entry.(type) {
+	case *RequestEntry:
+		return &entryView{
+			Time:    e.Time,
+			Type:    e.Type,
+			Auth:    e.Auth,
+			Request: e.Request,
+			Error:   e.Error,
+		}, nil
+	case *ResponseEntry:
+		return &entryView{
+			Time:     e.Time,
+			Type:     e.Type,
+			Auth:     e.Auth,
+			Request:  e.Request,
+			Response: e.Response,
+			Error:    e.Error,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported audit entry type %T", entry)
+	}
+}
+
+// timestamp parses the time of the entry, which is omitted in some tests.
+func (v *entryView) timestamp() (time.Time, bool) {
+	if v.Time == "" {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, v.Time)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
 // NewFormatterConfig should be used to create a FormatterConfig.
 // Accepted options: WithElision, WithHMACAccessor, WithOmitTime, WithRaw, WithFormat.
 func NewFormatterConfig(opt ...Option) (FormatterConfig, error) {
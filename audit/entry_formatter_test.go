@@ -290,6 +290,34 @@ func TestEntryFormatter_Process(t *testing.T) {
 			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
 			RootNamespace:   true,
 		},
+		"cef-request-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         RequestType,
+			RequiredFormat:  CEFFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
+		"cef-response-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         ResponseType,
+			RequiredFormat:  CEFFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
+		"ocsf-request-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         RequestType,
+			RequiredFormat:  OCSFFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
+		"ocsf-response-basic-input-and-request-with-ns": {
+			IsErrorExpected: false,
+			Subtype:         ResponseType,
+			RequiredFormat:  OCSFFormat,
+			Data:            &logical.LogInput{Request: &logical.Request{ID: "123"}},
+			RootNamespace:   true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -106,6 +106,10 @@ func NewTemporaryFormatter(requiredFormat, prefix string) (*EntryFormatterWriter
 	switch {
 	case strings.EqualFold(requiredFormat, JSONxFormat.String()):
 		w = &JSONxWriter{Prefix: prefix}
+	case strings.EqualFold(requiredFormat, CEFFormat.String()):
+		w = &CEFWriter{Prefix: prefix}
+	case strings.EqualFold(requiredFormat, OCSFFormat.String()):
+		w = &OCSFWriter{Prefix: prefix}
 	default:
 		w = &JSONWriter{Prefix: prefix}
 	}
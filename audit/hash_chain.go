@@ -0,0 +1,376 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+var (
+	_ eventlogger.Node   = (*HashChainSink)(nil)
+	_ eventlogger.Closer = (*HashChainSink)(nil)
+)
+
+// chainSuffix matches the chain which is appended to the end of a chained
+// audit entry.
+var chainSuffix = regexp.MustCompile(`,"chain":"([^"]*)"}$`)
+
+// sequenceSuffix matches the sequence number which is appended to the end of a
+// chained audit entry, once its chain has been removed.
+var sequenceSuffix = regexp.MustCompile(`,"sequence":([0-9]+)}$`)
+
+// chainHeadPath is the path, within the storage view of an audit device, at
+// which the head of its hash chain is persisted.
+const chainHeadPath = "chain/head"
+
+// chainHeadPersistInterval is the minimum interval at which the head of a hash
+// chain is persisted while entries are being written.
+const chainHeadPersistInterval = 5 * time.Second
+
+// ChainHeadReader is implemented by audit backends which can report the head
+// of their hash chain, including entries which have not yet been persisted.
+type ChainHeadReader interface {
+	ChainHead(context.Context) (*ChainHead, error)
+}
+
+// ChainHead is the sequence number and chain of the last entry written by a
+// hash chained audit device.
+type ChainHead struct {
+	Sequence uint64 `json:"sequence"`
+	Chain    string `json:"chain"`
+}
+
+// ReadChainHead reads the head of the hash chain from the storage view of an
+// audit device. A nil head is returned if the device has not yet written a
+// chained entry.
+func ReadChainHead(ctx context.Context, storage logical.Storage) (*ChainHead, error) {
+	raw, err := storage.Get(ctx, chainHeadPath)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var head ChainHead
+	if err := raw.DecodeJSON(&head); err != nil {
+		return nil, err
+	}
+
+	return &head, nil
+}
+
+// writeChainHead persists the head of the hash chain to the storage view of an
+// audit device.
+func writeChainHead(ctx context.Context, storage logical.Storage, head *ChainHead) error {
+	entry, err := logical.StorageEntryJSON(chainHeadPath, head)
+	if err != nil {
+		return err
+	}
+
+	return storage.Put(ctx, entry)
+}
+
+// NodesChainHead returns the head of the hash chain of the HashChainSink among
+// the nodes of an audit device. If the device has no HashChainSink, the head
+// persisted in its storage view is returned instead.
+func NodesChainHead(ctx context.Context, nodes map[eventlogger.NodeID]eventlogger.Node, storage logical.Storage) (*ChainHead, error) {
+	for _, node := range nodes {
+		if s, ok := node.(*HashChainSink); ok && s != nil {
+			return s.Head(ctx)
+		}
+	}
+
+	return ReadChainHead(ctx, storage)
+}
+
+// HashChainSink wraps a sink node, adding a sequence number and a HMAC chain
+// to each JSON formatted audit entry before it is passed to the sink.
+//
+// The chain of an entry is the HMAC, keyed by the audit device's salt, of the
+// chain of the previous entry followed by the entry itself, including its
+// sequence number. Entries are chained and written under a lock, so that the
+// order of the entries in the sink matches the order they were chained in.
+//
+// The head of the chain is kept in memory, and persisted in the storage view
+// of the audit device at most once per chainHeadPersistInterval and when the
+// sink is closed. The chain continues from the persisted head when the audit
+// device is next set up, so that entries removed from the end of the log
+// before a restart can still be detected. Where the storage view is read-only,
+// such as on performance standbys and DR secondaries, the head is only kept in
+// memory.
+type HashChainSink struct {
+	salter          Salter
+	storage         logical.Storage
+	prefix          string
+	sink            eventlogger.Node
+	persistInterval time.Duration
+
+	lock      sync.Mutex
+	loaded    bool
+	sequence  uint64
+	chain     string
+	dirty     bool
+	readOnly  bool
+	persisted time.Time
+}
+
+// NewHashChainSink should be used to create a HashChainSink wrapping the
+// supplied sink. The storage is the storage view of the audit device, in which
+// the head of the chain is persisted. The prefix is the prefix configured on
+// the audit device, which is not covered by the chain.
+func NewHashChainSink(salter Salter, storage logical.Storage, prefix string, sink eventlogger.Node) (*HashChainSink, error) {
+	const op = "audit.NewHashChainSink"
+
+	switch {
+	case salter == nil:
+		return nil, fmt.Errorf("%s: cannot create a new hash chain sink with nil salter: %w", op, event.ErrInvalidParameter)
+	case storage == nil:
+		return nil, fmt.Errorf("%s: cannot create a new hash chain sink with nil storage: %w", op, event.ErrInvalidParameter)
+	case sink == nil:
+		return nil, fmt.Errorf("%s: cannot create a new hash chain sink with nil sink: %w", op, event.ErrInvalidParameter)
+	}
+
+	return &HashChainSink{
+		salter:          salter,
+		storage:         storage,
+		prefix:          prefix,
+		sink:            sink,
+		persistInterval: chainHeadPersistInterval,
+	}, nil
+}
+
+// Process chains the JSON formatted audit entry and passes it to the wrapped
+// sink. The chain only advances once the wrapped sink has successfully
+// processed the entry. Failing to persist the new head of the chain does not
+// fail the entry, which has already been written; persisting is retried after
+// the next interval and when the sink is closed.
+func (s *HashChainSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "audit.(HashChainSink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, event.ErrInvalidParameter)
+	}
+
+	formatted, found := e.Format(JSONFormat.String())
+	if !found {
+		return nil, fmt.Errorf("%s: unable to retrieve event formatted as %q: %w", op, JSONFormat, event.ErrInvalidParameter)
+	}
+
+	entry := bytes.TrimSuffix(bytes.TrimPrefix(formatted, []byte(s.prefix)), []byte("\n"))
+	if !bytes.HasPrefix(entry, []byte("{")) || !bytes.HasSuffix(entry, []byte("}")) {
+		return nil, fmt.Errorf("%s: formatted event is not a JSON object: %w", op, event.ErrInvalidParameter)
+	}
+
+	salt, err := s.salter.Salt(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to obtain salt: %w", op, err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.loadLocked(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sequence := s.sequence + 1
+	sequenced := withSequence(entry, sequence)
+	chain := salt.GetIdentifiedHMAC(ChainInput(s.chain, sequenced))
+
+	var chained bytes.Buffer
+	chained.WriteString(s.prefix)
+	chained.Write(sequenced[:len(sequenced)-1])
+	chained.WriteString(`,"chain":"`)
+	chained.WriteString(chain)
+	chained.WriteString("\"}\n")
+
+	ce := &eventlogger.Event{
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt,
+		Formatted: make(map[string][]byte),
+		Payload:   e.Payload,
+	}
+	ce.FormattedAs(JSONFormat.String(), chained.Bytes())
+
+	res, err := s.sink.Process(ctx, ce)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sequence = sequence
+	s.chain = chain
+	s.dirty = true
+
+	if time.Since(s.persisted) >= s.persistInterval {
+		_ = s.persistLocked(ctx)
+	}
+
+	return res, nil
+}
+
+// Head returns the head of the chain, which may not yet have been persisted.
+// A nil head is returned if no chained entry has been written.
+func (s *HashChainSink) Head(ctx context.Context) (*ChainHead, error) {
+	const op = "audit.(HashChainSink).Head"
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.loadLocked(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if s.sequence == 0 {
+		return nil, nil
+	}
+
+	return &ChainHead{Sequence: s.sequence, Chain: s.chain}, nil
+}
+
+// Close persists the head of the chain, and closes the wrapped sink if it
+// supports being closed.
+func (s *HashChainSink) Close(ctx context.Context) error {
+	const op = "audit.(HashChainSink).Close"
+
+	var result *multierror.Error
+
+	s.lock.Lock()
+	if err := s.persistLocked(ctx); err != nil {
+		result = multierror.Append(result, fmt.Errorf("%s: unable to persist chain head: %w", op, err))
+	}
+	s.lock.Unlock()
+
+	if err := eventlogger.NewNodeController(s.sink).Close(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// loadLocked loads the persisted head of the chain, if it has not already been
+// loaded. The head is loaded when it is first needed rather than when the sink
+// is created, as the storage view is read-only during setup. The lock must be
+// held when calling this.
+func (s *HashChainSink) loadLocked(ctx context.Context) error {
+	if s.loaded {
+		return nil
+	}
+
+	head, err := ReadChainHead(ctx, s.storage)
+	if err != nil {
+		return fmt.Errorf("unable to read chain head: %w", err)
+	}
+	if head != nil {
+		s.sequence = head.Sequence
+		s.chain = head.Chain
+	}
+	s.loaded = true
+
+	return nil
+}
+
+// persistLocked writes the head of the chain to storage if it has advanced
+// since it was last persisted. The lock must be held when calling this.
+func (s *HashChainSink) persistLocked(ctx context.Context) error {
+	if !s.dirty || s.readOnly {
+		return nil
+	}
+
+	s.persisted = time.Now()
+
+	err := writeChainHead(ctx, s.storage, &ChainHead{Sequence: s.sequence, Chain: s.chain})
+	switch {
+	case errors.Is(err, logical.ErrReadOnly):
+		// Only the active node persists the head.
+		s.readOnly = true
+		return nil
+	case err != nil:
+		return err
+	}
+
+	s.dirty = false
+
+	return nil
+}
+
+// Reopen simply wraps the Reopen method of the wrapped sink.
+func (s *HashChainSink) Reopen() error {
+	return s.sink.Reopen()
+}
+
+// Unwrap returns the wrapped sink.
+func (s *HashChainSink) Unwrap() eventlogger.Node {
+	return s.sink
+}
+
+// Type simply wraps the Type method of the wrapped sink.
+func (s *HashChainSink) Type() eventlogger.NodeType {
+	return s.sink.Type()
+}
+
+// withSequence adds the sequence number to the end of a JSON object.
+func withSequence(entry []byte, sequence uint64) []byte {
+	sequenced := make([]byte, 0, len(entry)+32)
+	sequenced = append(sequenced, entry[:len(entry)-1]...)
+	sequenced = append(sequenced, `,"sequence":`...)
+	sequenced = strconv.AppendUint(sequenced, sequence, 10)
+	sequenced = append(sequenced, '}')
+
+	return sequenced
+}
+
+// ChainInput returns the data which is HMAC'd to produce the chain of an
+// entry, given the chain of the previous entry and the entry, including its
+// sequence number but excluding its chain. The previous chain is empty for
+// the first entry.
+func ChainInput(previousChain string, sequencedEntry []byte) string {
+	return previousChain + string(sequencedEntry)
+}
+
+// SplitChainedEntry splits a chained audit entry, without a prefix or
+// trailing newline, into the entry including its sequence number, the
+// sequence number, and the chain.
+func SplitChainedEntry(line []byte) ([]byte, uint64, string, error) {
+	m := chainSuffix.FindSubmatchIndex(line)
+	if m == nil {
+		return nil, 0, "", fmt.Errorf("entry has no chain")
+	}
+
+	chain := string(line[m[2]:m[3]])
+
+	sequenced := make([]byte, 0, m[0]+1)
+	sequenced = append(sequenced, line[:m[0]]...)
+	sequenced = append(sequenced, '}')
+
+	sm := sequenceSuffix.FindSubmatch(sequenced)
+	if sm == nil {
+		return nil, 0, "", fmt.Errorf("entry has no sequence number")
+	}
+
+	sequence, err := strconv.ParseUint(string(sm[1]), 10, 64)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("entry has an invalid sequence number: %w", err)
+	}
+
+	return sequenced, sequence, chain, nil
+}
@@ -0,0 +1,273 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink is a sink node which records the JSON formatted events it
+// processes, and can be made to fail them.
+type recordingSink struct {
+	fail    bool
+	entries [][]byte
+}
+
+func (s *recordingSink) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	if s.fail {
+		return nil, errors.New("sink failed")
+	}
+
+	b, _ := e.Format(JSONFormat.String())
+	s.entries = append(s.entries, b)
+
+	return nil, nil
+}
+
+func (s *recordingSink) Reopen() error {
+	return nil
+}
+
+func (s *recordingSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// jsonEvent returns an event formatted as the supplied JSON.
+func jsonEvent(formatted string) *eventlogger.Event {
+	e := &eventlogger.Event{
+		Type:      eventlogger.EventType(event.AuditType.String()),
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+	}
+	e.FormattedAs(JSONFormat.String(), []byte(formatted))
+
+	return e
+}
+
+// TestNewHashChainSink ensures that we validate the input arguments.
+func TestNewHashChainSink(t *testing.T) {
+	t.Parallel()
+
+	storage := &logical.InmemStorage{}
+
+	_, err := NewHashChainSink(nil, storage, "", &recordingSink{})
+	require.EqualError(t, err, "audit.NewHashChainSink: cannot create a new hash chain sink with nil salter: invalid parameter")
+
+	_, err = NewHashChainSink(newStaticSalt(t), nil, "", &recordingSink{})
+	require.EqualError(t, err, "audit.NewHashChainSink: cannot create a new hash chain sink with nil storage: invalid parameter")
+
+	_, err = NewHashChainSink(newStaticSalt(t), storage, "", nil)
+	require.EqualError(t, err, "audit.NewHashChainSink: cannot create a new hash chain sink with nil sink: invalid parameter")
+
+	s, err := NewHashChainSink(newStaticSalt(t), storage, "", &recordingSink{})
+	require.NoError(t, err)
+	require.Equal(t, eventlogger.NodeTypeSink, s.Type())
+	require.NoError(t, s.Reopen())
+}
+
+// TestHashChainSink_Process ensures that entries are given consecutive
+// sequence numbers and chained to the entry before them, and that the chain
+// only advances when the wrapped sink succeeds.
+func TestHashChainSink_Process(t *testing.T) {
+	t.Parallel()
+
+	ss := newStaticSalt(t)
+	sink := &recordingSink{}
+	s, err := NewHashChainSink(ss, &logical.InmemStorage{}, "@cee: ", sink)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = s.Process(ctx, jsonEvent("@cee: {\"type\":\"request\"}\n"))
+	require.NoError(t, err)
+
+	sink.fail = true
+	_, err = s.Process(ctx, jsonEvent("@cee: {\"type\":\"lost\"}\n"))
+	require.Error(t, err)
+	sink.fail = false
+
+	_, err = s.Process(ctx, jsonEvent("@cee: {\"type\":\"response\"}\n"))
+	require.NoError(t, err)
+
+	require.Len(t, sink.entries, 2)
+
+	var previous string
+	for i, entry := range sink.entries {
+		require.True(t, bytes.HasPrefix(entry, []byte("@cee: ")))
+		require.True(t, bytes.HasSuffix(entry, []byte("\n")))
+
+		line := bytes.TrimSuffix(bytes.TrimPrefix(entry, []byte("@cee: ")), []byte("\n"))
+		sequenced, sequence, chain, err := SplitChainedEntry(line)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i+1), sequence)
+		require.Equal(t, ss.salt.GetIdentifiedHMAC(ChainInput(previous, sequenced)), chain)
+
+		previous = chain
+	}
+
+	require.Contains(t, string(sink.entries[0]), `{"type":"request","sequence":1,"chain":"hmac-sha256:`)
+	require.Contains(t, string(sink.entries[1]), `{"type":"response","sequence":2,"chain":"hmac-sha256:`)
+
+	_, err = s.Process(ctx, jsonEvent("not json"))
+	require.EqualError(t, err, "audit.(HashChainSink).Process: formatted event is not a JSON object: invalid parameter")
+
+	_, err = s.Process(ctx, &eventlogger.Event{Formatted: make(map[string][]byte)})
+	require.EqualError(t, err, `audit.(HashChainSink).Process: unable to retrieve event formatted as "json": invalid parameter`)
+}
+
+// readOnlyStorage is storage which rejects writes, as on a performance standby.
+type readOnlyStorage struct {
+	logical.InmemStorage
+}
+
+func (s *readOnlyStorage) Put(context.Context, *logical.StorageEntry) error {
+	return logical.ErrReadOnly
+}
+
+// TestHashChainSink_Persistence ensures that the head of the chain is kept in
+// memory, persisted at most once per interval and when the sink is closed, and
+// that a new sink using the same storage continues the chain rather than
+// starting a new one.
+func TestHashChainSink_Persistence(t *testing.T) {
+	t.Parallel()
+
+	ss := newStaticSalt(t)
+	storage := &logical.InmemStorage{}
+	sink := &recordingSink{}
+	ctx := context.Background()
+
+	head, err := ReadChainHead(ctx, storage)
+	require.NoError(t, err)
+	require.Nil(t, head)
+
+	s, err := NewHashChainSink(ss, storage, "", sink)
+	require.NoError(t, err)
+	head, err = s.Head(ctx)
+	require.NoError(t, err)
+	require.Nil(t, head)
+
+	_, err = s.Process(ctx, jsonEvent(`{"type":"request"}`))
+	require.NoError(t, err)
+	_, err = s.Process(ctx, jsonEvent(`{"type":"response"}`))
+	require.NoError(t, err)
+
+	_, _, first, err := SplitChainedEntry(bytes.TrimSuffix(sink.entries[0], []byte("\n")))
+	require.NoError(t, err)
+	_, _, chain, err := SplitChainedEntry(bytes.TrimSuffix(sink.entries[1], []byte("\n")))
+	require.NoError(t, err)
+
+	// The first entry is persisted straight away, while the second is only
+	// held in memory until the interval has passed.
+	head, err = ReadChainHead(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, &ChainHead{Sequence: 1, Chain: first}, head)
+
+	head, err = s.Head(ctx)
+	require.NoError(t, err)
+	require.Equal(t, &ChainHead{Sequence: 2, Chain: chain}, head)
+
+	require.NoError(t, s.Close(ctx))
+	head, err = ReadChainHead(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, &ChainHead{Sequence: 2, Chain: chain}, head)
+
+	// A sink created when the audit device is next set up continues from the
+	// persisted head.
+	s, err = NewHashChainSink(ss, storage, "", sink)
+	require.NoError(t, err)
+	s.persistInterval = 0
+	_, err = s.Process(ctx, jsonEvent(`{"type":"request"}`))
+	require.NoError(t, err)
+
+	sequenced, sequence, next, err := SplitChainedEntry(bytes.TrimSuffix(sink.entries[2], []byte("\n")))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), sequence)
+	require.Equal(t, ss.salt.GetIdentifiedHMAC(ChainInput(chain, sequenced)), next)
+
+	head, err = ReadChainHead(ctx, storage)
+	require.NoError(t, err)
+	require.Equal(t, &ChainHead{Sequence: 3, Chain: next}, head)
+}
+
+// TestHashChainSink_ReadOnly ensures that entries are still chained when the
+// storage view is read-only, with the head only kept in memory.
+func TestHashChainSink_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sink := &recordingSink{}
+
+	s, err := NewHashChainSink(newStaticSalt(t), &readOnlyStorage{}, "", sink)
+	require.NoError(t, err)
+	s.persistInterval = 0
+
+	for i := 0; i < 2; i++ {
+		_, err = s.Process(ctx, jsonEvent(`{"type":"request"}`))
+		require.NoError(t, err)
+	}
+	require.Len(t, sink.entries, 2)
+
+	head, err := s.Head(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), head.Sequence)
+
+	require.NoError(t, s.Close(ctx))
+}
+
+// TestSplitChainedEntry ensures that chained entries are split correctly, and
+// that entries without a chain or sequence are rejected.
+func TestSplitChainedEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		Line                 string
+		ExpectedEntry        string
+		ExpectedSequence     uint64
+		ExpectedChain        string
+		ExpectedErrorMessage string
+	}{
+		"chained": {
+			Line:             `{"type":"request","sequence":42,"chain":"hmac-sha256:abc"}`,
+			ExpectedEntry:    `{"type":"request","sequence":42}`,
+			ExpectedSequence: 42,
+			ExpectedChain:    "hmac-sha256:abc",
+		},
+		"no-chain": {
+			Line:                 `{"type":"request","sequence":42}`,
+			ExpectedErrorMessage: "entry has no chain",
+		},
+		"no-sequence": {
+			Line:                 `{"type":"request","chain":"hmac-sha256:abc"}`,
+			ExpectedErrorMessage: "entry has no sequence number",
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			entry, sequence, chain, err := SplitChainedEntry([]byte(tc.Line))
+			switch {
+			case tc.ExpectedErrorMessage != "":
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedEntry, string(entry))
+				require.Equal(t, tc.ExpectedSequence, sequence)
+				require.Equal(t, tc.ExpectedChain, chain)
+			}
+		})
+	}
+}
@@ -18,7 +18,8 @@ import (
 // and manually iterate over the supplied nodes calling Process on each.
 // Order of IDs in the NodeID slice determines the order they are processed.
 // (Audit) Event will be of RequestType (as opposed to ResponseType).
-// The last node must be a sink node (eventlogger.NodeTypeSink).
+// The last node must be a sink node (eventlogger.NodeTypeSink), unless the
+// event is filtered out by an earlier filter node (eventlogger.NodeTypeFilter).
 func ProcessManual(ctx context.Context, data *logical.LogInput, ids []eventlogger.NodeID, nodes map[eventlogger.NodeID]eventlogger.Node) error {
 	switch {
 	case data == nil:
@@ -60,6 +61,12 @@ func ProcessManual(ctx context.Context, data *logical.LogInput, ids []eventlogge
 			return fmt.Errorf("node not found: %v", id)
 		}
 
+		// Test messages aren't chained, as chaining requires the audit
+		// device's salt, which cannot be used while the device is set up.
+		if chainNode, ok := node.(*HashChainSink); ok && chainNode != nil {
+			node = chainNode.sink
+		}
+
 		switch node.Type() {
 		case eventlogger.NodeTypeFormatter:
 			// Use a temporary formatter node  which doesn't persist its salt anywhere.
@@ -74,6 +81,12 @@ func ProcessManual(ctx context.Context, data *logical.LogInput, ids []eventlogge
 			return err
 		}
 
+		// A filter node returns a nil event when the event has been filtered
+		// out, in which case there is nothing further to process.
+		if e == nil {
+			return nil
+		}
+
 		// Track the last node we have processed, as we should end with a sink.
 		lastSeen = node.Type()
 	}
@@ -35,6 +35,12 @@ func (s *SinkWrapper) Reopen() error {
 	return s.Sink.Reopen()
 }
 
+// Unwrap returns this SinkWrapper's sink field, allowing the broker to close it
+// if it supports being closed.
+func (s *SinkWrapper) Unwrap() eventlogger.Node {
+	return s.Sink
+}
+
 // Type simply wraps the Type method of this SinkWrapper's sink field without
 // doing any additional work.
 func (s *SinkWrapper) Type() eventlogger.NodeType {
@@ -24,6 +24,8 @@ const (
 const (
 	JSONFormat  format = "json"
 	JSONxFormat format = "jsonx"
+	CEFFormat   format = "cef"
+	OCSFFormat  format = "ocsf"
 )
 
 // version defines the version of audit events.
@@ -140,7 +142,8 @@ type FormatterConfig struct {
 	// This should only ever be used in a testing context
 	OmitTime bool
 
-	// The required/target format for the event (supported: JSONFormat and JSONxFormat).
+	// The required/target format for the event (supported: JSONFormat, JSONxFormat,
+	// CEFFormat and OCSFFormat).
 	RequiredFormat format
 }
 
@@ -165,6 +168,17 @@ type ResponseEntry struct {
 	Forwarded bool      `json:"forwarded,omitempty"`
 }
 
+// entryView is the subset of a RequestEntry or ResponseEntry which is mapped
+// onto formats with a fixed schema, such as CEF and OCSF.
+type entryView struct {
+	Time     string
+	Type     string
+	Auth     *Auth
+	Request  *Request
+	Response *Response
+	Error    string
+}
+
 type Request struct {
 	ID                            string                 `json:"id,omitempty"`
 	ClientID                      string                 `json:"client_id,omitempty"`
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	vaultVersion "github.com/hashicorp/vault/version"
+)
+
+var _ Writer = (*CEFWriter)(nil)
+
+const (
+	cefVersion = 0
+	cefVendor  = "HashiCorp"
+	cefProduct = "Vault"
+
+	// cefSeverityInfo and cefSeverityError are the severities of entries
+	// without and with an error respectively, on the CEF scale of 0-10.
+	cefSeverityInfo  = 3
+	cefSeverityError = 7
+)
+
+var (
+	// cefHeaderEscaper escapes the characters which are special within the
+	// pipe-delimited prefix of a CEF event.
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+
+	// cefExtensionEscaper escapes the characters which are special within the
+	// key=value extension of a CEF event.
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+// CEFWriter is a Writer implementation that structures data into the Common
+// Event Format (CEF).
+type CEFWriter struct {
+	Prefix string
+}
+
+func (f *CEFWriter) WriteRequest(w io.Writer, req *RequestEntry) error {
+	if req == nil {
+		return fmt.Errorf("request entry was nil, cannot encode")
+	}
+
+	return f.write(w, req)
+}
+
+func (f *CEFWriter) WriteResponse(w io.Writer, resp *ResponseEntry) error {
+	if resp == nil {
+		return fmt.Errorf("response entry was nil, cannot encode")
+	}
+
+	return f.write(w, resp)
+}
+
+func (f *CEFWriter) write(w io.Writer, entry any) error {
+	if len(f.Prefix) > 0 {
+		_, err := w.Write([]byte(f.Prefix))
+		if err != nil {
+			return err
+		}
+	}
+
+	cefBytes, err := encodeCEF(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(cefBytes)
+	return err
+}
+
+// encodeCEF maps a RequestEntry or ResponseEntry onto a single, newline
+// terminated, CEF event.
+func encodeCEF(entry any) ([]byte, error) {
+	v, err := newEntryView(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	severity := cefSeverityInfo
+	outcome := "success"
+	if v.Error != "" {
+		severity = cefSeverityError
+		outcome = "failure"
+	}
+
+	var operation, path string
+	if v.Request != nil {
+		operation = string(v.Request.Operation)
+		path = v.Request.Path
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:%d|%s|%s|%s|%s|%s|%d|",
+		cefVersion,
+		cefHeaderEscaper.Replace(cefVendor),
+		cefHeaderEscaper.Replace(cefProduct),
+		cefHeaderEscaper.Replace(vaultVersion.GetVersion().Version),
+		cefHeaderEscaper.Replace(v.Type),
+		cefHeaderEscaper.Replace(strings.TrimSpace(operation+" "+path)),
+		severity,
+	)
+
+	var ext []string
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefExtensionEscaper.Replace(value))
+		}
+	}
+	addCustom := func(n int, label, value string) {
+		if value != "" {
+			add("cs"+strconv.Itoa(n)+"Label", label)
+			add("cs"+strconv.Itoa(n), value)
+		}
+	}
+
+	if t, ok := v.timestamp(); ok {
+		add("rt", strconv.FormatInt(t.UnixMilli(), 10))
+	}
+	add("outcome", outcome)
+	add("reason", v.Error)
+
+	if v.Auth != nil {
+		add("suser", v.Auth.DisplayName)
+		add("suid", v.Auth.EntityID)
+		addCustom(4, "policies", strings.Join(v.Auth.Policies, ","))
+	}
+
+	if v.Request != nil {
+		add("externalId", v.Request.ID)
+		add("act", operation)
+		add("request", path)
+		add("src", v.Request.RemoteAddr)
+		if v.Request.RemotePort != 0 {
+			add("spt", strconv.Itoa(v.Request.RemotePort))
+		}
+		if v.Request.Namespace != nil {
+			addCustom(1, "namespace", v.Request.Namespace.Path)
+		}
+		addCustom(2, "mount_type", v.Request.MountType)
+		addCustom(3, "mount_point", v.Request.MountPoint)
+		addCustom(5, "client_token_accessor", v.Request.ClientTokenAccessor)
+	}
+
+	if v.Response != nil && v.Response.Secret != nil {
+		addCustom(6, "lease_id", v.Response.Secret.LeaseID)
+	}
+
+	b.WriteString(strings.Join(ext, " "))
+	b.WriteString("\n")
+
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	vaultVersion "github.com/hashicorp/vault/version"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCEFWriter_WriteRequest ensures that request entries are mapped onto CEF
+// events as expected.
+func TestCEFWriter_WriteRequest(t *testing.T) {
+	t.Parallel()
+
+	v := vaultVersion.GetVersion().Version
+
+	tests := map[string]struct {
+		Prefix   string
+		Entry    *RequestEntry
+		Expected string
+	}{
+		"basic": {
+			Entry: &RequestEntry{
+				Time: "2023-07-11T15:49:10.123Z",
+				Type: "request",
+				Auth: &Auth{
+					DisplayName: "token",
+					EntityID:    "entity",
+					Policies:    []string{"default", "kv"},
+				},
+				Request: &Request{
+					ID:                  "123",
+					Operation:           logical.UpdateOperation,
+					MountPoint:          "secret/",
+					MountType:           "kv",
+					ClientTokenAccessor: "hmac-sha256:abc",
+					Namespace:           &Namespace{ID: "root"},
+					Path:                "secret/foo",
+					RemoteAddr:          "127.0.0.1",
+					RemotePort:          1234,
+				},
+			},
+			Expected: fmt.Sprintf("CEF:0|HashiCorp|Vault|%s|request|update secret/foo|3|"+
+				"rt=1689090550123 outcome=success suser=token suid=entity cs4Label=policies cs4=default,kv "+
+				"externalId=123 act=update request=secret/foo src=127.0.0.1 spt=1234 "+
+				"cs2Label=mount_type cs2=kv cs3Label=mount_point cs3=secret/ "+
+				"cs5Label=client_token_accessor cs5=hmac-sha256:abc\n", v),
+		},
+		"error-escaped-with-prefix": {
+			Prefix: "prefix ",
+			Entry: &RequestEntry{
+				Type:  "request",
+				Error: "permission denied\nkey=value",
+				Request: &Request{
+					Operation: logical.ReadOperation,
+					Path:      `sys/a|b\c`,
+				},
+			},
+			Expected: fmt.Sprintf(`prefix CEF:0|HashiCorp|Vault|%s|request|read sys/a\|b\\c|7|`+
+				`outcome=failure reason=permission denied\nkey\=value act=read request=sys/a|b\\c`+"\n", v),
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			w := &CEFWriter{Prefix: tc.Prefix}
+			require.NoError(t, w.WriteRequest(&buf, tc.Entry))
+			require.Equal(t, tc.Expected, buf.String())
+		})
+	}
+}
+
+// TestCEFWriter_WriteResponse ensures that response entries are mapped onto
+// CEF events, including the lease ID of any secret.
+func TestCEFWriter_WriteResponse(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := &CEFWriter{}
+	err := w.WriteResponse(&buf, &ResponseEntry{
+		Type: "response",
+		Request: &Request{
+			Operation: logical.ReadOperation,
+			Path:      "database/creds/role",
+		},
+		Response: &Response{
+			Secret: &Secret{LeaseID: "database/creds/role/abc"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("CEF:0|HashiCorp|Vault|%s|response|read database/creds/role|3|"+
+		"outcome=success act=read request=database/creds/role cs6Label=lease_id cs6=database/creds/role/abc\n",
+		vaultVersion.GetVersion().Version), buf.String())
+
+	require.EqualError(t, w.WriteResponse(&buf, nil), "response entry was nil, cannot encode")
+}
@@ -0,0 +1,269 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	vaultVersion "github.com/hashicorp/vault/version"
+)
+
+var _ Writer = (*OCSFWriter)(nil)
+
+// OCSF API Activity class constants, see: https://schema.ocsf.io/classes/api_activity
+const (
+	ocsfSchemaVersion = "1.1.0"
+	ocsfCategoryUID   = 6
+	ocsfCategoryName  = "Application Activity"
+	ocsfClassUID      = 6003
+	ocsfClassName     = "API Activity"
+
+	ocsfActivityCreate = 1
+	ocsfActivityRead   = 2
+	ocsfActivityUpdate = 3
+	ocsfActivityDelete = 4
+	ocsfActivityOther  = 99
+
+	ocsfSeverityInformational = 1
+	ocsfSeverityMedium        = 3
+
+	ocsfStatusSuccess = 1
+	ocsfStatusFailure = 2
+)
+
+// ocsfAPIActivity is an event of the OCSF API Activity class.
+type ocsfAPIActivity struct {
+	ActivityID   int    `json:"activity_id"`
+	ActivityName string `json:"activity_name"`
+	CategoryUID  int    `json:"category_uid"`
+	CategoryName string `json:"category_name"`
+	ClassUID     int    `json:"class_uid"`
+	ClassName    string `json:"class_name"`
+	TypeUID      int    `json:"type_uid"`
+	Time         int64  `json:"time,omitempty"`
+	SeverityID   int    `json:"severity_id"`
+	Severity     string `json:"severity"`
+	StatusID     int    `json:"status_id"`
+	Status       string `json:"status"`
+	StatusDetail string `json:"status_detail,omitempty"`
+
+	Metadata    ocsfMetadata      `json:"metadata"`
+	Actor       ocsfActor         `json:"actor"`
+	API         ocsfAPI           `json:"api"`
+	SrcEndpoint *ocsfEndpoint     `json:"src_endpoint,omitempty"`
+	Resources   []ocsfResource    `json:"resources,omitempty"`
+	Unmapped    map[string]string `json:"unmapped,omitempty"`
+}
+
+type ocsfMetadata struct {
+	Version string      `json:"version"`
+	LogName string      `json:"log_name,omitempty"`
+	UID     string      `json:"uid,omitempty"`
+	Product ocsfProduct `json:"product"`
+}
+
+type ocsfProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+}
+
+type ocsfActor struct {
+	User    *ocsfUser    `json:"user,omitempty"`
+	Session *ocsfSession `json:"session,omitempty"`
+}
+
+type ocsfUser struct {
+	Name string `json:"name,omitempty"`
+	UID  string `json:"uid,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+type ocsfSession struct {
+	UID string `json:"uid,omitempty"`
+}
+
+type ocsfAPI struct {
+	Operation string        `json:"operation"`
+	Request   *ocsfRequest  `json:"request,omitempty"`
+	Response  *ocsfResponse `json:"response,omitempty"`
+	Service   *ocsfService  `json:"service,omitempty"`
+}
+
+type ocsfRequest struct {
+	UID string `json:"uid,omitempty"`
+}
+
+type ocsfResponse struct {
+	Error string `json:"error,omitempty"`
+}
+
+type ocsfService struct {
+	Name string `json:"name,omitempty"`
+}
+
+type ocsfEndpoint struct {
+	IP   string `json:"ip,omitempty"`
+	Port int    `json:"port,omitempty"`
+}
+
+type ocsfResource struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	UID  string `json:"uid,omitempty"`
+}
+
+// OCSFWriter is a Writer implementation that structures data into the Open
+// Cybersecurity Schema Framework (OCSF) API Activity class, encoded as JSON.
+type OCSFWriter struct {
+	Prefix string
+}
+
+func (f *OCSFWriter) WriteRequest(w io.Writer, req *RequestEntry) error {
+	if req == nil {
+		return fmt.Errorf("request entry was nil, cannot encode")
+	}
+
+	return f.write(w, req)
+}
+
+func (f *OCSFWriter) WriteResponse(w io.Writer, resp *ResponseEntry) error {
+	if resp == nil {
+		return fmt.Errorf("response entry was nil, cannot encode")
+	}
+
+	return f.write(w, resp)
+}
+
+func (f *OCSFWriter) write(w io.Writer, entry any) error {
+	if len(f.Prefix) > 0 {
+		_, err := w.Write([]byte(f.Prefix))
+		if err != nil {
+			return err
+		}
+	}
+
+	ocsfBytes, err := encodeOCSF(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(ocsfBytes)
+	return err
+}
+
+// encodeOCSF maps a RequestEntry or ResponseEntry onto an OCSF API Activity
+// event, encoded as newline terminated JSON.
+func encodeOCSF(entry any) ([]byte, error) {
+	v, err := newEntryView(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &ocsfAPIActivity{
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: ocsfCategoryName,
+		ClassUID:     ocsfClassUID,
+		ClassName:    ocsfClassName,
+		SeverityID:   ocsfSeverityInformational,
+		Severity:     "Informational",
+		StatusID:     ocsfStatusSuccess,
+		Status:       "Success",
+		Metadata: ocsfMetadata{
+			Version: ocsfSchemaVersion,
+			LogName: v.Type,
+			Product: ocsfProduct{
+				Name:       "Vault",
+				VendorName: "HashiCorp",
+				Version:    vaultVersion.GetVersion().Version,
+			},
+		},
+		Unmapped: make(map[string]string),
+	}
+
+	if v.Error != "" {
+		a.SeverityID = ocsfSeverityMedium
+		a.Severity = "Medium"
+		a.StatusID = ocsfStatusFailure
+		a.Status = "Failure"
+		a.StatusDetail = v.Error
+		a.API.Response = &ocsfResponse{Error: v.Error}
+	}
+
+	if t, ok := v.timestamp(); ok {
+		a.Time = t.UnixMilli()
+	}
+
+	if v.Auth != nil && (v.Auth.DisplayName != "" || v.Auth.EntityID != "") {
+		a.Actor.User = &ocsfUser{
+			Name: v.Auth.DisplayName,
+			UID:  v.Auth.EntityID,
+			Type: v.Auth.TokenType,
+		}
+	}
+
+	var operation logical.Operation
+	if v.Request != nil {
+		operation = v.Request.Operation
+
+		a.Metadata.UID = v.Request.ID
+		a.API.Operation = string(operation)
+		if v.Request.ID != "" {
+			a.API.Request = &ocsfRequest{UID: v.Request.ID}
+		}
+		if v.Request.MountType != "" {
+			a.API.Service = &ocsfService{Name: v.Request.MountType}
+		}
+		if v.Request.ClientTokenAccessor != "" {
+			a.Actor.Session = &ocsfSession{UID: v.Request.ClientTokenAccessor}
+		}
+		if v.Request.RemoteAddr != "" {
+			a.SrcEndpoint = &ocsfEndpoint{
+				IP:   v.Request.RemoteAddr,
+				Port: v.Request.RemotePort,
+			}
+		}
+		if v.Request.Path != "" {
+			a.Resources = []ocsfResource{{
+				Name: v.Request.Path,
+				Type: v.Request.MountType,
+				UID:  v.Request.MountAccessor,
+			}}
+		}
+		if v.Request.Namespace != nil && v.Request.Namespace.Path != "" {
+			a.Unmapped["namespace"] = v.Request.Namespace.Path
+		}
+		if v.Request.MountPoint != "" {
+			a.Unmapped["mount_point"] = v.Request.MountPoint
+		}
+	}
+
+	if v.Response != nil && v.Response.Secret != nil && v.Response.Secret.LeaseID != "" {
+		a.Unmapped["lease_id"] = v.Response.Secret.LeaseID
+	}
+
+	a.ActivityID, a.ActivityName = ocsfActivity(operation)
+	a.TypeUID = a.ClassUID*100 + a.ActivityID
+
+	return jsonutil.EncodeJSON(a)
+}
+
+// ocsfActivity maps a Vault operation onto an API Activity activity.
+func ocsfActivity(operation logical.Operation) (int, string) {
+	switch operation {
+	case logical.CreateOperation:
+		return ocsfActivityCreate, "Create"
+	case logical.ReadOperation, logical.ListOperation:
+		return ocsfActivityRead, "Read"
+	case logical.UpdateOperation, logical.PatchOperation:
+		return ocsfActivityUpdate, "Update"
+	case logical.DeleteOperation:
+		return ocsfActivityDelete, "Delete"
+	default:
+		return ocsfActivityOther, "Other"
+	}
+}
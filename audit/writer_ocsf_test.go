@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOCSFWriter_WriteRequest ensures that request entries are mapped onto the
+// OCSF API Activity class as expected.
+func TestOCSFWriter_WriteRequest(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := &OCSFWriter{}
+	err := w.WriteRequest(&buf, &RequestEntry{
+		Time: "2023-07-11T15:49:10.123Z",
+		Type: "request",
+		Auth: &Auth{
+			DisplayName: "token",
+			EntityID:    "entity",
+			TokenType:   "service",
+		},
+		Request: &Request{
+			ID:                  "123",
+			Operation:           logical.CreateOperation,
+			MountPoint:          "secret/",
+			MountType:           "kv",
+			MountAccessor:       "kv_123",
+			ClientTokenAccessor: "hmac-sha256:abc",
+			Namespace:           &Namespace{ID: "ns1", Path: "ns1/"},
+			Path:                "secret/foo",
+			RemoteAddr:          "127.0.0.1",
+			RemotePort:          1234,
+		},
+	})
+	require.NoError(t, err)
+
+	var a ocsfAPIActivity
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &a))
+
+	require.Equal(t, ocsfClassUID, a.ClassUID)
+	require.Equal(t, ocsfCategoryUID, a.CategoryUID)
+	require.Equal(t, ocsfActivityCreate, a.ActivityID)
+	require.Equal(t, 600301, a.TypeUID)
+	require.Equal(t, int64(1689090550123), a.Time)
+	require.Equal(t, ocsfStatusSuccess, a.StatusID)
+	require.Equal(t, "request", a.Metadata.LogName)
+	require.Equal(t, "123", a.Metadata.UID)
+	require.Equal(t, &ocsfUser{Name: "token", UID: "entity", Type: "service"}, a.Actor.User)
+	require.Equal(t, &ocsfSession{UID: "hmac-sha256:abc"}, a.Actor.Session)
+	require.Equal(t, "create", a.API.Operation)
+	require.Equal(t, &ocsfService{Name: "kv"}, a.API.Service)
+	require.Equal(t, &ocsfEndpoint{IP: "127.0.0.1", Port: 1234}, a.SrcEndpoint)
+	require.Equal(t, []ocsfResource{{Name: "secret/foo", Type: "kv", UID: "kv_123"}}, a.Resources)
+	require.Equal(t, map[string]string{"namespace": "ns1/", "mount_point": "secret/"}, a.Unmapped)
+	require.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+}
+
+// TestOCSFWriter_WriteResponse ensures that failed responses are mapped onto
+// the OCSF API Activity class as expected.
+func TestOCSFWriter_WriteResponse(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := &OCSFWriter{Prefix: "prefix"}
+	err := w.WriteResponse(&buf, &ResponseEntry{
+		Type:  "response",
+		Error: "permission denied",
+		Request: &Request{
+			Operation: logical.ListOperation,
+			Path:      "secret/",
+		},
+		Response: &Response{},
+	})
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("prefix{")))
+
+	var a ocsfAPIActivity
+	require.NoError(t, json.Unmarshal(bytes.TrimPrefix(buf.Bytes(), []byte("prefix")), &a))
+
+	require.Equal(t, ocsfActivityRead, a.ActivityID)
+	require.Equal(t, ocsfStatusFailure, a.StatusID)
+	require.Equal(t, "permission denied", a.StatusDetail)
+	require.Equal(t, &ocsfResponse{Error: "permission denied"}, a.API.Response)
+	require.Nil(t, a.Actor.User)
+	require.Zero(t, a.Time)
+
+	require.EqualError(t, w.WriteResponse(&buf, nil), "response entry was nil, cannot encode")
+}
+
+// TestOCSFActivity ensures operations are mapped onto the expected activities.
+func TestOCSFActivity(t *testing.T) {
+	t.Parallel()
+
+	tests := map[logical.Operation]int{
+		logical.CreateOperation:   ocsfActivityCreate,
+		logical.ReadOperation:     ocsfActivityRead,
+		logical.ListOperation:     ocsfActivityRead,
+		logical.UpdateOperation:   ocsfActivityUpdate,
+		logical.PatchOperation:    ocsfActivityUpdate,
+		logical.DeleteOperation:   ocsfActivityDelete,
+		logical.HelpOperation:     ocsfActivityOther,
+		logical.RollbackOperation: ocsfActivityOther,
+	}
+
+	for op, expected := range tests {
+		id, _ := ocsfActivity(op)
+		require.Equal(t, expected, id, op)
+	}
+}
@@ -12,10 +12,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/vault/audit"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/salt"
 	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAuditFile_fileModeNew(t *testing.T) {
@@ -162,6 +164,43 @@ func TestAuditFile_EventLogger_fileModeNew(t *testing.T) {
 	}
 }
 
+// TestAuditFile_EventLogger_filter verifies that the Factory function adds a
+// filter node to the pipeline when a filter is configured, and that filters
+// are rejected when the useEventLogger argument is set to false.
+func TestAuditFile_EventLogger_filter(t *testing.T) {
+	config := map[string]string{
+		"path":   "discard",
+		"filter": `mount_type == "kv" and operation != "read"`,
+	}
+
+	_, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config:     config,
+	}, false, nil)
+	require.EqualError(t, err, "cannot configure a filter when the event logger is disabled")
+
+	be, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config:     config,
+	}, true, nil)
+	require.NoError(t, err)
+
+	b, ok := be.(*Backend)
+	require.True(t, ok)
+	require.Len(t, b.nodeIDList, 3)
+	require.Equal(t, eventlogger.NodeTypeFilter, b.nodeMap[b.nodeIDList[0]].Type())
+
+	config["filter"] = "this is not valid"
+	_, err = Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config:     config,
+	}, true, nil)
+	require.ErrorContains(t, err, "error creating filter node")
+}
+
 func BenchmarkAuditFile_request(b *testing.B) {
 	config := map[string]string{
 		"path": "/dev/null",
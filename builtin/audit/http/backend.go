@@ -0,0 +1,379 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-rootcerts"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/internal/observability/event"
+	"github.com/hashicorp/vault/sdk/helper/salt"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool, headersConfig audit.HeaderFormatter) (audit.Backend, error) {
+	if conf.SaltConfig == nil {
+		return nil, fmt.Errorf("nil salt config")
+	}
+	if conf.SaltView == nil {
+		return nil, fmt.Errorf("nil salt view")
+	}
+
+	if err := audit.ValidateFilterConfig(conf.Config, useEventLogger); err != nil {
+		return nil, err
+	}
+
+	address, ok := conf.Config["address"]
+	if !ok {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	var cfgOpts []audit.Option
+
+	if format, ok := conf.Config["format"]; ok {
+		cfgOpts = append(cfgOpts, audit.WithFormat(format))
+	}
+
+	// Check if hashing of accessor is disabled
+	if hmacAccessorRaw, ok := conf.Config["hmac_accessor"]; ok {
+		v, err := strconv.ParseBool(hmacAccessorRaw)
+		if err != nil {
+			return nil, err
+		}
+		cfgOpts = append(cfgOpts, audit.WithHMACAccessor(v))
+	}
+
+	// Check if raw logging is enabled
+	if raw, ok := conf.Config["log_raw"]; ok {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, err
+		}
+		cfgOpts = append(cfgOpts, audit.WithRaw(v))
+	}
+
+	if elideListResponsesRaw, ok := conf.Config["elide_list_responses"]; ok {
+		v, err := strconv.ParseBool(elideListResponsesRaw)
+		if err != nil {
+			return nil, err
+		}
+		cfgOpts = append(cfgOpts, audit.WithElision(v))
+	}
+
+	// Check if hash chaining is enabled
+	var hashChain bool
+	if hashChainRaw, ok := conf.Config["hash_chain"]; ok {
+		v, err := strconv.ParseBool(hashChainRaw)
+		if err != nil {
+			return nil, err
+		}
+		hashChain = v
+	}
+
+	cfg, err := audit.NewFormatterConfig(cfgOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if hashChain && (!useEventLogger || cfg.RequiredFormat != audit.JSONFormat) {
+		return nil, fmt.Errorf("hash_chain requires the json format and the event logger to be enabled")
+	}
+
+	sinkOpts, err := sinkOptions(conf.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	sink, err := event.NewHTTPSink(cfg.RequiredFormat.String(), address, sinkOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating http sink: %w", err)
+	}
+
+	b := &Backend{
+		saltConfig:   conf.SaltConfig,
+		saltView:     conf.SaltView,
+		formatConfig: cfg,
+
+		sink: sink,
+	}
+
+	// Configure the formatter for either case.
+	f, err := audit.NewEntryFormatter(b.formatConfig, b, audit.WithHeaderFormatter(headersConfig), audit.WithPrefix(conf.Config["prefix"]))
+	if err != nil {
+		return nil, fmt.Errorf("error creating formatter: %w", err)
+	}
+	var w audit.Writer
+	switch b.formatConfig.RequiredFormat {
+	case audit.JSONFormat:
+		w = &audit.JSONWriter{Prefix: conf.Config["prefix"]}
+	case audit.CEFFormat:
+		w = &audit.CEFWriter{Prefix: conf.Config["prefix"]}
+	case audit.OCSFFormat:
+		w = &audit.OCSFWriter{Prefix: conf.Config["prefix"]}
+	}
+
+	fw, err := audit.NewEntryFormatterWriter(b.formatConfig, f, w)
+	if err != nil {
+		return nil, fmt.Errorf("error creating formatter writer: %w", err)
+	}
+
+	b.formatter = fw
+
+	if useEventLogger {
+		b.nodeIDList = []eventlogger.NodeID{}
+		b.nodeMap = make(map[eventlogger.NodeID]eventlogger.Node)
+
+		if err := audit.AddFilterNode(conf.Config, &b.nodeIDList, b.nodeMap); err != nil {
+			return nil, err
+		}
+
+		formatterNodeID, err := event.GenerateNodeID()
+		if err != nil {
+			return nil, fmt.Errorf("error generating random NodeID for formatter node: %w", err)
+		}
+		b.nodeIDList = append(b.nodeIDList, formatterNodeID)
+		b.nodeMap[formatterNodeID] = f
+
+		var sinkNode eventlogger.Node = &audit.SinkWrapper{Name: conf.MountPath, Sink: sink}
+		if hashChain {
+			sinkNode, err = audit.NewHashChainSink(b, conf.SaltView, conf.Config["prefix"], sinkNode)
+			if err != nil {
+				return nil, fmt.Errorf("error creating hash chain sink node: %w", err)
+			}
+		}
+
+		sinkNodeID, err := event.GenerateNodeID()
+		if err != nil {
+			return nil, fmt.Errorf("error generating random NodeID for sink node: %w", err)
+		}
+		b.nodeIDList = append(b.nodeIDList, sinkNodeID)
+		b.nodeMap[sinkNodeID] = sinkNode
+	}
+
+	return b, nil
+}
+
+// sinkOptions translates the audit device configuration into the options
+// understood by the HTTP sink.
+func sinkOptions(config map[string]string) ([]event.Option, error) {
+	var opts []event.Option
+
+	if headersRaw, ok := config["headers"]; ok && headersRaw != "" {
+		headers := make(map[string]string)
+		if err := json.Unmarshal([]byte(headersRaw), &headers); err != nil {
+			return nil, fmt.Errorf("unable to parse headers, must be a JSON object of strings: %w", err)
+		}
+		opts = append(opts, event.WithHeaders(headers))
+	}
+
+	timeout, ok := config["request_timeout"]
+	if !ok {
+		timeout = "10s"
+	}
+	opts = append(opts, event.WithMaxDuration(timeout))
+
+	tlsConfig, err := tlsConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, event.WithTLSConfig(tlsConfig))
+	}
+
+	optFuncs := map[string]func(string) event.Option{
+		"batch_size":     event.WithBatchSize,
+		"batch_interval": event.WithBatchInterval,
+		"max_retries":    event.WithMaxRetries,
+		"retry_wait_min": event.WithRetryWaitMin,
+		"retry_wait_max": event.WithRetryWaitMax,
+		"spool_path":     event.WithSpoolPath,
+		"spool_max_size": event.WithSpoolMaxSize,
+		"spool_mode":     event.WithFileMode,
+	}
+	for key, optFunc := range optFuncs {
+		if v, ok := config[key]; ok {
+			opts = append(opts, optFunc(v))
+		}
+	}
+
+	return opts, nil
+}
+
+// tlsConfig builds the TLS configuration used to connect to the endpoint, or
+// returns nil when no TLS settings have been supplied.
+func tlsConfig(config map[string]string) (*tls.Config, error) {
+	caCert := config["tls_ca_cert"]
+	clientCert := config["tls_client_cert"]
+	clientKey := config["tls_client_key"]
+	serverName := config["tls_server_name"]
+	skipVerifyRaw, hasSkipVerify := config["tls_skip_verify"]
+
+	if caCert == "" && clientCert == "" && clientKey == "" && serverName == "" && !hasSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if hasSkipVerify {
+		v, err := strconv.ParseBool(skipVerifyRaw)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.InsecureSkipVerify = v
+	}
+
+	if caCert != "" {
+		if err := rootcerts.ConfigureTLS(tlsConfig, &rootcerts.Config{CAFile: caCert}); err != nil {
+			return nil, fmt.Errorf("error loading tls_ca_cert: %w", err)
+		}
+	}
+
+	switch {
+	case clientCert != "" && clientKey != "":
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case clientCert != "" || clientKey != "":
+		return nil, fmt.Errorf("both tls_client_cert and tls_client_key must be provided")
+	}
+
+	return tlsConfig, nil
+}
+
+// Backend is the audit backend for the HTTP audit transport.
+type Backend struct {
+	sink *event.HTTPSink
+
+	formatter    *audit.EntryFormatterWriter
+	formatConfig audit.FormatterConfig
+
+	saltMutex  sync.RWMutex
+	salt       *salt.Salt
+	saltConfig *salt.Config
+	saltView   logical.Storage
+
+	nodeIDList []eventlogger.NodeID
+	nodeMap    map[eventlogger.NodeID]eventlogger.Node
+}
+
+var (
+	_ audit.Backend         = (*Backend)(nil)
+	_ audit.ChainHeadReader = (*Backend)(nil)
+)
+
+func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.sink.Enqueue(ctx, buf.Bytes())
+}
+
+func (b *Backend) LogResponse(ctx context.Context, in *logical.LogInput) error {
+	var buf bytes.Buffer
+	if err := b.formatter.FormatAndWriteResponse(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.sink.Enqueue(ctx, buf.Bytes())
+}
+
+func (b *Backend) LogTestMessage(ctx context.Context, in *logical.LogInput, config map[string]string) error {
+	// Event logger behavior - manually Process each node
+	if len(b.nodeIDList) > 0 {
+		return audit.ProcessManual(ctx, in, b.nodeIDList, b.nodeMap)
+	}
+
+	// Old behavior
+	var buf bytes.Buffer
+
+	temporaryFormatter, err := audit.NewTemporaryFormatter(config["format"], config["prefix"])
+	if err != nil {
+		return err
+	}
+
+	if err = temporaryFormatter.FormatAndWriteRequest(ctx, &buf, in); err != nil {
+		return err
+	}
+
+	return b.sink.Enqueue(ctx, buf.Bytes())
+}
+
+// Reload flushes any events waiting to be batched; the HTTP transport holds
+// no long-lived connections which need to be re-established.
+func (b *Backend) Reload(_ context.Context) error {
+	b.sink.Flush()
+
+	return nil
+}
+
+// Close delivers any events waiting to be batched, and stops the backend
+// accepting new events.
+func (b *Backend) Close(ctx context.Context) error {
+	return b.sink.Close(ctx)
+}
+
+func (b *Backend) Salt(ctx context.Context) (*salt.Salt, error) {
+	b.saltMutex.RLock()
+	if b.salt != nil {
+		defer b.saltMutex.RUnlock()
+		return b.salt, nil
+	}
+	b.saltMutex.RUnlock()
+	b.saltMutex.Lock()
+	defer b.saltMutex.Unlock()
+	if b.salt != nil {
+		return b.salt, nil
+	}
+	salt, err := salt.NewSalt(ctx, b.saltView, b.saltConfig)
+	if err != nil {
+		return nil, err
+	}
+	b.salt = salt
+	return salt, nil
+}
+
+func (b *Backend) Invalidate(_ context.Context) {
+	b.saltMutex.Lock()
+	defer b.saltMutex.Unlock()
+	b.salt = nil
+}
+
+// ChainHead returns the head of the hash chain of the backend, as required by
+// the audit.ChainHeadReader interface.
+func (b *Backend) ChainHead(ctx context.Context) (*audit.ChainHead, error) {
+	return audit.NodesChainHead(ctx, b.nodeMap, b.saltView)
+}
+
+// RegisterNodesAndPipeline registers the nodes and a pipeline as required by
+// the audit.Backend interface.
+func (b *Backend) RegisterNodesAndPipeline(broker *eventlogger.Broker, name string) error {
+	for id, node := range b.nodeMap {
+		if err := broker.RegisterNode(id, node); err != nil {
+			return err
+		}
+	}
+
+	pipeline := eventlogger.Pipeline{
+		PipelineID: eventlogger.PipelineID(name),
+		EventType:  eventlogger.EventType("audit"),
+		NodeIDs:    b.nodeIDList,
+	}
+
+	return broker.RegisterPipeline(pipeline)
+}
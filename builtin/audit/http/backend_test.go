@@ -0,0 +1,141 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/salt"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAuditHTTP_Factory verifies that the Factory function validates the
+// device configuration.
+func TestAuditHTTP_Factory(t *testing.T) {
+	tests := map[string]struct {
+		config         map[string]string
+		useEventLogger bool
+		expectedError  string
+	}{
+		"no-address": {
+			config:        map[string]string{},
+			expectedError: "address is required",
+		},
+		"jsonx": {
+			config:        map[string]string{"address": "https://localhost", "format": "jsonx"},
+			expectedError: "error creating http sink: event.NewHTTPSink: the jsonx format is not supported: invalid parameter",
+		},
+		"bad-headers": {
+			config:        map[string]string{"address": "https://localhost", "headers": "not json"},
+			expectedError: "unable to parse headers, must be a JSON object of strings: invalid character 'o' in literal null (expecting 'u')",
+		},
+		"client-cert-without-key": {
+			config:        map[string]string{"address": "https://localhost", "tls_client_cert": "cert.pem"},
+			expectedError: "both tls_client_cert and tls_client_key must be provided",
+		},
+		"hash-chain-without-event-logger": {
+			config:        map[string]string{"address": "https://localhost", "hash_chain": "true"},
+			expectedError: "hash_chain requires the json format and the event logger to be enabled",
+		},
+		"happy": {
+			config:         map[string]string{"address": "https://localhost", "format": "cef", "batch_size": "10"},
+			useEventLogger: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			be, err := Factory(context.Background(), &audit.BackendConfig{
+				SaltConfig: &salt.Config{},
+				SaltView:   &logical.InmemStorage{},
+				Config:     tc.config,
+			}, tc.useEventLogger, nil)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, be.(*Backend).Close(context.Background()))
+		})
+	}
+}
+
+// TestAuditHTTP_EventLogger_filter verifies that the Factory function adds a
+// filter node to the pipeline when a filter is configured.
+func TestAuditHTTP_EventLogger_filter(t *testing.T) {
+	be, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config: map[string]string{
+			"address": "https://localhost",
+			"filter":  `mount_type == "kv"`,
+		},
+	}, true, nil)
+	require.NoError(t, err)
+
+	b, ok := be.(*Backend)
+	require.True(t, ok)
+	require.Len(t, b.nodeIDList, 3)
+	require.Equal(t, eventlogger.NodeTypeFilter, b.nodeMap[b.nodeIDList[0]].Type())
+	require.Equal(t, eventlogger.NodeTypeSink, b.nodeMap[b.nodeIDList[2]].Type())
+}
+
+// TestAuditHTTP_LogRequest verifies that audit entries are delivered to the
+// endpoint with the configured headers.
+func TestAuditHTTP_LogRequest(t *testing.T) {
+	var lock sync.Mutex
+	var bodies []string
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		lock.Lock()
+		defer lock.Unlock()
+		bodies = append(bodies, string(body))
+		headers = append(headers, r.Header.Clone())
+	}))
+	defer server.Close()
+
+	be, err := Factory(context.Background(), &audit.BackendConfig{
+		SaltConfig: &salt.Config{},
+		SaltView:   &logical.InmemStorage{},
+		Config: map[string]string{
+			"address": server.URL,
+			"headers": `{"Authorization": "Bearer foo"}`,
+		},
+	}, false, nil)
+	require.NoError(t, err)
+
+	in := &logical.LogInput{
+		Request: &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "secret/foo",
+		},
+	}
+	ctx := namespace.RootContext(context.Background())
+	require.NoError(t, be.LogRequest(ctx, in))
+	require.NoError(t, be.(*Backend).Close(ctx))
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Len(t, bodies, 1)
+	require.Equal(t, "Bearer foo", headers[0].Get("Authorization"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(bodies[0])), &entry))
+	require.Equal(t, "request", entry["type"])
+	require.Equal(t, "secret/foo", entry["request"].(map[string]interface{})["path"])
+}
@@ -30,6 +30,10 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		return nil, fmt.Errorf("nil salt view")
 	}
 
+	if err := audit.ValidateFilterConfig(conf.Config, useEventLogger); err != nil {
+		return nil, err
+	}
+
 	address, ok := conf.Config["address"]
 	if !ok {
 		return nil, fmt.Errorf("address is required")
@@ -80,11 +84,25 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		cfgOpts = append(cfgOpts, audit.WithElision(v))
 	}
 
+	// Check if hash chaining is enabled
+	var hashChain bool
+	if hashChainRaw, ok := conf.Config["hash_chain"]; ok {
+		v, err := strconv.ParseBool(hashChainRaw)
+		if err != nil {
+			return nil, err
+		}
+		hashChain = v
+	}
+
 	cfg, err := audit.NewFormatterConfig(cfgOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if hashChain && (!useEventLogger || cfg.RequiredFormat != audit.JSONFormat) {
+		return nil, fmt.Errorf("hash_chain requires the json format and the event logger to be enabled")
+	}
+
 	b := &Backend{
 		saltConfig:   conf.SaltConfig,
 		saltView:     conf.SaltView,
@@ -106,6 +124,10 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		w = &audit.JSONWriter{Prefix: conf.Config["prefix"]}
 	case audit.JSONxFormat:
 		w = &audit.JSONxWriter{Prefix: conf.Config["prefix"]}
+	case audit.CEFFormat:
+		w = &audit.CEFWriter{Prefix: conf.Config["prefix"]}
+	case audit.OCSFFormat:
+		w = &audit.OCSFWriter{Prefix: conf.Config["prefix"]}
 	}
 
 	fw, err := audit.NewEntryFormatterWriter(b.formatConfig, f, w)
@@ -126,26 +148,38 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 			opts = append(opts, event.WithMaxDuration(writeDeadline))
 		}
 
-		b.nodeIDList = make([]eventlogger.NodeID, 2)
+		b.nodeIDList = []eventlogger.NodeID{}
 		b.nodeMap = make(map[eventlogger.NodeID]eventlogger.Node)
 
+		if err := audit.AddFilterNode(conf.Config, &b.nodeIDList, b.nodeMap); err != nil {
+			return nil, err
+		}
+
 		formatterNodeID, err := event.GenerateNodeID()
 		if err != nil {
 			return nil, fmt.Errorf("error generating random NodeID for formatter node: %w", err)
 		}
-		b.nodeIDList[0] = formatterNodeID
+		b.nodeIDList = append(b.nodeIDList, formatterNodeID)
 		b.nodeMap[formatterNodeID] = f
 
 		n, err := event.NewSocketSink(b.formatConfig.RequiredFormat.String(), address, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("error creating socket sink node: %w", err)
 		}
-		sinkNode := &audit.SinkWrapper{Name: conf.MountPath, Sink: n}
+		var sinkNode eventlogger.Node = &audit.SinkWrapper{Name: conf.MountPath, Sink: n}
+		if hashChain {
+			// The socket formatter node doesn't apply the prefix.
+			sinkNode, err = audit.NewHashChainSink(b, conf.SaltView, "", sinkNode)
+			if err != nil {
+				return nil, fmt.Errorf("error creating hash chain sink node: %w", err)
+			}
+		}
+
 		sinkNodeID, err := event.GenerateNodeID()
 		if err != nil {
 			return nil, fmt.Errorf("error generating random NodeID for sink node: %w", err)
 		}
-		b.nodeIDList[1] = sinkNodeID
+		b.nodeIDList = append(b.nodeIDList, sinkNodeID)
 		b.nodeMap[sinkNodeID] = sinkNode
 	}
 
@@ -174,7 +208,10 @@ type Backend struct {
 	nodeMap    map[eventlogger.NodeID]eventlogger.Node
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend         = (*Backend)(nil)
+	_ audit.ChainHeadReader = (*Backend)(nil)
+)
 
 func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 	var buf bytes.Buffer
@@ -332,6 +369,12 @@ func (b *Backend) Invalidate(_ context.Context) {
 	b.salt = nil
 }
 
+// ChainHead returns the head of the hash chain of the backend, as required by
+// the audit.ChainHeadReader interface.
+func (b *Backend) ChainHead(ctx context.Context) (*audit.ChainHead, error) {
+	return audit.NodesChainHead(ctx, b.nodeMap, b.saltView)
+}
+
 // RegisterNodesAndPipeline registers the nodes and a pipeline as required by
 // the audit.Backend interface.
 func (b *Backend) RegisterNodesAndPipeline(broker *eventlogger.Broker, name string) error {
@@ -27,6 +27,10 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		return nil, fmt.Errorf("nil salt view")
 	}
 
+	if err := audit.ValidateFilterConfig(conf.Config, useEventLogger); err != nil {
+		return nil, err
+	}
+
 	// Get facility or default to AUTH
 	facility, ok := conf.Config["facility"]
 	if !ok {
@@ -71,11 +75,25 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		cfgOpts = append(cfgOpts, audit.WithElision(v))
 	}
 
+	// Check if hash chaining is enabled
+	var hashChain bool
+	if hashChainRaw, ok := conf.Config["hash_chain"]; ok {
+		v, err := strconv.ParseBool(hashChainRaw)
+		if err != nil {
+			return nil, err
+		}
+		hashChain = v
+	}
+
 	cfg, err := audit.NewFormatterConfig(cfgOpts...)
 	if err != nil {
 		return nil, err
 	}
 
+	if hashChain && (!useEventLogger || cfg.RequiredFormat != audit.JSONFormat) {
+		return nil, fmt.Errorf("hash_chain requires the json format and the event logger to be enabled")
+	}
+
 	// Get the logger
 	logger, err := gsyslog.NewLogger(gsyslog.LOG_INFO, facility, tag)
 	if err != nil {
@@ -101,6 +119,10 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 		w = &audit.JSONWriter{Prefix: conf.Config["prefix"]}
 	case audit.JSONxFormat:
 		w = &audit.JSONxWriter{Prefix: conf.Config["prefix"]}
+	case audit.CEFFormat:
+		w = &audit.CEFWriter{Prefix: conf.Config["prefix"]}
+	case audit.OCSFFormat:
+		w = &audit.OCSFWriter{Prefix: conf.Config["prefix"]}
 	}
 
 	fw, err := audit.NewEntryFormatterWriter(b.formatConfig, f, w)
@@ -122,27 +144,38 @@ func Factory(ctx context.Context, conf *audit.BackendConfig, useEventLogger bool
 			opts = append(opts, event.WithTag(tag))
 		}
 
-		b.nodeIDList = make([]eventlogger.NodeID, 2)
+		b.nodeIDList = []eventlogger.NodeID{}
 		b.nodeMap = make(map[eventlogger.NodeID]eventlogger.Node)
 
+		if err := audit.AddFilterNode(conf.Config, &b.nodeIDList, b.nodeMap); err != nil {
+			return nil, err
+		}
+
 		formatterNodeID, err := event.GenerateNodeID()
 		if err != nil {
 			return nil, fmt.Errorf("error generating random NodeID for formatter node: %w", err)
 		}
-		b.nodeIDList[0] = formatterNodeID
+		b.nodeIDList = append(b.nodeIDList, formatterNodeID)
 		b.nodeMap[formatterNodeID] = f
 
 		n, err := event.NewSyslogSink(b.formatConfig.RequiredFormat.String(), opts...)
 		if err != nil {
 			return nil, fmt.Errorf("error creating syslog sink node: %w", err)
 		}
-		sinkNode := &audit.SinkWrapper{Name: conf.MountPath, Sink: n}
+		var sinkNode eventlogger.Node = &audit.SinkWrapper{Name: conf.MountPath, Sink: n}
+
+		if hashChain {
+			sinkNode, err = audit.NewHashChainSink(b, conf.SaltView, conf.Config["prefix"], sinkNode)
+			if err != nil {
+				return nil, fmt.Errorf("error creating hash chain sink node: %w", err)
+			}
+		}
 
 		sinkNodeID, err := event.GenerateNodeID()
 		if err != nil {
 			return nil, fmt.Errorf("error generating random NodeID for sink node: %w", err)
 		}
-		b.nodeIDList[1] = sinkNodeID
+		b.nodeIDList = append(b.nodeIDList, sinkNodeID)
 		b.nodeMap[sinkNodeID] = sinkNode
 	}
 	return b, nil
@@ -164,7 +197,10 @@ type Backend struct {
 	nodeMap    map[eventlogger.NodeID]eventlogger.Node
 }
 
-var _ audit.Backend = (*Backend)(nil)
+var (
+	_ audit.Backend         = (*Backend)(nil)
+	_ audit.ChainHeadReader = (*Backend)(nil)
+)
 
 func (b *Backend) LogRequest(ctx context.Context, in *logical.LogInput) error {
 	var buf bytes.Buffer
@@ -241,6 +277,12 @@ func (b *Backend) Invalidate(_ context.Context) {
 	b.salt = nil
 }
 
+// ChainHead returns the head of the hash chain of the backend, as required by
+// the audit.ChainHeadReader interface.
+func (b *Backend) ChainHead(ctx context.Context) (*audit.ChainHead, error) {
+	return audit.NodesChainHead(ctx, b.nodeMap, b.saltView)
+}
+
 // RegisterNodesAndPipeline registers the nodes and a pipeline as required by
 // the audit.Backend interface.
 func (b *Backend) RegisterNodesAndPipeline(broker *eventlogger.Broker, name string) error {
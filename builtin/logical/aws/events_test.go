@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/hashicorp/go-secure-stdlib/awsutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAWS_Events ensures that rotating static credentials sends an event which
+// identifies the new access key without including its secret, and that the
+// initial credentials of a new static role are not reported as a rotation.
+func TestAWS_Events(t *testing.T) {
+	ctx := context.Background()
+
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	miam, err := awsutil.NewMockIAM(
+		awsutil.WithGetUserOutput(&iam.GetUserOutput{User: &iam.User{UserName: aws.String("jane-doe"), UserId: aws.String("unique-id")}}),
+		awsutil.WithListAccessKeysOutput(&iam.ListAccessKeysOutput{
+			AccessKeyMetadata: []*iam.AccessKeyMetadata{},
+			IsTruncated:       aws.Bool(false),
+		}),
+		awsutil.WithCreateAccessKeyOutput(&iam.CreateAccessKeyOutput{
+			AccessKey: &iam.AccessKey{
+				AccessKeyId:     aws.String("access-key-id"),
+				SecretAccessKey: aws.String("secret-access-key"),
+				UserName:        aws.String("jane-doe"),
+			},
+		}),
+	)(nil)
+	require.NoError(t, err)
+
+	b := Backend(config)
+	b.iamClient = miam
+	require.NoError(t, b.Setup(ctx, config))
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "static-roles/test",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"username":        "jane-doe",
+			"rotation_period": "24h",
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, resp.IsError(), "%v", resp)
+	require.Empty(t, events.Events(), "creating a static role is not a rotation")
+
+	// Make the role due for rotation.
+	item, err := b.credRotationQueue.PopByKey("test")
+	require.NoError(t, err)
+	item.Priority = time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, b.credRotationQueue.Push(item))
+
+	rotated, err := b.rotateCredential(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.True(t, rotated)
+
+	require.Len(t, events.Events(), 1)
+	e := events.Events()[0]
+	require.Equal(t, "aws/static-creds-rotate", e.EventType)
+
+	fields := e.Event.Metadata.AsMap()
+	require.Equal(t, "static-creds-rotate", fields[logical.EventMetadataOperation])
+	require.Equal(t, "static-creds/test", fields[logical.EventMetadataDataPath])
+	require.Equal(t, "true", fields[logical.EventMetadataModified])
+	require.Equal(t, "test", fields["name"])
+	require.Equal(t, "jane-doe", fields["username"])
+	require.Equal(t, "access-key-id", fields["access_key_id"])
+	for _, value := range fields {
+		require.NotEqual(t, "secret-access-key", value)
+	}
+}
@@ -119,6 +119,8 @@ func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.R
 		return nil, fmt.Errorf("error deleting old access key: %w", err)
 	}
 
+	b.SendOperationEvent(ctx, "aws", "root-rotate", "", true, "access_key_id", config.AccessKey)
+
 	return &logical.Response{
 		Data: map[string]interface{}{
 			"access_key": config.AccessKey,
@@ -199,7 +199,7 @@ func (b *backend) pathStaticRolesWrite(ctx context.Context, req *logical.Request
 		return nil, fmt.Errorf("unable to verify if credentials already exist for role %q: %w", config.Name, err)
 	}
 	if existingCreds == nil {
-		err := b.createCredential(ctx, req.Storage, config, false)
+		_, err := b.createCredential(ctx, req.Storage, config, false)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create new credentials for role %q: %w", config.Name, err)
 		}
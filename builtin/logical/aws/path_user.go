@@ -139,9 +139,10 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		}
 	}
 
+	var resp *logical.Response
 	switch credentialType {
 	case iamUserCred:
-		return b.secretAccessKeysCreate(ctx, req.Storage, req.DisplayName, roleName, role)
+		resp, err = b.secretAccessKeysCreate(ctx, req.Storage, req.DisplayName, roleName, role)
 	case assumedRoleCred:
 		switch {
 		case roleArn == "":
@@ -152,12 +153,22 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		case !strutil.StrListContains(role.RoleArns, roleArn):
 			return logical.ErrorResponse(fmt.Sprintf("role_arn %q not in allowed role arns for Vault role %q", roleArn, roleName)), nil
 		}
-		return b.assumeRole(ctx, req.Storage, req.DisplayName, roleName, roleArn, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl, roleSessionName)
+		resp, err = b.assumeRole(ctx, req.Storage, req.DisplayName, roleName, roleArn, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl, roleSessionName)
 	case federationTokenCred:
-		return b.getFederationToken(ctx, req.Storage, req.DisplayName, roleName, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl)
+		resp, err = b.getFederationToken(ctx, req.Storage, req.DisplayName, roleName, role.PolicyDocument, role.PolicyArns, role.IAMGroups, ttl)
 	default:
 		return logical.ErrorResponse(fmt.Sprintf("unknown credential_type: %q", credentialType)), nil
 	}
+	if err != nil || resp.IsError() {
+		return resp, err
+	}
+
+	b.SendOperationEvent(ctx, "aws", "creds-create", "", false,
+		"name", roleName,
+		"credential_type", credentialType,
+	)
+
+	return resp, nil
 }
 
 func (b *backend) pathUserRollback(ctx context.Context, req *logical.Request, _kind string, data interface{}) error {
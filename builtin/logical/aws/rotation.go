@@ -60,11 +60,17 @@ func (b *backend) rotateCredential(ctx context.Context, storage logical.Storage)
 
 	cfg := item.Value.(staticRoleEntry)
 
-	err = b.createCredential(ctx, storage, cfg, true)
+	accessKeyID, err := b.createCredential(ctx, storage, cfg, true)
 	if err != nil {
 		return false, err
 	}
 
+	b.SendOperationEvent(ctx, "aws", "static-creds-rotate", "static-creds/"+cfg.Name, true,
+		"name", cfg.Name,
+		"username", cfg.Username,
+		"access_key_id", accessKeyID,
+	)
+
 	// set new priority and re-queue
 	item.Priority = time.Now().Add(cfg.RotationPeriod).Unix()
 	err = b.credRotationQueue.Push(item)
@@ -75,11 +81,12 @@ func (b *backend) rotateCredential(ctx context.Context, storage logical.Storage)
 	return true, nil
 }
 
-// createCredential will create a new iam credential, deleting the oldest one if necessary.
-func (b *backend) createCredential(ctx context.Context, storage logical.Storage, cfg staticRoleEntry, shouldLockStorage bool) error {
+// createCredential will create a new iam credential, deleting the oldest one if necessary. It returns the
+// ID of the new access key.
+func (b *backend) createCredential(ctx context.Context, storage logical.Storage, cfg staticRoleEntry, shouldLockStorage bool) (string, error) {
 	iamClient, err := b.clientIAM(ctx, storage)
 	if err != nil {
-		return fmt.Errorf("unable to get the AWS IAM client: %w", err)
+		return "", fmt.Errorf("unable to get the AWS IAM client: %w", err)
 	}
 
 	// IAM users can have a most 2 sets of keys at a time.
@@ -89,14 +96,14 @@ func (b *backend) createCredential(ctx context.Context, storage logical.Storage,
 
 	err = b.validateIAMUserExists(ctx, storage, &cfg, false)
 	if err != nil {
-		return fmt.Errorf("iam user didn't exist, or username/userid didn't match: %w", err)
+		return "", fmt.Errorf("iam user didn't exist, or username/userid didn't match: %w", err)
 	}
 
 	accessKeys, err := iamClient.ListAccessKeys(&iam.ListAccessKeysInput{
 		UserName: aws.String(cfg.Username),
 	})
 	if err != nil {
-		return fmt.Errorf("unable to list existing access keys for IAM user %q: %w", cfg.Username, err)
+		return "", fmt.Errorf("unable to list existing access keys for IAM user %q: %w", cfg.Username, err)
 	}
 
 	// If we have the maximum number of keys, we have to delete one to make another (so we can get the credentials).
@@ -119,7 +126,7 @@ func (b *backend) createCredential(ctx context.Context, storage logical.Storage,
 			UserName:    oldestKey.UserName,
 		})
 		if err != nil {
-			return fmt.Errorf("unable to delete oldest access keys for user %q: %w", cfg.Username, err)
+			return "", fmt.Errorf("unable to delete oldest access keys for user %q: %w", cfg.Username, err)
 		}
 	}
 
@@ -128,7 +135,7 @@ func (b *backend) createCredential(ctx context.Context, storage logical.Storage,
 		UserName: aws.String(cfg.Username),
 	})
 	if err != nil {
-		return fmt.Errorf("unable to create new access keys for user %q: %w", cfg.Username, err)
+		return "", fmt.Errorf("unable to create new access keys for user %q: %w", cfg.Username, err)
 	}
 
 	// Persist new keys
@@ -137,7 +144,7 @@ func (b *backend) createCredential(ctx context.Context, storage logical.Storage,
 		SecretAccessKey: *out.AccessKey.SecretAccessKey,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal object to JSON: %w", err)
+		return "", fmt.Errorf("failed to marshal object to JSON: %w", err)
 	}
 	if shouldLockStorage {
 		b.roleMutex.Lock()
@@ -145,10 +152,10 @@ func (b *backend) createCredential(ctx context.Context, storage logical.Storage,
 	}
 	err = storage.Put(ctx, entry)
 	if err != nil {
-		return fmt.Errorf("failed to save object in storage: %w", err)
+		return "", fmt.Errorf("failed to save object in storage: %w", err)
 	}
 
-	return nil
+	return *out.AccessKey.AccessKeyId, nil
 }
 
 // delete credential will remove the credential associated with the role from storage.
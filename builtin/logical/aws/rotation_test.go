@@ -141,7 +141,7 @@ func TestRotation(t *testing.T) {
 				}
 				b.iamClient = miam
 
-				err = b.createCredential(bgCTX, config.StorageView, cred.config, true)
+				_, err = b.createCredential(bgCTX, config.StorageView, cred.config, true)
 				if err != nil {
 					t.Fatalf("couldn't insert credential %d: %s", i, err)
 				}
@@ -332,7 +332,7 @@ func TestCreateCredential(t *testing.T) {
 			b := Backend(config)
 			b.iamClient = fiam
 
-			err = b.createCredential(context.Background(), config.StorageView, staticRoleEntry{Username: c.username, ID: c.id}, true)
+			_, err = b.createCredential(context.Background(), config.StorageView, staticRoleEntry{Username: c.username, ID: c.id}, true)
 			if err != nil {
 				t.Fatalf("got an error we didn't expect: %q", err)
 			}
@@ -112,10 +112,15 @@ func Backend(conf *logical.BackendConfig) *databaseBackend {
 			pathRoles(&b),
 			pathCredsCreate(&b),
 			pathRotateRootCredentials(&b),
+			[]*framework.Path{
+				pathListLibraries(&b),
+			},
+			pathLibraries(&b),
 		),
 
 		Secrets: []*framework.Secret{
 			secretCreds(&b),
+			secretLibraryCreds(&b),
 		},
 		Clean:             b.clean,
 		Invalidate:        b.invalidate,
@@ -176,6 +181,11 @@ type databaseBackend struct {
 	// issues with the priority queue.
 	roleLocks []*locksutil.LockEntry
 
+	// libraryLock serializes library check-outs and check-ins so that an
+	// account cannot be handed to two callers at once. It must be acquired
+	// before any role lock.
+	libraryLock sync.Mutex
+
 	// the running gauge collection process
 	gaugeCollectionProcess     *metricsutil.GaugeCollectionProcess
 	gaugeCollectionProcessStop sync.Once
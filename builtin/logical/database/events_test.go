@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDatabase_Events ensures that creating and rotating credentials sends
+// events which identify the role without including any credentials.
+func TestDatabase_Events(t *testing.T) {
+	ctx := namespace.RootContext(nil)
+
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b := Backend(config)
+	require.NoError(t, b.Setup(ctx, config))
+	defer b.Cleanup(ctx)
+	b.schedule = &TestSchedule{}
+	b.credRotationQueue = queue.New()
+	storage := config.StorageView
+
+	mockDB := setupMockDB(b)
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil)
+	mockDB.On("NewUser", mock.Anything, mock.Anything).
+		Return(v5.NewUserResponse{Username: "v-dynamic"}, nil)
+	configureDBMount(t, storage)
+
+	for _, req := range []*logical.Request{
+		{Operation: logical.CreateOperation, Path: "static-roles/static", Data: map[string]interface{}{
+			"username":        "static",
+			"db_name":         "mockv5",
+			"rotation_period": "86400s",
+		}},
+		{Operation: logical.UpdateOperation, Path: "rotate-role/static"},
+		{Operation: logical.CreateOperation, Path: "roles/dynamic", Data: map[string]interface{}{
+			"db_name":             "mockv5",
+			"creation_statements": "CREATE USER",
+		}},
+		{Operation: logical.ReadOperation, Path: "creds/dynamic"},
+	} {
+		req.Storage = storage
+		resp, err := b.HandleRequest(ctx, req)
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%s: %v", req.Path, resp)
+	}
+
+	var eventTypes []string
+	for _, e := range events.Events() {
+		eventTypes = append(eventTypes, e.EventType)
+		fields := e.Event.Metadata.AsMap()
+		require.Equal(t, "mockv5", fields["db_name"])
+		require.NotContains(t, fields, "password")
+	}
+	require.Equal(t, []string{"database/static-creds-rotate", "database/static-creds-rotate", "database/creds-create"}, eventTypes)
+
+	rotate := events.Events()[1].Event.Metadata.AsMap()
+	require.Equal(t, "static-creds/static", rotate[logical.EventMetadataDataPath])
+	require.Equal(t, "static", rotate["username"])
+	require.Equal(t, "true", rotate[logical.EventMetadataModified])
+
+	create := events.Events()[2].Event.Metadata.AsMap()
+	require.Equal(t, "dynamic", create["name"])
+	require.NotContains(t, create, logical.EventMetadataModified)
+}
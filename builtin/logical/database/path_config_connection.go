@@ -58,6 +58,13 @@ func (c *DatabaseConfig) SupportsCredentialType(credentialType v5.CredentialType
 	return false
 }
 
+// SupportsSelfManagedPassword returns whether the database plugin reported
+// that it supports self-managed passwords when it was initialized.
+func (c *DatabaseConfig) SupportsSelfManagedPassword() bool {
+	supported, _ := c.ConnectionDetails[v5.SupportsSelfManagedPasswordKey].(bool)
+	return supported
+}
+
 // pathResetConnection configures a path to reset a plugin.
 func pathResetConnection(b *databaseBackend) *framework.Path {
 	return &framework.Path{
@@ -283,6 +290,8 @@ func (b *databaseBackend) connectionReadHandler() framework.OperationFunc {
 		delete(config.ConnectionDetails, "password")
 		delete(config.ConnectionDetails, "private_key")
 		delete(config.ConnectionDetails, "service_account_json")
+		// Reported by the plugin rather than configured
+		delete(config.ConnectionDetails, v5.SupportsSelfManagedPasswordKey)
 
 		return &logical.Response{
 			Data: structs.New(config).Map(),
@@ -219,6 +219,12 @@ func (b *databaseBackend) pathCredsCreateRead() framework.OperationFunc {
 		resp := b.Secret(SecretCredsType).Response(respData, internal)
 		resp.Secret.TTL = role.DefaultTTL
 		resp.Secret.MaxTTL = role.MaxTTL
+
+		b.SendOperationEvent(ctx, "database", "creds-create", "", false,
+			"name", name,
+			"db_name", role.DBName,
+		)
+
 		return resp, nil
 	}
 }
@@ -246,6 +252,15 @@ func (b *databaseBackend) pathStaticCredsRead() framework.OperationFunc {
 			return nil, fmt.Errorf("%q is not an allowed role", name)
 		}
 
+		// Accounts in a library are only handed out through check-outs
+		libName, err := b.libraryForStaticRole(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if libName != "" {
+			return logical.ErrorResponse("static role %q belongs to library %q; its credentials must be checked out", name, libName), nil
+		}
+
 		respData := map[string]interface{}{
 			"username":            role.StaticAccount.Username,
 			"ttl":                 role.StaticAccount.CredentialTTL().Seconds(),
@@ -0,0 +1,735 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	databaseLibraryPath         = "library/"
+	databaseLibraryCheckOutPath = "library-check-out/"
+
+	defaultLibraryTTL    = time.Hour
+	defaultLibraryMaxTTL = 24 * time.Hour
+)
+
+func pathListLibraries(b *databaseBackend) *framework.Path {
+	return &framework.Path{
+		Pattern: "library/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixDatabase,
+			OperationVerb:   "list",
+			OperationSuffix: "libraries",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathLibraryList,
+		},
+
+		HelpSynopsis:    pathLibraryHelpSyn,
+		HelpDescription: pathLibraryHelpDesc,
+	}
+}
+
+func pathLibraries(b *databaseBackend) []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name"),
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationSuffix: "library",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library.",
+				},
+				"static_role_names": {
+					Type: framework.TypeCommaStringSlice,
+					Description: `The static roles whose accounts can be checked out
+	from this library. A static role can only belong to one library.`,
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The default and maximum lease TTL of a check-out. Defaults to 1 hour.",
+				},
+				"max_ttl": {
+					Type: framework.TypeDurationSecond,
+					Description: `The maximum amount of time an account can be checked
+	out, including renewals, before it is forcibly checked in. Defaults to 24 hours.`,
+				},
+				"disable_check_in_enforcement": {
+					Type: framework.TypeBool,
+					Description: `If true, any caller with access to the check-in
+	endpoint can check in accounts, not only the borrower.`,
+				},
+			},
+			ExistenceCheck: b.pathLibraryExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.pathLibraryRead,
+				logical.CreateOperation: b.pathLibraryCreateUpdate,
+				logical.UpdateOperation: b.pathLibraryCreateUpdate,
+				logical.DeleteOperation: b.pathLibraryDelete,
+			},
+
+			HelpSynopsis:    pathLibraryHelpSyn,
+			HelpDescription: pathLibraryHelpDesc,
+		},
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/check-out$",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "check-out",
+				OperationSuffix: "library-account",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library.",
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The lease TTL of the check-out. Cannot exceed the library's ttl.",
+				},
+			},
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckOut,
+			},
+
+			HelpSynopsis:    pathLibraryCheckOutHelpSyn,
+			HelpDescription: pathLibraryCheckOutHelpDesc,
+		},
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/check-in$",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "check-in",
+				OperationSuffix: "library-accounts",
+			},
+			Fields: libraryCheckInFields(),
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckIn(false),
+			},
+
+			HelpSynopsis:    pathLibraryCheckInHelpSyn,
+			HelpDescription: pathLibraryCheckInHelpDesc,
+		},
+		{
+			Pattern: "library/manage/" + framework.GenericNameRegex("name") + "/check-in$",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "force-check-in",
+				OperationSuffix: "library-accounts",
+			},
+			Fields: libraryCheckInFields(),
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckIn(true),
+			},
+
+			HelpSynopsis:    pathLibraryManageCheckInHelpSyn,
+			HelpDescription: pathLibraryManageCheckInHelpDesc,
+		},
+		{
+			Pattern: "library/" + framework.GenericNameRegex("name") + "/status$",
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: operationPrefixDatabase,
+				OperationVerb:   "read",
+				OperationSuffix: "library-status",
+			},
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the library.",
+				},
+			},
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.pathLibraryStatus,
+			},
+
+			HelpSynopsis:    pathLibraryStatusHelpSyn,
+			HelpDescription: pathLibraryStatusHelpDesc,
+		},
+	}
+}
+
+func libraryCheckInFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"name": {
+			Type:        framework.TypeString,
+			Description: "Name of the library.",
+		},
+		"static_role_names": {
+			Type: framework.TypeCommaStringSlice,
+			Description: `The static roles to check in. If empty, all accounts
+	in the library checked out by the caller are checked in.`,
+		},
+	}
+}
+
+type libraryEntry struct {
+	StaticRoleNames           []string      `json:"static_role_names"`
+	TTL                       time.Duration `json:"ttl"`
+	MaxTTL                    time.Duration `json:"max_ttl"`
+	DisableCheckInEnforcement bool          `json:"disable_check_in_enforcement"`
+}
+
+// libraryCheckOut records the exclusive lease of a static role's account to a
+// single borrower. It is stored under the static role name.
+type libraryCheckOut struct {
+	// ID identifies this check-out so that a lease from an earlier check-out
+	// of the same account cannot check in a later one.
+	ID          string `json:"id"`
+	LibraryName string `json:"library_name"`
+
+	BorrowerEntityID            string `json:"borrower_entity_id"`
+	BorrowerClientTokenAccessor string `json:"borrower_client_token_accessor"`
+	BorrowerDisplayName         string `json:"borrower_display_name"`
+
+	CheckedOutAt time.Time `json:"checked_out_at"`
+
+	// ExpiresAt is the time at which the account is forcibly checked in,
+	// regardless of the lease.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// borrowedBy returns true if the check-out belongs to the caller of req.
+func (c *libraryCheckOut) borrowedBy(req *logical.Request) bool {
+	if c.BorrowerEntityID != "" {
+		return c.BorrowerEntityID == req.EntityID
+	}
+	return c.BorrowerClientTokenAccessor != "" && c.BorrowerClientTokenAccessor == req.ClientTokenAccessor
+}
+
+func (b *databaseBackend) Library(ctx context.Context, s logical.Storage, name string) (*libraryEntry, error) {
+	entry, err := s.Get(ctx, databaseLibraryPath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result libraryEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// libraryForStaticRole returns the name of the library the given static role
+// belongs to, or an empty string if it does not belong to one.
+func (b *databaseBackend) libraryForStaticRole(ctx context.Context, s logical.Storage, roleName string) (string, error) {
+	names, err := s.List(ctx, databaseLibraryPath)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		lib, err := b.Library(ctx, s, name)
+		if err != nil {
+			return "", err
+		}
+		if lib != nil && strutil.StrListContains(lib.StaticRoleNames, roleName) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func (b *databaseBackend) libraryCheckOut(ctx context.Context, s logical.Storage, roleName string) (*libraryCheckOut, error) {
+	entry, err := s.Get(ctx, databaseLibraryCheckOutPath+roleName)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result libraryCheckOut
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *databaseBackend) pathLibraryExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	lib, err := b.Library(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return lib != nil, nil
+}
+
+func (b *databaseBackend) pathLibraryList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, databaseLibraryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+func (b *databaseBackend) pathLibraryRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	lib, err := b.Library(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"static_role_names":            lib.StaticRoleNames,
+			"ttl":                          lib.TTL.Seconds(),
+			"max_ttl":                      lib.MaxTTL.Seconds(),
+			"disable_check_in_enforcement": lib.DisableCheckInEnforcement,
+		},
+	}, nil
+}
+
+func (b *databaseBackend) pathLibraryCreateUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	if name == "" {
+		return logical.ErrorResponse("empty library name attribute given"), nil
+	}
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	lib, err := b.Library(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	createOperation := lib == nil
+	if lib == nil {
+		lib = &libraryEntry{
+			TTL:    defaultLibraryTTL,
+			MaxTTL: defaultLibraryMaxTTL,
+		}
+	}
+
+	if raw, ok := data.GetOk("static_role_names"); ok {
+		roleNames := strutil.RemoveDuplicates(raw.([]string), false)
+
+		// Accounts that are checked out cannot be removed from the library
+		for _, roleName := range lib.StaticRoleNames {
+			if strutil.StrListContains(roleNames, roleName) {
+				continue
+			}
+			checkOut, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if checkOut != nil {
+				return logical.ErrorResponse("static role %q is checked out and cannot be removed from the library", roleName), nil
+			}
+		}
+
+		for _, roleName := range roleNames {
+			role, err := b.StaticRole(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if role == nil {
+				return logical.ErrorResponse("static role %q does not exist", roleName), nil
+			}
+			libName, err := b.libraryForStaticRole(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if libName != "" && libName != name {
+				return logical.ErrorResponse("static role %q already belongs to library %q", roleName, libName), nil
+			}
+		}
+		lib.StaticRoleNames = roleNames
+	}
+	if len(lib.StaticRoleNames) == 0 {
+		return logical.ErrorResponse("static_role_names is required"), nil
+	}
+
+	if raw, ok := data.GetOk("ttl"); ok {
+		lib.TTL = time.Duration(raw.(int)) * time.Second
+	}
+	if raw, ok := data.GetOk("max_ttl"); ok {
+		lib.MaxTTL = time.Duration(raw.(int)) * time.Second
+	}
+	if lib.TTL <= 0 || lib.MaxTTL <= 0 {
+		return logical.ErrorResponse("ttl and max_ttl must be greater than zero"), nil
+	}
+	if lib.TTL > lib.MaxTTL {
+		return logical.ErrorResponse("ttl cannot be greater than max_ttl"), nil
+	}
+
+	if raw, ok := data.GetOk("disable_check_in_enforcement"); ok {
+		lib.DisableCheckInEnforcement = raw.(bool)
+	}
+
+	entry, err := logical.StorageEntryJSON(databaseLibraryPath+name, lib)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	operation := "library-update"
+	if createOperation {
+		operation = "library-create"
+	}
+	b.SendOperationEvent(ctx, "database", operation, databaseLibraryPath+name, true, "name", name)
+
+	return nil, nil
+}
+
+func (b *databaseBackend) pathLibraryDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	lib, err := b.Library(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return nil, nil
+	}
+
+	for _, roleName := range lib.StaticRoleNames {
+		checkOut, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if checkOut != nil {
+			return logical.ErrorResponse("static role %q is checked out; all accounts must be checked in before the library can be deleted", roleName), nil
+		}
+	}
+
+	if err := req.Storage.Delete(ctx, databaseLibraryPath+name); err != nil {
+		return nil, err
+	}
+	b.SendOperationEvent(ctx, "database", "library-delete", databaseLibraryPath+name, true, "name", name)
+
+	return nil, nil
+}
+
+func (b *databaseBackend) pathLibraryCheckOut(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	lib, err := b.Library(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return logical.ErrorResponse("unknown library: %s", name), nil
+	}
+
+	ttl := lib.TTL
+	if raw, ok := data.GetOk("ttl"); ok {
+		requested := time.Duration(raw.(int)) * time.Second
+		if requested > 0 && requested < ttl {
+			ttl = requested
+		}
+	}
+
+	for _, roleName := range lib.StaticRoleNames {
+		resp, err := b.checkOutStaticRole(ctx, req, name, lib, roleName, ttl)
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			return resp, nil
+		}
+	}
+
+	return logical.ErrorResponse("no accounts are available for check-out in library %q", name), nil
+}
+
+// checkOutStaticRole checks out the account of the given static role if it is
+// available. A nil response is returned if the account is unavailable.
+func (b *databaseBackend) checkOutStaticRole(ctx context.Context, req *logical.Request, libName string, lib *libraryEntry, roleName string, ttl time.Duration) (*logical.Response, error) {
+	lock := locksutil.LockForKey(b.roleLocks, roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, nil
+	}
+
+	role, err := b.StaticRole(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil || role.StaticAccount == nil {
+		b.Logger().Warn("static role in library not found", "library", libName, "role", roleName)
+		return nil, nil
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	checkOut := &libraryCheckOut{
+		ID:                          id,
+		LibraryName:                 libName,
+		BorrowerEntityID:            req.EntityID,
+		BorrowerClientTokenAccessor: req.ClientTokenAccessor,
+		BorrowerDisplayName:         req.DisplayName,
+		CheckedOutAt:                now,
+		ExpiresAt:                   now.Add(lib.MaxTTL),
+	}
+	entry, err := logical.StorageEntryJSON(databaseLibraryCheckOutPath+roleName, checkOut)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	// Make sure the rotation queue visits the account no later than the end
+	// of the check-out, so it is checked in even if the lease is not revoked
+	if item, err := b.popFromRotationQueueByKey(roleName); err == nil {
+		if item.Priority > checkOut.ExpiresAt.Unix() {
+			item.Priority = checkOut.ExpiresAt.Unix()
+		}
+		if err := b.pushItem(item); err != nil {
+			return nil, err
+		}
+	}
+
+	respData := map[string]interface{}{
+		"static_role": roleName,
+		"username":    role.StaticAccount.Username,
+	}
+	switch role.CredentialType {
+	case v5.CredentialTypePassword:
+		respData["password"] = role.StaticAccount.Password
+	case v5.CredentialTypeRSAPrivateKey:
+		respData["rsa_private_key"] = string(role.StaticAccount.PrivateKey)
+	}
+
+	resp := b.Secret(SecretLibraryCredsType).Response(respData, map[string]interface{}{
+		"library":      libName,
+		"static_role":  roleName,
+		"check_out_id": id,
+	})
+	resp.Secret.TTL = ttl
+	resp.Secret.MaxTTL = lib.MaxTTL
+
+	b.SendOperationEvent(ctx, "database", "library-check-out", databaseLibraryPath+libName, true,
+		"name", libName,
+		"static_role", roleName,
+	)
+
+	return resp, nil
+}
+
+func (b *databaseBackend) pathLibraryCheckIn(force bool) framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+		name := data.Get("name").(string)
+
+		b.libraryLock.Lock()
+		defer b.libraryLock.Unlock()
+
+		lib, err := b.Library(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if lib == nil {
+			return logical.ErrorResponse("unknown library: %s", name), nil
+		}
+		enforce := !force && !lib.DisableCheckInEnforcement
+
+		roleNames := data.Get("static_role_names").([]string)
+		explicit := len(roleNames) > 0
+		if !explicit {
+			roleNames = lib.StaticRoleNames
+		}
+
+		var checkIns []string
+		for _, roleName := range roleNames {
+			if !strutil.StrListContains(lib.StaticRoleNames, roleName) {
+				return logical.ErrorResponse("static role %q does not belong to library %q", roleName, name), nil
+			}
+
+			checkOut, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+			if err != nil {
+				return nil, err
+			}
+			if checkOut == nil {
+				continue
+			}
+			if enforce && !checkOut.borrowedBy(req) {
+				if explicit {
+					return logical.ErrorResponse("static role %q is not checked out by the caller", roleName), logical.ErrPermissionDenied
+				}
+				continue
+			}
+
+			if err := b.checkInStaticRole(ctx, req.Storage, roleName, ""); err != nil {
+				return nil, err
+			}
+			checkIns = append(checkIns, roleName)
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"check_ins": checkIns,
+			},
+		}, nil
+	}
+}
+
+// checkInStaticRole releases the check-out of the given static role and
+// rotates its credentials. If checkOutID is set, the account is only checked
+// in if it is still held by that check-out.
+func (b *databaseBackend) checkInStaticRole(ctx context.Context, s logical.Storage, roleName, checkOutID string) error {
+	lock := locksutil.LockForKey(b.roleLocks, roleName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	checkOut, err := b.libraryCheckOut(ctx, s, roleName)
+	if err != nil {
+		return err
+	}
+	if checkOut == nil || (checkOutID != "" && checkOut.ID != checkOutID) {
+		return nil
+	}
+
+	if err := s.Delete(ctx, databaseLibraryCheckOutPath+roleName); err != nil {
+		return err
+	}
+	b.SendOperationEvent(ctx, "database", "library-check-in", databaseLibraryPath+checkOut.LibraryName, true,
+		"name", checkOut.LibraryName,
+		"static_role", roleName,
+	)
+
+	role, err := b.StaticRole(ctx, s, roleName)
+	if err != nil {
+		return err
+	}
+	if role == nil {
+		return nil
+	}
+
+	// The previous borrower must not be able to keep using the account. A
+	// failed rotation is retried by the rotation queue.
+	if err := b.rotateStaticRoleNow(ctx, s, roleName, role); err != nil {
+		b.Logger().Warn("unable to rotate credentials on check-in", "role", roleName, "error", err)
+	}
+	return nil
+}
+
+func (b *databaseBackend) pathLibraryStatus(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	lib, err := b.Library(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return nil, nil
+	}
+
+	roleNames := make([]string, len(lib.StaticRoleNames))
+	copy(roleNames, lib.StaticRoleNames)
+	sort.Strings(roleNames)
+
+	status := make(map[string]interface{}, len(roleNames))
+	for _, roleName := range roleNames {
+		checkOut, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if checkOut == nil {
+			status[roleName] = map[string]interface{}{
+				"available": true,
+			}
+			continue
+		}
+		status[roleName] = map[string]interface{}{
+			"available":             false,
+			"borrower_entity_id":    checkOut.BorrowerEntityID,
+			"borrower_display_name": checkOut.BorrowerDisplayName,
+			"checked_out_at":        checkOut.CheckedOutAt.Format(time.RFC3339),
+			"expires_at":            checkOut.ExpiresAt.Format(time.RFC3339),
+		}
+	}
+
+	return &logical.Response{
+		Data: status,
+	}, nil
+}
+
+const pathLibraryHelpSyn = `
+Manage libraries of static role accounts that can be checked out.
+`
+
+const pathLibraryHelpDesc = `
+A library is a set of static roles whose accounts are leased exclusively to
+one caller at a time. Accounts are checked out with the "check-out" endpoint
+and are rotated when they are checked in, either explicitly, by revoking the
+lease, or forcibly once "max_ttl" has elapsed.
+
+The credentials of static roles in a library cannot be read from the
+"static-creds" endpoint.
+`
+
+const pathLibraryCheckOutHelpSyn = `
+Check out an account from a library.
+`
+
+const pathLibraryCheckOutHelpDesc = `
+This path checks out the first available account in the library and returns
+its credentials with a lease. The account is unavailable to other callers
+until it is checked in.
+`
+
+const pathLibraryCheckInHelpSyn = `
+Check in accounts borrowed from a library.
+`
+
+const pathLibraryCheckInHelpDesc = `
+This path checks in accounts checked out by the caller and rotates their
+credentials. Unless the library disables check-in enforcement, only the
+borrower of an account can check it in.
+`
+
+const pathLibraryManageCheckInHelpSyn = `
+Forcibly check in accounts borrowed from a library.
+`
+
+const pathLibraryManageCheckInHelpDesc = `
+This path checks in accounts regardless of which caller borrowed them and
+rotates their credentials. It is intended for operators.
+`
+
+const pathLibraryStatusHelpSyn = `
+Read the check-out status of the accounts in a library.
+`
+
+const pathLibraryStatusHelpDesc = `
+This path returns, for each static role in the library, whether its account is
+available and, if it is checked out, who borrowed it and when the check-out
+expires.
+`
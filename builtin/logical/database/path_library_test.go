@@ -0,0 +1,247 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLibrary_CheckOutCheckIn(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	createRole(t, b, storage, mockDB, "svc-1")
+	createRole(t, b, storage, mockDB, "svc-2")
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/pool",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_role_names": []string{"svc-1", "svc-2"},
+			"ttl":               "1h",
+			"max_ttl":           "2h",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+
+	// Roles can't be shared between libraries
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/other",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_role_names": []string{"svc-1"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	// Credentials of library accounts can't be read directly
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-creds/svc-1",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	checkOut := func(entityID string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "library/pool/check-out",
+			Storage:   storage,
+			EntityID:  entityID,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	first := checkOut("entity-a")
+	require.False(t, first.IsError(), first.Error())
+	require.Equal(t, "svc-1", first.Data["static_role"])
+	require.Equal(t, "svc-1", first.Data["username"])
+	require.Equal(t, time.Hour, first.Secret.TTL)
+
+	second := checkOut("entity-b")
+	require.False(t, second.IsError(), second.Error())
+	require.Equal(t, "svc-2", second.Data["static_role"])
+
+	exhausted := checkOut("entity-c")
+	require.True(t, exhausted.IsError())
+
+	// The library can't be deleted while accounts are checked out
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "library/pool",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "library/pool/status",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	status := resp.Data["svc-1"].(map[string]interface{})
+	require.Equal(t, false, status["available"])
+	require.Equal(t, "entity-a", status["borrower_entity_id"])
+
+	// Only the borrower can check an account back in
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/pool/check-in",
+		Storage:   storage,
+		EntityID:  "entity-b",
+		Data: map[string]interface{}{
+			"static_role_names": []string{"svc-1"},
+		},
+	})
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// Checking in rotates the account's credentials
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/pool/check-in",
+		Storage:   storage,
+		EntityID:  "entity-a",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"svc-1"}, resp.Data["check_ins"])
+	mockDB.AssertNumberOfCalls(t, "UpdateUser", 3)
+
+	role, err := b.StaticRole(ctx, storage, "svc-1")
+	require.NoError(t, err)
+	require.NotEqual(t, first.Data["password"], role.StaticAccount.Password)
+
+	// Revoking the lease of a stale check-out is a no-op
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    first.Secret,
+	})
+	require.NoError(t, err)
+	checkOut("entity-c")
+	_, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Storage:   storage,
+		Secret:    first.Secret,
+	})
+	require.NoError(t, err)
+	entry, err := b.libraryCheckOut(ctx, storage, "svc-1")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	require.Equal(t, "entity-c", entry.BorrowerEntityID)
+
+	// Operators can force a check-in
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Twice()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/manage/pool/check-in",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"svc-1", "svc-2"}, resp.Data["check_ins"])
+
+	// Static roles in a library can't be deleted
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "static-roles/svc-1",
+		Storage:   storage,
+	})
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "library/pool",
+		Storage:   storage,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+}
+
+func TestLibrary_ExpiredCheckOutIsCheckedIn(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	createRole(t, b, storage, mockDB, "svc-1")
+
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/pool",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"static_role_names": []string{"svc-1"},
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/pool/check-out",
+		Storage:   storage,
+		EntityID:  "entity-a",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+
+	// A check-out that is still valid holds off rotation
+	item, err := b.popFromRotationQueueByKey("svc-1")
+	require.NoError(t, err)
+	item.Priority = time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, b.pushItem(item))
+
+	require.True(t, b.rotateCredential(ctx, storage))
+	require.False(t, b.rotateCredential(ctx, storage))
+	mockDB.AssertNumberOfCalls(t, "UpdateUser", 1)
+
+	// Once the check-out expires, the account is checked in and rotated
+	checkOut, err := b.libraryCheckOut(ctx, storage, "svc-1")
+	require.NoError(t, err)
+	checkOut.ExpiresAt = time.Now().Add(-time.Second)
+	entry, err := logical.StorageEntryJSON(databaseLibraryCheckOutPath+"svc-1", checkOut)
+	require.NoError(t, err)
+	require.NoError(t, storage.Put(ctx, entry))
+
+	item, err = b.popFromRotationQueueByKey("svc-1")
+	require.NoError(t, err)
+	item.Priority = time.Now().Add(-time.Minute).Unix()
+	require.NoError(t, b.pushItem(item))
+
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Return(v5.UpdateUserResponse{}, nil).
+		Once()
+	require.True(t, b.rotateCredential(ctx, storage))
+	mockDB.AssertNumberOfCalls(t, "UpdateUser", 2)
+
+	checkOut, err = b.libraryCheckOut(ctx, storage, "svc-1")
+	require.NoError(t, err)
+	require.Nil(t, checkOut)
+}
@@ -217,6 +217,16 @@ func staticFields() map[string]*framework.FieldSchema {
 	this functionality. See the plugin's API page for more information on
 	support and formatting for this parameter.`,
 		},
+		"self_managed_password": {
+			Type: framework.TypeString,
+			Description: `The current password of the static account. If set, Vault
+	rotates the password by connecting to the database as the static account
+	itself instead of using the connection's root credentials. Only valid with
+	the "password" credential_type.`,
+			DisplayAttrs: &framework.DisplayAttributes{
+				Sensitive: true,
+			},
+		},
 	}
 	return fields
 }
@@ -249,6 +259,14 @@ func (b *databaseBackend) pathRoleDelete(ctx context.Context, req *logical.Reque
 func (b *databaseBackend) pathStaticRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	name := data.Get("name").(string)
 
+	libName, err := b.libraryForStaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if libName != "" {
+		return logical.ErrorResponse("static role %q belongs to library %q and must be removed from it before it can be deleted", name, libName), nil
+	}
+
 	// Grab the exclusive lock
 	lock := locksutil.LockForKey(b.roleLocks, name)
 	lock.Lock()
@@ -257,7 +275,7 @@ func (b *databaseBackend) pathStaticRoleDelete(ctx context.Context, req *logical
 	// Remove the item from the queue
 	_, _ = b.popFromRotationQueueByKey(name)
 
-	err := req.Storage.Delete(ctx, databaseStaticRolePath+name)
+	err = req.Storage.Delete(ctx, databaseStaticRolePath+name)
 	if err != nil {
 		return nil, err
 	}
@@ -305,6 +323,7 @@ func (b *databaseBackend) pathStaticRoleRead(ctx context.Context, req *logical.R
 	if role.StaticAccount != nil {
 		data["username"] = role.StaticAccount.Username
 		data["rotation_statements"] = role.Statements.Rotation
+		data["self_managed"] = role.StaticAccount.SelfManaged
 		if !role.StaticAccount.LastVaultRotation.IsZero() {
 			data["last_vault_rotation"] = role.StaticAccount.LastVaultRotation
 		}
@@ -635,6 +654,32 @@ func (b *databaseBackend) pathStaticRoleCreateUpdate(ctx context.Context, req *l
 		return logical.ErrorResponse("credential_config validation failed: %s", err), nil
 	}
 
+	if smPasswordRaw, ok := data.GetOk("self_managed_password"); ok {
+		smPassword := smPasswordRaw.(string)
+		switch {
+		case smPassword == "":
+			return logical.ErrorResponse("self_managed_password cannot be empty"), nil
+		case !createRole && !role.StaticAccount.SelfManaged:
+			return logical.ErrorResponse("cannot change an existing static role to use a self-managed password"), nil
+		}
+
+		dbConfig, err := b.DatabaseConfig(ctx, req.Storage, role.DBName)
+		if err != nil {
+			return nil, err
+		}
+		if !dbConfig.SupportsSelfManagedPassword() {
+			return logical.ErrorResponse("self_managed_password is not supported by the %q database plugin", dbConfig.PluginName), nil
+		}
+
+		// The stored password is used as the current credential when the
+		// account rotates itself
+		role.StaticAccount.SelfManaged = true
+		role.StaticAccount.Password = smPassword
+	}
+	if role.StaticAccount.SelfManaged && role.CredentialType != v5.CredentialTypePassword {
+		return logical.ErrorResponse("self_managed_password is only valid with the %q credential_type", v5.CredentialTypePassword.String()), nil
+	}
+
 	// lvr represents the roles' LastVaultRotation
 	lvr := role.StaticAccount.LastVaultRotation
 
@@ -823,6 +868,11 @@ type staticAccount struct {
 	// RevokeUser is a boolean flag to indicate if Vault should revoke the
 	// database user when the role is deleted
 	RevokeUserOnDelete bool `json:"revoke_user_on_delete"`
+
+	// SelfManaged indicates that the account rotates its own password by
+	// connecting to the database with its current Password, rather than with
+	// the connection's root credentials.
+	SelfManaged bool `json:"self_managed"`
 }
 
 // NextRotationTime calculates the next rotation for period and schedule-based
@@ -1195,6 +1195,132 @@ func TestWALsDeletedOnRoleDeletion(t *testing.T) {
 	requireWALs(t, storage, 1)
 }
 
+func TestStaticRole_SelfManagedPassword(t *testing.T) {
+	ctx := context.Background()
+	b, storage, mockDB := getBackend(t)
+	defer b.Cleanup(ctx)
+	configureDBMount(t, storage)
+
+	// RSA private keys cannot be used to authenticate a self-managed account
+	resp, err := b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/rsa",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "rsa",
+			"db_name":               "mockv5",
+			"rotation_period":       "86400s",
+			"credential_type":       v5.CredentialTypeRSAPrivateKey.String(),
+			"self_managed_password": "current",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error response, got resp: %#v err: %v", resp, err)
+	}
+
+	// Plugins which cannot rotate as the account itself are rejected
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/unsupported",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "unsupported",
+			"db_name":               "mockv5",
+			"rotation_period":       "86400s",
+			"self_managed_password": "current",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error response, got resp: %#v err: %v", resp, err)
+	}
+
+	// Plugins advertise support for self-managed passwords when initialized
+	entry, err := logical.StorageEntryJSON("config/mockv5", &DatabaseConfig{
+		AllowedRoles: []string{"*"},
+		ConnectionDetails: map[string]interface{}{
+			v5.SupportsSelfManagedPasswordKey: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Put(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []v5.UpdateUserRequest
+	mockDB.On("UpdateUser", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			requests = append(requests, args.Get(1).(v5.UpdateUserRequest))
+		}).
+		Return(v5.UpdateUserResponse{}, nil).
+		Twice()
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/hashicorp",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "hashicorp",
+			"db_name":               "mockv5",
+			"rotation_period":       "86400s",
+			"self_managed_password": "current",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/hashicorp",
+		Storage:   storage,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 UpdateUser calls, got %d", len(requests))
+	}
+	if requests[0].SelfManagedPassword != "current" {
+		t.Fatalf("expected initial rotation to use the configured password, got %q", requests[0].SelfManagedPassword)
+	}
+	if requests[1].SelfManagedPassword != requests[0].Password.NewPassword {
+		t.Fatal("expected second rotation to authenticate with the password set by the first")
+	}
+
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-roles/hashicorp",
+		Storage:   storage,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatal(resp, err)
+	}
+	if resp.Data["self_managed"] != true {
+		t.Fatalf("expected self_managed to be true, got %v", resp.Data["self_managed"])
+	}
+	if _, ok := resp.Data["self_managed_password"]; ok {
+		t.Fatal("self_managed_password should not be returned")
+	}
+
+	// An existing role cannot be switched to a self-managed password
+	createRole(t, b, storage, mockDB, "vault-managed")
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "static-roles/vault-managed",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"username":              "vault-managed",
+			"self_managed_password": "current",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error response, got resp: %#v err: %v", resp, err)
+	}
+}
+
 func TestIsInsideRotationWindow(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -174,6 +174,9 @@ func (b *databaseBackend) pathRotateRootCredentialsUpdate() framework.OperationF
 		if err != nil {
 			b.Logger().Warn("unable to delete WAL", "error", err, "WAL ID", walID)
 		}
+
+		b.SendOperationEvent(ctx, "database", "root-rotate", "", true, "name", name)
+
 		return nil, nil
 	}
 }
@@ -193,55 +196,62 @@ func (b *databaseBackend) pathRotateRoleCredentialsUpdate() framework.OperationF
 			return logical.ErrorResponse("no static role found for role name"), nil
 		}
 
-		// In create/update of static accounts, we only care if the operation
-		// err'd , and this call does not return credentials
-		item, err := b.popFromRotationQueueByKey(name)
-		if err != nil {
-			item = &queue.Item{
-				Key: name,
-			}
-		}
-
-		input := &setStaticAccountInput{
-			RoleName: name,
-			Role:     role,
-		}
-		if walID, ok := item.Value.(string); ok {
-			input.WALID = walID
-		}
-		resp, err := b.setStaticAccount(ctx, req.Storage, input)
-		// if err is not nil, we need to attempt to update the priority and place
-		// this item back on the queue. The err should still be returned at the end
-		// of this method.
-		if err != nil {
+		if err := b.rotateStaticRoleNow(ctx, req.Storage, name, role); err != nil {
 			b.logger.Warn("unable to rotate credentials in rotate-role", "error", err)
-			// Update the priority to re-try this rotation and re-add the item to
-			// the queue
-			item.Priority = time.Now().Add(10 * time.Second).Unix()
-
-			// Preserve the WALID if it was returned
-			if resp != nil && resp.WALID != "" {
-				item.Value = resp.WALID
-			}
-		} else {
-			item.Priority = role.StaticAccount.NextRotationTimeFromInput(resp.RotationTime).Unix()
-			// Clear any stored WAL ID as we must have successfully deleted our WAL to get here.
-			item.Value = ""
+			return nil, fmt.Errorf("unable to finish rotating credentials; retries will "+
+				"continue in the background but it is also safe to retry manually: %w", err)
 		}
 
-		// Add their rotation to the queue
-		if err := b.pushItem(item); err != nil {
-			return nil, err
+		return nil, nil
+	}
+}
+
+// rotateStaticRoleNow immediately rotates the credentials of the given static
+// role and updates its position in the rotation queue. If the rotation fails,
+// the role is put back on the queue so that it is retried in the background.
+func (b *databaseBackend) rotateStaticRoleNow(ctx context.Context, s logical.Storage, name string, role *roleEntry) error {
+	// In create/update of static accounts, we only care if the operation
+	// err'd , and this call does not return credentials
+	item, err := b.popFromRotationQueueByKey(name)
+	if err != nil {
+		item = &queue.Item{
+			Key: name,
 		}
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("unable to finish rotating credentials; retries will "+
-				"continue in the background but it is also safe to retry manually: %w", err)
+	input := &setStaticAccountInput{
+		RoleName: name,
+		Role:     role,
+	}
+	if walID, ok := item.Value.(string); ok {
+		input.WALID = walID
+	}
+	resp, err := b.setStaticAccount(ctx, s, input)
+	// if err is not nil, we need to attempt to update the priority and place
+	// this item back on the queue. The err should still be returned at the end
+	// of this method.
+	if err != nil {
+		// Update the priority to re-try this rotation and re-add the item to
+		// the queue
+		item.Priority = time.Now().Add(10 * time.Second).Unix()
+
+		// Preserve the WALID if it was returned
+		if resp != nil && resp.WALID != "" {
+			item.Value = resp.WALID
 		}
+	} else {
+		item.Priority = role.StaticAccount.NextRotationTimeFromInput(resp.RotationTime).Unix()
+		// Clear any stored WAL ID as we must have successfully deleted our WAL to get here.
+		item.Value = ""
+	}
 
-		// return any err from the setStaticAccount call
-		return nil, nil
+	// Add their rotation to the queue
+	if pushErr := b.pushItem(item); pushErr != nil {
+		return pushErr
 	}
+
+	// return any err from the setStaticAccount call
+	return err
 }
 
 const pathRotateCredentialsUpdateHelpSyn = `
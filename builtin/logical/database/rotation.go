@@ -223,7 +223,51 @@ func (b *databaseBackend) rotateCredential(ctx context.Context, s logical.Storag
 	}
 
 	now := time.Now()
-	if !role.StaticAccount.ShouldRotate(item.Priority, now) {
+
+	// Accounts checked out from a library are rotated when they are checked
+	// in rather than while they are in use. Check-outs that outlive the
+	// library's max_ttl are forcibly checked in here.
+	checkOut, err := b.libraryCheckOut(ctx, s, roleName)
+	if err != nil {
+		logger.Error("unable to load library check-out", "error", err)
+
+		item.Priority = time.Now().Add(10 * time.Second).Unix()
+		if err := b.pushItem(item); err != nil {
+			logger.Error("unable to push item on to queue", "error", err)
+		}
+		return true
+	}
+	forceCheckIn := checkOut != nil && !now.Before(checkOut.ExpiresAt)
+	if checkOut != nil && !forceCheckIn {
+		due := item.Priority <= now.Unix()
+		if item.Priority < checkOut.ExpiresAt.Unix() {
+			item.Priority = checkOut.ExpiresAt.Unix()
+		}
+		if err := b.pushItem(item); err != nil {
+			logger.Error("unable to push item on to queue", "error", err)
+		}
+		// Only keep processing the queue if this item was due; otherwise no
+		// other item is due either.
+		return due
+	}
+	if forceCheckIn {
+		logger.Info("forcing check-in of expired library check-out", "library", checkOut.LibraryName)
+		if err := s.Delete(ctx, databaseLibraryCheckOutPath+roleName); err != nil {
+			logger.Error("unable to delete library check-out", "error", err)
+
+			item.Priority = time.Now().Add(10 * time.Second).Unix()
+			if err := b.pushItem(item); err != nil {
+				logger.Error("unable to push item on to queue", "error", err)
+			}
+			return true
+		}
+		b.SendOperationEvent(ctx, "database", "library-check-in", databaseLibraryPath+checkOut.LibraryName, true,
+			"name", checkOut.LibraryName,
+			"static_role", roleName,
+		)
+	}
+
+	if !forceCheckIn && !role.StaticAccount.ShouldRotate(item.Priority, now) {
 		if !role.StaticAccount.IsInsideRotationWindow(now) {
 			// We are a schedule-based rotation and we are outside a rotation
 			// window so we update priority and NextVaultRotation
@@ -393,6 +437,12 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 		Commands: input.Role.Statements.Rotation,
 	}
 
+	// Self-managed accounts authenticate with their current password, so
+	// capture it before it is replaced with the new credential below
+	if input.Role.StaticAccount.SelfManaged {
+		updateReq.SelfManagedPassword = input.Role.StaticAccount.Password
+	}
+
 	// Use credential from input if available. This happens if we're restoring from
 	// a WAL item or processing the rotation queue with an item that has a WAL
 	// associated with it
@@ -503,6 +553,14 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 	}
 
 	_, err = dbi.database.UpdateUser(ctx, updateReq, false)
+	if err != nil && updateReq.SelfManagedPassword != "" && updateReq.Password != nil && input.WALID != "" && output.WALID == input.WALID {
+		// A previous attempt may have changed the password in the database
+		// without saving the role, in which case the account can only
+		// authenticate with the credential from the WAL entry
+		b.Logger().Debug("retrying self-managed rotation with WAL credential", "role", input.RoleName, "WAL ID", output.WALID)
+		updateReq.SelfManagedPassword = updateReq.Password.NewPassword
+		_, err = dbi.database.UpdateUser(ctx, updateReq, false)
+	}
 	if err != nil {
 		b.CloseIfShutdown(dbi, err)
 		return output, fmt.Errorf("error setting credentials: %w", err)
@@ -523,6 +581,12 @@ func (b *databaseBackend) setStaticAccount(ctx context.Context, s logical.Storag
 		return output, err
 	}
 
+	b.SendOperationEvent(ctx, "database", "static-creds-rotate", "static-creds/"+input.RoleName, true,
+		"name", input.RoleName,
+		"db_name", input.Role.DBName,
+		"username", input.Role.StaticAccount.Username,
+	)
+
 	// Cleanup WAL after successfully rotating and pushing new item on to queue
 	if err := framework.DeleteWAL(ctx, s, output.WALID); err != nil {
 		b.Logger().Warn("error deleting WAL", "WAL ID", output.WALID, "error", err)
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const SecretLibraryCredsType = "library_creds"
+
+func secretLibraryCreds(b *databaseBackend) *framework.Secret {
+	return &framework.Secret{
+		Type:   SecretLibraryCredsType,
+		Fields: map[string]*framework.FieldSchema{},
+
+		Renew:  b.secretLibraryCredsRenew,
+		Revoke: b.secretLibraryCredsRevoke,
+	}
+}
+
+func libraryCheckOutFromSecret(req *logical.Request) (libName, roleName, checkOutID string, err error) {
+	for key, dst := range map[string]*string{
+		"library":      &libName,
+		"static_role":  &roleName,
+		"check_out_id": &checkOutID,
+	} {
+		raw, ok := req.Secret.InternalData[key]
+		if !ok {
+			return "", "", "", fmt.Errorf("secret is missing %s internal data", key)
+		}
+		*dst, ok = raw.(string)
+		if !ok {
+			return "", "", "", fmt.Errorf("secret has invalid %s internal data", key)
+		}
+	}
+	return libName, roleName, checkOutID, nil
+}
+
+func (b *databaseBackend) secretLibraryCredsRenew(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	libName, roleName, checkOutID, err := libraryCheckOutFromSecret(req)
+	if err != nil {
+		return nil, err
+	}
+
+	lib, err := b.Library(ctx, req.Storage, libName)
+	if err != nil {
+		return nil, err
+	}
+	if lib == nil {
+		return nil, fmt.Errorf("error during renew: could not find library with name %q", libName)
+	}
+
+	checkOut, err := b.libraryCheckOut(ctx, req.Storage, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if checkOut == nil || checkOut.ID != checkOutID {
+		return nil, fmt.Errorf("error during renew: static role %q is no longer checked out by this lease", roleName)
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = lib.TTL
+	resp.Secret.MaxTTL = lib.MaxTTL
+	return resp, nil
+}
+
+func (b *databaseBackend) secretLibraryCredsRevoke(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	libName, roleName, checkOutID, err := libraryCheckOutFromSecret(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b.libraryLock.Lock()
+	defer b.libraryLock.Unlock()
+
+	// The account may already have been checked in explicitly, in which case
+	// there is nothing left to do
+	if err := b.checkInStaticRole(ctx, req.Storage, roleName, checkOutID); err != nil {
+		return nil, fmt.Errorf("failed to check in static role %q from library %q: %w", roleName, libName, err)
+	}
+	return nil, nil
+}
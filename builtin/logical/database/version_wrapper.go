@@ -132,6 +132,9 @@ func (d databaseVersionWrapper) UpdateUser(ctx context.Context, req v5.UpdateUse
 	}
 
 	// v4 Database
+	if req.SelfManagedPassword != "" {
+		return nil, fmt.Errorf("self-managed passwords are not supported by v4 database plugins")
+	}
 	if req.Password == nil && req.Expiration == nil {
 		return nil, fmt.Errorf("missing change to be sent to the database")
 	}
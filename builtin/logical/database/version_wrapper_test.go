@@ -453,6 +453,22 @@ func TestUpdateUser_legacyDB(t *testing.T) {
 
 			expectErr: true,
 		},
+		"self-managed password": {
+			req: v5.UpdateUserRequest{
+				Username: "existing_user",
+				Password: &v5.ChangePassword{
+					NewPassword: "newpassowrd",
+				},
+				SelfManagedPassword: "currentpassword",
+			},
+			isRootUser: false,
+
+			setCredentialsCalls: 0,
+			rotateRootCalls:     0,
+			renewUserCalls:      0,
+
+			expectErr: true,
+		},
 		"change password - SetCredentials": {
 			req: v5.UpdateUserRequest{
 				Username: "existing_user",
@@ -114,6 +114,12 @@ func Backend(conf *logical.BackendConfig) *backend {
 				"unified-crl",
 				"unified-ocsp",   // Unified OCSP POST
 				"unified-ocsp/*", // Unified OCSP GET
+				"est/cacerts",
+				"est/+/cacerts",
+				"est/csrattrs",
+				"est/+/csrattrs",
+				"scep",
+				"scep/pkiclient.exe",
 
 				// ACME paths are added below
 			},
@@ -125,7 +131,13 @@ func Backend(conf *logical.BackendConfig) *backend {
 				clusterConfigPath,
 				"crls/",
 				"certs/",
+				certIndexPrefix,
+				certIndexRolePrefix,
+				certIndexIssuerPrefix,
+				certIndexExpiryPrefix,
+				certIndexRevokedPrefix,
 				acmePathPrefix,
+				scepChallengePrefix,
 			},
 
 			Root: []string{
@@ -206,6 +218,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathFetchValidRaw(&b),
 			pathFetchValid(&b),
 			pathFetchListCerts(&b),
+			pathSearchCerts(&b),
 
 			// OCSP APIs
 			buildPathOcspGet(&b),
@@ -219,6 +232,12 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathAcmeConfig(&b),
 			pathAcmeEabList(&b),
 			pathAcmeEabDelete(&b),
+
+			// EST
+			pathConfigEst(&b),
+
+			// SCEP
+			pathConfigScep(&b),
 		},
 
 		Secrets: []*framework.Secret{
@@ -262,6 +281,9 @@ func Backend(conf *logical.BackendConfig) *backend {
 		setupAcmeDirectory(&b, prefix.acmePrefix, prefix.unauthPrefix, prefix.opts)
 	}
 
+	b.Backend.Paths = append(b.Backend.Paths, buildEstPaths(&b)...)
+	b.Backend.Paths = append(b.Backend.Paths, buildScepPaths(&b)...)
+
 	b.tidyCASGuard = new(uint32)
 	b.tidyCancelCAS = new(uint32)
 	b.tidyStatus = &tidyStatus{state: tidyStatusInactive}
@@ -332,6 +354,9 @@ type backend struct {
 	// Context around ACME operations
 	acmeState       *acmeState
 	acmeAccountLock sync.RWMutex // (Write) Locked on Tidy, (Read) Locked on Account Creation
+
+	// Lock around consuming and tidying SCEP challenge passwords.
+	scepChallengeLock sync.Mutex
 }
 
 type roleOperation func(ctx context.Context, req *logical.Request, data *framework.FieldData, role *roleEntry) (*logical.Response, error)
@@ -681,6 +706,17 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 		return nil
 	}
 
+	doScepTidy := func() error {
+		// Challenge passwords are stored locally, but we still can't write
+		// on a standby.
+		if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
+			b.System().ReplicationState().HasState(consts.ReplicationDRSecondary) {
+			return nil
+		}
+
+		return b.tidyScepChallenges(sc)
+	}
+
 	// First tidy any ACME nonces to free memory.
 	b.acmeState.DoTidyNonces()
 
@@ -691,6 +727,7 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 	// Then run the CRL rebuild and tidy operation.
 	crlErr := doCRL()
 	tidyErr := doAutoTidy()
+	scepErr := doScepTidy()
 
 	// Periodically re-emit gauges so that they don't disappear/go stale
 	tidyConfig, err := sc.getAutoTidyConfig()
@@ -708,6 +745,10 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 		errors = multierror.Append(errors, fmt.Errorf("Error running auto-tidy:\n - %w\n", tidyErr))
 	}
 
+	if scepErr != nil {
+		errors = multierror.Append(errors, fmt.Errorf("Error removing expired SCEP challenge passwords:\n - %w\n", scepErr))
+	}
+
 	if errors != nil {
 		return errors
 	}
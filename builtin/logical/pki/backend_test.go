@@ -6681,18 +6681,45 @@ func pathShouldBeUnauthedWriteOnly(t *testing.T, client *api.Client, path string
 	}
 }
 
+func pathShouldBeUnauthedReadWrite(t *testing.T, client *api.Client, path string, token string) {
+	// Should be able to read and write both with and without a token.
+	for _, tokenToUse := range []string{"", token} {
+		client.SetToken(tokenToUse)
+		resp, err := client.Logical().ReadWithContext(ctx, path)
+		if err != nil && isPermDenied(err) {
+			t.Fatalf("unexpected failure to read %v (token: %v): %v / %v", path, tokenToUse != "", err, resp)
+		}
+		resp, err = client.Logical().WriteWithContext(ctx, path, map[string]interface{}{})
+		if err != nil && isPermDenied(err) {
+			t.Fatalf("unexpected failure to write %v (token: %v): %v / %v", path, tokenToUse != "", err, resp)
+		}
+
+		// These should all be denied.
+		resp, err = client.Logical().DeleteWithContext(ctx, path)
+		if (err == nil && resp != nil) || (err != nil && !isDeniedOp(err)) {
+			t.Fatalf("unexpected failure during delete on read-write path %v (token: %v): %v / %v", path, tokenToUse != "", err, resp)
+		}
+		resp, err = client.Logical().JSONMergePatch(ctx, path, map[string]interface{}{})
+		if (err == nil && resp != nil) || (err != nil && !isDeniedOp(err)) {
+			t.Fatalf("unexpected failure during patch on read-write path %v (token: %v): %v / %v", path, tokenToUse != "", err, resp)
+		}
+	}
+}
+
 type pathAuthChecker int
 
 const (
 	shouldBeAuthed pathAuthChecker = iota
 	shouldBeUnauthedReadList
 	shouldBeUnauthedWriteOnly
+	shouldBeUnauthedReadWrite
 )
 
 var pathAuthChckerMap = map[pathAuthChecker]pathAuthCheckerFunc{
 	shouldBeAuthed:            pathShouldBeAuthed,
 	shouldBeUnauthedReadList:  pathShouldBeUnauthedReadList,
 	shouldBeUnauthedWriteOnly: pathShouldBeUnauthedWriteOnly,
+	shouldBeUnauthedReadWrite: pathShouldBeUnauthedReadWrite,
 }
 
 func TestProperAuthing(t *testing.T) {
@@ -6771,11 +6798,14 @@ func TestProperAuthing(t *testing.T) {
 		"certs/revoked/":                         shouldBeAuthed,
 		"certs/revocation-queue/":                shouldBeAuthed,
 		"certs/unified-revoked/":                 shouldBeAuthed,
+		"certs/search":                           shouldBeAuthed,
 		"config/acme":                            shouldBeAuthed,
 		"config/auto-tidy":                       shouldBeAuthed,
 		"config/ca":                              shouldBeAuthed,
 		"config/cluster":                         shouldBeAuthed,
 		"config/crl":                             shouldBeAuthed,
+		"config/est":                             shouldBeAuthed,
+		"config/scep":                            shouldBeAuthed,
 		"config/issuers":                         shouldBeAuthed,
 		"config/keys":                            shouldBeAuthed,
 		"config/urls":                            shouldBeAuthed,
@@ -6785,6 +6815,14 @@ func TestProperAuthing(t *testing.T) {
 		"crl/delta/pem":                          shouldBeUnauthedReadList,
 		"crl/rotate":                             shouldBeAuthed,
 		"crl/rotate-delta":                       shouldBeAuthed,
+		"est/cacerts":                            shouldBeUnauthedReadList,
+		"est/csrattrs":                           shouldBeUnauthedReadList,
+		"est/simpleenroll":                       shouldBeAuthed,
+		"est/simplereenroll":                     shouldBeAuthed,
+		"est/test/cacerts":                       shouldBeUnauthedReadList,
+		"est/test/csrattrs":                      shouldBeUnauthedReadList,
+		"est/test/simpleenroll":                  shouldBeAuthed,
+		"est/test/simplereenroll":                shouldBeAuthed,
 		"intermediate/cross-sign":                shouldBeAuthed,
 		"intermediate/generate/exported":         shouldBeAuthed,
 		"intermediate/generate/internal":         shouldBeAuthed,
@@ -6852,6 +6890,9 @@ func TestProperAuthing(t *testing.T) {
 		"root/rotate/kms":                        shouldBeAuthed,
 		"root/sign-intermediate":                 shouldBeAuthed,
 		"root/sign-self-issued":                  shouldBeAuthed,
+		"scep":                                   shouldBeUnauthedReadWrite,
+		"scep/pkiclient.exe":                     shouldBeUnauthedReadWrite,
+		"scep/challenge":                         shouldBeAuthed,
 		"sign-verbatim":                          shouldBeAuthed,
 		"sign-verbatim/test":                     shouldBeAuthed,
 		"sign/test":                              shouldBeAuthed,
@@ -6887,6 +6928,7 @@ func TestProperAuthing(t *testing.T) {
 		paths[acmePrefix+"order/13b80844-e60d-42d2-b7e9-152a8e834b90"] = shouldBeUnauthedWriteOnly
 		paths[acmePrefix+"order/13b80844-e60d-42d2-b7e9-152a8e834b90/finalize"] = shouldBeUnauthedWriteOnly
 		paths[acmePrefix+"order/13b80844-e60d-42d2-b7e9-152a8e834b90/cert"] = shouldBeUnauthedWriteOnly
+		paths[acmePrefix+"renewal-info/aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"] = shouldBeUnauthedReadList
 
 		// Make sure this new-eab path is auth'd
 		paths[acmePrefix+"new-eab"] = shouldBeAuthed
@@ -6923,6 +6965,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "ocsp/") && strings.Contains(raw_path, "{req}") {
 			raw_path = strings.ReplaceAll(raw_path, "{req}", "dGVzdAo=")
 		}
+		if strings.Contains(raw_path, "est/") && strings.Contains(raw_path, "{label}") {
+			raw_path = strings.ReplaceAll(raw_path, "{label}", "test")
+		}
 		if strings.Contains(raw_path, "{issuer_ref}") {
 			raw_path = strings.ReplaceAll(raw_path, "{issuer_ref}", "default")
 		}
@@ -6947,6 +6992,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "acme/") && strings.Contains(raw_path, "{order_id}") {
 			raw_path = strings.ReplaceAll(raw_path, "{order_id}", "13b80844-e60d-42d2-b7e9-152a8e834b90")
 		}
+		if strings.Contains(raw_path, "acme/") && strings.Contains(raw_path, "{cert_id}") {
+			raw_path = strings.ReplaceAll(raw_path, "{cert_id}", "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE")
+		}
 		if strings.Contains(raw_path, "eab") && strings.Contains(raw_path, "{key_id}") {
 			raw_path = strings.ReplaceAll(raw_path, "{key_id}", eabKid)
 		}
@@ -6986,6 +7034,10 @@ func TestProperAuthing(t *testing.T) {
 			if hasGet || hasList {
 				t.Fatalf("Unauthed write-only endpoints should not have GET/LIST capabilities: %v->%v", openapi_path, raw_path)
 			}
+		} else if handler == shouldBeUnauthedReadWrite {
+			if hasDelete || hasList {
+				t.Fatalf("Unauthed read-write endpoints should not have DELETE/LIST capabilities: %v->%v", openapi_path, raw_path)
+			}
 		}
 	}
 
@@ -1058,6 +1058,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		return nil, fmt.Errorf("error saving revoked certificate to new location: %w", err)
 	}
 	sc.Backend.ifCountEnabledIncrementTotalRevokedCertificatesCount(certsCounted, revEntry.Key)
+	sc.Backend.certEvent(sc.Context, "revoke", cert, "issuer_id", string(revInfo.CertificateIssuer))
 
 	// From here on out, the certificate has been revoked locally. Any other
 	// persistence issues might still err, but any other failure messages
@@ -1070,6 +1071,11 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		},
 	}
 
+	if err := sc.markCertIndexRevoked(hyphenSerial, &revInfo); err != nil {
+		sc.Backend.Logger().Error("Failed to update certificate index with revocation", "serial_number", colonSerial, "error", err)
+		resp.AddWarning(fmt.Sprintf("Failed to update the certificate index with the revocation: %v", err))
+	}
+
 	// If this flag is enabled after the fact, existing local entries will be published to
 	// the unified storage space through a periodic function.
 	failedWritingUnifiedCRL := false
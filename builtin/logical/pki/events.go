@@ -0,0 +1,24 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+)
+
+// certEvent sends a pki/<operation> event about a leaf certificate. Events
+// only carry identifying information about the certificate; never the
+// certificate or its private key.
+func (b *backend) certEvent(ctx context.Context, operation string, cert *x509.Certificate, metadataPairs ...string) {
+	serial := serialFromCert(cert)
+	metadata := []string{
+		"serial_number", serial,
+		"not_after", cert.NotAfter.UTC().Format(time.RFC3339),
+	}
+	metadata = append(metadata, metadataPairs...)
+
+	b.SendOperationEvent(ctx, "pki", operation, "cert/"+serial, true, metadata...)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPki_Events ensures that issuing and revoking leaf certificates sends
+// events which identify the certificate without including it.
+func TestPki_Events(t *testing.T) {
+	t.Parallel()
+
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b := Backend(config)
+	require.NoError(t, b.Setup(context.Background(), config))
+	b.pkiStorageVersion.Store(1)
+	s := config.StorageView
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root.example.com",
+		"key_type":    "ec",
+		"issuer_name": "root",
+		"ttl":         "24h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "host.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	serial := resp.Data["serial_number"].(string)
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serial,
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	var eventTypes []string
+	for _, e := range events.Events() {
+		eventTypes = append(eventTypes, e.EventType)
+		fields := e.Event.Metadata.AsMap()
+		require.Equal(t, serial, fields["serial_number"])
+		require.Equal(t, "cert/"+serial, fields[logical.EventMetadataDataPath])
+		require.Equal(t, "true", fields[logical.EventMetadataModified])
+		require.NotContains(t, fields, "certificate")
+		require.NotContains(t, fields, "private_key")
+	}
+	require.Equal(t, []string{"pki/issue", "pki/revoke"}, eventTypes)
+
+	issue := events.Events()[0].Event.Metadata.AsMap()
+	require.Equal(t, "issue", issue[logical.EventMetadataOperation])
+	require.Equal(t, "test", issue["role"])
+	require.Equal(t, "true", issue["stored"])
+}
@@ -166,6 +166,17 @@ Any values are added with OID 0.9.2342.19200300.100.1.1.`,
 		},
 	}
 
+	fields["cert_metadata"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `A base64 encoded value to store alongside the
+certificate, returned when searching certificates
+with certs/search. Requires the role to store
+certificates.`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Certificate Metadata",
+		},
+	}
+
 	fields = addIssuerRefField(fields)
 
 	return fields
@@ -446,7 +457,8 @@ func addTidyFields(fields map[string]*framework.FieldSchema) map[string]*framewo
 	fields["tidy_cert_store"] = &framework.FieldSchema{
 		Type: framework.TypeBool,
 		Description: `Set to true to enable tidying up
-the certificate store`,
+the certificate store. Stored certificates missing
+from the certificate search index are indexed.`,
 	}
 
 	fields["tidy_revocation_list"] = &framework.FieldSchema{
@@ -259,6 +259,14 @@ func (b *backend) acmeFinalizeOrderHandler(ac *acmeContext, r *logical.Request,
 		return nil, err
 	}
 
+	var replaced *acmeCertEntry
+	if order.Replaces != "" {
+		replaced, err = b.loadReplacedCert(ac, order.AccountId, order.Replaces)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var signedCertBundle *certutil.ParsedCertBundle
 	var issuerId issuerID
 	if ac.runtimeOpts.isCiepsEnabled {
@@ -274,7 +282,7 @@ func (b *backend) acmeFinalizeOrderHandler(ac *acmeContext, r *logical.Request,
 			return nil, err
 		}
 
-		err = storeCertificate(ac.sc, signedCertBundle)
+		err = storeCertificate(ac.sc, signedCertBundle, ac.role.Name, issuerId)
 		if err != nil {
 			return nil, err
 		}
@@ -286,6 +294,12 @@ func (b *backend) acmeFinalizeOrderHandler(ac *acmeContext, r *logical.Request,
 		return nil, err
 	}
 
+	if replaced != nil {
+		if err := b.acmeState.MarkIssuedCertReplaced(ac, replaced, order.OrderId); err != nil {
+			b.Logger().Warn("failed to mark ACME certificate as replaced", "serial_number", replaced.Serial, "order", orderId, "error", err)
+		}
+	}
+
 	order.Status = ACMEOrderValid
 	order.CertificateSerialNumber = hyphenSerialNumber
 	order.CertificateExpiry = signedCertBundle.Certificate.NotAfter
@@ -477,7 +491,7 @@ func removeDuplicatesAndSortIps(ipIdentifiers []net.IP) []net.IP {
 	return uniqueIpIdentifiers
 }
 
-func storeCertificate(sc *storageContext, signedCertBundle *certutil.ParsedCertBundle) error {
+func storeCertificate(sc *storageContext, signedCertBundle *certutil.ParsedCertBundle, role string, issuerId issuerID) error {
 	hyphenSerialNumber := normalizeSerialFromBigInt(signedCertBundle.Certificate.SerialNumber)
 	key := "certs/" + hyphenSerialNumber
 	certsCounted := sc.Backend.certsCounted.Load()
@@ -489,7 +503,7 @@ func storeCertificate(sc *storageContext, signedCertBundle *certutil.ParsedCertB
 		return fmt.Errorf("unable to store certificate locally: %w", err)
 	}
 	sc.Backend.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
-	return nil
+	return sc.writeCertIndex(signedCertBundle.Certificate, role, issuerId, nil)
 }
 
 func maybeAugmentReqDataWithSuitableCN(ac *acmeContext, csr *x509.CertificateRequest, data *framework.FieldData) {
@@ -753,6 +767,11 @@ func (b *backend) acmeNewOrderHandler(ac *acmeContext, _ *logical.Request, _ *fr
 		return nil, err
 	}
 
+	replaces, err := b.parseOrderReplaces(ac, account, data)
+	if err != nil {
+		return nil, err
+	}
+
 	// Per RFC 8555 -> 7.1.3. Order Objects
 	// For pending orders, the authorizations that the client needs to complete before the
 	// requested certificate can be issued (see Section 7.5), including
@@ -786,6 +805,7 @@ func (b *backend) acmeNewOrderHandler(ac *acmeContext, _ *logical.Request, _ *fr
 		Expires:          time.Now().Add(24 * time.Hour), // TODO: Readjust this based on authz and/or config
 		Identifiers:      identifiers,
 		AuthorizationIds: authorizationIds,
+		Replaces:         replaces,
 	}
 
 	err = b.acmeState.SaveOrder(ac, order)
@@ -854,6 +874,10 @@ func formatOrderResponse(acmeCtx *acmeContext, order *acmeOrder) *logical.Respon
 		resp.Data["certificate"] = baseOrderUrl + "/cert"
 	}
 
+	if order.Replaces != "" {
+		resp.Data["replaces"] = order.Replaces
+	}
+
 	return resp
 }
 
@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Clients poll for renewal information periodically; see draft-ietf-acme-ari
+// Section 4.3. Getting Renewal Information.
+const acmeRenewalInfoRetryAfter = 6 * time.Hour
+
+func certIdRegex(name string) string {
+	return fmt.Sprintf("(?P<%s>[[:alnum:]_=-]+\\.[[:alnum:]_=-]+)", name)
+}
+
+func pathAcmeRenewalInfo(b *backend, baseUrl string, opts acmeWrapperOpts) *framework.Path {
+	return patternAcmeRenewalInfo(b, baseUrl+"/renewal-info/"+certIdRegex("cert_id"), opts)
+}
+
+func patternAcmeRenewalInfo(b *backend, pattern string, opts acmeWrapperOpts) *framework.Path {
+	fields := map[string]*framework.FieldSchema{}
+	addFieldsForACMEPath(fields, pattern)
+	fields["cert_id"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: `The ARI certificate identifier to fetch renewal information for`,
+		Required:    true,
+	}
+
+	return &framework.Path{
+		Pattern: pattern,
+		Fields:  fields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback:                    b.acmeWrapper(opts, b.acmeRenewalInfoHandler),
+				ForwardPerformanceSecondary: false,
+				ForwardPerformanceStandby:   true,
+			},
+		},
+
+		HelpSynopsis:    pathAcmeHelpSync,
+		HelpDescription: pathAcmeHelpDesc,
+	}
+}
+
+func (b *backend) acmeRenewalInfoHandler(ac *acmeContext, _ *logical.Request, fields *framework.FieldData) (*logical.Response, error) {
+	aki, serial, err := parseAcmeCertId(fields.Get("cert_id").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := fetchAcmeRenewalCert(ac.sc, aki, serial)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return logical.RespondWithStatusCode(nil, nil, http.StatusNotFound)
+	}
+
+	revoked, rotation, err := getRenewalConstraints(ac.sc, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := suggestRenewalWindow(cert, time.Now(), revoked, rotation)
+	rawBody, err := json.Marshal(map[string]interface{}{
+		"suggestedWindow": map[string]interface{}{
+			"start": start.Format(time.RFC3339),
+			"end":   end.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding response: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/json",
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     rawBody,
+		},
+		Headers: map[string][]string{
+			"Retry-After": {strconv.Itoa(int(acmeRenewalInfoRetryAfter.Seconds()))},
+		},
+	}, nil
+}
+
+// parseAcmeCertId parses an ARI certificate identifier: the base64url
+// encoded key identifier from the certificate's Authority Key Identifier
+// extension and the base64url encoded DER contents of its serial number,
+// joined by a period.
+func parseAcmeCertId(certId string) ([]byte, *big.Int, error) {
+	rawAki, rawSerial, found := strings.Cut(certId, ".")
+	if !found {
+		return nil, nil, fmt.Errorf("%w: certificate identifier %q is missing its serial number", ErrMalformed, certId)
+	}
+
+	aki, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(rawAki, "="))
+	if err != nil || len(aki) == 0 {
+		return nil, nil, fmt.Errorf("%w: certificate identifier %q has an invalid authority key identifier", ErrMalformed, certId)
+	}
+
+	serialBytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(rawSerial, "="))
+	if err != nil || len(serialBytes) == 0 || serialBytes[0]&0x80 != 0 {
+		return nil, nil, fmt.Errorf("%w: certificate identifier %q has an invalid serial number", ErrMalformed, certId)
+	}
+
+	return aki, new(big.Int).SetBytes(serialBytes), nil
+}
+
+// fetchAcmeRenewalCert loads the stored certificate an ARI certificate
+// identifier refers to, returning nil when this mount didn't issue it.
+func fetchAcmeRenewalCert(sc *storageContext, aki []byte, serial *big.Int) (*x509.Certificate, error) {
+	certEntry, err := fetchCertBySerialBigInt(sc, "certs/", serial)
+	if err != nil {
+		return nil, err
+	}
+	if certEntry == nil {
+		return nil, nil
+	}
+
+	cert, err := x509.ParseCertificate(certEntry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse stored certificate: %v", ErrServerInternal, err)
+	}
+
+	if !bytes.Equal(cert.AuthorityKeyId, aki) {
+		return nil, nil
+	}
+
+	return cert, nil
+}
+
+// getRenewalConstraints reports whether the certificate or an issuer that
+// signed it was revoked, along with the earliest scheduled rotation of
+// those issuers, if any.
+func getRenewalConstraints(sc *storageContext, cert *x509.Certificate) (bool, time.Time, error) {
+	var rotation time.Time
+
+	revokedEntry, err := fetchCertBySerialBigInt(sc, revokedPath, cert.SerialNumber)
+	if err != nil {
+		return false, rotation, err
+	}
+	revoked := revokedEntry != nil
+
+	issuers, err := sc.listIssuers()
+	if err != nil {
+		return false, rotation, err
+	}
+
+	for _, issuerId := range issuers {
+		issuer, err := sc.fetchIssuerById(issuerId)
+		if err != nil {
+			return false, rotation, err
+		}
+
+		issuerCert, err := issuer.GetCertificate()
+		if err != nil {
+			return false, rotation, err
+		}
+
+		if !bytes.Equal(issuerCert.SubjectKeyId, cert.AuthorityKeyId) || cert.CheckSignatureFrom(issuerCert) != nil {
+			continue
+		}
+
+		if issuer.Revoked {
+			revoked = true
+		}
+		if !issuer.ScheduledRotationTime.IsZero() && (rotation.IsZero() || issuer.ScheduledRotationTime.Before(rotation)) {
+			rotation = issuer.ScheduledRotationTime
+		}
+	}
+
+	return revoked, rotation, nil
+}
+
+// suggestRenewalWindow returns the window in which the certificate should
+// be renewed: normally the second-to-last sixth of its validity period,
+// moved to end at its issuer's scheduled rotation if that comes first.
+// Revoked certificates, and those whose issuer is already due for rotation,
+// get a window ending now so that clients renew immediately.
+func suggestRenewalWindow(cert *x509.Certificate, now time.Time, revoked bool, rotation time.Time) (time.Time, time.Time) {
+	if revoked || (!rotation.IsZero() && !rotation.After(now)) {
+		return cert.NotBefore, now
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	start := cert.NotAfter.Add(-lifetime / 3)
+	end := cert.NotAfter.Add(-lifetime / 6)
+
+	if !rotation.IsZero() && rotation.Before(end) {
+		end = rotation
+		start = end.Add(-lifetime / 6)
+		if start.Before(cert.NotBefore) {
+			start = cert.NotBefore
+		}
+	}
+
+	return start, end
+}
+
+// parseOrderReplaces validates the optional ARI replaces field of a new
+// order, returning the certificate identifier it carries.
+func (b *backend) parseOrderReplaces(ac *acmeContext, account *acmeAccount, data map[string]interface{}) (string, error) {
+	rawReplaces, present := data["replaces"]
+	if !present {
+		return "", nil
+	}
+
+	certId, ok := rawReplaces.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid type (%T; expected string) for field 'replaces': %w", rawReplaces, ErrMalformed)
+	}
+
+	if _, err := b.loadReplacedCert(ac, account.KeyId, certId); err != nil {
+		return "", err
+	}
+
+	return certId, nil
+}
+
+// loadReplacedCert loads the account's record of the certificate an order
+// replaces. Accounts can only replace certificates issued to them, and each
+// certificate only once.
+func (b *backend) loadReplacedCert(ac *acmeContext, accountId string, certId string) (*acmeCertEntry, error) {
+	aki, serial, err := parseAcmeCertId(certId)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := fetchAcmeRenewalCert(ac.sc, aki, serial)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return nil, fmt.Errorf("%w: replaced certificate %v was not issued by this mount", ErrMalformed, certId)
+	}
+
+	entry, err := b.acmeState.GetIssuedCert(ac, accountId, serialFromCert(cert))
+	if err != nil {
+		return nil, fmt.Errorf("unable to replace certificate %v: %v: %w", certId, err, ErrMalformed)
+	}
+
+	if entry.ReplacedBy != "" {
+		return nil, fmt.Errorf("%w: certificate %v was already replaced by order %v", ErrAlreadyReplaced, certId, entry.ReplacedBy)
+	}
+
+	return entry, nil
+}
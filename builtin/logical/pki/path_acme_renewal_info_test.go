@@ -0,0 +1,215 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+// TestAcmeRenewalInfo verifies the suggested renewal windows of ACME
+// Renewal Information and the replaces field of new orders.
+func TestAcmeRenewalInfo(t *testing.T) {
+	t.Parallel()
+
+	cluster, client, _ := setupAcmeBackend(t)
+	defer cluster.Cleanup()
+
+	testCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	_, err := client.Logical().Write("sys/mounts/pki/tune", map[string]interface{}{
+		"allowed_response_headers": []string{"Last-Modified", "Replay-Nonce", "Link", "Location", "Retry-After"},
+	})
+	require.NoError(t, err, "failed tuning mount response headers")
+
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "failed creating ec key")
+	acmeClient := getAcmeClientForCluster(t, cluster, "/v1/pki/acme/", accountKey)
+	baseAcmeURL := strings.TrimSuffix(acmeClient.DirectoryURL, "directory")
+
+	resp, err := acmeClient.HTTPClient.Get(acmeClient.DirectoryURL)
+	require.NoError(t, err, "failed fetching directory")
+	var directory map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&directory))
+	resp.Body.Close()
+	require.Equal(t, baseAcmeURL+"renewal-info", directory["renewalInfo"])
+
+	renewalInfo := func(certId string) (time.Time, time.Time) {
+		resp, err := acmeClient.HTTPClient.Get(baseAcmeURL + "renewal-info/" + certId)
+		require.NoError(t, err, "failed fetching renewal info")
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "21600", resp.Header.Get("Retry-After"))
+
+		var body struct {
+			SuggestedWindow struct {
+				Start time.Time `json:"start"`
+				End   time.Time `json:"end"`
+			} `json:"suggestedWindow"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body.SuggestedWindow.Start, body.SuggestedWindow.End
+	}
+
+	_, certs := doACMEWorkflow(t, client, acmeClient)
+	leaf, err := x509.ParseCertificate(certs[0])
+	require.NoError(t, err, "failed parsing acme cert")
+	certId := acmeTestCertId(t, leaf)
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+
+	start, end := renewalInfo(certId)
+	require.WithinDuration(t, leaf.NotAfter.Add(-lifetime/3), start, time.Second)
+	require.WithinDuration(t, leaf.NotAfter.Add(-lifetime/6), end, time.Second)
+
+	// Unknown certificates are not found.
+	unknown, err := acmeClient.HTTPClient.Get(baseAcmeURL + "renewal-info/" + strings.Split(certId, ".")[0] + ".AQ")
+	require.NoError(t, err)
+	unknown.Body.Close()
+	require.Equal(t, http.StatusNotFound, unknown.StatusCode)
+
+	// Scheduling a rotation of the issuer moves the window ahead of it.
+	rotation := leaf.NotBefore.Add(lifetime / 2).Truncate(time.Second).UTC()
+	_, err = client.Logical().JSONMergePatch(testCtx, "pki/issuer/int-ca", map[string]interface{}{
+		"scheduled_rotation_time": rotation.Format(time.RFC3339),
+	})
+	require.NoError(t, err, "failed scheduling issuer rotation")
+	issuerResp, err := client.Logical().Read("pki/issuer/int-ca")
+	require.NoError(t, err)
+	require.Equal(t, rotation.Format(time.RFC3339), issuerResp.Data["scheduled_rotation_time"])
+
+	start, end = renewalInfo(certId)
+	require.Equal(t, rotation, end.UTC())
+	require.WithinDuration(t, rotation.Add(-lifetime/6), start, time.Second)
+
+	// Once the rotation is due, clients should renew immediately.
+	_, err = client.Logical().JSONMergePatch(testCtx, "pki/issuer/int-ca", map[string]interface{}{
+		"scheduled_rotation_time": time.Now().Add(-time.Minute).Format(time.RFC3339),
+	})
+	require.NoError(t, err)
+	start, end = renewalInfo(certId)
+	require.Equal(t, leaf.NotBefore, start)
+	require.WithinDuration(t, time.Now(), end, 5*time.Second)
+
+	_, err = client.Logical().JSONMergePatch(testCtx, "pki/issuer/int-ca", map[string]interface{}{
+		"scheduled_rotation_time": "",
+	})
+	require.NoError(t, err)
+	issuerResp, err = client.Logical().Read("pki/issuer/int-ca")
+	require.NoError(t, err)
+	require.NotContains(t, issuerResp.Data, "scheduled_rotation_time")
+
+	// Orders can replace certificates issued to their account, once.
+	status, order, _ := acmeTestNewOrder(t, acmeClient, "*.localdomain", "bad")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, ErrorPrefix+"malformed", order["type"])
+
+	status, order, location := acmeTestNewOrder(t, acmeClient, "*.localdomain", certId)
+	require.Equal(t, http.StatusCreated, status, "new order failed: %v", order)
+	require.Equal(t, certId, order["replaces"])
+
+	acct, err := acmeClient.GetReg(testCtx, "")
+	require.NoError(t, err, "failed looking up account")
+	acmeOrder, err := acmeClient.GetOrder(testCtx, location)
+	require.NoError(t, err, "failed loading order")
+	markAuthorizationSuccess(t, client, acmeClient, acct, acmeOrder)
+
+	csrKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "failed generated key for CSR")
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"*.localdomain"}}, csrKey)
+	require.NoError(t, err, "failed generating csr")
+	certs, _, err = acmeClient.CreateOrderCert(testCtx, acmeOrder.FinalizeURL, csr, true)
+	require.NoError(t, err, "failed finalizing order")
+	replacement, err := x509.ParseCertificate(certs[0])
+	require.NoError(t, err, "failed parsing acme cert")
+
+	status, order, _ = acmeTestNewOrder(t, acmeClient, "*.localdomain", certId)
+	require.Equal(t, http.StatusConflict, status)
+	require.Equal(t, ErrorPrefix+"alreadyReplaced", order["type"])
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "failed creating ec key")
+	otherClient := getAcmeClientForCluster(t, cluster, "/v1/pki/acme/", otherKey)
+	_, err = otherClient.Register(testCtx, &acme.Account{}, func(tosURL string) bool { return true })
+	require.NoError(t, err, "failed registering account")
+	status, order, _ = acmeTestNewOrder(t, otherClient, "*.localdomain", acmeTestCertId(t, replacement))
+	require.Equal(t, http.StatusBadRequest, status, "expected replacing another account's certificate to fail")
+	require.Equal(t, ErrorPrefix+"malformed", order["type"])
+
+	// Revoked certificates should be renewed immediately.
+	err = acmeClient.RevokeCert(testCtx, nil, certs[0], acme.CRLReasonUnspecified)
+	require.NoError(t, err, "failed revoking certificate")
+	start, end = renewalInfo(acmeTestCertId(t, replacement))
+	require.Equal(t, replacement.NotBefore, start)
+	require.WithinDuration(t, time.Now(), end, 5*time.Second)
+}
+
+// acmeTestCertId builds the ARI certificate identifier of a certificate.
+func acmeTestCertId(t *testing.T, cert *x509.Certificate) string {
+	t.Helper()
+
+	var serial asn1.RawValue
+	der, err := asn1.Marshal(cert.SerialNumber)
+	require.NoError(t, err)
+	_, err = asn1.Unmarshal(der, &serial)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(cert.AuthorityKeyId) + "." + base64.RawURLEncoding.EncodeToString(serial.Bytes)
+}
+
+// acmeTestNewOrder creates an order replacing the given certificate; the
+// ACME client library doesn't support the replaces field, so the request
+// is signed here. It returns the status code, body, and location of the
+// response.
+func acmeTestNewOrder(t *testing.T, acmeClient *acme.Client, identifier string, replaces string) (int, map[string]interface{}, string) {
+	t.Helper()
+
+	ctx := context.Background()
+	dir, err := acmeClient.Discover(ctx)
+	require.NoError(t, err, "failed discovering directory")
+	_, err = acmeClient.GetReg(ctx, "")
+	require.NoError(t, err, "failed looking up account")
+
+	nonceResp, err := acmeClient.HTTPClient.Head(dir.NonceURL)
+	require.NoError(t, err, "failed fetching nonce")
+	nonceResp.Body.Close()
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.ES256,
+		Key:       jose.JSONWebKey{Key: acmeClient.Key, KeyID: string(acmeClient.KID)},
+	}, (&jose.SignerOptions{}).
+		WithHeader("nonce", nonceResp.Header.Get("Replay-Nonce")).
+		WithHeader("url", dir.OrderURL))
+	require.NoError(t, err, "failed creating signer")
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"identifiers": []map[string]string{{"type": "dns", "value": identifier}},
+		"replaces":    replaces,
+	})
+	require.NoError(t, err)
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err, "failed signing request")
+
+	resp, err := acmeClient.HTTPClient.Post(dir.OrderURL, "application/jose+json", strings.NewReader(jws.FullSerialize()))
+	require.NoError(t, err, "failed creating order")
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body, resp.Header.Get("Location")
+}
@@ -0,0 +1,289 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageEstConfig = "config/est"
+
+	estAuthenticatorCert     = "cert"
+	estAuthenticatorUserpass = "userpass"
+
+	pathConfigEstHelpSyn  = "Configuration of EST Endpoints"
+	pathConfigEstHelpDesc = "Here we configure:\n\nenabled=false, whether EST is enabled, defaults to false meaning that clusters will by default not get EST support,\ndefault_path_policy=\"\", the policy used for EST requests without a label under /pki/est/; either \"sign-verbatim\" or \"role:<role_name>\", the default leaves these endpoints disabled,\nlabel_to_path_policy={}, a map of labels usable under /pki/est/<label>/ to the policy used for them,\nauthenticators={}, the auth mounts clients authenticate through, keyed by \"cert\" (with \"accessor\" and \"cert_role\") and \"userpass\" (with \"accessor\")"
+)
+
+// estConfigEntry is stored at config/est.
+type estConfigEntry struct {
+	Enabled           bool                              `json:"enabled"`
+	DefaultPathPolicy string                            `json:"default_path_policy"`
+	LabelToPathPolicy map[string]string                 `json:"label_to_path_policy"`
+	Authenticators    map[string]*estAuthenticatorEntry `json:"authenticators"`
+	LastUpdated       time.Time                         `json:"last_updated"`
+}
+
+type estAuthenticatorEntry struct {
+	Accessor string `json:"accessor"`
+	CertRole string `json:"cert_role,omitempty"`
+}
+
+func (sc *storageContext) getEstConfig() (*estConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageEstConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &estConfigEntry{
+		LabelToPathPolicy: map[string]string{},
+		Authenticators:    map[string]*estAuthenticatorEntry{},
+	}
+	if entry == nil {
+		return config, nil
+	}
+
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode EST configuration: %v", err)}
+	}
+
+	return config, nil
+}
+
+func (sc *storageContext) setEstConfig(entry *estConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageEstConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathConfigEst(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/est",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether EST is enabled, defaults to false meaning that clusters will by default not get EST support`,
+				Default:     false,
+			},
+			"default_path_policy": {
+				Type:        framework.TypeString,
+				Description: `the policy to be used for EST requests without a label; either "sign-verbatim" or a role as "role:<role_name>"; by default these requests are refused and only labels configured in label_to_path_policy can be used`,
+				Default:     "",
+			},
+			"label_to_path_policy": {
+				Type:        framework.TypeKVPairs,
+				Description: `a map of labels, usable as /pki/est/<label>/, to the policy used for requests under them, in the same format as default_path_policy`,
+			},
+			"authenticators": {
+				Type:        framework.TypeMap,
+				Description: `the auth mounts EST clients authenticate through; a "cert" entry takes the "accessor" of a cert auth mount and the "cert_role" to log in with, a "userpass" entry takes the "accessor" of a userpass auth mount used with HTTP Basic authentication`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "est-configuration",
+				},
+				Callback: b.pathEstConfigRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathEstConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "est",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigEstHelpSyn,
+		HelpDescription: pathConfigEstHelpDesc,
+	}
+}
+
+func (b *backend) pathEstConfigRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromEstConfig(config), nil
+}
+
+// genResponseFromEstConfig builds the config/est read response. Vault core
+// reads enabled and authenticators from it to log EST clients in on their
+// behalf; keep those fields stable.
+func genResponseFromEstConfig(config *estConfigEntry) *logical.Response {
+	authenticators := map[string]interface{}{}
+	for name, authenticator := range config.Authenticators {
+		entry := map[string]interface{}{
+			"accessor": authenticator.Accessor,
+		}
+		if name == estAuthenticatorCert {
+			entry["cert_role"] = authenticator.CertRole
+		}
+		authenticators[name] = entry
+	}
+
+	response := &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":              config.Enabled,
+			"default_path_policy":  config.DefaultPathPolicy,
+			"label_to_path_policy": config.LabelToPathPolicy,
+			"authenticators":       authenticators,
+		},
+	}
+	if !config.LastUpdated.IsZero() {
+		response.Data["last_updated"] = config.LastUpdated.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func (b *backend) pathEstConfigWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if defaultPathPolicyRaw, ok := d.GetOk("default_path_policy"); ok {
+		config.DefaultPathPolicy = defaultPathPolicyRaw.(string)
+	}
+
+	if labelsRaw, ok := d.GetOk("label_to_path_policy"); ok {
+		config.LabelToPathPolicy = labelsRaw.(map[string]string)
+	}
+
+	if authenticatorsRaw, ok := d.GetOk("authenticators"); ok {
+		authenticators, err := parseEstAuthenticators(authenticatorsRaw.(map[string]interface{}))
+		if err != nil {
+			return logical.ErrorResponse("invalid authenticators: %v", err), nil
+		}
+		config.Authenticators = authenticators
+	}
+
+	if config.DefaultPathPolicy != "" {
+		if _, err := getEstRoleForPolicy(sc, config.DefaultPathPolicy); err != nil {
+			return logical.ErrorResponse("invalid default_path_policy: %v", err), nil
+		}
+	}
+
+	for label, policy := range config.LabelToPathPolicy {
+		if !estLabelRegex.MatchString(label) {
+			return logical.ErrorResponse("invalid label %q: labels may only contain alphanumeric characters, dashes, underscores and periods", label), nil
+		}
+		if _, err := getEstRoleForPolicy(sc, policy); err != nil {
+			return logical.ErrorResponse("invalid policy for label %q: %v", label, err), nil
+		}
+	}
+
+	if config.Enabled && len(config.Authenticators) == 0 {
+		return logical.ErrorResponse("at least one authenticator must be configured to enable EST"), nil
+	}
+
+	config.LastUpdated = time.Now()
+	if err := sc.setEstConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromEstConfig(config), nil
+}
+
+func parseEstAuthenticators(raw map[string]interface{}) (map[string]*estAuthenticatorEntry, error) {
+	authenticators := map[string]*estAuthenticatorEntry{}
+	for name, value := range raw {
+		if name != estAuthenticatorCert && name != estAuthenticatorUserpass {
+			return nil, fmt.Errorf("unknown authenticator %q; expected %q or %q", name, estAuthenticatorCert, estAuthenticatorUserpass)
+		}
+
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("authenticator %q must be a map", name)
+		}
+
+		entry := &estAuthenticatorEntry{}
+		for key, fieldRaw := range fields {
+			field, ok := fieldRaw.(string)
+			if !ok {
+				return nil, fmt.Errorf("field %q of authenticator %q must be a string", key, name)
+			}
+			switch {
+			case key == "accessor":
+				entry.Accessor = field
+			case key == "cert_role" && name == estAuthenticatorCert:
+				entry.CertRole = field
+			default:
+				return nil, fmt.Errorf("unknown field %q for authenticator %q", key, name)
+			}
+		}
+
+		if entry.Accessor == "" {
+			return nil, fmt.Errorf("authenticator %q requires an accessor", name)
+		}
+		if name == estAuthenticatorCert && entry.CertRole == "" {
+			return nil, fmt.Errorf("authenticator %q requires a cert_role", name)
+		}
+
+		authenticators[name] = entry
+	}
+
+	return authenticators, nil
+}
+
+// getEstRoleForPolicy returns the role EST requests under the given path
+// policy are issued with.
+func getEstRoleForPolicy(sc *storageContext, policy string) (*roleEntry, error) {
+	switch {
+	case policy == "sign-verbatim":
+		return buildSignVerbatimRoleWithNoData(&roleEntry{
+			Issuer: defaultRef,
+		}), nil
+	case strings.HasPrefix(policy, rolePrefix):
+		roleName := policy[rolePrefixLength:]
+		if roleName == "" {
+			return nil, fmt.Errorf("no role specified by policy %v", policy)
+		}
+
+		role, err := sc.Backend.getRole(sc.Context, sc.Storage, roleName)
+		if err != nil {
+			return nil, fmt.Errorf("failed loading role %v: %w", roleName, err)
+		}
+		if role == nil {
+			return nil, fmt.Errorf("role %v does not exist", roleName)
+		}
+		return role, nil
+	default:
+		return nil, fmt.Errorf("string %v not a valid path policy; expected \"sign-verbatim\" or \"role:<role_name>\"", policy)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageScepConfig = "config/scep"
+
+	defaultScepChallengeTTL = 1 * time.Hour
+
+	pathConfigScepHelpSyn  = "Configuration of SCEP Endpoints"
+	pathConfigScepHelpDesc = "Here we configure:\n\nenabled=false, whether SCEP is enabled, defaults to false meaning that clusters will by default not get SCEP support,\nrole=\"\", the role certificates requested over SCEP are issued with,\nchallenge_ttl=\"1h\", how long challenge passwords generated at /pki/scep/challenge remain valid"
+)
+
+type scepConfigEntry struct {
+	Enabled      bool          `json:"enabled"`
+	Role         string        `json:"role"`
+	ChallengeTTL time.Duration `json:"challenge_ttl"`
+	LastUpdated  time.Time     `json:"last_updated"`
+}
+
+func (sc *storageContext) getScepConfig() (*scepConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageScepConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &scepConfigEntry{
+		ChallengeTTL: defaultScepChallengeTTL,
+	}
+	if entry == nil {
+		return config, nil
+	}
+
+	if err := entry.DecodeJSON(config); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode SCEP configuration: %v", err)}
+	}
+
+	return config, nil
+}
+
+func (sc *storageContext) setScepConfig(entry *scepConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageScepConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathConfigScep(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/scep",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether SCEP is enabled, defaults to false meaning that clusters will by default not get SCEP support`,
+				Default:     false,
+			},
+			"role": {
+				Type:        framework.TypeString,
+				Description: `the role certificates requested over SCEP are issued with; required to enable SCEP. The role's issuer must have an RSA key, as SCEP clients encrypt their requests to it`,
+			},
+			"challenge_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: `how long challenge passwords generated at /pki/scep/challenge remain valid, defaults to one hour`,
+				Default:     int(defaultScepChallengeTTL.Seconds()),
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "scep-configuration",
+				},
+				Callback: b.pathScepConfigRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathScepConfigWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "scep",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigScepHelpSyn,
+		HelpDescription: pathConfigScepHelpDesc,
+	}
+}
+
+func (b *backend) pathScepConfigRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromScepConfig(config), nil
+}
+
+func genResponseFromScepConfig(config *scepConfigEntry) *logical.Response {
+	response := &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":       config.Enabled,
+			"role":          config.Role,
+			"challenge_ttl": int64(config.ChallengeTTL.Seconds()),
+		},
+	}
+	if !config.LastUpdated.IsZero() {
+		response.Data["last_updated"] = config.LastUpdated.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func (b *backend) pathScepConfigWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if roleRaw, ok := d.GetOk("role"); ok {
+		config.Role = roleRaw.(string)
+	}
+
+	if challengeTTLRaw, ok := d.GetOk("challenge_ttl"); ok {
+		config.ChallengeTTL = time.Duration(challengeTTLRaw.(int)) * time.Second
+	}
+	if config.ChallengeTTL <= 0 {
+		return logical.ErrorResponse("challenge_ttl must be positive"), nil
+	}
+
+	if config.Enabled {
+		if config.Role == "" {
+			return logical.ErrorResponse("a role must be configured to enable SCEP"), nil
+		}
+		if _, _, err := b.getScepRoleAndCA(sc, config); err != nil {
+			return logical.ErrorResponse("unable to enable SCEP: %v", err), nil
+		}
+	}
+
+	config.LastUpdated = time.Now()
+	if err := sc.setScepConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromScepConfig(config), nil
+}
+
+// getScepRoleAndCA loads the configured role and the CA of its issuer.
+// SCEP requests are encrypted to the CA certificate, so the issuer's key
+// must be an RSA key held by Vault.
+func (b *backend) getScepRoleAndCA(sc *storageContext, config *scepConfigEntry) (*roleEntry, *scepCA, error) {
+	role, err := b.getRole(sc.Context, sc.Storage, config.Role)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed loading role %v: %w", config.Role, err)
+	}
+	if role == nil {
+		return nil, nil, fmt.Errorf("role %v does not exist", config.Role)
+	}
+	if role.Issuer == "" {
+		role.Issuer = defaultRef
+	}
+
+	bundle, err := sc.fetchCAInfo(role.Issuer, IssuanceUsage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed loading issuer %v of role %v: %w", role.Issuer, config.Role, err)
+	}
+	key, ok := bundle.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("issuer %v of role %v does not have an RSA key", role.Issuer, config.Role)
+	}
+
+	return role, &scepCA{bundle: bundle, key: key}, nil
+}
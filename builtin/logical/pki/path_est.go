@@ -0,0 +1,375 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/pkcs7"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// EST (RFC 7030) endpoints. They are mounted both as /pki/est/<operation>
+// and /pki/est/<label>/<operation>; the label selects the path policy from
+// the EST configuration. Clients do not carry Vault tokens: Vault core logs
+// them in through the configured authenticators before the enrollment
+// requests reach the backend.
+const (
+	estLabelParam = "label"
+
+	estContentTypeCerts    = "application/pkcs7-mime"
+	estContentTypeEnroll   = "application/pkcs7-mime; smime-type=certs-only"
+	estContentTypeCSRAttrs = "application/csrattrs"
+
+	// estMaximumRequestSize bounds the base64 encoded CSR we are willing to
+	// read; large RSA keys with many SANs stay well below it.
+	estMaximumRequestSize = 64 * 1024
+)
+
+var (
+	estLabelRegex = regexp.MustCompile("^" + framework.GenericNameRegex(estLabelParam) + "$")
+
+	oidPublicKeyRSA     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidPublicKeyECDSA   = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidPublicKeyEd25519 = asn1.ObjectIdentifier{1, 3, 101, 112}
+)
+
+func buildEstPaths(b *backend) []*framework.Path {
+	var paths []*framework.Path
+	for _, prefix := range []string{
+		"est",
+		"est/" + framework.GenericNameRegex(estLabelParam),
+	} {
+		paths = append(paths,
+			pathEstCACerts(b, prefix),
+			pathEstCSRAttrs(b, prefix),
+			pathEstSimpleEnroll(b, prefix, "simpleenroll", false),
+			pathEstSimpleEnroll(b, prefix, "simplereenroll", true),
+		)
+	}
+	return paths
+}
+
+func estFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		estLabelParam: {
+			Type:        framework.TypeString,
+			Description: `EST label selecting the path policy; when absent the default path policy is used`,
+		},
+	}
+}
+
+func pathEstCACerts(b *backend, prefix string) *framework.Path {
+	return &framework.Path{
+		Pattern: prefix + "/cacerts",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "read",
+			OperationSuffix: "est-ca-certificates",
+		},
+
+		Fields: estFields(),
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathEstCACerts,
+			},
+		},
+
+		HelpSynopsis:    pathEstCACertsHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+func pathEstCSRAttrs(b *backend, prefix string) *framework.Path {
+	return &framework.Path{
+		Pattern: prefix + "/csrattrs",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "read",
+			OperationSuffix: "est-csr-attributes",
+		},
+
+		Fields: estFields(),
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathEstCSRAttrs,
+			},
+		},
+
+		HelpSynopsis:    pathEstCSRAttrsHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+func pathEstSimpleEnroll(b *backend, prefix, operation string, reenroll bool) *framework.Path {
+	suffix := "est-enroll"
+	if reenroll {
+		suffix = "est-reenroll"
+	}
+
+	return &framework.Path{
+		Pattern: prefix + "/" + operation,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "sign",
+			OperationSuffix: suffix,
+		},
+
+		Fields: estFields(),
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: func(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+					return b.pathEstSimpleEnroll(ctx, req, data, reenroll)
+				},
+				// The request body is the raw CSR, which can't be replayed
+				// once the backend has consumed it; forward before that.
+				ForwardPerformanceStandby: true,
+			},
+		},
+
+		HelpSynopsis:    pathEstSimpleEnrollHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+// getEstRole resolves the role for the request's label. Requests are
+// refused with a 404 if EST is disabled or the label is unknown.
+func (b *backend) getEstRole(sc *storageContext, data *framework.FieldData) (*roleEntry, error) {
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return nil, logical.CodedError(http.StatusNotFound, "EST is disabled on this mount")
+	}
+
+	policy := config.DefaultPathPolicy
+	if label := data.Get(estLabelParam).(string); label != "" {
+		var ok bool
+		policy, ok = config.LabelToPathPolicy[label]
+		if !ok {
+			return nil, logical.CodedError(http.StatusNotFound, fmt.Sprintf("unknown EST label %q", label))
+		}
+	}
+	if policy == "" {
+		return nil, logical.CodedError(http.StatusNotFound, "EST requests without a label are disabled on this mount")
+	}
+
+	role, err := getEstRoleForPolicy(sc, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading EST path policy %v: %w", policy, err)
+	}
+	if role.Issuer == "" {
+		role.Issuer = defaultRef
+	}
+
+	return role, nil
+}
+
+func (b *backend) pathEstCACerts(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, err := b.getEstRole(sc, data)
+	if err != nil {
+		return nil, err
+	}
+
+	issuerId, err := sc.resolveIssuerReference(role.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving issuer %v: %w", role.Issuer, err)
+	}
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []byte
+	for _, certPem := range issuer.CAChain {
+		block, _ := pem.Decode([]byte(certPem))
+		if block == nil {
+			return nil, fmt.Errorf("failed decoding CA chain of issuer %v", issuerId)
+		}
+		chain = append(chain, block.Bytes...)
+	}
+
+	certs, err := pkcs7.DegenerateCertificate(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CA certificates: %w", err)
+	}
+
+	return estRawResponse(estContentTypeCerts, certs), nil
+}
+
+func (b *backend) pathEstCSRAttrs(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, err := b.getEstRole(sc, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The only attribute we require is the key type; without one, tell the
+	// client there are no requirements.
+	var attrs []asn1.ObjectIdentifier
+	switch role.KeyType {
+	case "rsa":
+		attrs = append(attrs, oidPublicKeyRSA)
+	case "ec":
+		attrs = append(attrs, oidPublicKeyECDSA)
+	case "ed25519":
+		attrs = append(attrs, oidPublicKeyEd25519)
+	default:
+		return &logical.Response{
+			Data: map[string]interface{}{
+				logical.HTTPStatusCode: http.StatusNoContent,
+			},
+		}, nil
+	}
+
+	encoded, err := asn1.Marshal(attrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CSR attributes: %w", err)
+	}
+
+	return estRawResponse(estContentTypeCSRAttrs, encoded), nil
+}
+
+func (b *backend) pathEstSimpleEnroll(ctx context.Context, req *logical.Request, data *framework.FieldData, reenroll bool) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, err := b.getEstRole(sc, data)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := parseEstCSR(req)
+	if err != nil {
+		return logical.ErrorResponse("failed parsing certificate request: %v", err), nil
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return logical.ErrorResponse("invalid signature on certificate request: %v", err), nil
+	}
+
+	if reenroll {
+		if errResp, err := b.checkEstReenrollment(sc, req, csr); err != nil || errResp != nil {
+			return errResp, err
+		}
+	}
+
+	cert, errResp, err := b.signEnrollmentCSR(ctx, req, role, csr)
+	if err != nil || errResp != nil {
+		return errResp, err
+	}
+	certs, err := pkcs7.DegenerateCertificate(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding issued certificate: %w", err)
+	}
+
+	return estRawResponse(estContentTypeEnroll, certs), nil
+}
+
+// checkEstReenrollment verifies that the client authenticated with a
+// current certificate of this mount and that the request renews it as-is,
+// as required by RFC 7030 section 4.2.2.
+func (b *backend) checkEstReenrollment(sc *storageContext, req *logical.Request, csr *x509.CertificateRequest) (*logical.Response, error) {
+	if req.Connection == nil || req.Connection.ConnState == nil || len(req.Connection.ConnState.PeerCertificates) == 0 {
+		return logical.ErrorResponse("re-enrollment requires a TLS client certificate"), nil
+	}
+	current := req.Connection.ConnState.PeerCertificates[0]
+	serial := serialFromCert(current)
+
+	entry, err := fetchCertBySerial(sc, "certs/", serial)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || !bytes.Equal(entry.Value, current.Raw) {
+		return logical.ErrorResponse("client certificate was not issued by this mount"), nil
+	}
+
+	revoked, err := fetchCertBySerial(sc, "revoked/", serial)
+	if err != nil {
+		return nil, err
+	}
+	if revoked != nil {
+		return logical.ErrorResponse("client certificate has been revoked"), nil
+	}
+
+	if !bytes.Equal(csr.RawSubject, current.RawSubject) ||
+		!slices.Equal(csr.DNSNames, current.DNSNames) ||
+		!slices.Equal(csr.EmailAddresses, current.EmailAddresses) ||
+		!slices.EqualFunc(csr.IPAddresses, current.IPAddresses, net.IP.Equal) ||
+		!slices.EqualFunc(csr.URIs, current.URIs, func(a, b *url.URL) bool { return a.String() == b.String() }) {
+		return logical.ErrorResponse("the subject and subject alternative names of a re-enrollment request must match the client certificate"), nil
+	}
+
+	return nil, nil
+}
+
+// parseEstCSR reads the PKCS#10 request from the raw request body. RFC 7030
+// requires it to be base64 encoded, but DER is accepted as well.
+func parseEstCSR(req *logical.Request) (*x509.CertificateRequest, error) {
+	if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+		return nil, errors.New("no data in request body; the request must be sent with Content-Type application/pkcs10")
+	}
+	defer req.HTTPRequest.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, estMaximumRequestSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) >= estMaximumRequestSize {
+		return nil, errors.New("request is too large")
+	}
+
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		der = body
+	}
+
+	return x509.ParseCertificateRequest(der)
+}
+
+// estRawResponse returns the DER body base64 encoded, as EST requires.
+func estRawResponse(contentType string, der []byte) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: contentType,
+			logical.HTTPRawBody:     []byte(base64.StdEncoding.EncodeToString(der)),
+			logical.HTTPStatusCode:  http.StatusOK,
+		},
+	}
+}
+
+const pathEstCACertsHelpSyn = `Fetch the EST CA certificates as a PKCS#7 certs-only message.`
+
+const pathEstCSRAttrsHelpSyn = `Fetch the attributes EST clients should include in certificate requests.`
+
+const pathEstSimpleEnrollHelpSyn = `Enroll or re-enroll a certificate over EST.`
+
+const pathEstHelpDesc = `
+These endpoints implement the EST (RFC 7030) cacerts, csrattrs, simpleenroll
+and simplereenroll operations. Requests are issued according to the path
+policy configured at config/est, either for the default path or for the
+label in the request path. Enrollment requests authenticate through the
+cert or userpass auth mount configured as an authenticator rather than with
+a Vault token.
+`
@@ -0,0 +1,302 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/builtin/audit/file"
+	"github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/helper/pkcs7"
+	vaulthttp "github.com/hashicorp/vault/http"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEst_Enrollment(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root.example.com",
+		"key_type":    "ec",
+		"issuer_name": "root",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	resp, err = CBWrite(b, s, "roles/devices", map[string]interface{}{
+		"allowed_domains":  "devices.example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+		"ttl":              "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// EST is disabled until configured.
+	_, err = CBRead(b, s, "est/cacerts")
+	require.Error(t, err)
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"enabled":             true,
+		"default_path_policy": "bad",
+		"authenticators": map[string]interface{}{
+			"userpass": map[string]interface{}{"accessor": "auth_userpass_1234"},
+		},
+	})
+	require.Error(t, err, "expected invalid default_path_policy to be rejected")
+
+	resp, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"enabled": true,
+		"label_to_path_policy": map[string]interface{}{
+			"devices": "role:devices",
+		},
+		"authenticators": map[string]interface{}{
+			"userpass": map[string]interface{}{"accessor": "auth_userpass_1234"},
+		},
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = CBRead(b, s, "config/est")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, map[string]string{"devices": "role:devices"}, resp.Data["label_to_path_policy"])
+
+	// Only labelled requests are allowed without a default path policy.
+	_, err = CBRead(b, s, "est/cacerts")
+	require.Error(t, err)
+	_, err = CBRead(b, s, "est/unknown/cacerts")
+	require.Error(t, err)
+
+	resp, err = CBRead(b, s, "est/devices/cacerts")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, estContentTypeCerts, resp.Data[logical.HTTPContentType])
+	certs := parseEstCerts(t, resp)
+	require.Len(t, certs, 1)
+	require.Equal(t, rootCert.Raw, certs[0].Raw)
+
+	resp, err = CBRead(b, s, "est/devices/csrattrs")
+	requireSuccessNonNilResponse(t, resp, err)
+	der, err := base64.StdEncoding.DecodeString(string(resp.Data[logical.HTTPRawBody].([]byte)))
+	require.NoError(t, err)
+	var attrs []asn1.ObjectIdentifier
+	_, err = asn1.Unmarshal(der, &attrs)
+	require.NoError(t, err)
+	require.Equal(t, []asn1.ObjectIdentifier{oidPublicKeyECDSA}, attrs)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr := func(cn string) []byte {
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject:  pkix.Name{CommonName: cn},
+			DNSNames: []string{cn},
+		}, key)
+		require.NoError(t, err)
+		return []byte(base64.StdEncoding.EncodeToString(der))
+	}
+	enroll := func(path string, body []byte, conn *logical.Connection) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation:   logical.UpdateOperation,
+			Path:        path,
+			Storage:     s,
+			Connection:  conn,
+			HTTPRequest: &http.Request{Body: io.NopCloser(bytes.NewReader(body))},
+		})
+	}
+
+	// The role's restrictions apply.
+	resp, err = enroll("est/devices/simpleenroll", csr("host.other.com"), nil)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "expected CSR outside of the role's domains to be rejected")
+
+	resp, err = enroll("est/devices/simpleenroll", csr("host.devices.example.com"), nil)
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, estContentTypeEnroll, resp.Data[logical.HTTPContentType])
+	certs = parseEstCerts(t, resp)
+	require.Len(t, certs, 1)
+	leaf := certs[0]
+	require.Equal(t, "host.devices.example.com", leaf.Subject.CommonName)
+	requireSignedBy(t, leaf, rootCert)
+
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(leaf))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// Re-enrollment requires the current certificate and the same subject.
+	resp, err = enroll("est/devices/simplereenroll", csr("host.devices.example.com"), nil)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "expected re-enrollment without a client certificate to be rejected")
+
+	conn := &logical.Connection{ConnState: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}}
+	resp, err = enroll("est/devices/simplereenroll", csr("other.devices.example.com"), conn)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "expected re-enrollment with a different subject to be rejected")
+
+	resp, err = enroll("est/devices/simplereenroll", csr("host.devices.example.com"), conn)
+	requireSuccessNonNilResponse(t, resp, err)
+	renewed := parseEstCerts(t, resp)[0]
+	require.NotEqual(t, leaf.SerialNumber, renewed.SerialNumber)
+	require.Equal(t, leaf.RawSubject, renewed.RawSubject)
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(leaf),
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = enroll("est/devices/simplereenroll", csr("host.devices.example.com"), conn)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "expected re-enrollment with a revoked certificate to be rejected")
+}
+
+func TestEst_UserpassAuthentication(t *testing.T) {
+	t.Parallel()
+
+	coreConfig := &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"userpass": userpass.Factory,
+		},
+		LogicalBackends: map[string]logical.Factory{
+			"pki": Factory,
+		},
+		AuditBackends: map[string]audit.Factory{
+			"file": file.Factory,
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: vaulthttp.Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+	client := cluster.Cores[0].Client
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, client.Sys().EnableAuditWithOptions("file", &api.EnableAuditOptions{
+		Type: "file",
+		Options: map[string]string{
+			"file_path": auditPath,
+		},
+	}))
+
+	require.NoError(t, client.Sys().PutPolicy("est", `
+path "pki/est/*" {
+  capabilities = ["update"]
+}`))
+	require.NoError(t, client.Sys().EnableAuth("userpass", "userpass", ""))
+	_, err := client.Logical().Write("auth/userpass/users/device", map[string]interface{}{
+		"password":       "secret",
+		"token_policies": "est",
+	})
+	require.NoError(t, err)
+	auths, err := client.Sys().ListAuth()
+	require.NoError(t, err)
+
+	mountPKIEndpoint(t, client, "pki")
+	_, err = client.Logical().Write("pki/root/generate/internal", map[string]interface{}{
+		"common_name": "root.example.com",
+		"key_type":    "ec",
+		"ttl":         "8760h",
+	})
+	require.NoError(t, err)
+	_, err = client.Logical().Write("pki/config/est", map[string]interface{}{
+		"enabled":             true,
+		"default_path_policy": "sign-verbatim",
+		"authenticators": map[string]interface{}{
+			"userpass": map[string]interface{}{"accessor": auths["userpass/"].Accessor},
+		},
+	})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "device.example.com"},
+	}, key)
+	require.NoError(t, err)
+
+	enroll := func(mount, username, password string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, client.Address()+"/v1/"+mount+"/est/simpleenroll",
+			strings.NewReader(base64.StdEncoding.EncodeToString(csr)))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/pkcs10")
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		resp, err := client.CloneConfig().HttpClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := enroll("pki", "", "")
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+
+	resp = enroll("pki", "device", "wrong")
+	resp.Body.Close()
+	require.NotEqual(t, http.StatusOK, resp.StatusCode)
+
+	// Only PKI mounts log in EST clients, even if another mount returns an
+	// EST configuration.
+	require.NoError(t, client.Sys().Mount("kv", &api.MountInput{Type: "kv"}))
+	_, err = client.Logical().Write("kv/config/est", map[string]interface{}{
+		"enabled": true,
+		"authenticators": map[string]interface{}{
+			"userpass": map[string]interface{}{"accessor": auths["userpass/"].Accessor},
+		},
+	})
+	require.NoError(t, err)
+	resp = enroll("kv", "", "")
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp = enroll("pki", "device", "secret")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	require.Equal(t, estContentTypeEnroll, resp.Header.Get("Content-Type"))
+	der, err := base64.StdEncoding.DecodeString(string(body))
+	require.NoError(t, err)
+	p7, err := pkcs7.Parse(der)
+	require.NoError(t, err)
+	require.Len(t, p7.Certificates, 1)
+	require.Equal(t, "device.example.com", p7.Certificates[0].Subject.CommonName)
+
+	// The token used for the request doesn't outlive it.
+	tokens, err := client.Logical().List("auth/token/accessors")
+	require.NoError(t, err)
+	require.Len(t, tokens.Data["keys"], 1, "expected only the root token to remain")
+
+	// Core's read of the EST configuration is audited.
+	auditLog, err := os.ReadFile(auditPath)
+	require.NoError(t, err)
+	require.Contains(t, string(auditLog), `"path":"pki/config/est"`)
+}
+
+func parseEstCerts(t *testing.T, resp *logical.Response) []*x509.Certificate {
+	t.Helper()
+
+	der, err := base64.StdEncoding.DecodeString(string(resp.Data[logical.HTTPRawBody].([]byte)))
+	require.NoError(t, err)
+	p7, err := pkcs7.Parse(der)
+	require.NoError(t, err)
+	return p7.Certificates
+}
@@ -209,6 +209,15 @@ to be set on all PR secondary clusters.`,
 		Default: false,
 	}
 
+	fields["scheduled_rotation_time"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `RFC 3339 timestamp at which this issuer is scheduled
+to be rotated out. ACME clients asking for renewal information about
+certificates issued by this issuer are told to renew before this time.
+The empty string (the default) clears any scheduled rotation.`,
+		Default: "",
+	}
+
 	updateIssuerSchema := map[int][]framework.Response{
 		http.StatusOK: {{
 			Description: "OK",
@@ -291,6 +300,11 @@ to be set on all PR secondary clusters.`,
 					Description: `Whether or not templating is enabled for AIA fields`,
 					Required:    false,
 				},
+				"scheduled_rotation_time": {
+					Type:        framework.TypeString,
+					Description: `Time at which this issuer is scheduled to be rotated out`,
+					Required:    false,
+				},
 			},
 		}},
 	}
@@ -459,6 +473,10 @@ func respondReadIssuer(issuer *issuerEntry) (*logical.Response, error) {
 		data["revocation_time_rfc3339"] = issuer.RevocationTimeUTC.Format(time.RFC3339Nano)
 	}
 
+	if !issuer.ScheduledRotationTime.IsZero() {
+		data["scheduled_rotation_time"] = issuer.ScheduledRotationTime.Format(time.RFC3339)
+	}
+
 	if issuer.AIAURIs != nil {
 		data["issuing_certificates"] = issuer.AIAURIs.IssuingCertificates
 		data["crl_distribution_points"] = issuer.AIAURIs.CRLDistributionPoints
@@ -559,6 +577,11 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		return nil, err
 	}
 
+	newRotationTime, err := parseScheduledRotationTime(data.Get("scheduled_rotation_time").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	// AIA access changes
 	enableTemplating := data.Get("enable_aia_url_templating").(bool)
 	issuerCertificates := data.Get("issuing_certificates").([]string)
@@ -617,6 +640,11 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		modified = true
 	}
 
+	if !newRotationTime.Equal(issuer.ScheduledRotationTime) {
+		issuer.ScheduledRotationTime = newRotationTime
+		modified = true
+	}
+
 	if issuer.AIAURIs == nil && (len(issuerCertificates) > 0 || len(crlDistributionPoints) > 0 || len(ocspServers) > 0) {
 		issuer.AIAURIs = &aiaConfigEntry{}
 	}
@@ -862,6 +890,20 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
+	// Scheduled rotation changes
+	rawRotationTime, ok := data.GetOk("scheduled_rotation_time")
+	if ok {
+		newRotationTime, err := parseScheduledRotationTime(rawRotationTime.(string))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+		if !newRotationTime.Equal(issuer.ScheduledRotationTime) {
+			issuer.ScheduledRotationTime = newRotationTime
+			modified = true
+		}
+	}
+
 	// AIA access changes.
 	if issuer.AIAURIs == nil {
 		issuer.AIAURIs = &aiaConfigEntry{}
@@ -985,6 +1027,21 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 	return response, err
 }
 
+// parseScheduledRotationTime parses the scheduled_rotation_time issuer
+// field; the empty string clears the scheduled rotation.
+func parseScheduledRotationTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	rotationTime, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid value for field `scheduled_rotation_time`; expected an RFC 3339 timestamp: %w", err)
+	}
+
+	return rotationTime.UTC(), nil
+}
+
 func (b *backend) pathGetRawIssuer(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	if b.useLegacyBundleCaStorage() {
 		return logical.ErrorResponse("Can not get issuer until migration has completed"), nil
@@ -7,10 +7,13 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -345,6 +348,19 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 			`the "format" path parameter must be "pem", "der", or "pem_bundle"`), nil
 	}
 
+	var metadata []byte
+	if metadataRaw, ok := data.GetOk("cert_metadata"); ok && metadataRaw.(string) != "" {
+		if role.NoStore {
+			return logical.ErrorResponse("cert_metadata cannot be set when the role does not store certificates (no_store=true)"), nil
+		}
+
+		var err error
+		metadata, err = base64.StdEncoding.DecodeString(metadataRaw.(string))
+		if err != nil {
+			return logical.ErrorResponse("cert_metadata must be base64 encoded: %v", err), nil
+		}
+	}
+
 	var caErr error
 	sc := b.makeStorageContext(ctx, req.Storage)
 	signingBundle, caErr := sc.fetchCAInfo(issuerName, IssuanceUsage)
@@ -409,8 +425,31 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 			return nil, fmt.Errorf("unable to store certificate locally: %w", err)
 		}
 		b.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
+
+		// Until the issuer migration completes, the issuer has no ID to
+		// index the certificate by.
+		var issuerId issuerID
+		if !b.useLegacyBundleCaStorage() {
+			issuerId, err = sc.resolveIssuerReference(issuerName)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := sc.writeCertIndex(parsedBundle.Certificate, role.Name, issuerId, metadata); err != nil {
+			return nil, err
+		}
 	}
 
+	operation := "issue"
+	if useCSR {
+		operation = "sign"
+	}
+	b.certEvent(ctx, operation, parsedBundle.Certificate,
+		"role", role.Name,
+		"issuer_ref", issuerName,
+		"stored", strconv.FormatBool(!role.NoStore),
+	)
+
 	if useCSR {
 		if role.UseCSRCommonName && data.Get("common_name").(string) != "" {
 			resp.AddWarning("the common_name field was provided but the role is set with \"use_csr_common_name\" set to true")
@@ -425,6 +464,46 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 	return resp, nil
 }
 
+// signEnrollmentCSR signs a CSR received over a certificate enrollment
+// protocol (EST, SCEP) with the given role and returns the DER encoded
+// certificate. A role built for sign-verbatim takes the subject and
+// extensions from the CSR, exactly like the sign-verbatim endpoint; real
+// roles behave like sign/:role.
+func (b *backend) signEnrollmentCSR(ctx context.Context, req *logical.Request, role *roleEntry, csr *x509.CertificateRequest) ([]byte, *logical.Response, error) {
+	issuerRef := role.Issuer
+	if issuerRef == "" {
+		issuerRef = defaultRef
+	}
+
+	signData := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"csr": string(pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE REQUEST",
+				Bytes: csr.Raw,
+			})),
+			"format":       "der",
+			issuerRefParam: issuerRef,
+		},
+		Schema: addIssuerRefField(getCsrSignVerbatimSchemaFields()),
+	}
+
+	useCSRValues := role.Name == ""
+	resp, err := b.pathIssueSignCert(ctx, req, signData, role, true, useCSRValues)
+	if err != nil || resp.IsError() {
+		return nil, resp, err
+	}
+	if resp == nil {
+		return nil, nil, errors.New("no certificate was issued")
+	}
+
+	cert, err := base64.StdEncoding.DecodeString(resp.Data["certificate"].(string))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed decoding issued certificate: %w", err)
+	}
+
+	return cert, nil, nil
+}
+
 type caChainOutput struct {
 	chain []*certutil.CertBlock
 }
@@ -726,6 +726,10 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 			if err != nil {
 				return nil, fmt.Errorf("error saving revoked issuer to new location: %w", err)
 			}
+
+			if err := sc.markCertIndexRevoked(normalizeSerial(issuer.SerialNumber), &revInfo); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -638,6 +638,12 @@ func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, dat
 		if err != nil {
 			return nil, err
 		}
+
+		// The role and issuer of externally presented certificates are
+		// unknown; they're indexed by name and validity only.
+		if err := sc.writeCertIndex(cert, "", "", nil); err != nil {
+			return nil, err
+		}
 	}
 
 	// Assumption: this check is cheap. Call this twice, in the cert-import
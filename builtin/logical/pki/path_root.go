@@ -295,6 +295,9 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 		return nil, fmt.Errorf("unable to store certificate locally: %w", err)
 	}
 	b.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
+	if err := sc.writeCertIndex(parsedBundle.Certificate, "", myIssuer.ID, nil); err != nil {
+		return nil, err
+	}
 
 	// Build a fresh CRL
 	warnings, err = b.crlBuilder.rebuild(sc, true)
@@ -430,6 +433,17 @@ func (b *backend) pathIssuerSignIntermediate(ctx context.Context, req *logical.R
 	}
 	b.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
 
+	var issuerId issuerID
+	if !b.useLegacyBundleCaStorage() {
+		issuerId, err = sc.resolveIssuerReference(issuerName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := sc.writeCertIndex(parsedBundle.Certificate, "", issuerId, nil); err != nil {
+		return nil, err
+	}
+
 	return resp, nil
 }
 
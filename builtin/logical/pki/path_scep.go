@@ -0,0 +1,576 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/base62"
+	"github.com/hashicorp/vault/helper/pkcs7"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// SCEP (RFC 8894) endpoints. Clients are unauthenticated; a PKCSReq is
+// authorized by the challenge password in its CSR, which must be a one-time
+// secret generated at scep/challenge. Certificates are issued with the role
+// from config/scep.
+const (
+	scepChallengePrefix = "scep/challenges/"
+
+	scepOperationGetCACaps    = "GetCACaps"
+	scepOperationGetCACert    = "GetCACert"
+	scepOperationPKIOperation = "PKIOperation"
+
+	scepContentTypeMessage  = "application/x-pki-message"
+	scepContentTypeCACert   = "application/x-x509-ca-cert"
+	scepContentTypeCARACert = "application/x-x509-ca-ra-cert"
+
+	scepMessageTypeCertRep  = "3"
+	scepMessageTypePKCSReq  = "19"
+	scepPkiStatusSuccess    = "0"
+	scepPkiStatusFailure    = "2"
+	scepFailInfoBadAlg      = "0"
+	scepFailInfoBadMsgCheck = "1"
+	scepFailInfoBadRequest  = "2"
+
+	scepChallengeLength = 32
+	scepNonceLength     = 16
+
+	// scepMaximumRequestSize bounds the PKIOperation messages we are willing
+	// to read; they carry a single CSR and the client's certificate.
+	scepMaximumRequestSize = 64 * 1024
+)
+
+// scepCapabilities are returned by GetCACaps. SCEPStandard implies AES,
+// POSTPKIOperation and SHA-256, but older clients only look for the
+// individual capabilities.
+var scepCapabilities = []string{"AES", "POSTPKIOperation", "SCEPStandard", "SHA-256"}
+
+var (
+	oidScepMessageType    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 2}
+	oidScepPkiStatus      = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 3}
+	oidScepFailInfo       = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 4}
+	oidScepSenderNonce    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 5}
+	oidScepRecipientNonce = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 6}
+	oidScepTransactionID  = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 7}
+	oidChallengePassword  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 7}
+)
+
+// scepCA is the issuer SCEP messages are decrypted and signed with.
+type scepCA struct {
+	bundle *certutil.CAInfoBundle
+	key    *rsa.PrivateKey
+}
+
+type scepChallengeEntry struct {
+	Expiration time.Time `json:"expiration"`
+}
+
+// scepMessage is the relevant content of a client's pkiMessage.
+type scepMessage struct {
+	messageType   string
+	transactionID string
+	senderNonce   []byte
+	signer        *x509.Certificate
+	envelope      []byte
+}
+
+func buildScepPaths(b *backend) []*framework.Path {
+	return []*framework.Path{
+		pathScep(b, "scep"),
+		pathScep(b, `scep/pkiclient\.exe`),
+		pathScepChallenge(b),
+	}
+}
+
+func pathScep(b *backend, pattern string) *framework.Path {
+	return &framework.Path{
+		Pattern: pattern,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"operation": {
+				Type:        framework.TypeString,
+				Description: `the SCEP operation: GetCACaps, GetCACert or PKIOperation`,
+				Query:       true,
+			},
+			"message": {
+				Type:        framework.TypeString,
+				Description: `the base64 encoded pkiMessage of a PKIOperation sent with GET`,
+				Query:       true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "query",
+					OperationSuffix: "scep",
+				},
+				Callback: b.pathScepRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "submit",
+					OperationSuffix: "scep-message",
+				},
+				Callback: b.pathScepWrite,
+				// The request body is the raw pkiMessage, which can't be
+				// replayed once the backend has consumed it; forward before
+				// that.
+				ForwardPerformanceStandby: true,
+			},
+		},
+
+		HelpSynopsis:    pathScepHelpSyn,
+		HelpDescription: pathScepHelpDesc,
+	}
+}
+
+func pathScepChallenge(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "scep/challenge",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "generate",
+			OperationSuffix: "scep-challenge",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback:                  b.pathScepChallengeWrite,
+				ForwardPerformanceStandby: true,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"challenge": {
+								Type:        framework.TypeString,
+								Description: `The one-time challenge password`,
+								Required:    true,
+							},
+							"expiration": {
+								Type:        framework.TypeTime,
+								Description: `The time after which the challenge password can no longer be used`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathScepChallengeHelpSyn,
+		HelpDescription: pathScepChallengeHelpDesc,
+	}
+}
+
+// getScepCA loads the configured role and CA, refusing the request with a
+// 404 if SCEP is disabled.
+func (b *backend) getScepCA(sc *storageContext) (*roleEntry, *scepCA, error) {
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !config.Enabled {
+		return nil, nil, logical.CodedError(http.StatusNotFound, "SCEP is disabled on this mount")
+	}
+
+	return b.getScepRoleAndCA(sc, config)
+}
+
+func (b *backend) pathScepRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	operation := data.Get("operation").(string)
+	if operation != scepOperationPKIOperation {
+		return b.handleScepOperation(ctx, req, operation, nil)
+	}
+
+	message, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data.Get("message").(string)), ""))
+	if err != nil || len(message) == 0 {
+		return nil, logical.CodedError(http.StatusBadRequest, "PKIOperation requires a base64 encoded message")
+	}
+	return b.handleScepOperation(ctx, req, operation, message)
+}
+
+func (b *backend) pathScepWrite(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+		return nil, logical.CodedError(http.StatusBadRequest, "no data in request body; the request must be sent with Content-Type "+scepContentTypeMessage)
+	}
+	defer req.HTTPRequest.Body.Close()
+
+	// POST data isn't parsed by Vault, so the operation is taken from the
+	// URL directly.
+	var operation string
+	if req.HTTPRequest.URL != nil {
+		operation = req.HTTPRequest.URL.Query().Get("operation")
+	}
+	if operation != scepOperationPKIOperation {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("unsupported SCEP operation %q for POST", operation))
+	}
+
+	message, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, scepMaximumRequestSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(message) >= scepMaximumRequestSize {
+		return nil, logical.CodedError(http.StatusRequestEntityTooLarge, "request is too large")
+	}
+
+	return b.handleScepOperation(ctx, req, operation, message)
+}
+
+func (b *backend) handleScepOperation(ctx context.Context, req *logical.Request, operation string, message []byte) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, ca, err := b.getScepCA(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case scepOperationGetCACaps:
+		return scepRawResponse("text/plain", []byte(strings.Join(scepCapabilities, "\n"))), nil
+	case scepOperationGetCACert:
+		return scepCACertResponse(ca)
+	case scepOperationPKIOperation:
+		return b.scepPKIOperation(ctx, req, role, ca, message)
+	default:
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("unsupported SCEP operation %q", operation))
+	}
+}
+
+// scepCACertResponse returns the CA certificate on its own, or a
+// certs-only PKCS#7 message with the whole chain for intermediates, as
+// described in RFC 8894 section 4.2.1.
+func scepCACertResponse(ca *scepCA) (*logical.Response, error) {
+	chain := ca.bundle.GetFullChain()
+	if len(chain) == 1 {
+		return scepRawResponse(scepContentTypeCACert, chain[0].Certificate.Raw), nil
+	}
+
+	var der []byte
+	for _, cert := range chain {
+		der = append(der, cert.Certificate.Raw...)
+	}
+	certs, err := pkcs7.DegenerateCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CA certificates: %w", err)
+	}
+
+	return scepRawResponse(scepContentTypeCARACert, certs), nil
+}
+
+// scepPKIOperation handles a PKCSReq. Once the client's message has been
+// verified, failures are reported to the client in a signed CertRep rather
+// than as HTTP errors.
+func (b *backend) scepPKIOperation(ctx context.Context, req *logical.Request, role *roleEntry, ca *scepCA, message []byte) (*logical.Response, error) {
+	msg, err := parseScepMessage(message)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("failed parsing SCEP message: %v", err))
+	}
+
+	if msg.messageType != scepMessageTypePKCSReq {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadRequest, nil)
+	}
+
+	envelope, err := pkcs7.Parse(msg.envelope)
+	if err != nil {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadMsgCheck, nil)
+	}
+	csrDER, err := envelope.Decrypt(ca.bundle.Certificate, ca.key)
+	if err != nil {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadAlg, nil)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadRequest, nil)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadMsgCheck, nil)
+	}
+
+	challenge, err := scepChallengePassword(csr)
+	if err != nil {
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadRequest, nil)
+	}
+	valid, err := b.consumeScepChallenge(ctx, req.Storage, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		b.Logger().Debug("rejected SCEP request with an unknown or expired challenge password", "transaction_id", msg.transactionID)
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadRequest, nil)
+	}
+
+	cert, errResp, err := b.signEnrollmentCSR(ctx, req, role, csr)
+	if err != nil {
+		return nil, err
+	}
+	if errResp != nil {
+		b.Logger().Debug("rejected SCEP request", "transaction_id", msg.transactionID, "error", errResp.Error())
+		return ca.certRep(msg, scepPkiStatusFailure, scepFailInfoBadRequest, nil)
+	}
+
+	certs, err := pkcs7.DegenerateCertificate(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding issued certificate: %w", err)
+	}
+	encrypted, err := pkcs7.EncryptWithAlgorithm(certs, []*x509.Certificate{msg.signer}, pkcs7.EncryptionAlgorithmAES128CBC)
+	if err != nil {
+		return nil, logical.CodedError(http.StatusBadRequest, fmt.Sprintf("failed encrypting certificate to the request's signer: %v", err))
+	}
+
+	return ca.certRep(msg, scepPkiStatusSuccess, "", encrypted)
+}
+
+// parseScepMessage verifies the signature of a pkiMessage and extracts its
+// attributes. The signer is the client's (usually self-signed) certificate
+// the response is encrypted to, so it isn't checked against a trust store.
+func parseScepMessage(der []byte) (*scepMessage, error) {
+	p7, err := pkcs7.Parse(der)
+	if err != nil {
+		return nil, err
+	}
+	if err := p7.Verify(); err != nil {
+		return nil, err
+	}
+
+	msg := &scepMessage{
+		signer:   p7.GetOnlySigner(),
+		envelope: p7.Content,
+	}
+	if msg.signer == nil {
+		return nil, errors.New("message must have exactly one signer")
+	}
+	if err := p7.UnmarshalSignedAttribute(oidScepMessageType, &msg.messageType); err != nil {
+		return nil, fmt.Errorf("missing messageType: %w", err)
+	}
+	if err := p7.UnmarshalSignedAttribute(oidScepTransactionID, &msg.transactionID); err != nil {
+		return nil, fmt.Errorf("missing transactionID: %w", err)
+	}
+	if err := p7.UnmarshalSignedAttribute(oidScepSenderNonce, &msg.senderNonce); err != nil {
+		return nil, fmt.Errorf("missing senderNonce: %w", err)
+	}
+
+	return msg, nil
+}
+
+// certRep builds a signed CertRep answering msg. The content, if any, must
+// already be encrypted to the requester.
+func (ca *scepCA) certRep(msg *scepMessage, status, failInfo string, content []byte) (*logical.Response, error) {
+	nonce := make([]byte, scepNonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	attrs := []pkcs7.Attribute{
+		{Type: oidScepMessageType, Value: scepMessageTypeCertRep},
+		{Type: oidScepPkiStatus, Value: status},
+		{Type: oidScepTransactionID, Value: msg.transactionID},
+		{Type: oidScepSenderNonce, Value: nonce},
+		{Type: oidScepRecipientNonce, Value: msg.senderNonce},
+	}
+	if status == scepPkiStatusFailure {
+		attrs = append(attrs, pkcs7.Attribute{Type: oidScepFailInfo, Value: failInfo})
+	}
+
+	sd, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, err
+	}
+	if err := sd.AddSigner(ca.bundle.Certificate, ca.key, pkcs7.SignerInfoConfig{ExtraSignedAttributes: attrs}); err != nil {
+		return nil, fmt.Errorf("failed signing SCEP response: %w", err)
+	}
+	signed, err := sd.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding SCEP response: %w", err)
+	}
+
+	return scepRawResponse(scepContentTypeMessage, signed), nil
+}
+
+// scepChallengePassword returns the challengePassword attribute of the CSR.
+// crypto/x509 skips attributes that aren't extension requests, so they are
+// parsed here directly.
+func scepChallengePassword(csr *x509.CertificateRequest) (string, error) {
+	var tbs struct {
+		Version       int
+		Subject       asn1.RawValue
+		PublicKey     asn1.RawValue
+		RawAttributes []asn1.RawValue `asn1:"tag:0"`
+	}
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return "", err
+	}
+
+	for _, rawAttr := range tbs.RawAttributes {
+		var attr struct {
+			Type   asn1.ObjectIdentifier
+			Values []asn1.RawValue `asn1:"set"`
+		}
+		if _, err := asn1.Unmarshal(rawAttr.FullBytes, &attr); err != nil {
+			continue
+		}
+		if !attr.Type.Equal(oidChallengePassword) || len(attr.Values) != 1 {
+			continue
+		}
+
+		var password string
+		if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &password); err != nil {
+			return "", fmt.Errorf("failed parsing challengePassword: %w", err)
+		}
+		return password, nil
+	}
+
+	return "", errors.New("certificate request has no challengePassword")
+}
+
+func (b *backend) pathScepChallengeWrite(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return logical.ErrorResponse("SCEP is disabled on this mount"), nil
+	}
+
+	challenge, err := base62.Random(scepChallengeLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed generating challenge password: %w", err)
+	}
+
+	// Only a hash of the challenge is kept; it is as good as a credential
+	// until it is used.
+	expiration := time.Now().Add(config.ChallengeTTL)
+	entry, err := logical.StorageEntryJSON(scepChallengePath(challenge), &scepChallengeEntry{
+		Expiration: expiration,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed storing challenge password: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"challenge":  challenge,
+			"expiration": expiration.Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// consumeScepChallenge removes the challenge password, returning whether it
+// was valid.
+func (b *backend) consumeScepChallenge(ctx context.Context, s logical.Storage, challenge string) (bool, error) {
+	b.scepChallengeLock.Lock()
+	defer b.scepChallengeLock.Unlock()
+
+	path := scepChallengePath(challenge)
+	entry, err := s.Get(ctx, path)
+	if err != nil {
+		return false, fmt.Errorf("failed reading challenge password: %w", err)
+	}
+	if entry == nil {
+		return false, nil
+	}
+	if err := s.Delete(ctx, path); err != nil {
+		return false, fmt.Errorf("failed removing challenge password: %w", err)
+	}
+
+	var stored scepChallengeEntry
+	if err := entry.DecodeJSON(&stored); err != nil {
+		return false, fmt.Errorf("failed decoding challenge password: %w", err)
+	}
+
+	return time.Now().Before(stored.Expiration), nil
+}
+
+// tidyScepChallenges removes expired challenge passwords.
+func (b *backend) tidyScepChallenges(sc *storageContext) error {
+	b.scepChallengeLock.Lock()
+	defer b.scepChallengeLock.Unlock()
+
+	hashes, err := sc.Storage.List(sc.Context, scepChallengePrefix)
+	if err != nil {
+		return fmt.Errorf("failed listing challenge passwords: %w", err)
+	}
+
+	now := time.Now()
+	for _, hash := range hashes {
+		entry, err := sc.Storage.Get(sc.Context, scepChallengePrefix+hash)
+		if err != nil {
+			return fmt.Errorf("failed reading challenge password: %w", err)
+		}
+		if entry == nil {
+			continue
+		}
+
+		var stored scepChallengeEntry
+		if err := entry.DecodeJSON(&stored); err == nil && now.Before(stored.Expiration) {
+			continue
+		}
+		if err := sc.Storage.Delete(sc.Context, scepChallengePrefix+hash); err != nil {
+			return fmt.Errorf("failed removing challenge password: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func scepChallengePath(challenge string) string {
+	hash := sha256.Sum256([]byte(challenge))
+	return scepChallengePrefix + hex.EncodeToString(hash[:])
+}
+
+// scepRawResponse returns the body as-is; unlike EST, SCEP bodies are
+// binary.
+func scepRawResponse(contentType string, body []byte) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: contentType,
+			logical.HTTPRawBody:     body,
+			logical.HTTPStatusCode:  http.StatusOK,
+		},
+	}
+}
+
+const pathScepHelpSyn = `Perform SCEP (RFC 8894) operations.`
+
+const pathScepHelpDesc = `
+This endpoint implements the SCEP GetCACaps, GetCACert and PKIOperation
+operations, selected with the "operation" query parameter. PKIOperation
+messages are accepted either base64 encoded in the "message" query parameter
+of a GET request or as the body of a POST request with Content-Type
+application/x-pki-message. Only PKCSReq messages are supported; their CSR
+must carry a challenge password generated at scep/challenge, and the
+certificate is issued with the role configured at config/scep.
+`
+
+const pathScepChallengeHelpSyn = `Generate a one-time SCEP challenge password.`
+
+const pathScepChallengeHelpDesc = `
+This endpoint generates a challenge password for a single SCEP enrollment.
+The password expires after the challenge_ttl configured at config/scep and
+can only be used once, whether or not the enrollment succeeds.
+`
@@ -0,0 +1,258 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/pkcs7"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScep_Enrollment(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root.example.com",
+		"key_type":    "rsa",
+		"issuer_name": "root",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "ec-root.example.com",
+		"key_type":    "ec",
+		"issuer_name": "ec-root",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = CBWrite(b, s, "roles/devices", map[string]interface{}{
+		"allowed_domains":  "devices.example.com",
+		"allow_subdomains": true,
+		"key_type":         "rsa",
+		"ttl":              "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	resp, err = CBWrite(b, s, "roles/ec-devices", map[string]interface{}{
+		"issuer_ref":     "ec-root",
+		"allow_any_name": true,
+		"key_type":       "any",
+		"ttl":            "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// SCEP is disabled until configured.
+	_, err = CBReq(b, s, logical.ReadOperation, "scep", map[string]interface{}{"operation": "GetCACaps"})
+	require.Error(t, err)
+	_, err = CBWrite(b, s, "scep/challenge", nil)
+	require.Error(t, err)
+
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{"enabled": true})
+	require.Error(t, err, "expected SCEP without a role to be rejected")
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{"enabled": true, "role": "ec-devices"})
+	require.Error(t, err, "expected SCEP with an EC issuer to be rejected")
+
+	resp, err = CBWrite(b, s, "config/scep", map[string]interface{}{"enabled": true, "role": "devices"})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, int64(3600), resp.Data["challenge_ttl"])
+
+	resp, err = CBReq(b, s, logical.ReadOperation, "scep/pkiclient.exe", map[string]interface{}{"operation": "GetCACaps"})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Contains(t, strings.Split(string(resp.Data[logical.HTTPRawBody].([]byte)), "\n"), "POSTPKIOperation")
+
+	resp, err = CBReq(b, s, logical.ReadOperation, "scep", map[string]interface{}{"operation": "GetCACert"})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, scepContentTypeCACert, resp.Data[logical.HTTPContentType])
+	require.Equal(t, rootCert.Raw, resp.Data[logical.HTTPRawBody])
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := scepSelfSignedCert(t, key)
+
+	challenge := func() string {
+		resp, err := CBWrite(b, s, "scep/challenge", nil)
+		requireSuccessNonNilResponse(t, resp, err)
+		require.NotEmpty(t, resp.Data["expiration"])
+		return resp.Data["challenge"].(string)
+	}
+	post := func(message []byte) *logical.Response {
+		u, err := url.Parse("/v1/pki/scep/pkiclient.exe?operation=PKIOperation")
+		require.NoError(t, err)
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation:   logical.UpdateOperation,
+			Path:        "scep/pkiclient.exe",
+			Storage:     s,
+			HTTPRequest: &http.Request{URL: u, Body: io.NopCloser(bytes.NewReader(message))},
+		})
+		requireSuccessNonNilResponse(t, resp, err)
+		require.Equal(t, scepContentTypeMessage, resp.Data[logical.HTTPContentType])
+		return resp
+	}
+
+	password := challenge()
+	message, nonce := scepPKCSReq(t, rootCert, signer, key, "host.devices.example.com", password)
+	p7 := scepParseCertRep(t, post(message), rootCert, nonce, scepPkiStatusSuccess)
+	envelope, err := pkcs7.Parse(p7.Content)
+	require.NoError(t, err)
+	certs, err := envelope.Decrypt(signer, key)
+	require.NoError(t, err)
+	degenerate, err := pkcs7.Parse(certs)
+	require.NoError(t, err)
+	require.Len(t, degenerate.Certificates, 1)
+	leaf := degenerate.Certificates[0]
+	require.Equal(t, "host.devices.example.com", leaf.Subject.CommonName)
+	requireSignedBy(t, leaf, rootCert)
+
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(leaf))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// Challenge passwords can only be used once.
+	message, nonce = scepPKCSReq(t, rootCert, signer, key, "host.devices.example.com", password)
+	p7 = scepParseCertRep(t, post(message), rootCert, nonce, scepPkiStatusFailure)
+	var failInfo string
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepFailInfo, &failInfo))
+	require.Equal(t, scepFailInfoBadRequest, failInfo)
+
+	// The role's restrictions apply, also over GET.
+	message, nonce = scepPKCSReq(t, rootCert, signer, key, "host.other.com", challenge())
+	resp, err = CBReq(b, s, logical.ReadOperation, "scep", map[string]interface{}{
+		"operation": "PKIOperation",
+		"message":   base64.StdEncoding.EncodeToString(message),
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	scepParseCertRep(t, resp, rootCert, nonce, scepPkiStatusFailure)
+
+	// Expired challenge passwords are removed periodically.
+	password = challenge()
+	entry, err := logical.StorageEntryJSON(scepChallengePath(password), &scepChallengeEntry{
+		Expiration: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Put(context.Background(), entry))
+	valid := challenge()
+	require.NoError(t, b.tidyScepChallenges(b.makeStorageContext(context.Background(), s)))
+	remaining, err := s.List(context.Background(), scepChallengePrefix)
+	require.NoError(t, err)
+	require.Equal(t, []string{strings.TrimPrefix(scepChallengePath(valid), scepChallengePrefix)}, remaining)
+}
+
+func scepSelfSignedCert(t *testing.T, key *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "scep-client"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// scepPKCSReq builds a PKCSReq pkiMessage the way SCEP clients do,
+// returning it along with its sender nonce.
+func scepPKCSReq(t *testing.T, ca, signer *x509.Certificate, key *rsa.PrivateKey, cn, challenge string) ([]byte, []byte) {
+	t.Helper()
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: cn},
+		DNSNames: []string{cn},
+	}, key)
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificateRequest(csr)
+	require.NoError(t, err)
+
+	// crypto/x509 can't add a challengePassword attribute, so re-sign the
+	// CSR with one.
+	var tbs struct {
+		Version       int
+		Subject       asn1.RawValue
+		PublicKey     asn1.RawValue
+		RawAttributes []asn1.RawValue `asn1:"tag:0"`
+	}
+	_, err = asn1.Unmarshal(parsed.RawTBSCertificateRequest, &tbs)
+	require.NoError(t, err)
+	attr, err := asn1.Marshal(struct {
+		Type   asn1.ObjectIdentifier
+		Values []string `asn1:"set"`
+	}{oidChallengePassword, []string{challenge}})
+	require.NoError(t, err)
+	tbs.RawAttributes = append(tbs.RawAttributes, asn1.RawValue{FullBytes: attr})
+	tbsDER, err := asn1.Marshal(tbs)
+	require.NoError(t, err)
+	digest := sha256.Sum256(tbsDER)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	csr, err = asn1.Marshal(struct {
+		TBS                asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{
+		asn1.RawValue{FullBytes: tbsDER},
+		pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}, Parameters: asn1.NullRawValue},
+		asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	require.NoError(t, err)
+
+	envelope, err := pkcs7.EncryptWithAlgorithm(csr, []*x509.Certificate{ca}, pkcs7.EncryptionAlgorithmAES128CBC)
+	require.NoError(t, err)
+
+	nonce := make([]byte, scepNonceLength)
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+	sd, err := pkcs7.NewSignedData(envelope)
+	require.NoError(t, err)
+	require.NoError(t, sd.AddSigner(signer, key, pkcs7.SignerInfoConfig{
+		ExtraSignedAttributes: []pkcs7.Attribute{
+			{Type: oidScepMessageType, Value: scepMessageTypePKCSReq},
+			{Type: oidScepTransactionID, Value: "transaction-" + cn},
+			{Type: oidScepSenderNonce, Value: nonce},
+		},
+	}))
+	message, err := sd.Finish()
+	require.NoError(t, err)
+	return message, nonce
+}
+
+func scepParseCertRep(t *testing.T, resp *logical.Response, ca *x509.Certificate, nonce []byte, status string) *pkcs7.PKCS7 {
+	t.Helper()
+
+	p7, err := pkcs7.Parse(resp.Data[logical.HTTPRawBody].([]byte))
+	require.NoError(t, err)
+	require.NoError(t, p7.Verify())
+	require.Equal(t, ca.Raw, p7.GetOnlySigner().Raw)
+
+	var messageType, pkiStatus string
+	var recipientNonce []byte
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepMessageType, &messageType))
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepPkiStatus, &pkiStatus))
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepRecipientNonce, &recipientNonce))
+	require.Equal(t, scepMessageTypeCertRep, messageType)
+	require.Equal(t, status, pkiStatus)
+	require.Equal(t, nonce, recipientNonce)
+	return p7
+}
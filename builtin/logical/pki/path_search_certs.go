@@ -0,0 +1,251 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/ryanuber/go-glob"
+)
+
+// defaultCertSearchLimit is the number of certificates returned by a search
+// which does not set a limit.
+const defaultCertSearchLimit = 100
+
+func pathSearchCerts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "certs/search/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "search",
+			OperationSuffix: "certs",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"role": {
+				Type:        framework.TypeString,
+				Description: `Only return certificates issued by this role.`,
+			},
+			"issuer_ref": {
+				Type:        framework.TypeString,
+				Description: `Only return certificates issued by this issuer, by name or ID.`,
+			},
+			"name": {
+				Type: framework.TypeString,
+				Description: `Only return certificates with a common name or
+subject alternative name matching this glob pattern,
+case-insensitively. For example, "*.payments.example.com".`,
+			},
+			"expires_within": {
+				Type: framework.TypeDurationSecond,
+				Description: `Only return unexpired certificates which expire
+within this duration from now.`,
+			},
+			"revoked": {
+				Type: framework.TypeBool,
+				Description: `When set, only return revoked certificates if true,
+or non-revoked certificates if false.`,
+			},
+			"after": {
+				Type: framework.TypeString,
+				Description: `Only return certificates with a serial number
+sorting after this one; set to the next_after value
+of a previous response to fetch the next page.`,
+			},
+			"limit": {
+				Type:        framework.TypeInt,
+				Description: `The maximum number of certificates to return.`,
+				Default:     defaultCertSearchLimit,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathSearchCertsHandler,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"keys": {
+								Type:        framework.TypeStringSlice,
+								Description: `Serial numbers of the matching certificates, in order`,
+								Required:    true,
+							},
+							"key_info": {
+								Type:        framework.TypeMap,
+								Description: `Role, issuer, names, validity, revocation state and metadata of each certificate`,
+								Required:    false,
+							},
+							"next_after": {
+								Type:        framework.TypeString,
+								Description: `Set when the limit was reached; the value of "after" to fetch the next page with`,
+								Required:    false,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathSearchCertsHelpSyn,
+		HelpDescription: pathSearchCertsHelpDesc,
+	}
+}
+
+func (b *backend) pathSearchCertsHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	role := data.Get("role").(string)
+	namePattern := strings.ToLower(data.Get("name").(string))
+	after := normalizeSerial(data.Get("after").(string))
+
+	var issuerId issuerID
+	if issuerRef := data.Get("issuer_ref").(string); issuerRef != "" {
+		var err error
+		issuerId, err = sc.resolveIssuerReference(issuerRef)
+		if err != nil {
+			return logical.ErrorResponse("unable to resolve issuer %v: %v", issuerRef, err), nil
+		}
+	}
+
+	now := time.Now()
+	var expiresBefore time.Time
+	if expiresWithin := data.Get("expires_within").(int); expiresWithin < 0 {
+		return logical.ErrorResponse("expires_within must not be negative"), nil
+	} else if expiresWithin > 0 {
+		expiresBefore = now.Add(time.Duration(expiresWithin) * time.Second)
+	}
+
+	revokedRaw, filterRevoked := data.GetOk("revoked")
+
+	limit := data.Get("limit").(int)
+	if limit <= 0 {
+		return logical.ErrorResponse("limit must be positive"), nil
+	}
+
+	// Only list the certificates under the secondary keys of the index
+	// matching the search. Certificates which are not revoked aren't listed
+	// separately, so they are filtered from the index entries instead.
+	serials, err := sc.listCertIndexMatching(certIndexFilter{
+		Role:          role,
+		IssuerID:      issuerId,
+		Revoked:       filterRevoked && revokedRaw.(bool),
+		ExpiresAfter:  now,
+		ExpiresBefore: expiresBefore,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if after != "" {
+		serials = serials[sort.Search(len(serials), func(i int) bool { return serials[i] > after }):]
+	}
+
+	keys := []string{}
+	keyInfo := map[string]interface{}{}
+	nextAfter := ""
+	for _, serial := range serials {
+		if len(keys) >= limit {
+			// There may be more matching certificates, so the client
+			// should continue from the last one returned.
+			nextAfter = keys[len(keys)-1]
+			break
+		}
+
+		entry, err := sc.fetchCertIndex(serial)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			continue
+		}
+
+		if role != "" && entry.Role != role {
+			continue
+		}
+		if issuerId != "" && entry.IssuerID != issuerId {
+			continue
+		}
+		if namePattern != "" && !certIndexEntryMatchesName(entry, namePattern) {
+			continue
+		}
+		if !expiresBefore.IsZero() && (!entry.NotAfter.After(now) || entry.NotAfter.After(expiresBefore)) {
+			continue
+		}
+
+		revoked := entry.RevocationTime != 0
+		if filterRevoked && revokedRaw.(bool) != revoked {
+			continue
+		}
+
+		info := map[string]interface{}{
+			"role":        entry.Role,
+			"issuer_id":   entry.IssuerID,
+			"common_name": entry.CommonName,
+			"alt_names":   entry.AltNames,
+			"not_before":  entry.NotBefore.Format(time.RFC3339),
+			"not_after":   entry.NotAfter.Format(time.RFC3339),
+			"revoked":     revoked,
+		}
+		if revoked {
+			info["revocation_time"] = entry.RevocationTime
+			if !entry.RevocationTimeUTC.IsZero() {
+				info["revocation_time_rfc3339"] = entry.RevocationTimeUTC.Format(time.RFC3339Nano)
+			}
+		}
+		if len(entry.Metadata) > 0 {
+			info["cert_metadata"] = base64.StdEncoding.EncodeToString(entry.Metadata)
+		}
+
+		key := denormalizeSerial(serial)
+		keys = append(keys, key)
+		keyInfo[key] = info
+	}
+
+	resp := logical.ListResponseWithInfo(keys, keyInfo)
+	if nextAfter != "" {
+		resp.Data["next_after"] = nextAfter
+	}
+	return resp, nil
+}
+
+// certIndexEntryMatchesName checks the lowercase glob pattern against the
+// common name and subject alternative names of the indexed certificate.
+func certIndexEntryMatchesName(entry *certIndexEntry, pattern string) bool {
+	if entry.CommonName != "" && glob.Glob(pattern, strings.ToLower(entry.CommonName)) {
+		return true
+	}
+
+	for _, name := range entry.AltNames {
+		if glob.Glob(pattern, strings.ToLower(name)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const pathSearchCertsHelpSyn = `
+Search stored certificates.
+`
+
+const pathSearchCertsHelpDesc = `
+This endpoint searches the certificates stored by this mount, filtering
+them by role, issuer, common name or subject alternative name, expiry
+and revocation state. Results are ordered by serial number; use the
+"after" and "limit" parameters to page through them. At most 100
+certificates are returned unless "limit" is set; when the limit is
+reached, "next_after" is set to the value of "after" to fetch the next
+page with.
+
+Certificates stored before this endpoint was introduced are indexed
+when the certificate store is tidied with tidy_cert_store.
+`
@@ -0,0 +1,193 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCerts(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root.example.com",
+		"key_type":    "ec",
+		"issuer_name": "root",
+		"not_after":   "9999-12-31T23:59:59Z",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootSerial := resp.Data["serial_number"].(string)
+
+	resp, err = CBWrite(b, s, "roles/payments", map[string]interface{}{
+		"allowed_domains":  "payments.example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	resp, err = CBWrite(b, s, "roles/other", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	resp, err = CBWrite(b, s, "roles/ephemeral", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"no_store":       true,
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	metadata := base64.StdEncoding.EncodeToString([]byte(`{"team":"payments"}`))
+	issue := func(role, cn string, validity time.Duration, extra map[string]interface{}) string {
+		data := map[string]interface{}{
+			"common_name": cn,
+			"not_after":   time.Now().Add(validity).UTC().Format(time.RFC3339),
+		}
+		for k, v := range extra {
+			data[k] = v
+		}
+		resp, err := CBWrite(b, s, "issue/"+role, data)
+		requireSuccessNonNilResponse(t, resp, err)
+		return resp.Data["serial_number"].(string)
+	}
+
+	soon := issue("payments", "api.payments.example.com", 10*24*time.Hour, map[string]interface{}{"cert_metadata": metadata})
+	later := issue("payments", "web.payments.example.com", 60*24*time.Hour, nil)
+	other := issue("other", "host.other.com", 10*24*time.Hour, map[string]interface{}{"alt_names": "alt.payments.example.com"})
+
+	_, err = CBWrite(b, s, "issue/payments", map[string]interface{}{
+		"common_name":   "bad.payments.example.com",
+		"cert_metadata": "not base64!",
+	})
+	require.Error(t, err, "expected invalid cert_metadata to be rejected")
+	_, err = CBWrite(b, s, "issue/ephemeral", map[string]interface{}{
+		"common_name":   "host.example.com",
+		"cert_metadata": metadata,
+	})
+	require.Error(t, err, "expected cert_metadata to be rejected by no_store roles")
+
+	search := func(data map[string]interface{}) *logical.Response {
+		resp, err := CBReq(b, s, logical.ReadOperation, "certs/search", data)
+		requireSuccessNonNilResponse(t, resp, err)
+		return resp
+	}
+
+	// Certificates for payments hosts expiring in the next 30 days, by
+	// common name or SAN.
+	resp = search(map[string]interface{}{
+		"name":           "*.payments.example.com",
+		"expires_within": "720h",
+	})
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("certs/search"), logical.ReadOperation), resp, true)
+	require.ElementsMatch(t, []string{soon, other}, resp.Data["keys"])
+	info := resp.Data["key_info"].(map[string]interface{})[soon].(map[string]interface{})
+	require.Equal(t, "payments", info["role"])
+	require.Equal(t, "api.payments.example.com", info["common_name"])
+	require.Equal(t, false, info["revoked"])
+	require.Equal(t, metadata, info["cert_metadata"])
+
+	// Names are matched case-insensitively.
+	resp = search(map[string]interface{}{"name": "*.PAYMENTS.example.com"})
+	require.ElementsMatch(t, []string{soon, later, other}, resp.Data["keys"])
+
+	resp = search(map[string]interface{}{"role": "other"})
+	require.Equal(t, []string{other}, resp.Data["keys"])
+
+	resp = search(map[string]interface{}{"issuer_ref": "root"})
+	require.ElementsMatch(t, []string{rootSerial, soon, later, other}, resp.Data["keys"])
+	_, err = CBReq(b, s, logical.ReadOperation, "certs/search", map[string]interface{}{"issuer_ref": "missing"})
+	require.Error(t, err, "expected unknown issuers to be rejected")
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{"serial_number": later})
+	requireSuccessNonNilResponse(t, resp, err)
+	resp = search(map[string]interface{}{"revoked": true})
+	require.Equal(t, []string{later}, resp.Data["keys"])
+	require.Equal(t, true, resp.Data["key_info"].(map[string]interface{})[later].(map[string]interface{})["revoked"])
+	resp = search(map[string]interface{}{"revoked": false, "role": "payments"})
+	require.Equal(t, []string{soon}, resp.Data["keys"])
+
+	// Pages cover all certificates, in order, until no next_after is
+	// returned.
+	resp = search(nil)
+	all := resp.Data["keys"].([]string)
+	require.Len(t, all, 4)
+	require.NotContains(t, resp.Data, "next_after")
+	var paged []string
+	after := ""
+	for {
+		resp = search(map[string]interface{}{"after": after, "limit": 3})
+		keys := resp.Data["keys"].([]string)
+		require.LessOrEqual(t, len(keys), 3)
+		paged = append(paged, keys...)
+		if resp.Data["next_after"] == nil {
+			break
+		}
+		require.Equal(t, keys[len(keys)-1], resp.Data["next_after"])
+		after = resp.Data["next_after"].(string)
+	}
+	require.Equal(t, all, paged)
+
+	// Searches list the secondary keys of the index matching them.
+	ctx := context.Background()
+	listed, err := s.List(ctx, certIndexRolePrefix+"payments/")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{normalizeSerial(soon), normalizeSerial(later)}, listed)
+	listed, err = s.List(ctx, certIndexRevokedPrefix)
+	require.NoError(t, err)
+	require.Equal(t, []string{normalizeSerial(later)}, listed)
+
+	_, err = CBReq(b, s, logical.ReadOperation, "certs/search", map[string]interface{}{"limit": 0})
+	require.Error(t, err, "expected a zero limit to be rejected")
+
+	// Tidying the certificate store indexes certificates missing from the
+	// index, along with their issuer and revocation state.
+	for _, serial := range []string{soon, later} {
+		require.NoError(t, s.Delete(ctx, certIndexPrefix+normalizeSerial(serial)))
+	}
+	resp = search(map[string]interface{}{"role": "payments"})
+	require.Empty(t, resp.Data["keys"])
+
+	_, err = CBWrite(b, s, "tidy", map[string]interface{}{
+		"tidy_cert_store": true,
+	})
+	require.NoError(t, err)
+	for {
+		time.Sleep(125 * time.Millisecond)
+
+		resp, err = CBRead(b, s, "tidy-status")
+		require.NoError(t, err)
+		state := resp.Data["state"].(string)
+		if state == "Finished" {
+			break
+		}
+		if state == "Error" {
+			t.Fatalf("unexpected state for tidy operation: Error:\nStatus: %v", resp.Data)
+		}
+	}
+
+	resp = search(map[string]interface{}{"revoked": true, "issuer_ref": "root"})
+	require.Equal(t, []string{later}, resp.Data["keys"])
+	info = resp.Data["key_info"].(map[string]interface{})[later].(map[string]interface{})
+	require.Equal(t, "web.payments.example.com", info["common_name"])
+	require.NotZero(t, info["revocation_time"])
+	resp = search(map[string]interface{}{"name": "api.payments.example.com"})
+	require.Equal(t, []string{soon}, resp.Data["keys"])
+
+	// Removing an index entry removes its secondary keys.
+	sc := b.makeStorageContext(ctx, s)
+	require.NoError(t, sc.deleteCertIndex(other))
+	listed, err = s.List(ctx, certIndexRolePrefix+"other/")
+	require.NoError(t, err)
+	require.Empty(t, listed)
+	resp = search(map[string]interface{}{"issuer_ref": "root"})
+	require.ElementsMatch(t, []string{rootSerial, soon, later}, resp.Data["keys"])
+}
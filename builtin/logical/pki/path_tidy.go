@@ -948,6 +948,8 @@ func (b *backend) startTidyOperation(req *logical.Request, config *tidyConfig) {
 }
 
 func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, logger hclog.Logger, config *tidyConfig) error {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	var issuerIDCertMap map[issuerID]*x509.Certificate
 	serials, err := req.Storage.List(ctx, "certs/")
 	if err != nil {
 		return fmt.Errorf("error fetching list of certs: %w", err)
@@ -979,6 +981,9 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 			if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 				return fmt.Errorf("error deleting nil entry with serial %s: %w", serial, err)
 			}
+			if err := sc.deleteCertIndex(serial); err != nil {
+				return fmt.Errorf("error deleting index of nil entry with serial %s: %w", serial, err)
+			}
 			b.tidyStatusIncCertStoreCount()
 			continue
 		}
@@ -988,6 +993,9 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 			if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 				return fmt.Errorf("error deleting entry with nil value with serial %s: %w", serial, err)
 			}
+			if err := sc.deleteCertIndex(serial); err != nil {
+				return fmt.Errorf("error deleting index of entry with nil value with serial %s: %w", serial, err)
+			}
 			b.tidyStatusIncCertStoreCount()
 			continue
 		}
@@ -1001,7 +1009,22 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 			if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 				return fmt.Errorf("error deleting serial %q from storage: %w", serial, err)
 			}
+			if err := sc.deleteCertIndex(serial); err != nil {
+				return fmt.Errorf("error deleting serial %q from certificate index: %w", serial, err)
+			}
 			b.tidyStatusIncCertStoreCount()
+			continue
+		}
+
+		// Index certificates stored before the certificate index existed.
+		if issuerIDCertMap == nil && !b.useLegacyBundleCaStorage() {
+			issuerIDCertMap, err = fetchIssuerMapForRevocationChecking(sc)
+			if err != nil {
+				return fmt.Errorf("error fetching issuers to index certificates: %w", err)
+			}
+		}
+		if err := sc.backfillCertIndex(serial, cert, issuerIDCertMap); err != nil {
+			return fmt.Errorf("error indexing serial %q: %w", serial, err)
 		}
 	}
 
@@ -1112,6 +1135,9 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 				if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 					return fmt.Errorf("error deleting serial %q from store when tidying revoked: %w", serial, err)
 				}
+				if err := sc.deleteCertIndex(serial); err != nil {
+					return fmt.Errorf("error deleting serial %q from certificate index when tidying revoked: %w", serial, err)
+				}
 				rebuildCRL = true
 				storeCert = false
 				b.tidyStatusIncRevokedCertCount()
@@ -44,6 +44,22 @@ const (
 	autoTidyConfigPath = "config/auto-tidy"
 	clusterConfigPath  = "config/cluster"
 
+	// Certificates stored under certs/ are indexed here by serial number,
+	// to allow searching them without parsing each certificate.
+	certIndexPrefix = "cert-index/"
+
+	// Index entries are also listed, by serial number, under secondary keys
+	// for their role, issuer, expiry date and revocation, so that searches
+	// filtering on these need only list the matching prefix.
+	certIndexRolePrefix    = "cert-index-role/"
+	certIndexIssuerPrefix  = "cert-index-issuer/"
+	certIndexExpiryPrefix  = "cert-index-expiry/"
+	certIndexRevokedPrefix = "cert-index-revoked/"
+
+	// certIndexExpiryFormat buckets certificates by the UTC day they expire
+	// on; these sort in date order.
+	certIndexExpiryFormat = "2006-01-02"
+
 	// Used as a quick sanity check for a reference id lookups...
 	uuidLength = 36
 
@@ -168,22 +184,39 @@ func NewIssuerUsageFromNames(names []string) (issuerUsage, error) {
 }
 
 type issuerEntry struct {
-	ID                   issuerID                  `json:"id"`
-	Name                 string                    `json:"name"`
-	KeyID                keyID                     `json:"key_id"`
-	Certificate          string                    `json:"certificate"`
-	CAChain              []string                  `json:"ca_chain"`
-	ManualChain          []issuerID                `json:"manual_chain"`
-	SerialNumber         string                    `json:"serial_number"`
-	LeafNotAfterBehavior certutil.NotAfterBehavior `json:"not_after_behavior"`
-	Usage                issuerUsage               `json:"usage"`
-	RevocationSigAlg     x509.SignatureAlgorithm   `json:"revocation_signature_algorithm"`
-	Revoked              bool                      `json:"revoked"`
-	RevocationTime       int64                     `json:"revocation_time"`
-	RevocationTimeUTC    time.Time                 `json:"revocation_time_utc"`
-	AIAURIs              *aiaConfigEntry           `json:"aia_uris,omitempty"`
-	LastModified         time.Time                 `json:"last_modified"`
-	Version              uint                      `json:"version"`
+	ID                    issuerID                  `json:"id"`
+	Name                  string                    `json:"name"`
+	KeyID                 keyID                     `json:"key_id"`
+	Certificate           string                    `json:"certificate"`
+	CAChain               []string                  `json:"ca_chain"`
+	ManualChain           []issuerID                `json:"manual_chain"`
+	SerialNumber          string                    `json:"serial_number"`
+	LeafNotAfterBehavior  certutil.NotAfterBehavior `json:"not_after_behavior"`
+	Usage                 issuerUsage               `json:"usage"`
+	RevocationSigAlg      x509.SignatureAlgorithm   `json:"revocation_signature_algorithm"`
+	Revoked               bool                      `json:"revoked"`
+	RevocationTime        int64                     `json:"revocation_time"`
+	RevocationTimeUTC     time.Time                 `json:"revocation_time_utc"`
+	AIAURIs               *aiaConfigEntry           `json:"aia_uris,omitempty"`
+	ScheduledRotationTime time.Time                 `json:"scheduled_rotation_time"`
+	LastModified          time.Time                 `json:"last_modified"`
+	Version               uint                      `json:"version"`
+}
+
+type certIndexEntry struct {
+	Role       string    `json:"role"`
+	IssuerID   issuerID  `json:"issuer_id"`
+	CommonName string    `json:"common_name"`
+	AltNames   []string  `json:"alt_names"`
+	NotBefore  time.Time `json:"not_before"`
+	NotAfter   time.Time `json:"not_after"`
+	Metadata   []byte    `json:"metadata,omitempty"`
+
+	// The revocation state is copied from the revocation entry, so that
+	// searches need not read it for each certificate. RevocationTime is
+	// zero for certificates which are not revoked.
+	RevocationTime    int64     `json:"revocation_time,omitempty"`
+	RevocationTimeUTC time.Time `json:"revocation_time_utc"`
 }
 
 type internalCRLConfigEntry struct {
@@ -1545,3 +1578,259 @@ func (sc *storageContext) fetchRevocationInfo(serial string) (*revocationInfo, e
 
 	return revInfo, nil
 }
+
+// writeCertIndex records a stored certificate in the certificate index,
+// along with the role and issuer it was issued by, if known, and any
+// metadata attached to it.
+func (sc *storageContext) writeCertIndex(cert *x509.Certificate, role string, issuerId issuerID, metadata []byte) error {
+	return sc.putCertIndex(normalizeSerialFromBigInt(cert.SerialNumber), newCertIndexEntry(cert, role, issuerId, metadata))
+}
+
+func newCertIndexEntry(cert *x509.Certificate, role string, issuerId issuerID, metadata []byte) *certIndexEntry {
+	altNames := append([]string{}, cert.DNSNames...)
+	altNames = append(altNames, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		altNames = append(altNames, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		altNames = append(altNames, uri.String())
+	}
+
+	return &certIndexEntry{
+		Role:       role,
+		IssuerID:   issuerId,
+		CommonName: cert.Subject.CommonName,
+		AltNames:   altNames,
+		NotBefore:  cert.NotBefore,
+		NotAfter:   cert.NotAfter,
+		Metadata:   metadata,
+	}
+}
+
+func (sc *storageContext) putCertIndex(serial string, index *certIndexEntry) error {
+	serial = normalizeSerial(serial)
+	entry, err := logical.StorageEntryJSON(certIndexPrefix+serial, index)
+	if err != nil {
+		return err
+	}
+
+	// The secondary keys are written first, so that a search never misses an
+	// index entry; those left without one are skipped.
+	for _, key := range certIndexSecondaryKeys(serial, index) {
+		if err := sc.Storage.Put(sc.Context, &logical.StorageEntry{Key: key}); err != nil {
+			return fmt.Errorf("unable to index certificate: %w", err)
+		}
+	}
+
+	if err := sc.Storage.Put(sc.Context, entry); err != nil {
+		return fmt.Errorf("unable to index certificate: %w", err)
+	}
+
+	return nil
+}
+
+// certIndexSecondaryKeys returns the keys under which the index entry of the
+// certificate with the given normalized serial number is listed.
+func certIndexSecondaryKeys(serial string, index *certIndexEntry) []string {
+	keys := []string{certIndexExpiryPrefix + index.NotAfter.UTC().Format(certIndexExpiryFormat) + "/" + serial}
+	if index.Role != "" {
+		keys = append(keys, certIndexRolePrefix+index.Role+"/"+serial)
+	}
+	if index.IssuerID != "" {
+		keys = append(keys, certIndexIssuerPrefix+string(index.IssuerID)+"/"+serial)
+	}
+	if index.RevocationTime != 0 {
+		keys = append(keys, certIndexRevokedPrefix+serial)
+	}
+	return keys
+}
+
+// markCertIndexRevoked copies the revocation state of a certificate into
+// its index entry, if it is indexed.
+func (sc *storageContext) markCertIndexRevoked(serial string, revInfo *revocationInfo) error {
+	index, err := sc.fetchCertIndex(serial)
+	if err != nil || index == nil {
+		return err
+	}
+
+	index.RevocationTime = revInfo.RevocationTime
+	index.RevocationTimeUTC = revInfo.RevocationTimeUTC
+	return sc.putCertIndex(serial, index)
+}
+
+// backfillCertIndex indexes a stored certificate missing from the
+// certificate index, such as one stored before the index was introduced.
+// The role the certificate was issued by is unknown; its issuer is looked up
+// in issuerIDCertMap.
+func (sc *storageContext) backfillCertIndex(serial string, cert *x509.Certificate, issuerIDCertMap map[issuerID]*x509.Certificate) error {
+	existing, err := sc.fetchCertIndex(serial)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	revInfo, err := sc.fetchRevocationInfo(serial)
+	if err != nil {
+		return err
+	}
+
+	var issuerId issuerID
+	var association revocationInfo
+	if !sc.Backend.useLegacyBundleCaStorage() && associateRevokedCertWithIsssuer(&association, cert, issuerIDCertMap) {
+		issuerId = association.CertificateIssuer
+	}
+
+	index := newCertIndexEntry(cert, "", issuerId, nil)
+	if revInfo != nil {
+		index.RevocationTime = revInfo.RevocationTime
+		index.RevocationTimeUTC = revInfo.RevocationTimeUTC
+	}
+	return sc.putCertIndex(serial, index)
+}
+
+func (sc *storageContext) fetchCertIndex(serial string) (*certIndexEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, certIndexPrefix+normalizeSerial(serial))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result certIndexEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode certificate index entry %v: %v", serial, err)}
+	}
+
+	return &result, nil
+}
+
+func (sc *storageContext) listCertIndex() ([]string, error) {
+	list, err := sc.Storage.List(sc.Context, certIndexPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed listing certificate index: %w", err)
+	}
+
+	sort.Strings(list)
+	return list, nil
+}
+
+// certIndexFilter selects the secondary keys listed by
+// listCertIndexMatching; zero values don't filter.
+type certIndexFilter struct {
+	Role     string
+	IssuerID issuerID
+	Revoked  bool
+
+	// Certificates expiring on the UTC days from ExpiresAfter to
+	// ExpiresBefore, inclusive.
+	ExpiresAfter  time.Time
+	ExpiresBefore time.Time
+}
+
+// listCertIndexMatching lists the sorted serial numbers of the indexed
+// certificates listed under each of the secondary keys selected by filter,
+// or of all indexed certificates if none are. The serial numbers are
+// candidates only: the index entries must still be checked against the
+// filter, as expiry is only bucketed by day and the secondary keys of a
+// removed entry may outlive it.
+func (sc *storageContext) listCertIndexMatching(filter certIndexFilter) ([]string, error) {
+	var lists [][]string
+	list := func(prefix string) error {
+		serials, err := sc.Storage.List(sc.Context, prefix)
+		if err != nil {
+			return fmt.Errorf("failed listing certificate index: %w", err)
+		}
+		lists = append(lists, serials)
+		return nil
+	}
+
+	if filter.Role != "" {
+		if err := list(certIndexRolePrefix + filter.Role + "/"); err != nil {
+			return nil, err
+		}
+	}
+	if filter.IssuerID != "" {
+		if err := list(certIndexIssuerPrefix + string(filter.IssuerID) + "/"); err != nil {
+			return nil, err
+		}
+	}
+	if filter.Revoked {
+		if err := list(certIndexRevokedPrefix); err != nil {
+			return nil, err
+		}
+	}
+	if !filter.ExpiresBefore.IsZero() {
+		days, err := sc.Storage.List(sc.Context, certIndexExpiryPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed listing certificate index: %w", err)
+		}
+
+		first := filter.ExpiresAfter.UTC().Format(certIndexExpiryFormat)
+		last := filter.ExpiresBefore.UTC().Format(certIndexExpiryFormat)
+		var expiring []string
+		for _, day := range days {
+			if name := strings.TrimSuffix(day, "/"); name < first || name > last {
+				continue
+			}
+
+			serials, err := sc.Storage.List(sc.Context, certIndexExpiryPrefix+day)
+			if err != nil {
+				return nil, fmt.Errorf("failed listing certificate index: %w", err)
+			}
+			expiring = append(expiring, serials...)
+		}
+		lists = append(lists, expiring)
+	}
+
+	if len(lists) == 0 {
+		return sc.listCertIndex()
+	}
+
+	// Intersect the lists, starting from the shortest.
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	result := lists[0]
+	for _, other := range lists[1:] {
+		if len(result) == 0 {
+			break
+		}
+
+		listed := make(map[string]struct{}, len(other))
+		for _, serial := range other {
+			listed[serial] = struct{}{}
+		}
+		matching := make([]string, 0, len(result))
+		for _, serial := range result {
+			if _, ok := listed[serial]; ok {
+				matching = append(matching, serial)
+			}
+		}
+		result = matching
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// deleteCertIndex removes the index entry of a certificate, along with its
+// secondary keys.
+func (sc *storageContext) deleteCertIndex(serial string) error {
+	serial = normalizeSerial(serial)
+	index, err := sc.fetchCertIndex(serial)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.Storage.Delete(sc.Context, certIndexPrefix+serial); err != nil {
+		return err
+	}
+	if index == nil {
+		return nil
+	}
+
+	for _, key := range certIndexSecondaryKeys(serial, index) {
+		if err := sc.Storage.Delete(sc.Context, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -20,6 +20,13 @@ type backend struct {
 	view      logical.Storage
 	salt      *salt.Salt
 	saltMutex sync.RWMutex
+
+	// krlLock serializes revocations and tidies with KRL rebuilds.
+	krlLock sync.Mutex
+
+	// issuersLock serializes changes to the set of issuers and the default
+	// issuer.
+	issuersLock sync.Mutex
 }
 
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
@@ -43,6 +50,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			Unauthenticated: []string{
 				"verify",
 				"public_key",
+				"krl",
 			},
 
 			LocalStorage: []string{
@@ -53,6 +61,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 				caPrivateKey,
 				caPrivateKeyStoragePath,
 				keysStoragePrefix,
+				issuersStoragePrefix,
 			},
 		},
 
@@ -68,14 +77,23 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			pathIssue(&b),
 			pathFetchPublicKey(&b),
 			pathCleanupKeys(&b),
+			pathRevoke(&b),
+			pathFetchKRL(&b),
+			pathTidyCerts(&b),
+			pathListIssuers(&b),
+			pathGenerateIssuer(&b),
+			pathImportIssuer(&b),
+			pathIssuer(&b),
+			pathConfigIssuers(&b),
 		},
 
 		Secrets: []*framework.Secret{
 			secretOTP(&b),
 		},
 
-		Invalidate:  b.invalidate,
-		BackendType: logical.TypeLogical,
+		InitializeFunc: b.initialize,
+		Invalidate:     b.invalidate,
+		BackendType:    logical.TypeLogical,
 	}
 	return &b, nil
 }
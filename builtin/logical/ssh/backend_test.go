@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
@@ -2517,6 +2518,331 @@ func TestBackend_CleanupDynamicHostKeys(t *testing.T) {
 	require.Contains(t, resp.Data["message"], "0 of 0")
 }
 
+func TestBackend_RevokeCertificates(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b, err := Backend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Setup(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: operation,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	readKRL := func() []byte {
+		t.Helper()
+		resp := request(logical.ReadOperation, "krl", nil)
+		krl := resp.Data[logical.HTTPRawBody].([]byte)
+		require.Equal(t, uint64(krlMagic), binary.BigEndian.Uint64(krl))
+		return krl
+	}
+
+	// An empty KRL is served before anything is revoked.
+	require.Len(t, readKRL(), 44)
+
+	request(logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"public_key":  testCAPublicKey,
+		"private_key": testCAPrivateKey,
+	})
+	request(logical.UpdateOperation, "roles/test", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+	})
+
+	var serials []string
+	for i := 0; i < 2; i++ {
+		resp := request(logical.UpdateOperation, "sign/test", map[string]interface{}{
+			"public_key":       publicKey4096,
+			"valid_principals": "toor",
+		})
+		require.False(t, resp.IsError(), resp.Error())
+		serials = append(serials, resp.Data["serial_number"].(string))
+	}
+
+	resp := request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": "abc",
+	})
+	require.True(t, resp.IsError())
+
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": serials[0],
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	require.NotZero(t, resp.Data["revocation_time"])
+
+	serial, err := parseSerial(serials[0])
+	require.NoError(t, err)
+	serialBytes := binary.BigEndian.AppendUint64(nil, serial)
+	caKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(testCAPublicKey))
+	require.NoError(t, err)
+
+	krl := readKRL()
+	require.True(t, bytes.Contains(krl, serialBytes))
+	require.True(t, bytes.Contains(krl, caKey.Marshal()))
+	other, err := parseSerial(serials[1])
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(krl, binary.BigEndian.AppendUint64(nil, other)))
+
+	// Revoking again returns the original revocation.
+	again := request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": serials[0],
+	})
+	require.Equal(t, resp.Data["revocation_time"], again.Data["revocation_time"])
+
+	// Nothing has expired yet.
+	resp = request(logical.DeleteOperation, "tidy/certs", nil)
+	require.Contains(t, resp.Data["message"], "0 of 3")
+
+	// Expire the revoked certificate; tidy removes it and drops it from the KRL.
+	for _, prefix := range []string{certsStoragePrefix, revokedStoragePrefix} {
+		cert, err := getCertEntry(context.Background(), config.StorageView, prefix+formatSerial(serial))
+		require.NoError(t, err)
+		cert.Expiration = time.Now().Add(-time.Minute)
+		entry, err := logical.StorageEntryJSON(prefix+formatSerial(serial), cert)
+		require.NoError(t, err)
+		require.NoError(t, config.StorageView.Put(context.Background(), entry))
+	}
+
+	resp = request(logical.DeleteOperation, "tidy/certs", nil)
+	require.Contains(t, resp.Data["message"], "2 of 3")
+	require.False(t, bytes.Contains(readKRL(), serialBytes))
+
+	// Certificates signed by a no_store role are not stored, so they cannot
+	// be revoked.
+	request(logical.UpdateOperation, "roles/no-store", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"no_store":                true,
+	})
+	resp = request(logical.ReadOperation, "roles/no-store", nil)
+	require.Equal(t, true, resp.Data["no_store"])
+	resp = request(logical.UpdateOperation, "sign/no-store", map[string]interface{}{
+		"public_key":       publicKey4096,
+		"valid_principals": "toor",
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	unstored := resp.Data["serial_number"].(string)
+	cert, err := getCertEntry(context.Background(), config.StorageView, certsStoragePrefix+unstored)
+	require.NoError(t, err)
+	require.Nil(t, cert)
+	resp = request(logical.UpdateOperation, "revoke", map[string]interface{}{
+		"serial_number": unstored,
+	})
+	require.True(t, resp.IsError())
+}
+
+func TestBackend_MultipleIssuers(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b, err := Backend(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Setup(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: operation,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	signingKey := func(role string) string {
+		t.Helper()
+		resp := request(logical.UpdateOperation, "sign/"+role, map[string]interface{}{
+			"public_key":       publicKey4096,
+			"valid_principals": "toor",
+		})
+		require.False(t, resp.IsError(), resp.Error())
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(resp.Data["signed_key"].(string)))
+		require.NoError(t, err)
+		return ssh.FingerprintSHA256(parsed.(*ssh.Certificate).SignatureKey)
+	}
+	fingerprint := func(publicKey string) string {
+		t.Helper()
+		parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		require.NoError(t, err)
+		return ssh.FingerprintSHA256(parsed)
+	}
+
+	resp := request(logical.UpdateOperation, "issuers/import", map[string]interface{}{
+		"issuer_name": "old",
+		"public_key":  testCAPublicKey,
+		"private_key": testCAPrivateKey,
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	oldID := resp.Data["issuer_id"].(string)
+	require.Equal(t, true, resp.Data["is_default"])
+
+	resp = request(logical.UpdateOperation, "issuers/generate", map[string]interface{}{
+		"issuer_name": "new",
+		"key_type":    "ed25519",
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	newID := resp.Data["issuer_id"].(string)
+	newPublicKey := resp.Data["public_key"].(string)
+	require.Equal(t, false, resp.Data["is_default"])
+
+	// Names must be unique and unambiguous.
+	resp = request(logical.UpdateOperation, "issuers/generate", map[string]interface{}{
+		"issuer_name": "new",
+	})
+	require.True(t, resp.IsError())
+	resp = request(logical.UpdateOperation, "issuers/generate", map[string]interface{}{
+		"issuer_name": "default",
+	})
+	require.True(t, resp.IsError())
+
+	resp = request(logical.ListOperation, "issuers", nil)
+	require.Equal(t, []string{oldID, newID}, resp.Data["keys"])
+
+	// Both CAs are trusted, the default one first.
+	resp = request(logical.ReadOperation, "public_key", nil)
+	require.Equal(t, testCAPublicKey+newPublicKey, string(resp.Data[logical.HTTPRawBody].([]byte)))
+
+	resp = request(logical.UpdateOperation, "roles/pinned", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"issuer_ref":              "missing",
+	})
+	require.True(t, resp.IsError())
+	request(logical.UpdateOperation, "roles/pinned", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"issuer_ref":              "new",
+	})
+	request(logical.UpdateOperation, "roles/default", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+	})
+	resp = request(logical.ReadOperation, "roles/default", nil)
+	require.Equal(t, defaultRef, resp.Data["issuer_ref"])
+
+	require.Equal(t, fingerprint(newPublicKey), signingKey("pinned"))
+	require.Equal(t, fingerprint(testCAPublicKey), signingKey("default"))
+
+	// Rotate the default issuer and retire the old one.
+	resp = request(logical.UpdateOperation, "config/issuers", map[string]interface{}{
+		"default": "new",
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	require.Equal(t, newID, resp.Data["default"])
+	require.Equal(t, fingerprint(newPublicKey), signingKey("default"))
+
+	resp = request(logical.ReadOperation, "config/ca", nil)
+	require.Equal(t, newPublicKey, resp.Data["public_key"])
+
+	resp = request(logical.DeleteOperation, "issuer/old", nil)
+	require.Nil(t, resp)
+	resp = request(logical.ReadOperation, "public_key", nil)
+	require.Equal(t, newPublicKey, string(resp.Data[logical.HTTPRawBody].([]byte)))
+
+	resp = request(logical.UpdateOperation, "issuer/"+newID, map[string]interface{}{
+		"issuer_name": "current",
+	})
+	require.False(t, resp.IsError(), resp.Error())
+	resp = request(logical.ReadOperation, "issuer/current", nil)
+	require.Equal(t, newID, resp.Data["issuer_id"])
+
+	// Deleting the default issuer leaves roles without a signing key.
+	resp = request(logical.DeleteOperation, "issuer/default", nil)
+	require.NotEmpty(t, resp.Warnings)
+	_, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "sign/default",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"public_key": publicKey4096,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestBackend_LegacyCAMigration(t *testing.T) {
+	ctx := context.Background()
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	for path, key := range map[string]string{
+		caPublicKeyStoragePath:  testCAPublicKey,
+		caPrivateKeyStoragePath: testCAPrivateKey,
+	} {
+		entry, err := logical.StorageEntryJSON(path, &keyStorageEntry{Key: key})
+		require.NoError(t, err)
+		require.NoError(t, config.StorageView.Put(ctx, entry))
+	}
+
+	b, err := Backend(config)
+	require.NoError(t, err)
+	require.NoError(t, b.Setup(ctx, config))
+
+	// Before initialization, the legacy CA is served as the default issuer.
+	issuer, err := fetchIssuerByRef(ctx, config.StorageView, defaultRef)
+	require.NoError(t, err)
+	require.Equal(t, testCAPublicKey, issuer.PublicKey)
+	require.Empty(t, issuer.ID)
+
+	require.NoError(t, b.Initialize(ctx, &logical.InitializationRequest{Storage: config.StorageView}))
+
+	issuer, err = fetchIssuerByRef(ctx, config.StorageView, defaultRef)
+	require.NoError(t, err)
+	require.NotEmpty(t, issuer.ID)
+	require.Equal(t, testCAPublicKey, issuer.PublicKey)
+	require.Equal(t, testCAPrivateKey, issuer.PrivateKey)
+
+	// The legacy key pair is kept, and the migration is recorded.
+	legacy, err := fetchLegacyIssuer(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.Equal(t, testCAPublicKey, legacy.PublicKey)
+	log, err := fetchLegacyCAMigrationLog(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.Equal(t, issuer.ID, log.IssuerID)
+	require.Equal(t, legacyCAHash(legacy), log.Hash)
+
+	// Initializing again is a no-op.
+	require.NoError(t, b.Initialize(ctx, &logical.InitializationRequest{Storage: config.StorageView}))
+	ids, err := listIssuers(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.Equal(t, []string{issuer.ID}, ids)
+
+	// Once migrated, the legacy CA is not served or migrated again after the
+	// issuer is deleted.
+	require.NoError(t, config.StorageView.Delete(ctx, issuersStoragePrefix+issuer.ID))
+	issuer, err = fetchIssuerByRef(ctx, config.StorageView, defaultRef)
+	require.NoError(t, err)
+	require.Nil(t, issuer)
+	require.NoError(t, b.Initialize(ctx, &logical.InitializationRequest{Storage: config.StorageView}))
+	ids, err = listIssuers(ctx, config.StorageView)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+}
+
 type pathAuthCheckerFunc func(t *testing.T, client *api.Client, path string, token string)
 
 func isPermDenied(err error) bool {
@@ -2758,15 +3084,23 @@ func TestProperAuthing(t *testing.T) {
 
 	paths := map[string]pathAuthChecker{
 		"config/ca":          shouldBeAuthed,
+		"config/issuers":     shouldBeAuthed,
 		"config/zeroaddress": shouldBeAuthed,
 		"creds/test-otp":     shouldBeAuthed,
 		"issue/test-ca":      shouldBeAuthed,
+		"issuer/default":     shouldBeAuthed,
+		"issuers/":           shouldBeAuthed,
+		"issuers/generate":   shouldBeAuthed,
+		"issuers/import":     shouldBeAuthed,
+		"krl":                shouldBeUnauthedReadList,
 		"lookup":             shouldBeAuthed,
 		"public_key":         shouldBeUnauthedReadList,
+		"revoke":             shouldBeAuthed,
 		"roles/test-ca":      shouldBeAuthed,
 		"roles/test-otp":     shouldBeAuthed,
 		"roles/":             shouldBeAuthed,
 		"sign/test-ca":       shouldBeAuthed,
+		"tidy/certs":         shouldBeAuthed,
 		"tidy/dynamic-keys":  shouldBeAuthed,
 		"verify":             shouldBeUnauthedWriteOnly,
 	}
@@ -2806,6 +3140,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "{role}") && strings.Contains(raw_path, "creds") {
 			raw_path = strings.ReplaceAll(raw_path, "{role}", "test-otp")
 		}
+		if strings.Contains(raw_path, "{issuer_ref}") {
+			raw_path = strings.ReplaceAll(raw_path, "{issuer_ref}", "default")
+		}
 
 		handler, present := paths[raw_path]
 		if !present {
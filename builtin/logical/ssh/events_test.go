@@ -0,0 +1,65 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSSH_Events ensures that configuring a CA and signing certificates sends
+// events which identify the CA and certificate without including any keys.
+func TestSSH_Events(t *testing.T) {
+	t.Parallel()
+
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b, err := Factory(context.Background(), config)
+	require.NoError(t, err)
+
+	for _, req := range []*logical.Request{
+		{Operation: logical.UpdateOperation, Path: "config/ca", Data: map[string]interface{}{"key_type": "ed25519"}},
+		{Operation: logical.UpdateOperation, Path: "roles/test", Data: map[string]interface{}{
+			"key_type":                "ca",
+			"allow_user_certificates": true,
+			"allowed_users":           "*",
+		}},
+		{Operation: logical.UpdateOperation, Path: "sign/test", Data: map[string]interface{}{
+			"public_key":       publicKey2,
+			"valid_principals": "alice",
+		}},
+	} {
+		req.Storage = config.StorageView
+		resp, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%s: %v", req.Path, resp)
+	}
+
+	var eventTypes []string
+	for _, e := range events.Events() {
+		eventTypes = append(eventTypes, e.EventType)
+		fields := e.Event.Metadata.AsMap()
+		require.NotEmpty(t, fields["issuer_id"])
+		require.NotContains(t, fields, "public_key")
+		require.NotContains(t, fields, "private_key")
+		require.NotContains(t, fields, "signed_key")
+	}
+	require.Equal(t, []string{"ssh/ca-configure", "ssh/sign"}, eventTypes)
+
+	configure := events.Events()[0].Event.Metadata.AsMap()
+	require.Equal(t, "config/ca", configure[logical.EventMetadataDataPath])
+	require.Equal(t, "true", configure["generated"])
+
+	sign := events.Events()[1].Event.Metadata.AsMap()
+	require.Equal(t, "sign", sign[logical.EventMetadataOperation])
+	require.Equal(t, "test", sign["role"])
+	require.Equal(t, "alice", sign["valid_principals"])
+	require.Equal(t, "user", sign["cert_type"])
+}
@@ -0,0 +1,398 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	issuersStoragePrefix     = "issuers/"
+	issuersConfigStoragePath = "config/issuers"
+	legacyCAMigrationLogPath = "config/legacy-ca-migration-log"
+
+	// defaultRef refers to the issuer currently set as default.
+	defaultRef = "default"
+)
+
+var errIssuerNameInUse = errors.New("issuer name already in use")
+
+// issuerEntry is a CA key pair certificates can be signed with. All issuers
+// are trusted; the public keys of every issuer are published on the
+// public_key endpoint so that a new CA can be rolled out before it is used.
+type issuerEntry struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	PublicKey  string    `json:"public_key"`
+	PrivateKey string    `json:"private_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type issuerConfigEntry struct {
+	DefaultIssuerID string `json:"default"`
+}
+
+// legacyCAMigrationLog records the migration of a CA configured before
+// issuers were introduced. The legacy key pair is left in place so that the
+// mount can still be read by older versions of Vault; Hash identifies the
+// legacy key pair that was migrated, so that it is migrated again if an older
+// version reconfigures it.
+type legacyCAMigrationLog struct {
+	Hash     string    `json:"hash"`
+	Created  time.Time `json:"created"`
+	IssuerID string    `json:"issuer_id"`
+}
+
+func listIssuers(ctx context.Context, s logical.Storage) ([]string, error) {
+	ids, err := s.List(ctx, issuersStoragePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issuers: %w", err)
+	}
+	return ids, nil
+}
+
+func fetchIssuerByID(ctx context.Context, s logical.Storage, id string) (*issuerEntry, error) {
+	entry, err := s.Get(ctx, issuersStoragePrefix+id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuer %v: %w", id, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var issuer issuerEntry
+	if err := entry.DecodeJSON(&issuer); err != nil {
+		return nil, fmt.Errorf("failed to decode issuer %v: %w", id, err)
+	}
+	return &issuer, nil
+}
+
+// fetchIssuers returns all issuers, the default issuer first and the rest
+// ordered by creation time.
+func fetchIssuers(ctx context.Context, s logical.Storage) ([]*issuerEntry, error) {
+	ids, err := listIssuers(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	config, err := fetchIssuerConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	issuers := make([]*issuerEntry, 0, len(ids))
+	for _, id := range ids {
+		issuer, err := fetchIssuerByID(ctx, s, id)
+		if err != nil {
+			return nil, err
+		}
+		if issuer != nil {
+			issuers = append(issuers, issuer)
+		}
+	}
+
+	sort.SliceStable(issuers, func(i, j int) bool {
+		if (issuers[i].ID == config.DefaultIssuerID) != (issuers[j].ID == config.DefaultIssuerID) {
+			return issuers[i].ID == config.DefaultIssuerID
+		}
+		return issuers[i].CreatedAt.Before(issuers[j].CreatedAt)
+	})
+	return issuers, nil
+}
+
+func writeIssuer(ctx context.Context, s logical.Storage, issuer *issuerEntry) error {
+	entry, err := logical.StorageEntryJSON(issuersStoragePrefix+issuer.ID, issuer)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func fetchIssuerConfig(ctx context.Context, s logical.Storage) (*issuerConfigEntry, error) {
+	entry, err := s.Get(ctx, issuersConfigStoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issuers configuration: %w", err)
+	}
+	if entry == nil {
+		return &issuerConfigEntry{}, nil
+	}
+
+	var config issuerConfigEntry
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode issuers configuration: %w", err)
+	}
+	return &config, nil
+}
+
+func writeIssuerConfig(ctx context.Context, s logical.Storage, config *issuerConfigEntry) error {
+	entry, err := logical.StorageEntryJSON(issuersConfigStoragePath, config)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// resolveIssuerReference maps "default", an issuer ID or an issuer name to
+// an issuer ID. An empty ID is returned if no issuer matches.
+func resolveIssuerReference(ctx context.Context, s logical.Storage, ref string) (string, error) {
+	if ref == "" || ref == defaultRef {
+		config, err := fetchIssuerConfig(ctx, s)
+		if err != nil {
+			return "", err
+		}
+		return config.DefaultIssuerID, nil
+	}
+
+	issuer, err := fetchIssuerByID(ctx, s, ref)
+	if err != nil {
+		return "", err
+	}
+	if issuer != nil {
+		return issuer.ID, nil
+	}
+
+	ids, err := listIssuers(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range ids {
+		issuer, err := fetchIssuerByID(ctx, s, id)
+		if err != nil {
+			return "", err
+		}
+		if issuer != nil && issuer.Name == ref {
+			return issuer.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// fetchIssuerByRef returns the issuer referenced by ref, or nil if there is
+// none. Mounts whose CA predates issuers and has not been migrated yet expose
+// it as the default issuer.
+func fetchIssuerByRef(ctx context.Context, s logical.Storage, ref string) (*issuerEntry, error) {
+	id, err := resolveIssuerReference(ctx, s, ref)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		return fetchIssuerByID(ctx, s, id)
+	}
+	if ref != "" && ref != defaultRef {
+		return nil, nil
+	}
+
+	ids, err := listIssuers(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		return nil, nil
+	}
+	return fetchUnmigratedLegacyIssuer(ctx, s)
+}
+
+func fetchLegacyIssuer(ctx context.Context, s logical.Storage) (*issuerEntry, error) {
+	publicKeyEntry, err := caKey(ctx, s, caPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA public key: %w", err)
+	}
+	privateKeyEntry, err := caKey(ctx, s, caPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA private key: %w", err)
+	}
+	if publicKeyEntry == nil || publicKeyEntry.Key == "" || privateKeyEntry == nil || privateKeyEntry.Key == "" {
+		return nil, nil
+	}
+
+	return &issuerEntry{
+		PublicKey:  publicKeyEntry.Key,
+		PrivateKey: privateKeyEntry.Key,
+	}, nil
+}
+
+// fetchUnmigratedLegacyIssuer returns the legacy CA key pair, or nil if there
+// is none or it has already been migrated to an issuer.
+func fetchUnmigratedLegacyIssuer(ctx context.Context, s logical.Storage) (*issuerEntry, error) {
+	legacy, err := fetchLegacyIssuer(ctx, s)
+	if err != nil || legacy == nil {
+		return nil, err
+	}
+
+	log, err := fetchLegacyCAMigrationLog(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if log != nil && log.Hash == legacyCAHash(legacy) {
+		return nil, nil
+	}
+	return legacy, nil
+}
+
+func legacyCAHash(legacy *issuerEntry) string {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(legacy.PublicKey) + "\n" + strings.TrimSpace(legacy.PrivateKey)))
+	return hex.EncodeToString(hash[:])
+}
+
+func fetchLegacyCAMigrationLog(ctx context.Context, s logical.Storage) (*legacyCAMigrationLog, error) {
+	entry, err := s.Get(ctx, legacyCAMigrationLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA migration log: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var log legacyCAMigrationLog
+	if err := entry.DecodeJSON(&log); err != nil {
+		return nil, fmt.Errorf("failed to decode CA migration log: %w", err)
+	}
+	return &log, nil
+}
+
+// importIssuer stores a new issuer. It becomes the default issuer if
+// setDefault is true or if there is no default issuer yet. Callers must hold
+// issuersLock.
+func (b *backend) importIssuer(ctx context.Context, s logical.Storage, name, publicKey, privateKey string, setDefault bool) (*issuerEntry, error) {
+	if name != "" {
+		id, err := resolveIssuerReference(ctx, s, name)
+		if err != nil {
+			return nil, err
+		}
+		if id != "" {
+			return nil, errIssuerNameInUse
+		}
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	issuer := &issuerEntry{
+		ID:         id,
+		Name:       name,
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := writeIssuer(ctx, s, issuer); err != nil {
+		return nil, fmt.Errorf("failed to store issuer: %w", err)
+	}
+
+	config, err := fetchIssuerConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if setDefault || config.DefaultIssuerID == "" {
+		config.DefaultIssuerID = issuer.ID
+		if err := writeIssuerConfig(ctx, s, config); err != nil {
+			return nil, fmt.Errorf("failed to update default issuer: %w", err)
+		}
+	}
+
+	return issuer, nil
+}
+
+// validateIssuerName rejects names that would be ambiguous issuer references.
+func validateIssuerName(name string) error {
+	if name == defaultRef {
+		return fmt.Errorf("issuer name %q is reserved", defaultRef)
+	}
+	if _, err := uuid.ParseUUID(name); err == nil {
+		return errors.New("issuer name must not be a UUID")
+	}
+	if strings.Contains(name, "/") {
+		return errors.New("issuer name must not contain slashes")
+	}
+	return nil
+}
+
+// migrateLegacyCA copies a CA configured before issuers were introduced into
+// the default issuer, and records the migration so that it happens only once.
+func (b *backend) migrateLegacyCA(ctx context.Context, s logical.Storage) error {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	return b.migrateLegacyCALocked(ctx, s)
+}
+
+// migrateLegacyCALocked is migrateLegacyCA for callers already holding
+// issuersLock.
+func (b *backend) migrateLegacyCALocked(ctx context.Context, s logical.Storage) error {
+	legacy, err := fetchUnmigratedLegacyIssuer(ctx, s)
+	if err != nil || legacy == nil {
+		return err
+	}
+
+	// Reuse an issuer holding the same key pair rather than duplicating it.
+	issuers, err := fetchIssuers(ctx, s)
+	if err != nil {
+		return err
+	}
+	var issuer *issuerEntry
+	for _, existing := range issuers {
+		if strings.TrimSpace(existing.PublicKey) == strings.TrimSpace(legacy.PublicKey) {
+			issuer = existing
+			break
+		}
+	}
+	if issuer == nil {
+		issuer, err = b.importIssuer(ctx, s, "", legacy.PublicKey, legacy.PrivateKey, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON(legacyCAMigrationLogPath, &legacyCAMigrationLog{
+		Hash:     legacyCAHash(legacy),
+		Created:  time.Now().UTC(),
+		IssuerID: issuer.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return fmt.Errorf("failed to write CA migration log: %w", err)
+	}
+
+	b.Logger().Info("migrated CA key pair to the default issuer", "issuer_id", issuer.ID)
+	return nil
+}
+
+func (b *backend) initialize(ctx context.Context, req *logical.InitializationRequest) error {
+	// Only migrate on a primary cluster or a performance secondary with a
+	// local mount; condition copied from PKI builtin.
+	if b.System().ReplicationState().HasState(consts.ReplicationDRSecondary|consts.ReplicationPerformanceStandby) ||
+		(!b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary)) {
+		return nil
+	}
+
+	if err := b.migrateLegacyCA(ctx, req.Storage); err != nil {
+		if strings.Contains(err.Error(), logical.ErrReadOnly.Error()) {
+			return nil
+		}
+		return fmt.Errorf("failed to migrate CA key pair to issuers: %w", err)
+	}
+	return nil
+}
+
+// signerForIssuer parses the issuer's private key.
+func signerForIssuer(issuer *issuerEntry) (ssh.Signer, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(issuer.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored CA private key: %w", err)
+	}
+	return signer, nil
+}
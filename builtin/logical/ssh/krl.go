@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	certsStoragePrefix   = "certs/"
+	revokedStoragePrefix = "revoked/"
+	krlStoragePath       = "krl"
+)
+
+// OpenSSH KRL format constants; see PROTOCOL.krl in the OpenSSH sources.
+const (
+	krlMagic                 = 0x5353484b524c0a00
+	krlFormatVersion         = 1
+	krlSectionCertificates   = 1
+	krlCertSectionSerialList = 0x20
+)
+
+// issuedCertEntry tracks a signed certificate by serial number so that it
+// can be revoked before it expires.
+type issuedCertEntry struct {
+	SerialNumber    string    `json:"serial_number"`
+	KeyID           string    `json:"key_id"`
+	CertType        string    `json:"cert_type"`
+	ValidPrincipals []string  `json:"valid_principals"`
+	Role            string    `json:"role"`
+	CAPublicKey     string    `json:"ca_public_key"`
+	Expiration      time.Time `json:"expiration"`
+	RevocationTime  time.Time `json:"revocation_time,omitempty"`
+}
+
+// krlEntry is the last generated KRL, served as-is by the krl endpoint.
+type krlEntry struct {
+	Version uint64 `json:"version"`
+	KRL     []byte `json:"krl"`
+}
+
+func formatSerial(serial uint64) string {
+	return strconv.FormatUint(serial, 16)
+}
+
+// parseSerial accepts serial numbers as returned by the sign and issue
+// endpoints, optionally colon-separated.
+func parseSerial(serial string) (uint64, error) {
+	serial = strings.ReplaceAll(strings.TrimSpace(serial), ":", "")
+	return strconv.ParseUint(serial, 16, 64)
+}
+
+func storeIssuedCert(ctx context.Context, s logical.Storage, cert *ssh.Certificate, role string) error {
+	certType := "user"
+	if cert.CertType == ssh.HostCert {
+		certType = "host"
+	}
+
+	entry, err := logical.StorageEntryJSON(certsStoragePrefix+formatSerial(cert.Serial), &issuedCertEntry{
+		SerialNumber:    formatSerial(cert.Serial),
+		KeyID:           cert.KeyId,
+		CertType:        certType,
+		ValidPrincipals: cert.ValidPrincipals,
+		Role:            role,
+		CAPublicKey:     strings.TrimSpace(string(ssh.MarshalAuthorizedKey(cert.SignatureKey))),
+		Expiration:      time.Unix(int64(cert.ValidBefore), 0).UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func getCertEntry(ctx context.Context, s logical.Storage, path string) (*issuedCertEntry, error) {
+	entry, err := s.Get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result issuedCertEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// rebuildKRL regenerates the stored KRL from the unexpired revoked
+// certificates. Callers must hold krlLock.
+func (b *backend) rebuildKRL(ctx context.Context, s logical.Storage) error {
+	serials, err := s.List(ctx, revokedStoragePrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list revoked certificates: %w", err)
+	}
+
+	now := time.Now()
+	revoked := make(map[string][]uint64)
+	for _, serial := range serials {
+		cert, err := getCertEntry(ctx, s, revokedStoragePrefix+serial)
+		if err != nil {
+			return fmt.Errorf("failed to read revoked certificate %v: %w", serial, err)
+		}
+		if cert == nil || cert.Expiration.Before(now) {
+			continue
+		}
+		parsed, err := parseSerial(cert.SerialNumber)
+		if err != nil {
+			return fmt.Errorf("failed to parse serial number of revoked certificate %v: %w", serial, err)
+		}
+		revoked[cert.CAPublicKey] = append(revoked[cert.CAPublicKey], parsed)
+	}
+
+	current, err := b.fetchKRL(ctx, s)
+	if err != nil {
+		return err
+	}
+	version := current.Version + 1
+
+	krl, err := marshalKRL(version, now, revoked)
+	if err != nil {
+		return err
+	}
+
+	entry, err := logical.StorageEntryJSON(krlStoragePath, &krlEntry{
+		Version: version,
+		KRL:     krl,
+	})
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// fetchKRL returns the stored KRL, or an empty KRL if no certificate has
+// been revoked yet.
+func (b *backend) fetchKRL(ctx context.Context, s logical.Storage) (*krlEntry, error) {
+	entry, err := s.Get(ctx, krlStoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KRL: %w", err)
+	}
+	if entry == nil {
+		krl, err := marshalKRL(0, time.Now(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return &krlEntry{KRL: krl}, nil
+	}
+
+	var result krlEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// marshalKRL encodes an OpenSSH KRL revoking the given certificate serial
+// numbers, keyed by the authorized_keys encoding of their signing CA.
+func marshalKRL(version uint64, generated time.Time, revoked map[string][]uint64) ([]byte, error) {
+	var out []byte
+	out = binary.BigEndian.AppendUint64(out, krlMagic)
+	out = binary.BigEndian.AppendUint32(out, krlFormatVersion)
+	out = binary.BigEndian.AppendUint64(out, version)
+	out = binary.BigEndian.AppendUint64(out, uint64(generated.Unix()))
+	out = binary.BigEndian.AppendUint64(out, 0) // flags
+	out = appendKRLString(out, nil)             // reserved
+	out = appendKRLString(out, nil)             // comment
+
+	caKeys := make([]string, 0, len(revoked))
+	for caKey := range revoked {
+		caKeys = append(caKeys, caKey)
+	}
+	sort.Strings(caKeys)
+
+	for _, caKey := range caKeys {
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CA public key: %w", err)
+		}
+
+		serials := revoked[caKey]
+		sort.Slice(serials, func(i, j int) bool { return serials[i] < serials[j] })
+
+		var serialList []byte
+		for _, serial := range serials {
+			serialList = binary.BigEndian.AppendUint64(serialList, serial)
+		}
+
+		var section []byte
+		section = appendKRLString(section, publicKey.Marshal())
+		section = appendKRLString(section, nil) // reserved
+		section = append(section, krlCertSectionSerialList)
+		section = appendKRLString(section, serialList)
+
+		out = append(out, krlSectionCertificates)
+		out = appendKRLString(out, section)
+	}
+
+	return out, nil
+}
+
+func appendKRLString(out, s []byte) []byte {
+	out = binary.BigEndian.AppendUint32(out, uint32(len(s)))
+	return append(out, s...)
+}
@@ -16,8 +16,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 
-	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
 	"golang.org/x/crypto/ssh"
@@ -105,18 +105,18 @@ Read operations will return the public key, if already stored/generated.`,
 }
 
 func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
+	issuer, err := fetchIssuerByRef(ctx, req.Storage, defaultRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read CA public key: %w", err)
 	}
 
-	if publicKeyEntry == nil {
+	if issuer == nil {
 		return logical.ErrorResponse("keys haven't been configured yet"), nil
 	}
 
 	response := &logical.Response{
 		Data: map[string]interface{}{
-			"public_key": publicKeyEntry.Key,
+			"public_key": issuer.PublicKey,
 		},
 	}
 
@@ -124,12 +124,32 @@ func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, da
 }
 
 func (b *backend) pathConfigCADelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	config, err := fetchIssuerConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config.DefaultIssuerID != "" {
+		if err := req.Storage.Delete(ctx, issuersStoragePrefix+config.DefaultIssuerID); err != nil {
+			return nil, err
+		}
+		config.DefaultIssuerID = ""
+		if err := writeIssuerConfig(ctx, req.Storage, config); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := req.Storage.Delete(ctx, caPrivateKeyStoragePath); err != nil {
 		return nil, err
 	}
 	if err := req.Storage.Delete(ctx, caPublicKeyStoragePath); err != nil {
 		return nil, err
 	}
+
+	b.SendOperationEvent(ctx, "ssh", "ca-delete", "config/ca", true)
+
 	return nil, nil
 }
 
@@ -204,22 +224,8 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 
 	// explicitly set to false, or not set and we have both a public and private key
 	case ok, publicKey != "" && privateKey != "":
-		if publicKey == "" {
-			return logical.ErrorResponse("missing public_key"), nil
-		}
-
-		if privateKey == "" {
-			return logical.ErrorResponse("missing private_key"), nil
-		}
-
-		_, err := ssh.ParsePrivateKey([]byte(privateKey))
-		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("Unable to parse private_key as an SSH private key: %v", err)), nil
-		}
-
-		_, err = parsePublicSSHKey(publicKey)
-		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("Unable to parse public_key as an SSH public key: %v", err)), nil
+		if err := validateCAKeyPair(publicKey, privateKey); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
 		}
 
 	// not set and no public/private key provided so generate
@@ -245,56 +251,26 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 		return nil, fmt.Errorf("failed to generate or parse the keys")
 	}
 
-	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read CA public key: %w", err)
-	}
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
 
-	privateKeyEntry, err := caKey(ctx, req.Storage, caPrivateKey)
+	existing, err := fetchIssuerByRef(ctx, req.Storage, defaultRef)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CA private key: %w", err)
+		return nil, fmt.Errorf("failed to read CA keys: %w", err)
 	}
-
-	if (publicKeyEntry != nil && publicKeyEntry.Key != "") || (privateKeyEntry != nil && privateKeyEntry.Key != "") {
+	if existing != nil {
 		return logical.ErrorResponse("keys are already configured; delete them before reconfiguring"), nil
 	}
 
-	entry, err := logical.StorageEntryJSON(caPublicKeyStoragePath, &keyStorageEntry{
-		Key: publicKey,
-	})
+	issuer, err := b.importIssuer(ctx, req.Storage, "", publicKey, privateKey, true)
 	if err != nil {
 		return nil, err
 	}
 
-	// Save the public key
-	err = req.Storage.Put(ctx, entry)
-	if err != nil {
-		return nil, err
-	}
-
-	entry, err = logical.StorageEntryJSON(caPrivateKeyStoragePath, &keyStorageEntry{
-		Key: privateKey,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Save the private key
-	err = req.Storage.Put(ctx, entry)
-	if err != nil {
-		var mErr *multierror.Error
-
-		mErr = multierror.Append(mErr, fmt.Errorf("failed to store CA private key: %w", err))
-
-		// If storing private key fails, the corresponding public key should be
-		// removed
-		if delErr := req.Storage.Delete(ctx, caPublicKeyStoragePath); delErr != nil {
-			mErr = multierror.Append(mErr, fmt.Errorf("failed to cleanup CA public key: %w", delErr))
-			return nil, mErr
-		}
-
-		return nil, err
-	}
+	b.SendOperationEvent(ctx, "ssh", "ca-configure", "config/ca", true,
+		"issuer_id", issuer.ID,
+		"generated", strconv.FormatBool(generateSigningKey),
+	)
 
 	if generateSigningKey {
 		response := &logical.Response{
@@ -309,6 +285,28 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 	return nil, nil
 }
 
+// validateCAKeyPair checks that both halves of a supplied CA key pair are
+// present and parse as SSH keys.
+func validateCAKeyPair(publicKey, privateKey string) error {
+	if publicKey == "" {
+		return errors.New("missing public_key")
+	}
+
+	if privateKey == "" {
+		return errors.New("missing private_key")
+	}
+
+	if _, err := ssh.ParsePrivateKey([]byte(privateKey)); err != nil {
+		return fmt.Errorf("Unable to parse private_key as an SSH private key: %v", err)
+	}
+
+	if _, err := parsePublicSSHKey(publicKey); err != nil {
+		return fmt.Errorf("Unable to parse public_key as an SSH public key: %v", err)
+	}
+
+	return nil
+}
+
 func generateSSHKeyPair(randomSource io.Reader, keyType string, keyBits int) (string, string, error) {
 	if randomSource == nil {
 		randomSource = rand.Reader
@@ -146,6 +146,12 @@ func (b *backend) pathCredsCreateWrite(ctx context.Context, req *logical.Request
 		}, map[string]interface{}{
 			"otp": otp,
 		})
+
+		b.SendOperationEvent(ctx, "ssh", "creds-create", "", false,
+			"role", roleName,
+			"username", username,
+			"ip", ip,
+		)
 	} else if role.KeyType == KeyTypeDynamic {
 		return nil, fmt.Errorf("dynamic key types have been removed")
 	} else {
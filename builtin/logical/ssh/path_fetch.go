@@ -5,6 +5,7 @@ package ssh
 
 import (
 	"context"
+	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -24,23 +25,36 @@ func pathFetchPublicKey(b *backend) *framework.Path {
 		},
 
 		HelpSynopsis:    `Retrieve the public key.`,
-		HelpDescription: `This allows the public key of the SSH CA certificate that this backend has been configured with to be fetched. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
+		HelpDescription: `This allows the public keys of the SSH CA issuers that this backend has been configured with to be fetched, one per line with the default issuer first, suitable for sshd's TrustedUserCAKeys option. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
 	}
 }
 
 func (b *backend) pathFetchPublicKey(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
+	issuers, err := fetchIssuers(ctx, req.Storage)
 	if err != nil {
 		return nil, err
 	}
-	if publicKeyEntry == nil || publicKeyEntry.Key == "" {
-		return nil, nil
+	if len(issuers) == 0 {
+		legacy, err := fetchUnmigratedLegacyIssuer(ctx, req.Storage)
+		if err != nil {
+			return nil, err
+		}
+		if legacy == nil {
+			return nil, nil
+		}
+		issuers = append(issuers, legacy)
+	}
+
+	var publicKeys strings.Builder
+	for _, issuer := range issuers {
+		publicKeys.WriteString(strings.TrimSpace(issuer.PublicKey))
+		publicKeys.WriteString("\n")
 	}
 
 	response := &logical.Response{
 		Data: map[string]interface{}{
 			logical.HTTPContentType: "text/plain",
-			logical.HTTPRawBody:     []byte(publicKeyEntry.Key),
+			logical.HTTPRawBody:     []byte(publicKeys.String()),
 			logical.HTTPStatusCode:  200,
 		},
 	}
@@ -101,17 +101,21 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	privateKeyEntry, err := caKey(ctx, req.Storage, caPrivateKey)
+	issuerRef := role.IssuerRef
+	if issuerRef == "" {
+		issuerRef = defaultRef
+	}
+	issuer, err := fetchIssuerByRef(ctx, req.Storage, issuerRef)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read CA private key: %w", err)
 	}
-	if privateKeyEntry == nil || privateKeyEntry.Key == "" {
-		return nil, errors.New("failed to read CA private key")
+	if issuer == nil || issuer.PrivateKey == "" {
+		return nil, fmt.Errorf("failed to read CA private key of issuer %q", issuerRef)
 	}
 
-	signer, err := ssh.ParsePrivateKey([]byte(privateKeyEntry.Key))
+	signer, err := signerForIssuer(issuer)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse stored CA private key: %w", err)
+		return nil, err
 	}
 
 	cBundle := creationBundle{
@@ -131,6 +135,12 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 		return nil, err
 	}
 
+	if !role.NoStore {
+		if err := storeIssuedCert(ctx, req.Storage, certificate, data.Get("role").(string)); err != nil {
+			return nil, fmt.Errorf("failed to store certificate: %w", err)
+		}
+	}
+
 	signedSSHCertificate := ssh.MarshalAuthorizedKey(certificate)
 	if len(signedSSHCertificate) == 0 {
 		return nil, errors.New("error marshaling signed certificate")
@@ -147,6 +157,23 @@ func (b *backend) pathSignIssueCertificateHelper(ctx context.Context, req *logic
 		response.AddWarning("default_extension templating enabled with at least one extension requiring identity templating. However, this request lacked identity entity information, causing one or more extensions to be skipped from the generated certificate.")
 	}
 
+	operation := "sign"
+	if strings.HasPrefix(req.Path, "issue/") {
+		operation = "issue"
+	}
+	certType := "user"
+	if certificateType == ssh.HostCert {
+		certType = "host"
+	}
+	b.SendOperationEvent(ctx, "ssh", operation, "", false,
+		"role", data.Get("role").(string),
+		"serial_number", strconv.FormatUint(certificate.Serial, 16),
+		"key_id", keyID,
+		"cert_type", certType,
+		"valid_principals", strings.Join(parsedPrincipals, ","),
+		"issuer_id", issuer.ID,
+	)
+
 	return response, nil
 }
 
@@ -0,0 +1,456 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func issuerNameSchema() *framework.FieldSchema {
+	return &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: `Optional name to refer to this issuer by. Must be unique, must not be "default" and must not be a UUID.`,
+	}
+}
+
+func setDefaultSchema() *framework.FieldSchema {
+	return &framework.FieldSchema{
+		Type:        framework.TypeBool,
+		Description: `Make the new issuer the default issuer, used by roles without an explicit issuer_ref. The first issuer always becomes the default.`,
+	}
+}
+
+func pathListIssuers(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuers/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "issuers",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathListIssuers,
+		},
+
+		HelpSynopsis:    `List the SSH CA issuers of this mount.`,
+		HelpDescription: `This endpoint lists the IDs of all issuers, along with their names and which one is the default issuer.`,
+	}
+}
+
+func pathGenerateIssuer(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuers/generate",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "generate",
+			OperationSuffix: "issuer",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"issuer_name": issuerNameSchema(),
+			"set_default": setDefaultSchema(),
+			"key_type": {
+				Type:        framework.TypeString,
+				Description: `Specifies the desired key type; could be a OpenSSH key type identifier (ssh-rsa, ecdsa-sha2-nistp256, ecdsa-sha2-nistp384, ecdsa-sha2-nistp521, or ssh-ed25519) or an algorithm (rsa, ec, ed25519).`,
+				Default:     "ssh-rsa",
+			},
+			"key_bits": {
+				Type:        framework.TypeInt,
+				Description: `Specifies the desired key bits for variable-length keys (such as when key_type="ssh-rsa") or which NIST P-curve to use when key_type="ec" (256, 384, or 521).`,
+				Default:     0,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathGenerateIssuer,
+		},
+
+		HelpSynopsis:    `Generate a new SSH CA issuer.`,
+		HelpDescription: pathIssuersCreateHelpDesc,
+	}
+}
+
+func pathImportIssuer(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuers/import",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "import",
+			OperationSuffix: "issuer",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"issuer_name": issuerNameSchema(),
+			"set_default": setDefaultSchema(),
+			"private_key": {
+				Type:        framework.TypeString,
+				Description: `Private half of the SSH key that will be used to sign certificates.`,
+			},
+			"public_key": {
+				Type:        framework.TypeString,
+				Description: `Public half of the SSH key that will be used to sign certificates.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathImportIssuer,
+		},
+
+		HelpSynopsis:    `Import an existing SSH key pair as a new CA issuer.`,
+		HelpDescription: pathIssuersCreateHelpDesc,
+	}
+}
+
+func pathIssuer(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuer/" + framework.GenericNameRegex("issuer_ref"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"issuer_ref": {
+				Type:        framework.TypeString,
+				Description: `Reference to an issuer, either "default", the issuer's ID or its name.`,
+				Required:    true,
+			},
+			"issuer_name": {
+				Type:        framework.TypeString,
+				Description: `New name of the issuer. Set to the empty string to remove the name.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathIssuerRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "read",
+					OperationSuffix: "issuer",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathIssuerUpdate,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "write",
+					OperationSuffix: "issuer",
+				},
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathIssuerDelete,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "delete",
+					OperationSuffix: "issuer",
+				},
+			},
+		},
+
+		HelpSynopsis:    `Read, rename or delete an SSH CA issuer.`,
+		HelpDescription: pathIssuerHelpDesc,
+	}
+}
+
+func pathConfigIssuers(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/issuers",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"default": {
+				Type:        framework.TypeString,
+				Description: `Reference (ID or name) to the issuer used by roles without an explicit issuer_ref.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathConfigIssuersRead,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "issuers-configuration",
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathConfigIssuersWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "issuers",
+				},
+			},
+		},
+
+		HelpSynopsis:    `Read or set the default SSH CA issuer.`,
+		HelpDescription: `The default issuer signs certificates for roles without an explicit issuer_ref and is returned by config/ca.`,
+	}
+}
+
+func (b *backend) pathListIssuers(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	issuers, err := fetchIssuers(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	config, err := fetchIssuerConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(issuers))
+	keyInfo := make(map[string]interface{}, len(issuers))
+	for _, issuer := range issuers {
+		keys = append(keys, issuer.ID)
+		keyInfo[issuer.ID] = map[string]interface{}{
+			"issuer_name": issuer.Name,
+			"is_default":  issuer.ID == config.DefaultIssuerID,
+		}
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
+}
+
+func (b *backend) pathGenerateIssuer(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("issuer_name").(string)
+	if name != "" {
+		if err := validateIssuerName(name); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	publicKey, privateKey, err := generateSSHKeyPair(b.Backend.GetRandomReader(), data.Get("key_type").(string), data.Get("key_bits").(int))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return b.createIssuer(ctx, req, "issuer-generate", name, publicKey, privateKey, data.Get("set_default").(bool))
+}
+
+func (b *backend) pathImportIssuer(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("issuer_name").(string)
+	if name != "" {
+		if err := validateIssuerName(name); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	publicKey := data.Get("public_key").(string)
+	privateKey := data.Get("private_key").(string)
+	if err := validateCAKeyPair(publicKey, privateKey); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	return b.createIssuer(ctx, req, "issuer-import", name, publicKey, privateKey, data.Get("set_default").(bool))
+}
+
+func (b *backend) createIssuer(ctx context.Context, req *logical.Request, operation, name, publicKey, privateKey string, setDefault bool) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	// A CA configured before issuers were introduced must become an issuer
+	// before any other is added, so that it stays trusted.
+	if err := b.migrateLegacyCALocked(ctx, req.Storage); err != nil {
+		return nil, err
+	}
+
+	issuer, err := b.importIssuer(ctx, req.Storage, name, publicKey, privateKey, setDefault)
+	if errors.Is(err, errIssuerNameInUse) {
+		return logical.ErrorResponse("issuer name %q is already in use", name), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.SendOperationEvent(ctx, "ssh", operation, "", true, "issuer_id", issuer.ID)
+
+	return b.issuerResponse(ctx, req.Storage, issuer)
+}
+
+func (b *backend) pathIssuerRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	issuer, err := fetchIssuerByRef(ctx, req.Storage, data.Get("issuer_ref").(string))
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil || issuer.ID == "" {
+		return nil, nil
+	}
+
+	return b.issuerResponse(ctx, req.Storage, issuer)
+}
+
+func (b *backend) pathIssuerUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	issuer, err := b.fetchIssuerForUpdate(ctx, req.Storage, data.Get("issuer_ref").(string))
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil {
+		return logical.ErrorResponse("unable to find issuer %q", data.Get("issuer_ref").(string)), nil
+	}
+
+	if nameRaw, ok := data.GetOk("issuer_name"); ok {
+		name := nameRaw.(string)
+		if name != "" && name != issuer.Name {
+			if err := validateIssuerName(name); err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+			id, err := resolveIssuerReference(ctx, req.Storage, name)
+			if err != nil {
+				return nil, err
+			}
+			if id != "" && id != issuer.ID {
+				return logical.ErrorResponse("issuer name %q is already in use", name), nil
+			}
+		}
+		issuer.Name = name
+	}
+
+	if err := writeIssuer(ctx, req.Storage, issuer); err != nil {
+		return nil, fmt.Errorf("failed to store issuer: %w", err)
+	}
+
+	b.SendOperationEvent(ctx, "ssh", "issuer-update", "", true, "issuer_id", issuer.ID)
+
+	return b.issuerResponse(ctx, req.Storage, issuer)
+}
+
+func (b *backend) pathIssuerDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	issuer, err := b.fetchIssuerForUpdate(ctx, req.Storage, data.Get("issuer_ref").(string))
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil {
+		return nil, nil
+	}
+
+	if err := req.Storage.Delete(ctx, issuersStoragePrefix+issuer.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete issuer: %w", err)
+	}
+
+	var response *logical.Response
+	config, err := fetchIssuerConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if config.DefaultIssuerID == issuer.ID {
+		config.DefaultIssuerID = ""
+		if err := writeIssuerConfig(ctx, req.Storage, config); err != nil {
+			return nil, fmt.Errorf("failed to update default issuer: %w", err)
+		}
+		response = &logical.Response{}
+		response.AddWarning("Deleted the default issuer; roles using the default issuer will fail to sign certificates until a new default is set on config/issuers.")
+	}
+
+	b.SendOperationEvent(ctx, "ssh", "issuer-delete", "", true, "issuer_id", issuer.ID)
+
+	return response, nil
+}
+
+func (b *backend) pathConfigIssuersRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	config, err := fetchIssuerConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default": config.DefaultIssuerID,
+		},
+	}, nil
+}
+
+func (b *backend) pathConfigIssuersWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ref := data.Get("default").(string)
+	if ref == "" || ref == defaultRef {
+		return logical.ErrorResponse("default must be set to the ID or name of an issuer"), nil
+	}
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	issuer, err := b.fetchIssuerForUpdate(ctx, req.Storage, ref)
+	if err != nil {
+		return nil, err
+	}
+	if issuer == nil {
+		return logical.ErrorResponse("unable to find issuer %q", ref), nil
+	}
+
+	config, err := fetchIssuerConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	config.DefaultIssuerID = issuer.ID
+	if err := writeIssuerConfig(ctx, req.Storage, config); err != nil {
+		return nil, fmt.Errorf("failed to update default issuer: %w", err)
+	}
+
+	b.SendOperationEvent(ctx, "ssh", "issuers-config", "", true, "issuer_id", issuer.ID)
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"default": config.DefaultIssuerID,
+		},
+	}, nil
+}
+
+// fetchIssuerForUpdate resolves ref after migrating a legacy CA, so that
+// the legacy CA can be managed like any other issuer. Callers must hold
+// issuersLock.
+func (b *backend) fetchIssuerForUpdate(ctx context.Context, s logical.Storage, ref string) (*issuerEntry, error) {
+	if err := b.migrateLegacyCALocked(ctx, s); err != nil {
+		return nil, err
+	}
+
+	id, err := resolveIssuerReference(ctx, s, ref)
+	if err != nil || id == "" {
+		return nil, err
+	}
+	return fetchIssuerByID(ctx, s, id)
+}
+
+func (b *backend) issuerResponse(ctx context.Context, s logical.Storage, issuer *issuerEntry) (*logical.Response, error) {
+	config, err := fetchIssuerConfig(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"issuer_id":   issuer.ID,
+			"issuer_name": issuer.Name,
+			"public_key":  issuer.PublicKey,
+			"is_default":  issuer.ID == config.DefaultIssuerID,
+		},
+	}, nil
+}
+
+const pathIssuersCreateHelpDesc = `
+This endpoint adds a CA key pair to the mount as a new issuer. All issuers are
+trusted: their public keys are returned by the public_key endpoint, so a new
+issuer can be distributed to hosts' TrustedUserCAKeys before roles are moved
+to it with issuer_ref or before it is made the default issuer.
+`
+
+const pathIssuerHelpDesc = `
+This endpoint reads, renames or deletes a single issuer, referenced by
+"default", its ID or its name. For security reasons, the private key of an
+issuer cannot be retrieved.
+
+Once an issuer is deleted, its public key is no longer returned by the
+public_key endpoint and certificates signed by it stop being trusted on hosts
+that refresh their TrustedUserCAKeys.
+`
@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathRevoke(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "revoke",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "revoke",
+			OperationSuffix: "certificate",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"serial_number": {
+				Type:        framework.TypeString,
+				Description: `Serial number of the certificate to revoke, in hex as returned by the sign and issue endpoints.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRevokeWrite,
+		},
+
+		HelpSynopsis:    pathRevokeHelpSyn,
+		HelpDescription: pathRevokeHelpDesc,
+	}
+}
+
+func pathFetchKRL(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "krl",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationSuffix: "krl",
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathFetchKRL,
+		},
+
+		HelpSynopsis:    `Retrieve the key revocation list.`,
+		HelpDescription: `This returns the OpenSSH key revocation list (KRL) of revoked certificates, suitable for sshd's RevokedKeys option. This is a raw response endpoint without JSON encoding; use -format=raw or an external tool (e.g., curl) to fetch this value.`,
+	}
+}
+
+func pathTidyCerts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy/certs",
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixSSH,
+			OperationVerb:   "tidy",
+			OperationSuffix: "certificates",
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.DeleteOperation: b.handleTidyCerts,
+		},
+		HelpSynopsis:    `This endpoint removes expired certificates from the issued and revoked certificate lists.`,
+		HelpDescription: `For more information, refer to the API documentation.`,
+	}
+}
+
+func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	serialRaw := data.Get("serial_number").(string)
+	if serialRaw == "" {
+		return logical.ErrorResponse("missing serial_number"), nil
+	}
+	serial, err := parseSerial(serialRaw)
+	if err != nil {
+		return logical.ErrorResponse("invalid serial_number %q: %v", serialRaw, err), nil
+	}
+	serialNumber := formatSerial(serial)
+
+	b.krlLock.Lock()
+	defer b.krlLock.Unlock()
+
+	revoked, err := getCertEntry(ctx, req.Storage, revokedStoragePrefix+serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revoked certificate: %w", err)
+	}
+	if revoked != nil {
+		return revokeResponse(revoked), nil
+	}
+
+	cert, err := getCertEntry(ctx, req.Storage, certsStoragePrefix+serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	if cert == nil {
+		return logical.ErrorResponse("certificate with serial %s not found", serialNumber), nil
+	}
+
+	cert.RevocationTime = time.Now().UTC()
+	entry, err := logical.StorageEntryJSON(revokedStoragePrefix+serialNumber, cert)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to store revoked certificate: %w", err)
+	}
+
+	if err := b.rebuildKRL(ctx, req.Storage); err != nil {
+		return nil, fmt.Errorf("failed to rebuild KRL: %w", err)
+	}
+
+	b.SendOperationEvent(ctx, "ssh", "revoke", "", true,
+		"serial_number", serialNumber,
+		"key_id", cert.KeyID,
+		"cert_type", cert.CertType,
+	)
+
+	return revokeResponse(cert), nil
+}
+
+func revokeResponse(cert *issuedCertEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"serial_number":           cert.SerialNumber,
+			"revocation_time":         cert.RevocationTime.Unix(),
+			"revocation_time_rfc3339": cert.RevocationTime.Format(time.RFC3339Nano),
+		},
+	}
+}
+
+func (b *backend) pathFetchKRL(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	krl, err := b.fetchKRL(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: "application/octet-stream",
+			logical.HTTPRawBody:     krl.KRL,
+			logical.HTTPStatusCode:  200,
+		},
+	}
+
+	return response, nil
+}
+
+func (b *backend) handleTidyCerts(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.krlLock.Lock()
+	defer b.krlLock.Unlock()
+
+	now := time.Now()
+	var total, removed, removedRevoked int
+	for _, prefix := range []string{certsStoragePrefix, revokedStoragePrefix} {
+		serials, err := req.Storage.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list certificates for removal: %w", err)
+		}
+		total += len(serials)
+
+		for _, serial := range serials {
+			cert, err := getCertEntry(ctx, req.Storage, prefix+serial)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read certificate %v: %w", serial, err)
+			}
+			if cert != nil && cert.Expiration.After(now) {
+				continue
+			}
+			if err := req.Storage.Delete(ctx, prefix+serial); err != nil {
+				return nil, fmt.Errorf("unable to delete certificate %v: %w", serial, err)
+			}
+			removed++
+			if prefix == revokedStoragePrefix {
+				removedRevoked++
+			}
+		}
+	}
+
+	if removedRevoked > 0 {
+		if err := b.rebuildKRL(ctx, req.Storage); err != nil {
+			return nil, fmt.Errorf("failed to rebuild KRL: %w", err)
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"message": fmt.Sprintf("Removed %v of %v certificate entries.", removed, total),
+		},
+	}, nil
+}
+
+const pathRevokeHelpSyn = `
+Revoke a certificate issued by this mount.
+`
+
+const pathRevokeHelpDesc = `
+This endpoint revokes a certificate by serial number and adds it to the key
+revocation list (KRL) served by the unauthenticated "krl" endpoint. Point
+sshd's RevokedKeys option at a periodically refreshed copy of the KRL to
+reject revoked certificates before they expire.
+
+Only certificates signed or issued after revocation support was added are
+tracked and can be revoked.
+`
@@ -31,7 +31,7 @@ const (
 	// Present version of the sshRole struct; when adding a new field or are
 	// needing to perform a migration, increment this struct and read the note
 	// in checkUpgrade(...).
-	roleEntryVersion = 3
+	roleEntryVersion = 4
 )
 
 // Structure that represents a role in SSH backend. This is a common role structure
@@ -66,6 +66,8 @@ type sshRole struct {
 	AlgorithmSigner            string            `mapstructure:"algorithm_signer" json:"algorithm_signer"`
 	Version                    int               `mapstructure:"role_version" json:"role_version"`
 	NotBeforeDuration          time.Duration     `mapstructure:"not_before_duration" json:"not_before_duration"`
+	IssuerRef                  string            `mapstructure:"issuer_ref" json:"issuer_ref"`
+	NoStore                    bool              `mapstructure:"no_store" json:"no_store"`
 }
 
 func pathListRoles(b *backend) *framework.Path {
@@ -363,6 +365,29 @@ func pathRoles(b *backend) *framework.Path {
 					Value: 30,
 				},
 			},
+			"issuer_ref": {
+				Type:    framework.TypeString,
+				Default: defaultRef,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				Reference (ID or name) to the issuer used to sign certificates for this role.
+				Defaults to "default", the mount's default issuer.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Issuer reference",
+				},
+			},
+			"no_store": {
+				Type: framework.TypeBool,
+				Description: `
+				[Not applicable for OTP type] [Optional for CA type]
+				If set, certificates signed against this role are not stored in the
+				storage backend. This can improve performance when signing large
+				numbers of certificates, but such certificates cannot be listed or
+				revoked, so this is recommended only for short-lived certificates.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Do not store certificates",
+				},
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -460,6 +485,18 @@ func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, d *fr
 		if errorResponse != nil {
 			return errorResponse, nil
 		}
+
+		// The default issuer may legitimately be configured after the role,
+		// but an explicit reference has to point to an existing issuer.
+		if role.IssuerRef != defaultRef {
+			issuerID, err := resolveIssuerReference(ctx, req.Storage, role.IssuerRef)
+			if err != nil {
+				return nil, err
+			}
+			if issuerID == "" {
+				return logical.ErrorResponse("unable to find issuer %q", role.IssuerRef), nil
+			}
+		}
 		roleEntry = *role
 	} else {
 		return logical.ErrorResponse("invalid key type"), nil
@@ -499,6 +536,11 @@ func (b *backend) createCARole(allowedUsers, defaultUser, signer string, data *f
 		AlgorithmSigner:           signer,
 		Version:                   roleEntryVersion,
 		NotBeforeDuration:         time.Duration(data.Get("not_before_duration").(int)) * time.Second,
+		IssuerRef:                 data.Get("issuer_ref").(string),
+		NoStore:                   data.Get("no_store").(bool),
+	}
+	if role.IssuerRef == "" {
+		role.IssuerRef = defaultRef
 	}
 
 	if !role.AllowUserCertificates && !role.AllowHostCertificates {
@@ -587,17 +629,17 @@ func (b *backend) checkUpgrade(ctx context.Context, s logical.Storage, n string,
 		// signing key type as we want to make ssh-rsa an explicitly notated
 		// algorithm choice.
 		var publicKey ssh.PublicKey
-		publicKeyEntry, err := caKey(ctx, s, caPublicKey)
+		issuer, err := fetchIssuerByRef(ctx, s, defaultRef)
 		if err != nil {
 			b.Logger().Debug(fmt.Sprintf("failed to load public key entry while attempting to migrate: %v", err))
 			goto SKIPVERSION2
 		}
-		if publicKeyEntry == nil || publicKeyEntry.Key == "" {
+		if issuer == nil || issuer.PublicKey == "" {
 			b.Logger().Debug(fmt.Sprintf("got empty public key entry while attempting to migrate"))
 			goto SKIPVERSION2
 		}
 
-		publicKey, err = parsePublicSSHKey(publicKeyEntry.Key)
+		publicKey, err = parsePublicSSHKey(issuer.PublicKey)
 		if err == nil {
 			// Move an empty signing algorithm to an explicit ssh-rsa (SHA-1)
 			// if this key is of type RSA. This isn't a secure default but
@@ -622,6 +664,16 @@ func (b *backend) checkUpgrade(ctx context.Context, s logical.Storage, n string,
 		result.Version = 3
 	}
 
+	// Role version 4 pins CA roles which predate issuers to the default
+	// issuer.
+	if result.Version < 4 {
+		modified = true
+		if result.KeyType == KeyTypeCA && result.IssuerRef == "" {
+			result.IssuerRef = defaultRef
+		}
+		result.Version = 4
+	}
+
 	// Add new migrations just before here.
 	//
 	// Condition copied from PKI builtin.
@@ -691,6 +743,8 @@ func (b *backend) parseRole(role *sshRole) (map[string]interface{}, error) {
 			"allowed_user_key_lengths":    role.AllowedUserKeyTypesLengths,
 			"algorithm_signer":            role.AlgorithmSigner,
 			"not_before_duration":         int64(role.NotBeforeDuration.Seconds()),
+			"issuer_ref":                  role.IssuerRef,
+			"no_store":                    role.NoStore,
 		}
 	case KeyTypeDynamic:
 		return nil, fmt.Errorf("dynamic key type roles are no longer supported")
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	cache "github.com/patrickmn/go-cache"
 )
@@ -38,6 +39,7 @@ func Backend() *backend {
 			pathListKeys(&b),
 			pathKeys(&b),
 			pathCode(&b),
+			pathGenerateHOTPCode(&b),
 		},
 
 		Secrets:     []*framework.Secret{},
@@ -45,6 +47,7 @@ func Backend() *backend {
 	}
 
 	b.usedCodes = cache.New(0, 30*time.Second)
+	b.keyLocks = locksutil.CreateLocks()
 
 	return &b
 }
@@ -53,8 +56,13 @@ type backend struct {
 	*framework.Backend
 
 	usedCodes *cache.Cache
+
+	// keyLocks serializes updates to a key, such as advancing the counter of
+	// an HOTP key
+	keyLocks []*locksutil.LockEntry
 }
 
 const backendHelp = `
-The TOTP backend dynamically generates time-based one-time use passwords.
+The TOTP backend dynamically generates time-based one-time use passwords, and
+counter-based one-time use passwords for HOTP keys.
 `
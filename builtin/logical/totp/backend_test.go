@@ -18,6 +18,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 )
 
@@ -1037,6 +1038,175 @@ func TestBackend_generatedKeyExportedFalse(t *testing.T) {
 	})
 }
 
+func hotpRequest(t *testing.T, b logical.Backend, s logical.Storage, operation logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: operation,
+		Path:      path,
+		Storage:   s,
+		Data:      data,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: err: %v resp: %#v", err, resp)
+	}
+	return resp
+}
+
+func TestBackend_hotpCounter(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := config.StorageView
+
+	key, _ := createKey()
+	codeAt := func(counter uint64) string {
+		code, err := hotplib.GenerateCode(key, counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+	validate := func(data map[string]interface{}) bool {
+		resp := hotpRequest(t, b, s, logical.UpdateOperation, "code/test", data)
+		return resp.Data["valid"].(bool)
+	}
+
+	hotpRequest(t, b, s, logical.UpdateOperation, "keys/test", map[string]interface{}{
+		"key":            key,
+		"algorithm_type": "hotp",
+		"counter":        5,
+		"look_ahead":     2,
+	})
+
+	// Reading a code would advance the counter, so is rejected
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "code/test",
+		Storage:   s,
+	})
+	if err != nil || !resp.IsError() {
+		t.Fatalf("expected reading an HOTP code to be rejected: err: %v resp: %#v", err, resp)
+	}
+
+	// Generating a code uses the current counter and advances it
+	resp = hotpRequest(t, b, s, logical.UpdateOperation, "code/test/generate", nil)
+	if resp.Data["code"] != codeAt(5) || resp.Data["counter"] != uint64(5) {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	// Codes within the look-ahead window are valid once
+	if !validate(map[string]interface{}{"code": codeAt(8)}) {
+		t.Fatal("expected code within the look-ahead window to be valid")
+	}
+	if validate(map[string]interface{}{"code": codeAt(8)}) {
+		t.Fatal("expected code to be invalid once used")
+	}
+	if validate(map[string]interface{}{"code": codeAt(20)}) {
+		t.Fatal("expected code outside of the look-ahead window to be invalid")
+	}
+
+	// Two consecutive codes resynchronize the counter
+	if validate(map[string]interface{}{"code": codeAt(20), "next_code": codeAt(22)}) {
+		t.Fatal("expected codes that are not consecutive to be invalid")
+	}
+	if !validate(map[string]interface{}{"code": codeAt(20), "next_code": codeAt(21)}) {
+		t.Fatal("expected consecutive codes to resynchronize the counter")
+	}
+
+	resp = hotpRequest(t, b, s, logical.ReadOperation, "keys/test", nil)
+	if resp.Data["algorithm_type"] != "hotp" || resp.Data["counter"] != uint64(22) {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	if _, ok := resp.Data["period"]; ok {
+		t.Fatal("period should not be returned for HOTP keys")
+	}
+}
+
+func TestBackend_hotpWindowLimits(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := createKey()
+	for name, data := range map[string]map[string]interface{}{
+		"look_ahead":    {"look_ahead": maxHOTPWindow + 1},
+		"resync_window": {"resync_window": maxHOTPWindow + 1},
+	} {
+		data["key"] = key
+		data["algorithm_type"] = "hotp"
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "keys/test",
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("%s: expected a window larger than %d to be rejected: err: %v resp: %#v", name, maxHOTPWindow, err, resp)
+		}
+	}
+
+	hotpRequest(t, b, config.StorageView, logical.UpdateOperation, "keys/test", map[string]interface{}{
+		"key":            key,
+		"algorithm_type": "hotp",
+		"look_ahead":     maxHOTPWindow,
+		"resync_window":  maxHOTPWindow,
+	})
+}
+
+func TestBackend_hotpGeneratedURL(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := config.StorageView
+
+	resp := hotpRequest(t, b, s, logical.UpdateOperation, "keys/generated", map[string]interface{}{
+		"generate":       true,
+		"issuer":         "Vault",
+		"account_name":   "test@email.com",
+		"algorithm_type": "hotp",
+		"counter":        3,
+	})
+	if _, ok := resp.Data["barcode"]; !ok {
+		t.Fatal("a barcode was not returned for a generated key")
+	}
+
+	urlObject, err := url.Parse(resp.Data["url"].(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urlObject.Scheme != "otpauth" || urlObject.Host != "hotp" || urlObject.Query().Get("counter") != "3" {
+		t.Fatalf("bad url: %s", urlObject)
+	}
+
+	// The url can be used to create the same key
+	hotpRequest(t, b, s, logical.UpdateOperation, "keys/imported", map[string]interface{}{
+		"url": urlObject.String(),
+	})
+	resp = hotpRequest(t, b, s, logical.ReadOperation, "keys/imported", nil)
+	if resp.Data["algorithm_type"] != "hotp" || resp.Data["counter"] != uint64(3) || resp.Data["account_name"] != "test@email.com" {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	code, err := hotplib.GenerateCode(urlObject.Query().Get("secret"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp = hotpRequest(t, b, s, logical.UpdateOperation, "code/imported/generate", nil)
+	if resp.Data["code"] != code {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+}
+
 func testAccStepCreateKey(t *testing.T, name string, keyData map[string]interface{}, expectFail bool) logicaltest.TestStep {
 	return logicaltest.TestStep{
 		Operation: logical.UpdateOperation,
@@ -9,8 +9,10 @@ import (
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 )
 
@@ -30,7 +32,11 @@ func pathCode(b *backend) *framework.Path {
 			},
 			"code": {
 				Type:        framework.TypeString,
-				Description: "TOTP code to be validated.",
+				Description: "TOTP or HOTP code to be validated.",
+			},
+			"next_code": {
+				Type:        framework.TypeString,
+				Description: "The HOTP code following code. If set, the counter of the key is resynchronized to the two consecutive codes.",
 			},
 		},
 
@@ -54,6 +60,34 @@ func pathCode(b *backend) *framework.Path {
 	}
 }
 
+func pathGenerateHOTPCode(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "code/" + framework.GenericNameWithAtRegex("name") + "/generate",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTOTP,
+			OperationVerb:   "generate",
+			OperationSuffix: "hotp-code",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the key.",
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathGenerateHOTPCode,
+			},
+		},
+
+		HelpSynopsis:    pathGenerateHOTPCodeHelpSyn,
+		HelpDescription: pathGenerateHOTPCodeHelpDesc,
+	}
+}
+
 func (b *backend) pathReadCode(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	name := data.Get("name").(string)
 
@@ -66,6 +100,10 @@ func (b *backend) pathReadCode(ctx context.Context, req *logical.Request, data *
 		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
 	}
 
+	if key.isHOTP() {
+		return logical.ErrorResponse("generating a code for an HOTP key advances its counter; use code/%s/generate instead", name), nil
+	}
+
 	// Generate password using totp library
 	totpToken, err := totplib.GenerateCodeCustom(key.Key, time.Now(), totplib.ValidateOpts{
 		Period:    key.Period,
@@ -102,6 +140,10 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
 	}
 
+	if key.isHOTP() {
+		return b.validateHOTPCode(ctx, req, name, code, data.Get("next_code").(string))
+	}
+
 	usedName := fmt.Sprintf("%s_%s", name, code)
 
 	_, ok := b.usedCodes.Get(usedName)
@@ -136,11 +178,141 @@ func (b *backend) pathValidateCode(ctx context.Context, req *logical.Request, da
 	}, nil
 }
 
+// pathGenerateHOTPCode generates the code for the current counter of an HOTP
+// key, and advances the counter.
+func (b *backend) pathGenerateHOTPCode(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Reload the key now that it is locked
+	key, err := b.Key(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
+	}
+	if !key.isHOTP() {
+		return logical.ErrorResponse("key %s is not an HOTP key; read code/%s instead", name, name), nil
+	}
+
+	hotpToken, err := hotplib.GenerateCodeCustom(key.Key, key.Counter, hotplib.ValidateOpts{
+		Digits:    key.Digits,
+		Algorithm: key.Algorithm,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counter := key.Counter
+	key.Counter++
+	if err := b.putKey(ctx, req.Storage, name, key); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"code":    hotpToken,
+			"counter": counter,
+		},
+	}, nil
+}
+
+// validateHOTPCode validates a code against the counter values from the
+// current counter of an HOTP key up to its look-ahead window, and moves the
+// counter past the matching value. If nextCode is set, the two codes must
+// be consecutive, and the larger resync window is searched instead.
+func (b *backend) validateHOTPCode(ctx context.Context, req *logical.Request, name, code, nextCode string) (*logical.Response, error) {
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Reload the key now that it is locked
+	key, err := b.Key(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown key: %s", name)), nil
+	}
+
+	opts := hotplib.ValidateOpts{
+		Digits:    key.Digits,
+		Algorithm: key.Algorithm,
+	}
+	validAt := func(counter uint64, code string) (bool, error) {
+		valid, err := hotplib.ValidateCustom(code, counter, key.Key, opts)
+		if err != nil && err != otplib.ErrValidateInputInvalidLength {
+			return false, err
+		}
+		return valid, nil
+	}
+
+	window := uint64(key.LookAhead)
+	if nextCode != "" {
+		window = uint64(key.ResyncWindow)
+	}
+
+	valid := false
+	for counter := key.Counter; counter <= key.Counter+window; counter++ {
+		ok, err := validAt(counter, code)
+		if err != nil {
+			return logical.ErrorResponse("an error occurred while validating the code"), err
+		}
+		next := counter + 1
+		if ok && nextCode != "" {
+			ok, err = validAt(next, nextCode)
+			if err != nil {
+				return logical.ErrorResponse("an error occurred while validating the code"), err
+			}
+			next++
+		}
+		if ok {
+			valid = true
+			key.Counter = next
+			break
+		}
+	}
+
+	if valid {
+		if err := b.putKey(ctx, req.Storage, name, key); err != nil {
+			return nil, err
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"valid": valid,
+		},
+	}, nil
+}
+
 const pathCodeHelpSyn = `
-Request time-based one-time use password or validate a password for a certain key .
+Request a one-time use password or validate a password for a certain key.
 `
 
 const pathCodeHelpDesc = `
-This path generates and validates time-based one-time use passwords for a certain key. 
+This path generates and validates one-time use passwords for a certain key.
+
+Passwords for HOTP keys are generated with the code/<name>/generate path
+instead, as generating one advances the counter of the key. A password
+is valid if it matches one of the counter values from the current counter up to
+the look_ahead window of the key, and the counter is then moved past the
+matching value, so that a password can only be used once. If the counter is out
+of sync, pass two consecutive passwords as code and next_code to search the
+larger resync_window of the key instead.
+
+`
+
+const pathGenerateHOTPCodeHelpSyn = `
+Generate a one-time use password for an HOTP key.
+`
 
+const pathGenerateHOTPCodeHelpDesc = `
+This path generates a one-time use password from the current counter of an
+HOTP key, and advances the counter, so that each password is only generated
+once.
 `
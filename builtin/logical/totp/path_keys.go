@@ -15,11 +15,22 @@ import (
 	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	otplib "github.com/pquerna/otp"
+	hotplib "github.com/pquerna/otp/hotp"
 	totplib "github.com/pquerna/otp/totp"
 )
 
+const (
+	keyTypeTOTP = "totp"
+	keyTypeHOTP = "hotp"
+
+	// maxHOTPWindow bounds the look_ahead and resync_window of HOTP keys, as
+	// each value in the window is checked when validating a code.
+	maxHOTPWindow = 1000
+)
+
 func pathListKeys(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: "keys/?$",
@@ -118,7 +129,31 @@ func pathKeys(b *backend) *framework.Path {
 
 			"url": {
 				Type:        framework.TypeString,
-				Description: `A TOTP url string containing all of the parameters for key setup. Only used if generate is false.`,
+				Description: `A TOTP or HOTP url string containing all of the parameters for key setup. Only used if generate is false.`,
+			},
+
+			"algorithm_type": {
+				Type:        framework.TypeString,
+				Default:     keyTypeTOTP,
+				Description: `The type of one-time password the key generates. Options include totp, for time-based codes, and hotp, for counter-based codes.`,
+			},
+
+			"counter": {
+				Type:        framework.TypeInt,
+				Default:     0,
+				Description: `The initial value of the counter used to generate HOTP codes. Only used if algorithm_type is hotp.`,
+			},
+
+			"look_ahead": {
+				Type:        framework.TypeInt,
+				Default:     10,
+				Description: `The number of counter values after the current counter that are accepted when validating an HOTP code, at most 1000. Only used if algorithm_type is hotp.`,
+			},
+
+			"resync_window": {
+				Type:        framework.TypeInt,
+				Default:     100,
+				Description: `The number of counter values after the current counter that are searched when resynchronizing the counter with two consecutive HOTP codes, at most 1000. Only used if algorithm_type is hotp.`,
 			},
 		},
 
@@ -166,7 +201,13 @@ func (b *backend) Key(ctx context.Context, s logical.Storage, n string) (*keyEnt
 }
 
 func (b *backend) pathKeyDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	err := req.Storage.Delete(ctx, "key/"+data.Get("name").(string))
+	name := data.Get("name").(string)
+
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := req.Storage.Delete(ctx, "key/"+name)
 	if err != nil {
 		return nil, err
 	}
@@ -187,15 +228,24 @@ func (b *backend) pathKeyRead(ctx context.Context, req *logical.Request, data *f
 	algorithm := key.Algorithm.String()
 
 	// Return values of key
-	return &logical.Response{
+	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"issuer":       key.Issuer,
-			"account_name": key.AccountName,
-			"period":       key.Period,
-			"algorithm":    algorithm,
-			"digits":       key.Digits,
+			"issuer":         key.Issuer,
+			"account_name":   key.AccountName,
+			"algorithm":      algorithm,
+			"digits":         key.Digits,
+			"algorithm_type": key.algorithmType(),
 		},
-	}, nil
+	}
+	if key.isHOTP() {
+		resp.Data["counter"] = key.Counter
+		resp.Data["look_ahead"] = key.LookAhead
+		resp.Data["resync_window"] = key.ResyncWindow
+	} else {
+		resp.Data["period"] = key.Period
+	}
+
+	return resp, nil
 }
 
 func (b *backend) pathKeyList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -221,6 +271,10 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 	qrSize := data.Get("qr_size").(int)
 	keySize := data.Get("key_size").(int)
 	inputURL := data.Get("url").(string)
+	algorithmType := data.Get("algorithm_type").(string)
+	counter := data.Get("counter").(int)
+	lookAhead := data.Get("look_ahead").(int)
+	resyncWindow := data.Get("resync_window").(int)
 
 	if generate {
 		if keyString != "" {
@@ -239,6 +293,12 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 			return logical.ErrorResponse("an error occurred while parsing url string"), err
 		}
 
+		// Read the type of key from the url host
+		switch urlObject.Host {
+		case keyTypeTOTP, keyTypeHOTP:
+			algorithmType = urlObject.Host
+		}
+
 		// Set up query object
 		urlQuery := urlObject.Query()
 		path := strings.TrimPrefix(urlObject.Path, "/")
@@ -289,6 +349,22 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		if algorithmQuery != "" {
 			algorithm = algorithmQuery
 		}
+
+		// Read counter
+		counterQuery := urlQuery.Get("counter")
+		if counterQuery != "" && algorithmType == keyTypeHOTP {
+			counterInt, err := strconv.Atoi(counterQuery)
+			if err != nil {
+				return logical.ErrorResponse("an error occurred while parsing counter value in url"), err
+			}
+			counter = counterInt
+		}
+	}
+
+	switch algorithmType {
+	case keyTypeTOTP, keyTypeHOTP:
+	default:
+		return logical.ErrorResponse("the algorithm_type value must be totp or hotp"), nil
 	}
 
 	// Translate digits and algorithm to a format the totp library understands
@@ -335,6 +411,18 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		return logical.ErrorResponse("the key_size value must be greater than zero"), nil
 	}
 
+	if counter < 0 {
+		return logical.ErrorResponse("the counter value must be greater than or equal to zero"), nil
+	}
+
+	if lookAhead < 0 || lookAhead > maxHOTPWindow {
+		return logical.ErrorResponse("the look_ahead value must be between 0 and %d", maxHOTPWindow), nil
+	}
+
+	if resyncWindow <= 0 || resyncWindow > maxHOTPWindow {
+		return logical.ErrorResponse("the resync_window value must be between 1 and %d", maxHOTPWindow), nil
+	}
+
 	// Period, Skew and Key Size need to be unsigned ints
 	uintPeriod := uint(period)
 	uintSkew := uint(skew)
@@ -354,15 +442,29 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 		}
 
 		// Generate a new key
-		keyObject, err := totplib.Generate(totplib.GenerateOpts{
-			Issuer:      issuer,
-			AccountName: accountName,
-			Period:      uintPeriod,
-			Digits:      keyDigits,
-			Algorithm:   keyAlgorithm,
-			SecretSize:  uintKeySize,
-			Rand:        b.GetRandomReader(),
-		})
+		var keyObject *otplib.Key
+		var err error
+		switch algorithmType {
+		case keyTypeHOTP:
+			keyObject, err = generateHOTPKey(hotplib.GenerateOpts{
+				Issuer:      issuer,
+				AccountName: accountName,
+				Digits:      keyDigits,
+				Algorithm:   keyAlgorithm,
+				SecretSize:  uintKeySize,
+				Rand:        b.GetRandomReader(),
+			}, uint64(counter))
+		default:
+			keyObject, err = totplib.Generate(totplib.GenerateOpts{
+				Issuer:      issuer,
+				AccountName: accountName,
+				Period:      uintPeriod,
+				Digits:      keyDigits,
+				Algorithm:   keyAlgorithm,
+				SecretSize:  uintKeySize,
+				Rand:        b.GetRandomReader(),
+			})
+		}
 		if err != nil {
 			return logical.ErrorResponse("an error occurred while generating a key"), err
 		}
@@ -416,23 +518,55 @@ func (b *backend) pathKeyCreate(ctx context.Context, req *logical.Request, data
 	}
 
 	// Store it
-	entry, err := logical.StorageEntryJSON("key/"+name, &keyEntry{
-		Key:         keyString,
-		Issuer:      issuer,
-		AccountName: accountName,
-		Period:      uintPeriod,
-		Algorithm:   keyAlgorithm,
-		Digits:      keyDigits,
-		Skew:        uintSkew,
+	lock := locksutil.LockForKey(b.keyLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	err := b.putKey(ctx, req.Storage, name, &keyEntry{
+		Key:           keyString,
+		Issuer:        issuer,
+		AccountName:   accountName,
+		Period:        uintPeriod,
+		Algorithm:     keyAlgorithm,
+		Digits:        keyDigits,
+		Skew:          uintSkew,
+		AlgorithmType: algorithmType,
+		Counter:       uint64(counter),
+		LookAhead:     uint(lookAhead),
+		ResyncWindow:  uint(resyncWindow),
 	})
 	if err != nil {
 		return nil, err
 	}
-	if err := req.Storage.Put(ctx, entry); err != nil {
+
+	return response, nil
+}
+
+func (b *backend) putKey(ctx context.Context, s logical.Storage, name string, key *keyEntry) error {
+	entry, err := logical.StorageEntryJSON("key/"+name, key)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// generateHOTPKey generates a new HOTP key, with the initial counter included
+// in its url as expected by authenticator apps.
+func generateHOTPKey(opts hotplib.GenerateOpts, counter uint64) (*otplib.Key, error) {
+	keyObject, err := hotplib.Generate(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	urlObject, err := url.Parse(keyObject.String())
+	if err != nil {
 		return nil, err
 	}
+	urlQuery := urlObject.Query()
+	urlQuery.Set("counter", strconv.FormatUint(counter, 10))
+	urlObject.RawQuery = urlQuery.Encode()
 
-	return response, nil
+	return otplib.NewKeyFromURL(urlObject.String())
 }
 
 type keyEntry struct {
@@ -443,6 +577,24 @@ type keyEntry struct {
 	Algorithm   otplib.Algorithm `json:"algorithm" mapstructure:"algorithm" structs:"algorithm"`
 	Digits      otplib.Digits    `json:"digits" mapstructure:"digits" structs:"digits"`
 	Skew        uint             `json:"skew" mapstructure:"skew" structs:"skew"`
+
+	AlgorithmType string `json:"algorithm_type" mapstructure:"algorithm_type" structs:"algorithm_type"`
+	Counter       uint64 `json:"counter" mapstructure:"counter" structs:"counter"`
+	LookAhead     uint   `json:"look_ahead" mapstructure:"look_ahead" structs:"look_ahead"`
+	ResyncWindow  uint   `json:"resync_window" mapstructure:"resync_window" structs:"resync_window"`
+}
+
+// algorithmType returns the type of the key. Keys created before HOTP was
+// supported are TOTP keys.
+func (k *keyEntry) algorithmType() string {
+	if k.AlgorithmType == "" {
+		return keyTypeTOTP
+	}
+	return k.AlgorithmType
+}
+
+func (k *keyEntry) isHOTP() bool {
+	return k.algorithmType() == keyTypeHOTP
 }
 
 const pathKeyHelpSyn = `
@@ -63,6 +63,7 @@ func Backend(ctx context.Context, conf *logical.BackendConfig) (*backend, error)
 			b.pathEncrypt(),
 			b.pathDecrypt(),
 			b.pathDatakey(),
+			b.pathDerive(),
 			b.pathRandom(),
 			b.pathHash(),
 			b.pathHMAC(),
@@ -119,6 +120,9 @@ type backend struct {
 	checkAutoRotateAfter time.Time
 	autoRotateOnce       sync.Once
 	backendUUID          string
+	// Keys with usage counts which have yet to be persisted, mapped to the
+	// policy holding the counts.
+	pendingUsage sync.Map
 }
 
 func GetCacheSizeFromStorage(ctx context.Context, s logical.Storage) (int, error) {
@@ -203,9 +207,21 @@ func (b *backend) periodicFunc(ctx context.Context, req *logical.Request) error
 		b.autoRotateOnce = sync.Once{}
 	}
 
+	if usageErr := b.persistPendingUsage(ctx, req.Storage); usageErr != nil {
+		err = multierror.Append(err, usageErr)
+	}
+
 	return err
 }
 
+// canUpdateKeys returns whether this node may write keys outside of a
+// request, which is only the case on primary nodes and performance
+// secondary nodes which have a local mount.
+func (b *backend) canUpdateKeys() bool {
+	return !b.System().ReplicationState().HasState(consts.ReplicationDRSecondary|consts.ReplicationPerformanceStandby) &&
+		(b.System().LocalMount() || !b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary))
+}
+
 // autoRotateKeys retrieves all transit keys and rotates those which have an
 // auto rotate period defined which has passed. This operation only happens
 // on primary nodes and performance secondary nodes which have a local mount.
@@ -218,8 +234,7 @@ func (b *backend) autoRotateKeys(ctx context.Context, req *logical.Request) erro
 	b.checkAutoRotateAfter = time.Now().Add(1 * time.Hour)
 
 	// Early exit if not a primary or performance secondary with a local mount.
-	if b.System().ReplicationState().HasState(consts.ReplicationDRSecondary|consts.ReplicationPerformanceStandby) ||
-		(!b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary)) {
+	if !b.canUpdateKeys() {
 		return nil
 	}
 
@@ -257,7 +272,9 @@ func (b *backend) autoRotateKeys(ctx context.Context, req *logical.Request) erro
 	return errs.ErrorOrNil()
 }
 
-// rotateIfRequired rotates a key if it is due for autorotation.
+// rotateIfRequired rotates a key if it is due for autorotation, either because
+// its auto rotate period has passed or because its latest version reached its
+// auto rotate usage count.
 func (b *backend) rotateIfRequired(ctx context.Context, req *logical.Request, key string, p *keysutil.Policy) error {
 	if !b.System().CachingDisabled() {
 		p.Lock(true)
@@ -270,19 +287,115 @@ func (b *backend) rotateIfRequired(ctx context.Context, req *logical.Request, ke
 	}
 
 	// If the policy's automatic rotation period is 0, it should not
-	// automatically rotate.
-	if p.AutoRotatePeriod == 0 {
-		return nil
+	// automatically rotate based on time.
+	periodElapsed := false
+	if p.AutoRotatePeriod != 0 {
+		// Retrieve the latest version of the policy and determine if it is time to rotate.
+		latestKey := p.Keys[strconv.Itoa(p.LatestVersion)]
+		periodElapsed = time.Now().After(latestKey.CreationTime.Add(p.AutoRotatePeriod))
 	}
 
-	// Retrieve the latest version of the policy and determine if it is time to rotate.
-	latestKey := p.Keys[strconv.Itoa(p.LatestVersion)]
-	if time.Now().After(latestKey.CreationTime.Add(p.AutoRotatePeriod)) {
+	if periodElapsed || p.UsageRotationRequired() {
 		if b.Logger().IsDebug() {
 			b.Logger().Debug("automatically rotating key", "key", key)
 		}
-		return p.Rotate(ctx, req.Storage, b.GetRandomReader())
+		if err := p.Rotate(ctx, req.Storage, b.GetRandomReader()); err != nil {
+			return err
+		}
 
+		b.keyEvent(ctx, "key-rotate", key, p, "auto", "true")
 	}
 	return nil
 }
+
+// recordKeyUsage ensures the operations counted against a key while serving
+// a request are persisted. The counts are normally persisted by the periodic
+// function, within a block of usage reserved in storage ahead of time, but
+// are persisted before the request returns when caching is disabled, as the
+// policy is then loaded from storage for every request, when the request used
+// up the reserved usage, or when the key is due to be rotated because of its
+// usage. It must be called after the policy has been unlocked.
+func (b *backend) recordKeyUsage(ctx context.Context, storage logical.Storage, name string, p *keysutil.Policy) {
+	if !p.HasPendingUsage() || !b.canUpdateKeys() {
+		return
+	}
+
+	persistNow := b.System().CachingDisabled() || p.UsageReservationExceeded()
+	if !persistNow {
+		p.Lock(false)
+		persistNow = p.UsageRotationRequired() && (!p.Imported || p.AllowImportedKeyRotation)
+		p.Unlock()
+	}
+	if !persistNow {
+		b.pendingUsage.Store(name, p)
+		return
+	}
+
+	if err := b.persistKeyUsage(ctx, storage, name, p); err != nil {
+		b.Logger().Error("failed to persist key usage", "key", name, "error", err)
+	}
+}
+
+// persistPendingUsage persists the usage counts of all keys used since it was
+// last called.
+func (b *backend) persistPendingUsage(ctx context.Context, storage logical.Storage) error {
+	if !b.canUpdateKeys() {
+		return nil
+	}
+
+	var errs *multierror.Error
+	b.pendingUsage.Range(func(rawName, rawPolicy interface{}) bool {
+		name := rawName.(string)
+		b.pendingUsage.Delete(name)
+		if err := b.persistKeyUsage(ctx, storage, name, rawPolicy.(*keysutil.Policy)); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to persist usage of key %q: %w", name, err))
+		}
+		return true
+	})
+
+	return errs.ErrorOrNil()
+}
+
+// persistKeyUsage persists the usage counted in memory by the given policy,
+// rotating the key instead if its latest version reached its auto rotate
+// usage count.
+func (b *backend) persistKeyUsage(ctx context.Context, storage logical.Storage, name string, p *keysutil.Policy) error {
+	current, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		// The key was deleted in the meantime
+		return nil
+	}
+	if !b.System().CachingDisabled() {
+		current.Lock(true)
+	}
+	defer current.Unlock()
+
+	// The policy used by the request may have since been evicted from the
+	// cache, or loaded from storage without caching
+	if current != p {
+		p.TransferUsage(current)
+	}
+
+	if current.UsageRotationRequired() && (!current.Imported || current.AllowImportedKeyRotation) {
+		if b.Logger().IsDebug() {
+			b.Logger().Debug("automatically rotating key after reaching its usage count", "key", name)
+		}
+		if err := current.Rotate(ctx, storage, b.GetRandomReader()); err != nil {
+			return err
+		}
+
+		b.keyEvent(ctx, "key-rotate", name, current, "auto", "true")
+		return nil
+	}
+
+	if !current.HasPendingUsage() {
+		return nil
+	}
+	return current.Persist(ctx, storage)
+}
@@ -393,6 +393,56 @@ func TestBackend_datakey(t *testing.T) {
 	})
 }
 
+func TestBackend_datakeyMLKEM(t *testing.T) {
+	for _, keyType := range []string{"ml-kem-768", "ml-kem-1024"} {
+		t.Run(keyType, func(t *testing.T) {
+			dataKeyInfo := make(map[string]interface{})
+			logicaltest.Test(t, logicaltest.TestCase{
+				LogicalFactory: Factory,
+				Steps: []logicaltest.TestStep{
+					{
+						Operation: logical.UpdateOperation,
+						Path:      "keys/test",
+						Data: map[string]interface{}{
+							"type": keyType,
+						},
+					},
+					testAccStepWriteDatakey(t, "test", false, 256, dataKeyInfo),
+					testAccStepDecryptDatakey(t, "test", dataKeyInfo),
+					{
+						Operation: logical.UpdateOperation,
+						Path:      "datakey/plaintext/test",
+						Data: map[string]interface{}{
+							"bits": 512,
+						},
+						ErrorOk: true,
+						Check: func(resp *logical.Response) error {
+							if resp == nil || !resp.IsError() {
+								return fmt.Errorf("expected error requesting a 512-bit data key, got %#v", resp)
+							}
+							return nil
+						},
+					},
+					{
+						Operation: logical.UpdateOperation,
+						Path:      "encrypt/test",
+						Data: map[string]interface{}{
+							"plaintext": base64.StdEncoding.EncodeToString([]byte(testPlaintext)),
+						},
+						ErrorOk: true,
+						Check: func(resp *logical.Response) error {
+							if resp == nil || !resp.IsError() {
+								return fmt.Errorf("expected error encrypting with an ML-KEM key, got %#v", resp)
+							}
+							return nil
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
 func TestBackend_rotation(t *testing.T) {
 	defer os.Setenv("TRANSIT_ACC_KEY_TYPE", "")
 	testBackendRotation(t)
@@ -1761,6 +1811,102 @@ func TestTransit_AutoRotateKeys(t *testing.T) {
 	}
 }
 
+func TestTransit_KeyUsage(t *testing.T) {
+	ctx := context.Background()
+	b, storage := createBackendWithSysView(t)
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+
+	doReq := func(path string, data map[string]interface{}) (*logical.Response, error) {
+		return b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Data:      data,
+		})
+	}
+	readKey := func() *logical.Response {
+		resp, err := b.HandleRequest(ctx, &logical.Request{
+			Storage:   storage,
+			Operation: logical.ReadOperation,
+			Path:      "keys/foo",
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		return resp
+	}
+
+	resp, err := doReq("keys/bar", map[string]interface{}{
+		"allowed_operations": "encrypt,wrap",
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for unknown operation, err:%v resp:%#v", err, resp)
+	}
+
+	resp, err = doReq("keys/foo", map[string]interface{}{
+		"allowed_operations":      "encrypt,decrypt",
+		"auto_rotate_usage_count": 3,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+
+	var ciphertext string
+	for i := 0; i < 2; i++ {
+		resp, err = doReq("encrypt/foo", map[string]interface{}{"plaintext": plaintext})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("err:%v resp:%#v", err, resp)
+		}
+		ciphertext = resp.Data["ciphertext"].(string)
+	}
+	resp, err = doReq("decrypt/foo", map[string]interface{}{"ciphertext": ciphertext})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+
+	resp, err = doReq("hmac/foo", map[string]interface{}{"input": plaintext})
+	if err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected HMAC to be disallowed, err:%v resp:%#v", err, resp)
+	}
+
+	resp = readKey()
+	require.Equal(t, []string{"encrypt", "decrypt"}, resp.Data["allowed_operations"])
+	require.Equal(t, uint64(3), resp.Data["auto_rotate_usage_count"])
+	expected := keysutil.KeyUsage{Encryptions: 2, Decryptions: 1}
+	require.Equal(t, expected, resp.Data["usage"].(map[string]keysutil.KeyUsage)["1"])
+
+	// With a usage count this low, a single operation is reserved at a time,
+	// so the counts are persisted with each operation
+	p, err := keysutil.LoadPolicy(ctx, storage, "policy/foo")
+	require.NoError(t, err)
+	require.Equal(t, expected, p.Usage(1))
+	require.Equal(t, uint64(3), p.Keys["1"].EncryptionsReserved)
+	require.NoError(t, b.periodicFunc(ctx, &logical.Request{Storage: storage}))
+
+	// Reaching the usage count rotates the key
+	resp, err = doReq("encrypt/foo", map[string]interface{}{"plaintext": plaintext})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	resp = readKey()
+	require.Equal(t, 2, resp.Data["latest_version"])
+	require.Equal(t, uint64(3), resp.Data["usage"].(map[string]keysutil.KeyUsage)["1"].Encryptions)
+	require.Equal(t, keysutil.KeyUsage{}, resp.Data["usage"].(map[string]keysutil.KeyUsage)["2"])
+
+	// Clearing the allowed operations allows all operations
+	resp, err = doReq("keys/foo/config", map[string]interface{}{
+		"allowed_operations": "",
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	require.Equal(t, []string{}, resp.Data["allowed_operations"])
+	resp, err = doReq("hmac/foo", map[string]interface{}{"input": plaintext})
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+}
+
 func TestTransit_AEAD(t *testing.T) {
 	testTransit_AEAD(t, "aes128-gcm96")
 	testTransit_AEAD(t, "aes256-gcm96")
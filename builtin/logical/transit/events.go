@@ -0,0 +1,29 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package transit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+)
+
+// keyEvent sends a transit/<operation> event about a change to the named key.
+// The key's type and latest version are included when its policy is given.
+// Events only describe the key; they never carry key material.
+func (b *backend) keyEvent(ctx context.Context, operation, name string, p *keysutil.Policy, metadataPairs ...string) {
+	metadata := []string{
+		"name", name,
+	}
+	if p != nil {
+		metadata = append(metadata,
+			"type", p.Type.String(),
+			"latest_version", strconv.Itoa(p.LatestVersion),
+		)
+	}
+	metadata = append(metadata, metadataPairs...)
+
+	b.SendOperationEvent(ctx, "transit", operation, "keys/"+name, true, metadata...)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package transit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransit_Events ensures that key lifecycle operations send events
+// which describe the key without including key material.
+func TestTransit_Events(t *testing.T) {
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b, err := Factory(context.Background(), config)
+	require.NoError(t, err)
+
+	for _, req := range []*logical.Request{
+		{Operation: logical.UpdateOperation, Path: "keys/test"},
+		{Operation: logical.UpdateOperation, Path: "keys/test/rotate"},
+		{Operation: logical.UpdateOperation, Path: "keys/test/config", Data: map[string]interface{}{"deletion_allowed": true}},
+		{Operation: logical.DeleteOperation, Path: "keys/test"},
+	} {
+		req.Storage = config.StorageView
+		resp, err := b.HandleRequest(context.Background(), req)
+		require.NoError(t, err)
+		require.False(t, resp.IsError(), "%s: %v", req.Path, resp)
+	}
+
+	var eventTypes []string
+	for _, e := range events.Events() {
+		eventTypes = append(eventTypes, e.EventType)
+		fields := e.Event.Metadata.AsMap()
+		require.Equal(t, "test", fields["name"])
+		require.Equal(t, "keys/test", fields[logical.EventMetadataDataPath])
+	}
+	require.Equal(t, []string{"transit/key-create", "transit/key-rotate", "transit/key-config-update", "transit/key-delete"}, eventTypes)
+
+	rotate := events.Events()[1].Event.Metadata.AsMap()
+	require.Equal(t, "key-rotate", rotate[logical.EventMetadataOperation])
+	require.Equal(t, "2", rotate["latest_version"])
+	require.Equal(t, "false", rotate["auto"])
+}
@@ -154,7 +154,7 @@ func getBYOKExportKey(dstP *keysutil.Policy, srcP *keysutil.Policy, key *keysuti
 
 	var targetKey interface{}
 	switch srcP.Type {
-	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_HMAC:
+	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_AES256_GCM_SIV, keysutil.KeyType_XChaCha20_Poly1305, keysutil.KeyType_HMAC:
 		targetKey = key.Key
 	case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
 		targetKey = key.RSAKey
@@ -179,6 +179,18 @@ func getBYOKExportKey(dstP *keysutil.Policy, srcP *keysutil.Policy, key *keysuti
 		}
 	case keysutil.KeyType_ED25519:
 		targetKey = ed25519.PrivateKey(key.Key)
+	case keysutil.KeyType_X25519:
+		var err error
+		targetKey, err = key.ECDHPrivateKey(srcP.Type)
+		if err != nil {
+			return "", err
+		}
+	case keysutil.KeyType_ML_DSA_44, keysutil.KeyType_ML_DSA_65, keysutil.KeyType_ML_DSA_87, keysutil.KeyType_ML_KEM_768, keysutil.KeyType_ML_KEM_1024:
+		var err error
+		targetKey, err = key.PQCPrivateKey(srcP.Type)
+		if err != nil {
+			return "", err
+		}
 	default:
 		return "", fmt.Errorf("unable to export to unknown key type: %v", srcP.Type)
 	}
@@ -120,28 +120,41 @@ func (b *backend) pathDatakeyWrite(ctx context.Context, req *logical.Request, d
 	if p == nil {
 		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
 	}
+	defer b.recordKeyUsage(ctx, req.Storage, name, p)
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
 	defer p.Unlock()
 
-	newKey := make([]byte, 32)
 	bits := d.Get("bits").(int)
-	switch bits {
-	case 512:
-		newKey = make([]byte, 64)
-	case 256:
-	case 128:
-		newKey = make([]byte, 16)
-	default:
-		return logical.ErrorResponse("invalid bit length"), logical.ErrInvalidRequest
-	}
-	_, err = rand.Read(newKey)
-	if err != nil {
-		return nil, err
-	}
 
-	ciphertext, err := p.Encrypt(ver, context, nonce, base64.StdEncoding.EncodeToString(newKey))
+	var newKey []byte
+	var ciphertext string
+	if p.Type.KeyEncapsulationSupported() {
+		// Key encapsulation keys cannot encrypt a random key; instead, the
+		// data key is a newly encapsulated shared key of fixed length.
+		if bits != 256 {
+			return logical.ErrorResponse(fmt.Sprintf("invalid bit length; keys of type %v only support 256 bits", p.Type)), logical.ErrInvalidRequest
+		}
+		newKey, ciphertext, err = p.Encapsulate(ver)
+	} else {
+		newKey = make([]byte, 32)
+		switch bits {
+		case 512:
+			newKey = make([]byte, 64)
+		case 256:
+		case 128:
+			newKey = make([]byte, 16)
+		default:
+			return logical.ErrorResponse("invalid bit length"), logical.ErrInvalidRequest
+		}
+		_, err = rand.Read(newKey)
+		if err != nil {
+			return nil, err
+		}
+
+		ciphertext, err = p.Encrypt(ver, context, nonce, base64.StdEncoding.EncodeToString(newKey))
+	}
 	if err != nil {
 		switch err.(type) {
 		case errutil.UserError:
@@ -195,4 +208,8 @@ or 512 bits can be specified; if not specified, the default
 is 256 bits. Call with the the "wrapped" path to prevent the
 (base64-encoded) plaintext key from being returned along with
 the encrypted key, the "plaintext" path returns both.
+
+With ML-KEM keys, the data key is a 256-bit shared key
+encapsulated to the named key, and the ciphertext is its
+encapsulation; decrypting the ciphertext returns the shared key.
 `
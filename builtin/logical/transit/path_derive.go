@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/helper/kdf"
+	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func (b *backend) pathDerive() *framework.Path {
+	return &framework.Path{
+		Pattern: "derive/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixTransit,
+			OperationVerb:   "derive",
+			OperationSuffix: "key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "The key to perform the key agreement with",
+			},
+
+			"peer_public_key": {
+				Type: framework.TypeString,
+				Description: `The public key of the peer, either as a PEM-encoded PKIX
+public key, or as the base64-encoded raw public key (32 bytes for
+x25519 keys, or an uncompressed point for ECDSA keys).`,
+			},
+
+			"salt": {
+				Type:        framework.TypeString,
+				Description: "Base64 encoded salt for the HKDF applied to the shared secret",
+			},
+
+			"context": {
+				Type: framework.TypeString,
+				Description: `Base64 encoded context (the HKDF "info" parameter) binding
+the derived key to its purpose.`,
+			},
+
+			"bits": {
+				Type: framework.TypeInt,
+				Description: `Number of bits for the derived key; currently 128, 256,
+and 512 bits are supported. Defaults to 256.`,
+				Default: 256,
+			},
+
+			"key_version": {
+				Type: framework.TypeInt,
+				Description: `The version of the key to use for the key agreement.
+Must be 0 (for latest) or a value greater than or equal
+to the min_encryption_version configured on the key.`,
+			},
+
+			"wrapping_key": {
+				Type: framework.TypeString,
+				Description: `The name of a transit key to encrypt the derived key with.
+If set, only the ciphertext of the derived key is returned.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathDeriveWrite,
+		},
+
+		HelpSynopsis:    pathDeriveHelpSyn,
+		HelpDescription: pathDeriveHelpDesc,
+	}
+}
+
+func (b *backend) pathDeriveWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+	ver := d.Get("key_version").(int)
+	wrappingKeyName := d.Get("wrapping_key").(string)
+
+	peerPublicKey := d.Get("peer_public_key").(string)
+	if peerPublicKey == "" {
+		return logical.ErrorResponse("missing peer_public_key"), logical.ErrInvalidRequest
+	}
+
+	var err error
+
+	// Decode the salt and context if any
+	saltRaw := d.Get("salt").(string)
+	var salt []byte
+	if len(saltRaw) != 0 {
+		salt, err = base64.StdEncoding.DecodeString(saltRaw)
+		if err != nil {
+			return logical.ErrorResponse("failed to base64-decode salt"), logical.ErrInvalidRequest
+		}
+	}
+
+	contextRaw := d.Get("context").(string)
+	var context []byte
+	if len(contextRaw) != 0 {
+		context, err = base64.StdEncoding.DecodeString(contextRaw)
+		if err != nil {
+			return logical.ErrorResponse("failed to base64-decode context"), logical.ErrInvalidRequest
+		}
+	}
+
+	bits := d.Get("bits").(int)
+	switch bits {
+	case 128, 256, 512:
+	default:
+		return logical.ErrorResponse("invalid bit length"), logical.ErrInvalidRequest
+	}
+
+	// Get the policy
+	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    name,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return logical.ErrorResponse("key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		p.Lock(false)
+	}
+
+	if !p.Type.KeyAgreementSupported() {
+		p.Unlock()
+		return logical.ErrorResponse(fmt.Sprintf("key agreement not supported for key type %v", p.Type)), logical.ErrInvalidRequest
+	}
+
+	keyVersion := ver
+	if keyVersion == 0 {
+		keyVersion = p.LatestVersion
+	}
+
+	secret, err := p.ECDH(ver, []byte(peerPublicKey))
+	p.Unlock()
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		default:
+			return nil, err
+		}
+	}
+
+	derivedKey, err := kdf.HKDFSHA256(secret, salt, context, uint32(bits))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"key_version": keyVersion,
+		},
+	}
+
+	if wrappingKeyName == "" {
+		resp.Data["derived_key"] = base64.StdEncoding.EncodeToString(derivedKey)
+		return resp, nil
+	}
+
+	// Wrap the derived key with the wrapping key, as with a data key
+	wrappingP, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
+		Storage: req.Storage,
+		Name:    wrappingKeyName,
+	}, b.GetRandomReader())
+	if err != nil {
+		return nil, err
+	}
+	if wrappingP == nil {
+		return logical.ErrorResponse("wrapping key not found"), logical.ErrInvalidRequest
+	}
+	if !b.System().CachingDisabled() {
+		wrappingP.Lock(false)
+	}
+	defer wrappingP.Unlock()
+
+	ciphertext, err := wrappingP.Encrypt(0, nil, nil, base64.StdEncoding.EncodeToString(derivedKey))
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		default:
+			return nil, err
+		}
+	}
+	if ciphertext == "" {
+		return nil, fmt.Errorf("empty ciphertext returned")
+	}
+
+	resp.Data["ciphertext"] = ciphertext
+	return resp, nil
+}
+
+const pathDeriveHelpSyn = `Derive a shared key with a peer using ECDH`
+
+const pathDeriveHelpDesc = `
+This path performs an elliptic curve Diffie-Hellman key agreement
+between the named key, which must be an ecdsa-p256, ecdsa-p384,
+ecdsa-p521 or x25519 key, and the given peer public key. The shared
+secret is passed through HKDF-SHA256 with the optional salt and
+context, and the result returned as the base64-encoded derived key.
+
+If "wrapping_key" is set, the derived key is instead encrypted with
+that transit key, and only the ciphertext is returned.
+`
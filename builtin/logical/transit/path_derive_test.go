@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package transit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/kdf"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestTransit_Derive(t *testing.T) {
+	curves := map[string]ecdh.Curve{
+		"ecdsa-p256": ecdh.P256(),
+		"ecdsa-p384": ecdh.P384(),
+		"ecdsa-p521": ecdh.P521(),
+		"x25519":     ecdh.X25519(),
+	}
+
+	for keyType, curve := range curves {
+		t.Run(keyType, func(t *testing.T) {
+			b, storage := createBackendWithSysView(t)
+
+			req := &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "keys/foo",
+				Data: map[string]interface{}{
+					"type": keyType,
+				},
+			}
+			if _, err := b.HandleRequest(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+
+			// Fetch the public key of the named key, as a peer would
+			req = &logical.Request{
+				Storage:   storage,
+				Operation: logical.ReadOperation,
+				Path:      "export/public-key/foo/latest",
+			}
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			exported := resp.Data["keys"].(map[string]string)["1"]
+
+			var vaultPublicKey *ecdh.PublicKey
+			if keyType == "x25519" {
+				raw, err := base64.StdEncoding.DecodeString(exported)
+				if err != nil {
+					t.Fatal(err)
+				}
+				vaultPublicKey, err = curve.NewPublicKey(raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				block, _ := pem.Decode([]byte(exported))
+				if block == nil {
+					t.Fatalf("public key is not PEM encoded: %q", exported)
+				}
+				parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				vaultPublicKey, err = parsed.(interface {
+					ECDH() (*ecdh.PublicKey, error)
+				}).ECDH()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			peerKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			secret, err := peerKey.ECDH(vaultPublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			salt := []byte("salt")
+			info := []byte("session key")
+			expected, err := kdf.HKDFSHA256(secret, salt, info, 256)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req = &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "derive/foo",
+				Data: map[string]interface{}{
+					"peer_public_key": base64.StdEncoding.EncodeToString(peerKey.PublicKey().Bytes()),
+					"salt":            base64.StdEncoding.EncodeToString(salt),
+					"context":         base64.StdEncoding.EncodeToString(info),
+				},
+			}
+			resp, err = b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			derived, err := base64.StdEncoding.DecodeString(resp.Data["derived_key"].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(derived, expected) {
+				t.Fatal("derived key does not match the peer's derived key")
+			}
+
+			// Wrap the derived key with another transit key and check it
+			// decrypts to the same key
+			req = &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "keys/wrapper",
+			}
+			if _, err := b.HandleRequest(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+
+			req = &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "derive/foo",
+				Data: map[string]interface{}{
+					"peer_public_key": base64.StdEncoding.EncodeToString(peerKey.PublicKey().Bytes()),
+					"salt":            base64.StdEncoding.EncodeToString(salt),
+					"context":         base64.StdEncoding.EncodeToString(info),
+					"wrapping_key":    "wrapper",
+				},
+			}
+			resp, err = b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if _, ok := resp.Data["derived_key"]; ok {
+				t.Fatal("derived key returned despite wrapping key")
+			}
+
+			req = &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "decrypt/wrapper",
+				Data: map[string]interface{}{
+					"ciphertext": resp.Data["ciphertext"],
+				},
+			}
+			resp, err = b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if resp.Data["plaintext"] != base64.StdEncoding.EncodeToString(expected) {
+				t.Fatal("wrapped derived key does not match the peer's derived key")
+			}
+		})
+	}
+}
+
+func TestTransit_Derive_Errors(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	for name, keyType := range map[string]string{"aes": "aes256-gcm96", "p256": "ecdsa-p256"} {
+		req := &logical.Request{
+			Storage:   storage,
+			Operation: logical.UpdateOperation,
+			Path:      "keys/" + name,
+			Data: map[string]interface{}{
+				"type": keyType,
+			},
+		}
+		if _, err := b.HandleRequest(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519Public := base64.StdEncoding.EncodeToString(x25519Key.PublicKey().Bytes())
+
+	for name, data := range map[string]map[string]interface{}{
+		"unsupported key type": {"name": "aes", "peer_public_key": x25519Public},
+		"missing peer key":     {"name": "p256"},
+		"mismatched curve":     {"name": "p256", "peer_public_key": x25519Public},
+		"invalid bits":         {"name": "p256", "peer_public_key": x25519Public, "bits": 100},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "derive/" + data["name"].(string),
+				Data:      data,
+			}
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err == nil && (resp == nil || !resp.IsError()) {
+				t.Fatalf("expected error, got resp:%#v", resp)
+			}
+		})
+	}
+}
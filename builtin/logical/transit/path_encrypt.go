@@ -128,7 +128,8 @@ encryption key) this nonce value is **never reused**.
 				Description: `
 This parameter is required when encryption key is expected to be created.
 When performing an upsert operation, the type of key to create. Currently,
-"aes128-gcm96" (symmetric) and "aes256-gcm96" (symmetric) are the only types supported. Defaults to "aes256-gcm96".`,
+"aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "chacha20-poly1305" (symmetric),
+"aes256-gcm-siv" (symmetric) and "xchacha20-poly1305" (symmetric) are supported. Defaults to "aes256-gcm96".`,
 			},
 
 			"convergent_encryption": {
@@ -436,6 +437,10 @@ func (b *backend) pathEncryptWrite(ctx context.Context, req *logical.Request, d
 			polReq.KeyType = keysutil.KeyType_AES256_GCM96
 		case "chacha20-poly1305":
 			polReq.KeyType = keysutil.KeyType_ChaCha20_Poly1305
+		case "aes256-gcm-siv":
+			polReq.KeyType = keysutil.KeyType_AES256_GCM_SIV
+		case "xchacha20-poly1305":
+			polReq.KeyType = keysutil.KeyType_XChaCha20_Poly1305
 		case "ecdsa-p256", "ecdsa-p384", "ecdsa-p521":
 			return logical.ErrorResponse(fmt.Sprintf("key type %v not supported for this operation", keyType)), logical.ErrInvalidRequest
 		case "managed_key":
@@ -457,6 +462,7 @@ func (b *backend) pathEncryptWrite(ctx context.Context, req *logical.Request, d
 	if p == nil {
 		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
 	}
+	defer b.recordKeyUsage(ctx, req.Storage, name, p)
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
@@ -580,7 +586,7 @@ func nonceAllowed(p *keysutil.Policy) bool {
 	switch p.Type {
 	case keysutil.KeyType_MANAGED_KEY:
 		return true
-	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305:
+	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_AES256_GCM_SIV, keysutil.KeyType_XChaCha20_Poly1305:
 		supportedKeyType = true
 	default:
 		supportedKeyType = false
@@ -631,7 +637,7 @@ func shouldWarnAboutNonceUsage(p *keysutil.Policy, userSuppliedNonce []byte) boo
 
 	var supportedKeyType bool
 	switch p.Type {
-	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305:
+	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_AES256_GCM_SIV, keysutil.KeyType_XChaCha20_Poly1305:
 		supportedKeyType = true
 	default:
 		supportedKeyType = false
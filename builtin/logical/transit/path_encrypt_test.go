@@ -1066,3 +1066,158 @@ func TestTransit_EncryptWithRSAPublicKey(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestTransit_EncryptGCMSIVXChaCha20(t *testing.T) {
+	b, s := createBackendWithStorage(t)
+	plaintext := "dGhlIHF1aWNrIGJyb3duIGZveA=="
+
+	for _, keyType := range []string{"aes256-gcm-siv", "xchacha20-poly1305"} {
+		t.Run(keyType, func(t *testing.T) {
+			// Create the key on upsert
+			encReq := &logical.Request{
+				Operation: logical.CreateOperation,
+				Path:      "encrypt/" + keyType,
+				Storage:   s,
+				Data: map[string]interface{}{
+					"plaintext": plaintext,
+					"type":      keyType,
+				},
+			}
+			resp, err := b.HandleRequest(context.Background(), encReq)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			ciphertext := resp.Data["ciphertext"].(string)
+
+			encReq.Operation = logical.UpdateOperation
+			resp, err = b.HandleRequest(context.Background(), encReq)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if deterministic := keyType == "aes256-gcm-siv"; (resp.Data["ciphertext"] == ciphertext) != deterministic {
+				t.Fatalf("expected deterministic encryption to be %t", deterministic)
+			}
+
+			// Rotate and rewrap, then decrypt the rewrapped ciphertext
+			resp, err = b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "keys/" + keyType + "/rotate",
+				Storage:   s,
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+
+			resp, err = b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "rewrap/" + keyType,
+				Storage:   s,
+				Data: map[string]interface{}{
+					"ciphertext": ciphertext,
+				},
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			rewrapped := resp.Data["ciphertext"].(string)
+			if !strings.HasPrefix(rewrapped, "vault:v2:") {
+				t.Fatalf("expected rewrapped ciphertext to use version 2: %s", rewrapped)
+			}
+
+			resp, err = b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "decrypt/" + keyType,
+				Storage:   s,
+				Data: map[string]interface{}{
+					"ciphertext": rewrapped,
+				},
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if resp.Data["plaintext"] != plaintext {
+				t.Fatalf("bad plaintext: %v", resp.Data["plaintext"])
+			}
+
+			// Generate and decrypt a data key
+			resp, err = b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "datakey/plaintext/" + keyType,
+				Storage:   s,
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			dataKey := resp.Data["plaintext"]
+
+			resp, err = b.HandleRequest(context.Background(), &logical.Request{
+				Operation: logical.UpdateOperation,
+				Path:      "decrypt/" + keyType,
+				Storage:   s,
+				Data: map[string]interface{}{
+					"ciphertext": resp.Data["ciphertext"],
+				},
+			})
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if resp.Data["plaintext"] != dataKey {
+				t.Fatal("decrypted data key does not match")
+			}
+		})
+	}
+
+	// Convergent aes256-gcm-siv keys encrypt deterministically, with a
+	// nonce derived from the plaintext
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "keys/convergent",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"type":                  "aes256-gcm-siv",
+			"derived":               true,
+			"convergent_encryption": true,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+
+	encReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "encrypt/convergent",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"plaintext": plaintext,
+			"context":   "dGVzdGNvbnRleHQ=",
+		},
+	}
+	resp, err = b.HandleRequest(context.Background(), encReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	ciphertext := resp.Data["ciphertext"]
+	resp, err = b.HandleRequest(context.Background(), encReq)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["ciphertext"] != ciphertext {
+		t.Fatal("expected convergent encryption to be deterministic")
+	}
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "decrypt/convergent",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"ciphertext": ciphertext,
+			"context":    "dGVzdGNvbnRleHQ=",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err:%v resp:%#v", err, resp)
+	}
+	if resp.Data["plaintext"] != plaintext {
+		t.Fatalf("bad plaintext: %v", resp.Data["plaintext"])
+	}
+}
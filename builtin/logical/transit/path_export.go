@@ -98,7 +98,7 @@ func (b *backend) pathPolicyExportRead(ctx context.Context, req *logical.Request
 
 	switch exportType {
 	case exportTypeEncryptionKey:
-		if !p.Type.EncryptionSupported() {
+		if !p.Type.EncryptionSupported() && !p.Type.KeyEncapsulationSupported() {
 			return logical.ErrorResponse("encryption not supported for the key"), logical.ErrInvalidRequest
 		}
 	case exportTypeSigningKey:
@@ -176,7 +176,7 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 
 	case exportTypeEncryptionKey:
 		switch policy.Type {
-		case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305:
+		case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_AES256_GCM_SIV, keysutil.KeyType_XChaCha20_Poly1305:
 			return strings.TrimSpace(base64.StdEncoding.EncodeToString(key.Key)), nil
 
 		case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
@@ -185,6 +185,9 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 				return "", err
 			}
 			return rsaKey, nil
+
+		case keysutil.KeyType_ML_KEM_768, keysutil.KeyType_ML_KEM_1024:
+			return encodePQCPrivateKey(policy.Type, key)
 		}
 
 	case exportTypeSigningKey:
@@ -218,6 +221,9 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 				return "", err
 			}
 			return rsaKey, nil
+
+		case keysutil.KeyType_ML_DSA_44, keysutil.KeyType_ML_DSA_65, keysutil.KeyType_ML_DSA_87:
+			return encodePQCPrivateKey(policy.Type, key)
 		}
 	case exportTypePublicKey:
 		switch policy.Type {
@@ -237,7 +243,7 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 			}
 			return ecKey, nil
 
-		case keysutil.KeyType_ED25519:
+		case keysutil.KeyType_ED25519, keysutil.KeyType_X25519:
 			return strings.TrimSpace(key.FormattedPublicKey), nil
 
 		case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
@@ -246,6 +252,9 @@ func getExportKey(policy *keysutil.Policy, key *keysutil.KeyEntry, exportType st
 				return "", err
 			}
 			return rsaKey, nil
+
+		case keysutil.KeyType_ML_DSA_44, keysutil.KeyType_ML_DSA_65, keysutil.KeyType_ML_DSA_87, keysutil.KeyType_ML_KEM_768, keysutil.KeyType_ML_KEM_1024:
+			return strings.TrimSpace(key.FormattedPublicKey), nil
 		}
 	case exportTypeCertificateChain:
 		if key.CertificateChain == nil {
@@ -291,6 +300,34 @@ func encodeRSAPrivateKey(key *keysutil.KeyEntry) (string, error) {
 	return string(pemBytes), nil
 }
 
+func encodePQCPrivateKey(keyType keysutil.KeyType, key *keysutil.KeyEntry) (string, error) {
+	if key == nil {
+		return "", errors.New("nil KeyEntry provided")
+	}
+
+	if key.IsPrivateKeyMissing() {
+		return "", nil
+	}
+
+	privKey, err := key.PQCPrivateKey(keyType)
+	if err != nil {
+		return "", err
+	}
+
+	derBytes, err := keysutil.MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		return "", err
+	}
+
+	pemBlock := pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: derBytes,
+	}
+
+	pemBytes := pem.EncodeToMemory(&pemBlock)
+	return string(pemBytes), nil
+}
+
 func encodeRSAPublicKey(key *keysutil.KeyEntry) (string, error) {
 	if key == nil {
 		return "", errors.New("nil KeyEntry provided")
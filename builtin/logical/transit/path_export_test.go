@@ -32,6 +32,10 @@ func TestTransit_Export_KeyVersion_ExportsCorrectVersion(t *testing.T) {
 	verifyExportsCorrectVersion(t, "signing-key", "ecdsa-p384")
 	verifyExportsCorrectVersion(t, "signing-key", "ecdsa-p521")
 	verifyExportsCorrectVersion(t, "signing-key", "ed25519")
+	verifyExportsCorrectVersion(t, "signing-key", "ml-dsa-65")
+	verifyExportsCorrectVersion(t, "encryption-key", "ml-kem-768")
+	verifyExportsCorrectVersion(t, "public-key", "ml-dsa-65")
+	verifyExportsCorrectVersion(t, "public-key", "ml-kem-768")
 	verifyExportsCorrectVersion(t, "hmac-key", "aes128-gcm96")
 	verifyExportsCorrectVersion(t, "hmac-key", "aes256-gcm96")
 	verifyExportsCorrectVersion(t, "hmac-key", "chacha20-poly1305")
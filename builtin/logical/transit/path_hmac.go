@@ -79,8 +79,12 @@ func (b *backend) pathHMAC() *framework.Path {
 * sha3-256
 * sha3-384
 * sha3-512
+* cmac
+* kmac128
+* kmac256
 
-Defaults to "sha2-256".`,
+The cmac, kmac128 and kmac256 algorithms are only supported
+by aes128-gcm96, aes256-gcm96 and hmac keys. Defaults to "sha2-256".`,
 			},
 
 			"urlalgorithm": {
@@ -149,7 +153,13 @@ func (b *backend) pathHMACWrite(ctx context.Context, req *logical.Request, d *fr
 		return logical.ErrorResponse("cannot generate HMAC: version is too old (disallowed by policy)"), logical.ErrInvalidRequest
 	}
 
-	key, err := p.HMACKey(ver)
+	macType, macFn, err := macFunc(algorithm, p.Type)
+	if err != nil {
+		p.Unlock()
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	key, err := p.MACKey(ver, macType)
 	if err != nil {
 		p.Unlock()
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -159,14 +169,6 @@ func (b *backend) pathHMACWrite(ctx context.Context, req *logical.Request, d *fr
 		return nil, fmt.Errorf("HMAC key value could not be computed")
 	}
 
-	hashAlgorithm, ok := keysutil.HashTypeMap[algorithm]
-	if !ok {
-		p.Unlock()
-		return logical.ErrorResponse("unsupported algorithm %q", hashAlgorithm), nil
-	}
-
-	hashAlg := keysutil.HashFuncMap[hashAlgorithm]
-
 	batchInputRaw := d.Raw["batch_input"]
 	var batchInputItems []batchRequestHMACItem
 	if batchInputRaw != nil {
@@ -223,9 +225,12 @@ func (b *backend) pathHMACWrite(ctx context.Context, req *logical.Request, d *fr
 				response[i].err = err
 			}
 		} else {
-			hf := hmac.New(hashAlg, key)
-			hf.Write(input)
-			retBytes = hf.Sum(nil)
+			retBytes, err = macFn(key, input)
+			if err != nil {
+				response[i].Error = err.Error()
+				response[i].err = logical.ErrInvalidRequest
+				continue
+			}
 		}
 
 		retStr := base64.StdEncoding.EncodeToString(retBytes)
@@ -283,14 +288,12 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 		p.Lock(false)
 	}
 
-	hashAlgorithm, ok := keysutil.HashTypeMap[algorithm]
-	if !ok {
+	macType, macFn, err := macFunc(algorithm, p.Type)
+	if err != nil {
 		p.Unlock()
-		return logical.ErrorResponse("unsupported algorithm %q", hashAlgorithm), nil
+		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	hashAlg := keysutil.HashFuncMap[hashAlgorithm]
-
 	batchInputRaw := d.Raw["batch_input"]
 	var batchInputItems []batchRequestHMACItem
 	if batchInputRaw != nil {
@@ -380,7 +383,7 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 			continue
 		}
 
-		key, err := p.HMACKey(ver)
+		key, err := p.MACKey(ver, macType)
 		if err != nil {
 			response[i].Error = err.Error()
 			response[i].err = logical.ErrInvalidRequest
@@ -392,9 +395,12 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 			continue
 		}
 
-		hf := hmac.New(hashAlg, key)
-		hf.Write(input)
-		retBytes := hf.Sum(nil)
+		retBytes, err := macFn(key, input)
+		if err != nil {
+			response[i].Error = err.Error()
+			response[i].err = logical.ErrInvalidRequest
+			continue
+		}
 		response[i].Valid = hmac.Equal(retBytes, verBytes)
 	}
 
@@ -426,8 +432,39 @@ func (b *backend) pathHMACVerify(ctx context.Context, req *logical.Request, d *f
 	return resp, nil
 }
 
+// macFunc returns the MAC type and the function computing the MAC for the
+// named algorithm, which is either a hash algorithm to use with HMAC, or one
+// of the CMAC and KMAC algorithms for the key types supporting them.
+func macFunc(algorithm string, keyType keysutil.KeyType) (keysutil.MACType, func(key, input []byte) ([]byte, error), error) {
+	if macType, ok := keysutil.MACTypeMap[algorithm]; ok {
+		if !keyType.MACSupported() {
+			return 0, nil, fmt.Errorf("algorithm %q not supported for key type %v", algorithm, keyType)
+		}
+		return macType, func(key, input []byte) ([]byte, error) {
+			return keysutil.ComputeMAC(macType, key, input)
+		}, nil
+	}
+
+	hashAlgorithm, ok := keysutil.HashTypeMap[algorithm]
+	if !ok || hashAlgorithm == keysutil.HashTypeNone {
+		return 0, nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	hashAlg := keysutil.HashFuncMap[hashAlgorithm]
+	return keysutil.MACTypeHMAC, func(key, input []byte) ([]byte, error) {
+		hf := hmac.New(hashAlg, key)
+		hf.Write(input)
+		return hf.Sum(nil), nil
+	}, nil
+}
+
 const pathHMACHelpSyn = `Generate an HMAC for input data using the named key`
 
 const pathHMACHelpDesc = `
 Generates an HMAC sum of the given algorithm and key against the given input data.
+For aes128-gcm96, aes256-gcm96 and hmac keys, the algorithm may instead be one
+of "cmac", "kmac128" or "kmac256" to generate an AES-CMAC or KMAC tag. CMAC
+with AES keys uses a key derived from the key's HMAC key material, or the AES
+key itself for keys only allowed the "hmac" operation; all other algorithms use
+the key's HMAC key material.
 `
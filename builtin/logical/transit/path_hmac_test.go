@@ -5,6 +5,8 @@ package transit
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"strconv"
 	"strings"
@@ -372,3 +374,158 @@ func TestTransit_batchHMAC(t *testing.T) {
 		t.Fatalf("expected error validating hmac\nreq\n%#v\nresp\n%#v", *req, *resp)
 	}
 }
+
+func TestTransit_CMAC_KMAC(t *testing.T) {
+	b, storage := createBackendWithSysView(t)
+
+	cases := []struct {
+		name      string
+		typ       string
+		key       string
+		algorithm string
+		input     string
+		expected  string
+	}{
+		{
+			// NIST SP 800-38B, Appendix D.1, Example 2
+			name:      "aes128",
+			typ:       "aes128-gcm96",
+			key:       "2b7e151628aed2a6abf7158809cf4f3c",
+			algorithm: "cmac",
+			input:     "6bc1bee22e409f96e93d7e117393172a",
+			expected:  "070a16b46b4d4144f79bdd9dd04a287c",
+		},
+		{
+			// NIST SP 800-38B, Appendix D.3, Example 2
+			name:      "aes256",
+			typ:       "aes256-gcm96",
+			key:       "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4",
+			algorithm: "cmac",
+			input:     "6bc1bee22e409f96e93d7e117393172a",
+			expected:  "28a7023f452e8f82bd4bf28d8c37c35c",
+		},
+		{
+			// NIST SP 800-185 KMAC sample 1
+			name:      "hmac",
+			typ:       "hmac",
+			key:       "404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f",
+			algorithm: "kmac128",
+			input:     "00010203",
+			expected:  "e5780b0d3ea6f7d3a429c5706aa43a00fadbd7d49628839e3187243f456ee14e",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "keys/" + c.name,
+				Data: map[string]interface{}{
+					"type": c.typ,
+					// MAC only AES keys use the AES key itself for CMAC
+					"allowed_operations": "hmac",
+				},
+			}
+			if c.typ == "hmac" {
+				req.Data["key_size"] = 32
+			}
+			if _, err := b.HandleRequest(context.Background(), req); err != nil {
+				t.Fatal(err)
+			}
+
+			// Set the key material to that of the test vector
+			p, _, err := b.GetPolicy(context.Background(), keysutil.PolicyRequest{
+				Storage: storage,
+				Name:    c.name,
+			}, b.GetRandomReader())
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, _ := hex.DecodeString(c.key)
+			keyEntry := p.Keys["1"]
+			keyEntry.Key = key
+			if c.typ == "hmac" {
+				keyEntry.HMACKey = key
+			}
+			p.Keys["1"] = keyEntry
+			if err = p.Persist(context.Background(), storage); err != nil {
+				t.Fatal(err)
+			}
+
+			input, _ := hex.DecodeString(c.input)
+			expected, _ := hex.DecodeString(c.expected)
+			expectedMAC := "vault:v1:" + base64.StdEncoding.EncodeToString(expected)
+
+			req.Path = "hmac/" + c.name + "/" + c.algorithm
+			req.Data = map[string]interface{}{
+				"input": base64.StdEncoding.EncodeToString(input),
+			}
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			if resp.Data["hmac"] != expectedMAC {
+				t.Fatalf("expected %s, got %v", expectedMAC, resp.Data["hmac"])
+			}
+
+			// Verify the MAC, and a tampered MAC, in a batch
+			req.Path = "verify/" + c.name
+			req.Data = map[string]interface{}{
+				"algorithm": c.algorithm,
+				"batch_input": []interface{}{
+					map[string]interface{}{"input": base64.StdEncoding.EncodeToString(input), "hmac": expectedMAC},
+					map[string]interface{}{"input": base64.StdEncoding.EncodeToString([]byte("tampered")), "hmac": expectedMAC},
+				},
+			}
+			resp, err = b.HandleRequest(context.Background(), req)
+			if err != nil || (resp != nil && resp.IsError()) {
+				t.Fatalf("err:%v resp:%#v", err, resp)
+			}
+			batchResults := resp.Data["batch_results"].([]batchResponseHMACItem)
+			if !batchResults[0].Valid {
+				t.Fatal("expected MAC to verify")
+			}
+			if batchResults[1].Valid {
+				t.Fatal("expected MAC over tampered input to fail verification")
+			}
+		})
+	}
+
+	// MAC only AES keys cannot later be allowed to encrypt, as the AES key
+	// itself has been used for CMAC
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "keys/aes256/config",
+		Data: map[string]interface{}{
+			"allowed_operations": "encrypt,decrypt,hmac",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error allowing encryption with a MAC only key, err:%v resp:%#v", err, resp)
+	}
+
+	// CMAC and KMAC are not available for other key types
+	req := &logical.Request{
+		Storage:   storage,
+		Operation: logical.UpdateOperation,
+		Path:      "keys/ecdsa",
+		Data: map[string]interface{}{
+			"type": "ecdsa-p256",
+		},
+	}
+	if _, err := b.HandleRequest(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	for _, algorithm := range []string{"cmac", "kmac128", "kmac256"} {
+		req.Path = "hmac/ecdsa/" + algorithm
+		req.Data = map[string]interface{}{
+			"input": "dGhlIHF1aWNrIGJyb3duIGZveA==",
+		}
+		resp, err = b.HandleRequest(context.Background(), req)
+		if err == nil && (resp == nil || !resp.IsError()) {
+			t.Fatalf("expected error using %s with ecdsa-p256 key", algorithm)
+		}
+	}
+}
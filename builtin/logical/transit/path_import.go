@@ -43,9 +43,11 @@ func (b *backend) pathImport() *framework.Path {
 			"type": {
 				Type:    framework.TypeString,
 				Default: "aes256-gcm96",
-				Description: `The type of key being imported. Currently, "aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "ecdsa-p256"
-(asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521" (asymmetric), "ed25519" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
-(asymmetric), "rsa-4096" (asymmetric) are supported.  Defaults to "aes256-gcm96".
+				Description: `The type of key being imported. Currently, "aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "aes256-gcm-siv"
+(symmetric), "xchacha20-poly1305" (symmetric), "ecdsa-p256" (asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521"
+(asymmetric), "ed25519" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
+(asymmetric), "rsa-4096" (asymmetric), "ml-dsa-44" (asymmetric), "ml-dsa-65" (asymmetric), "ml-dsa-87" (asymmetric),
+"ml-kem-768" (asymmetric), "ml-kem-1024" (asymmetric), "x25519" (asymmetric) are supported.  Defaults to "aes256-gcm96".
 `,
 			},
 			"hash_function": {
@@ -103,6 +105,14 @@ being automatically rotated. A value of 0
 (default) disables automatic rotation for the
 key.`,
 			},
+			"allowed_operations": {
+				Type: framework.TypeCommaStringSlice,
+				Description: fmt.Sprintf(`Operations which may be performed
+with the key, from: %s. If empty (default),
+all operations supported by the key type are
+allowed. AES keys limited to "hmac" use the
+imported key itself for CMAC.`, strings.Join(keysutil.KeyOperations, ", ")),
+			},
 		},
 		Callbacks: map[logical.Operation]framework.OperationFunc{
 			logical.UpdateOperation: b.pathImportWrite,
@@ -164,6 +174,7 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 	allowPlaintextBackup := d.Get("allow_plaintext_backup").(bool)
 	autoRotatePeriod := time.Second * time.Duration(d.Get("auto_rotate_period").(int))
 	allowRotation := d.Get("allow_rotation").(bool)
+	allowedOperations := d.Get("allowed_operations").([]string)
 
 	// Ensure the caller didn't supply "convergent_encryption" as a field, since it's not supported on import.
 	if _, ok := d.Raw["convergent_encryption"]; ok {
@@ -174,6 +185,10 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		return nil, errors.New("allow_rotation must be set to true if auto-rotation is enabled")
 	}
 
+	if err := keysutil.ValidateKeyOperations(allowedOperations); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	// Ensure that at least on `key` field has been set
 	isCiphertextSet, err := checkKeyFieldsSet(d)
 	if err != nil {
@@ -188,6 +203,7 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		AllowPlaintextBackup:     allowPlaintextBackup,
 		AutoRotatePeriod:         autoRotatePeriod,
 		AllowImportedKeyRotation: allowRotation,
+		AllowedOperations:        allowedOperations,
 		IsPrivateKey:             isCiphertextSet,
 	}
 
@@ -198,6 +214,10 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		polReq.KeyType = keysutil.KeyType_AES256_GCM96
 	case "chacha20-poly1305":
 		polReq.KeyType = keysutil.KeyType_ChaCha20_Poly1305
+	case "aes256-gcm-siv":
+		polReq.KeyType = keysutil.KeyType_AES256_GCM_SIV
+	case "xchacha20-poly1305":
+		polReq.KeyType = keysutil.KeyType_XChaCha20_Poly1305
 	case "ecdsa-p256":
 		polReq.KeyType = keysutil.KeyType_ECDSA_P256
 	case "ecdsa-p384":
@@ -212,6 +232,18 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		polReq.KeyType = keysutil.KeyType_RSA3072
 	case "rsa-4096":
 		polReq.KeyType = keysutil.KeyType_RSA4096
+	case "ml-dsa-44":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_44
+	case "ml-dsa-65":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_65
+	case "ml-dsa-87":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_87
+	case "ml-kem-768":
+		polReq.KeyType = keysutil.KeyType_ML_KEM_768
+	case "ml-kem-1024":
+		polReq.KeyType = keysutil.KeyType_ML_KEM_1024
+	case "x25519":
+		polReq.KeyType = keysutil.KeyType_X25519
 	case "hmac":
 		polReq.KeyType = keysutil.KeyType_HMAC
 	default:
@@ -240,6 +272,8 @@ func (b *backend) pathImportWrite(ctx context.Context, req *logical.Request, d *
 		return nil, err
 	}
 
+	b.keyEvent(ctx, "key-import", name, nil, "type", polReq.KeyType.String())
+
 	return nil, nil
 }
 
@@ -298,6 +332,8 @@ func (b *backend) pathImportVersionWrite(ctx context.Context, req *logical.Reque
 		return nil, err
 	}
 
+	b.keyEvent(ctx, "key-import", name, p)
+
 	return nil, nil
 }
 
@@ -60,9 +60,11 @@ func (b *backend) pathKeys() *framework.Path {
 				Type:    framework.TypeString,
 				Default: "aes256-gcm96",
 				Description: `
-The type of key to create. Currently, "aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "ecdsa-p256"
-(asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521" (asymmetric), "ed25519" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
-(asymmetric), "rsa-4096" (asymmetric) are supported.  Defaults to "aes256-gcm96".
+The type of key to create. Currently, "aes128-gcm96" (symmetric), "aes256-gcm96" (symmetric), "aes256-gcm-siv"
+(symmetric), "xchacha20-poly1305" (symmetric), "ecdsa-p256" (asymmetric), "ecdsa-p384" (asymmetric), "ecdsa-p521"
+(asymmetric), "ed25519" (asymmetric), "rsa-2048" (asymmetric), "rsa-3072"
+(asymmetric), "rsa-4096" (asymmetric), "ml-dsa-44" (asymmetric), "ml-dsa-65" (asymmetric), "ml-dsa-87" (asymmetric),
+"ml-kem-768" (asymmetric), "ml-kem-1024" (asymmetric), "x25519" (asymmetric) are supported.  Defaults to "aes256-gcm96".
 `,
 			},
 
@@ -118,6 +120,21 @@ return the public key for the given context.`,
 being automatically rotated. A value of 0
 (default) disables automatic rotation for the
 key.`,
+			},
+			"auto_rotate_usage_count": {
+				Type:    framework.TypeInt,
+				Default: 0,
+				Description: `Number of encryptions and signatures
+after which the latest key version should be
+automatically rotated. A value of 0 (default)
+disables usage based rotation for the key.`,
+			},
+			"allowed_operations": {
+				Type: framework.TypeCommaStringSlice,
+				Description: fmt.Sprintf(`Operations which may be performed
+with the key, from: %s. If empty (default),
+all operations supported by the key type are
+allowed.`, strings.Join(keysutil.KeyOperations, ", ")),
 			},
 			"key_size": {
 				Type:        framework.TypeInt,
@@ -178,6 +195,8 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 	exportable := d.Get("exportable").(bool)
 	allowPlaintextBackup := d.Get("allow_plaintext_backup").(bool)
 	autoRotatePeriod := time.Second * time.Duration(d.Get("auto_rotate_period").(int))
+	autoRotateUsageCount := d.Get("auto_rotate_usage_count").(int)
+	allowedOperations := d.Get("allowed_operations").([]string)
 	managedKeyName := d.Get("managed_key_name").(string)
 	managedKeyId := d.Get("managed_key_id").(string)
 
@@ -185,6 +204,14 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		return logical.ErrorResponse("auto rotate period must be 0 to disable or at least an hour"), nil
 	}
 
+	if autoRotateUsageCount < 0 {
+		return logical.ErrorResponse("auto rotate usage count must be 0 to disable or positive"), nil
+	}
+
+	if err := keysutil.ValidateKeyOperations(allowedOperations); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	if !derived && convergent {
 		return logical.ErrorResponse("convergent encryption requires derivation to be enabled"), nil
 	}
@@ -198,6 +225,8 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		Exportable:           exportable,
 		AllowPlaintextBackup: allowPlaintextBackup,
 		AutoRotatePeriod:     autoRotatePeriod,
+		AutoRotateUsageCount: uint64(autoRotateUsageCount),
+		AllowedOperations:    allowedOperations,
 	}
 
 	switch keyType {
@@ -207,6 +236,10 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		polReq.KeyType = keysutil.KeyType_AES256_GCM96
 	case "chacha20-poly1305":
 		polReq.KeyType = keysutil.KeyType_ChaCha20_Poly1305
+	case "aes256-gcm-siv":
+		polReq.KeyType = keysutil.KeyType_AES256_GCM_SIV
+	case "xchacha20-poly1305":
+		polReq.KeyType = keysutil.KeyType_XChaCha20_Poly1305
 	case "ecdsa-p256":
 		polReq.KeyType = keysutil.KeyType_ECDSA_P256
 	case "ecdsa-p384":
@@ -221,6 +254,18 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		polReq.KeyType = keysutil.KeyType_RSA3072
 	case "rsa-4096":
 		polReq.KeyType = keysutil.KeyType_RSA4096
+	case "ml-dsa-44":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_44
+	case "ml-dsa-65":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_65
+	case "ml-dsa-87":
+		polReq.KeyType = keysutil.KeyType_ML_DSA_87
+	case "ml-kem-768":
+		polReq.KeyType = keysutil.KeyType_ML_KEM_768
+	case "ml-kem-1024":
+		polReq.KeyType = keysutil.KeyType_ML_KEM_1024
+	case "x25519":
+		polReq.KeyType = keysutil.KeyType_X25519
 	case "hmac":
 		polReq.KeyType = keysutil.KeyType_HMAC
 	case "managed_key":
@@ -258,6 +303,10 @@ func (b *backend) pathPolicyWrite(ctx context.Context, req *logical.Request, d *
 		p.Unlock()
 	}
 
+	if upserted {
+		b.keyEvent(ctx, "key-create", name, p)
+	}
+
 	resp, err := b.formatKeyPolicy(p, nil)
 	if err != nil {
 		return nil, err
@@ -310,28 +359,46 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 	// Return the response
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"name":                   p.Name,
-			"type":                   p.Type.String(),
-			"derived":                p.Derived,
-			"deletion_allowed":       p.DeletionAllowed,
-			"min_available_version":  p.MinAvailableVersion,
-			"min_decryption_version": p.MinDecryptionVersion,
-			"min_encryption_version": p.MinEncryptionVersion,
-			"latest_version":         p.LatestVersion,
-			"exportable":             p.Exportable,
-			"allow_plaintext_backup": p.AllowPlaintextBackup,
-			"supports_encryption":    p.Type.EncryptionSupported(),
-			"supports_decryption":    p.Type.DecryptionSupported(),
-			"supports_signing":       p.Type.SigningSupported(),
-			"supports_derivation":    p.Type.DerivationSupported(),
-			"auto_rotate_period":     int64(p.AutoRotatePeriod.Seconds()),
-			"imported_key":           p.Imported,
+			"name":                    p.Name,
+			"type":                    p.Type.String(),
+			"derived":                 p.Derived,
+			"deletion_allowed":        p.DeletionAllowed,
+			"min_available_version":   p.MinAvailableVersion,
+			"min_decryption_version":  p.MinDecryptionVersion,
+			"min_encryption_version":  p.MinEncryptionVersion,
+			"latest_version":          p.LatestVersion,
+			"exportable":              p.Exportable,
+			"allow_plaintext_backup":  p.AllowPlaintextBackup,
+			"supports_encryption":     p.Type.EncryptionSupported(),
+			"supports_decryption":     p.Type.DecryptionSupported(),
+			"supports_key_agreement":  p.Type.KeyAgreementSupported(),
+			"supports_signing":        p.Type.SigningSupported(),
+			"supports_derivation":     p.Type.DerivationSupported(),
+			"auto_rotate_period":      int64(p.AutoRotatePeriod.Seconds()),
+			"auto_rotate_usage_count": p.AutoRotateUsageCount,
+			"imported_key":            p.Imported,
 		},
 	}
 	if p.KeySize != 0 {
 		resp.Data["key_size"] = p.KeySize
 	}
 
+	allowedOperations := p.AllowedOperations
+	if allowedOperations == nil {
+		allowedOperations = []string{}
+	}
+	resp.Data["allowed_operations"] = allowedOperations
+
+	usage := map[string]keysutil.KeyUsage{}
+	for k := range p.Keys {
+		ver, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, err
+		}
+		usage[k] = p.Usage(ver)
+	}
+	resp.Data["usage"] = usage
+
 	if p.Imported {
 		resp.Data["imported_key_allow_rotation"] = p.AllowImportedKeyRotation
 	}
@@ -364,14 +431,15 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 	}
 
 	switch p.Type {
-	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305:
+	case keysutil.KeyType_AES128_GCM96, keysutil.KeyType_AES256_GCM96, keysutil.KeyType_ChaCha20_Poly1305, keysutil.KeyType_AES256_GCM_SIV, keysutil.KeyType_XChaCha20_Poly1305:
 		retKeys := map[string]int64{}
 		for k, v := range p.Keys {
 			retKeys[k] = v.DeprecatedCreationTime
 		}
 		resp.Data["keys"] = retKeys
 
-	case keysutil.KeyType_ECDSA_P256, keysutil.KeyType_ECDSA_P384, keysutil.KeyType_ECDSA_P521, keysutil.KeyType_ED25519, keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
+	case keysutil.KeyType_ECDSA_P256, keysutil.KeyType_ECDSA_P384, keysutil.KeyType_ECDSA_P521, keysutil.KeyType_ED25519, keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096,
+		keysutil.KeyType_ML_DSA_44, keysutil.KeyType_ML_DSA_65, keysutil.KeyType_ML_DSA_87, keysutil.KeyType_ML_KEM_768, keysutil.KeyType_ML_KEM_1024, keysutil.KeyType_X25519:
 		retKeys := map[string]map[string]interface{}{}
 		for k, v := range p.Keys {
 			key := asymKey{
@@ -419,6 +487,8 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 					}
 				}
 				key.Name = "ed25519"
+			case keysutil.KeyType_X25519:
+				key.Name = "x25519"
 			case keysutil.KeyType_RSA2048, keysutil.KeyType_RSA3072, keysutil.KeyType_RSA4096:
 				key.Name = "rsa-2048"
 				if p.Type == keysutil.KeyType_RSA3072 {
@@ -450,6 +520,8 @@ func (b *backend) formatKeyPolicy(p *keysutil.Policy, context []byte) (*logical.
 					return nil, fmt.Errorf("failed to PEM-encode RSA public key")
 				}
 				key.PublicKey = string(pemBytes)
+			case keysutil.KeyType_ML_DSA_44, keysutil.KeyType_ML_DSA_65, keysutil.KeyType_ML_DSA_87, keysutil.KeyType_ML_KEM_768, keysutil.KeyType_ML_KEM_1024:
+				key.Name = p.Type.String()
 			}
 
 			retKeys[k] = structs.New(key).Map()
@@ -469,6 +541,8 @@ func (b *backend) pathPolicyDelete(ctx context.Context, req *logical.Request, d
 		return logical.ErrorResponse(fmt.Sprintf("error deleting policy %s: %s", name, err)), err
 	}
 
+	b.keyEvent(ctx, "key-delete", name, nil)
+
 	return nil, nil
 }
 
@@ -6,10 +6,12 @@ package transit
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/keysutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -65,6 +67,22 @@ the latest version of the key is allowed.`,
 being automatically rotated. A value of 0
 disables automatic rotation for the key.`,
 			},
+
+			"auto_rotate_usage_count": {
+				Type: framework.TypeInt,
+				Description: `Number of encryptions and signatures
+after which the latest key version should be
+automatically rotated. A value of 0 disables
+usage based rotation for the key.`,
+			},
+
+			"allowed_operations": {
+				Type: framework.TypeCommaStringSlice,
+				Description: fmt.Sprintf(`Operations which may be performed
+with the key, from: %s. If set to an empty
+list, all operations supported by the key
+type are allowed.`, strings.Join(keysutil.KeyOperations, ", ")),
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -104,6 +122,8 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 	originalDeletionAllowed := p.DeletionAllowed
 	originalExportable := p.Exportable
 	originalAllowPlaintextBackup := p.AllowPlaintextBackup
+	originalAutoRotateUsageCount := p.AutoRotateUsageCount
+	originalAllowedOperations := p.AllowedOperations
 
 	defer func() {
 		if retErr != nil || (resp != nil && resp.IsError()) {
@@ -112,6 +132,8 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 			p.DeletionAllowed = originalDeletionAllowed
 			p.Exportable = originalExportable
 			p.AllowPlaintextBackup = originalAllowPlaintextBackup
+			p.AutoRotateUsageCount = originalAutoRotateUsageCount
+			p.AllowedOperations = originalAllowedOperations
 		}
 	}()
 
@@ -220,6 +242,42 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 		}
 	}
 
+	autoRotateUsageCountRaw, ok, err := d.GetOkErr("auto_rotate_usage_count")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		autoRotateUsageCount := autoRotateUsageCountRaw.(int)
+		if autoRotateUsageCount < 0 {
+			return logical.ErrorResponse("auto rotate usage count must be 0 to disable or positive"), nil
+		}
+
+		if uint64(autoRotateUsageCount) != p.AutoRotateUsageCount {
+			p.AutoRotateUsageCount = uint64(autoRotateUsageCount)
+			persistNeeded = true
+		}
+	}
+
+	allowedOperationsRaw, ok := d.GetOk("allowed_operations")
+	if ok {
+		allowedOperations := allowedOperationsRaw.([]string)
+		if err := keysutil.ValidateKeyOperations(allowedOperations); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+		if len(allowedOperations) == 0 {
+			allowedOperations = nil
+		}
+		if !strutil.EquivalentSlices(allowedOperations, p.AllowedOperations) {
+			macOnly := p.MACOnly()
+			p.AllowedOperations = allowedOperations
+			if p.MACOnly() != macOnly {
+				return logical.ErrorResponse("allowed_operations of %s keys cannot be changed to or from only %q after the key is created, as this changes the key used for CMAC", p.Type, keysutil.KeyOperationHMAC), nil
+			}
+			persistNeeded = true
+		}
+	}
+
 	if !persistNeeded {
 		resp, err := b.formatKeyPolicy(p, nil)
 		if err != nil {
@@ -242,6 +300,8 @@ func (b *backend) pathKeysConfigWrite(ctx context.Context, req *logical.Request,
 		return nil, err
 	}
 
+	b.keyEvent(ctx, "key-config-update", name, p)
+
 	resp, err = b.formatKeyPolicy(p, nil)
 	if err != nil {
 		return nil, err
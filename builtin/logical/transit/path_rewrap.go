@@ -135,9 +135,10 @@ func (b *backend) pathRewrapWrite(ctx context.Context, req *logical.Request, d *
 	}
 
 	// Get the policy
+	name := d.Get("name").(string)
 	p, _, err := b.GetPolicy(ctx, keysutil.PolicyRequest{
 		Storage: req.Storage,
-		Name:    d.Get("name").(string),
+		Name:    name,
 	}, b.GetRandomReader())
 	if err != nil {
 		return nil, err
@@ -145,6 +146,7 @@ func (b *backend) pathRewrapWrite(ctx context.Context, req *logical.Request, d *
 	if p == nil {
 		return logical.ErrorResponse("encryption key not found"), logical.ErrInvalidRequest
 	}
+	defer b.recordKeyUsage(ctx, req.Storage, name, p)
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
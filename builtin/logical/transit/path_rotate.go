@@ -83,6 +83,8 @@ func (b *backend) pathRotateWrite(ctx context.Context, req *logical.Request, d *
 		return nil, err
 	}
 
+	b.keyEvent(ctx, "key-rotate", name, p, "auto", "false")
+
 	return b.formatKeyPolicy(p, nil)
 }
 
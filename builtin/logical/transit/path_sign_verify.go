@@ -119,7 +119,7 @@ derivation is enabled; currently only available with ed25519 keys.`,
 * none
 
 Defaults to "sha2-256". Not valid for all key types,
-including ed25519. Using none requires setting prehashed=true and
+including ed25519 and ML-DSA. Using none requires setting prehashed=true and
 signature_algorithm=pkcs1v15, yielding a PKCSv1_5_NoOID instead of
 the usual PKCSv1_5_DERnull signature.`,
 			},
@@ -368,6 +368,7 @@ func (b *backend) pathSignWrite(ctx context.Context, req *logical.Request, d *fr
 	if p == nil {
 		return logical.ErrorResponse("signing key not found"), logical.ErrInvalidRequest
 	}
+	defer b.recordKeyUsage(ctx, req.Storage, name, p)
 	if !b.System().CachingDisabled() {
 		p.Lock(false)
 	}
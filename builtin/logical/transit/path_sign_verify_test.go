@@ -979,3 +979,69 @@ func testTransit_SignVerify_RSA_PSS(t *testing.T, bits int) {
 		}
 	}
 }
+
+func TestTransit_SignVerify_MLDSA(t *testing.T) {
+	for _, keyType := range []string{"ml-dsa-44", "ml-dsa-65", "ml-dsa-87"} {
+		t.Run(keyType, func(t *testing.T) {
+			b, storage := createBackendWithSysView(t)
+
+			req := &logical.Request{
+				Storage:   storage,
+				Operation: logical.UpdateOperation,
+				Path:      "keys/foo",
+				Data: map[string]interface{}{
+					"type": keyType,
+				},
+			}
+			_, err := b.HandleRequest(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Derivation is not supported for ML-DSA keys
+			req.Path = "keys/bar"
+			req.Data["derived"] = true
+			resp, err := b.HandleRequest(context.Background(), req)
+			if err == nil && (resp == nil || !resp.IsError()) {
+				t.Fatal("expected error creating derived ML-DSA key")
+			}
+
+			input := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+			for marshalingName := range keysutil.MarshalingTypeMap {
+				req = &logical.Request{
+					Storage:   storage,
+					Operation: logical.UpdateOperation,
+					Path:      "sign/foo",
+					Data: map[string]interface{}{
+						"input":                input,
+						"marshaling_algorithm": marshalingName,
+					},
+				}
+				resp, err = b.HandleRequest(context.Background(), req)
+				if err != nil || (resp != nil && resp.IsError()) {
+					t.Fatalf("err:%v resp:%#v", err, resp)
+				}
+				signature := resp.Data["signature"].(string)
+
+				req.Path = "verify/foo"
+				req.Data["signature"] = signature
+				resp, err = b.HandleRequest(context.Background(), req)
+				if err != nil || (resp != nil && resp.IsError()) {
+					t.Fatalf("err:%v resp:%#v", err, resp)
+				}
+				if !resp.Data["valid"].(bool) {
+					t.Fatalf("signature with %s marshaling did not verify", marshalingName)
+				}
+
+				req.Data["input"] = base64.StdEncoding.EncodeToString([]byte("the lazy dog"))
+				resp, err = b.HandleRequest(context.Background(), req)
+				if err != nil || (resp != nil && resp.IsError()) {
+					t.Fatalf("err:%v resp:%#v", err, resp)
+				}
+				if resp.Data["valid"].(bool) {
+					t.Fatalf("signature with %s marshaling verified over the wrong input", marshalingName)
+				}
+			}
+		})
+	}
+}
@@ -94,6 +94,7 @@ func (c *AuditEnableCommand) Flags() *FlagSets {
 func (c *AuditEnableCommand) AutocompleteArgs() complete.Predictor {
 	return complete.PredictSet(
 		"file",
+		"http",
 		"syslog",
 		"socket",
 	)
@@ -191,6 +191,9 @@ func TestAuditEnableCommand_Run(t *testing.T) {
 			switch b {
 			case "file":
 				args = append(args, "file_path=discard")
+			case "http":
+				args = append(args, "address=http://127.0.0.1:8888",
+					"skip_test=true")
 			case "socket":
 				args = append(args, "address=127.0.0.1:8888",
 					"skip_test=true")
@@ -0,0 +1,369 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/audit"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*AuditVerifyCommand)(nil)
+	_ cli.CommandAutocomplete = (*AuditVerifyCommand)(nil)
+)
+
+type AuditVerifyCommand struct {
+	*BaseCommand
+
+	flagPath         string
+	flagPrefix       string
+	flagAllowPartial bool
+}
+
+func (c *AuditVerifyCommand) Synopsis() string {
+	return "Verifies the hash chain of an audit log"
+}
+
+func (c *AuditVerifyCommand) Help() string {
+	helpText := `
+Usage: vault audit verify [options] FILE
+
+  Verifies the hash chain of a log written by an audit device with the
+  "hash_chain" option enabled. Deleted, reordered and modified entries are
+  reported. The chain is checked using the salt of the audit device, which
+  requires permission to use the "sys/audit-hash" endpoint for the device.
+  Entries are hashed in batches, rather than one request per entry.
+
+  The audit device keeps the sequence number and chain of the last entry it
+  wrote, and continues the chain from it when Vault is restarted or unsealed.
+  The log is checked against this head, read from the "sys/audit-chain"
+  endpoint, so that entries removed from the end of the log are reported.
+
+  Verify the log written by the audit device enabled at "file/":
+
+      $ vault audit verify /var/log/audit.log
+
+  Verify a rotated log written by the audit device enabled at "audit/":
+
+      $ vault audit verify -path=audit/ -allow-partial /var/log/audit.log.1
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AuditVerifyCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "path",
+		Target:     &c.flagPath,
+		Default:    "file/",
+		EnvVar:     "",
+		Completion: c.PredictVaultAudits(),
+		Usage:      "Path of the audit device which wrote the log.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "prefix",
+		Target:     &c.flagPrefix,
+		Default:    "",
+		EnvVar:     "",
+		Completion: complete.PredictAnything,
+		Usage:      "Prefix configured on the audit device, which precedes each entry.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "allow-partial",
+		Target:  &c.flagAllowPartial,
+		Default: false,
+		EnvVar:  "",
+		Usage: "Allow the log to begin part way through the chain, or to end " +
+			"before the last entry written by the audit device, for example " +
+			"after log rotation. The first entry of the log is trusted.",
+	})
+
+	return set
+}
+
+func (c *AuditVerifyCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *AuditVerifyCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *AuditVerifyCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	switch {
+	case len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1, got %d)", len(args)))
+		return 1
+	case len(args) > 1:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	path := ensureTrailingSlash(sanitizePath(c.flagPath))
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error opening audit log: %s", err))
+		return 2
+	}
+	defer file.Close()
+
+	// The head is read before the size of the log, so that every entry up to
+	// and including the head has been written to the part of the log which is
+	// verified.
+	head, err := client.Sys().AuditChainHead(path)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading the chain head of the audit device: %s", err))
+		return 2
+	}
+
+	// Verifying the log generates audit entries of its own, so only the entries
+	// which were present when verification started are read.
+	info, err := file.Stat()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading audit log: %s", err))
+		return 2
+	}
+
+	v := &auditChainVerifier{
+		hash:         func(inputs []string) ([]string, error) { return client.Sys().AuditHashes(path, inputs) },
+		prefix:       c.flagPrefix,
+		allowPartial: c.flagAllowPartial,
+		head:         head,
+	}
+
+	if err := v.verify(io.LimitReader(file, info.Size())); err != nil {
+		c.UI.Error(fmt.Sprintf("Error verifying audit log: %s", err))
+		return 2
+	}
+
+	if len(v.failures) > 0 {
+		for _, failure := range v.failures {
+			c.UI.Error(failure)
+		}
+		c.UI.Error(fmt.Sprintf("Verification failed! Found %d problem(s) in %d entries", len(v.failures), v.entries))
+		return 2
+	}
+
+	c.UI.Output(fmt.Sprintf("Success! Verified %d entries", v.entries))
+
+	return 0
+}
+
+// auditVerifyBatchSize and auditVerifyBatchBytes bound the number and the
+// total size of the entries hashed by each request to the audit device.
+const (
+	auditVerifyBatchSize  = 256
+	auditVerifyBatchBytes = 1024 * 1024
+)
+
+// auditChainVerifier verifies the sequence numbers and hash chain of the
+// entries of an audit log, and that the log contains the head of the chain
+// persisted by the audit device.
+type auditChainVerifier struct {
+	hash         func(inputs []string) ([]string, error)
+	prefix       string
+	allowPartial bool
+	head         *api.AuditChainHead
+
+	entries  int
+	failures []string
+
+	sequence  uint64
+	chain     string
+	headChain string
+
+	// pending holds the checks of the entries which have not yet been hashed,
+	// along with the failures found in between them, so that failures are
+	// reported in the order of the log.
+	pending      []auditChainCheck
+	pendingBytes int
+}
+
+// auditChainCheck is either the chain of an entry which is checked once the
+// entry has been hashed, or a failure which has already been found.
+type auditChainCheck struct {
+	lineNum int
+	input   string
+	chain   string
+	failure string
+}
+
+func (v *auditChainVerifier) verify(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	for lineNum := 1; ; lineNum++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		if len(line) > 0 {
+			if verr := v.verifyLine(lineNum, line); verr != nil {
+				return verr
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			if ferr := v.flush(); ferr != nil {
+				return ferr
+			}
+			v.verifyHead()
+			return nil
+		}
+	}
+}
+
+// verifyHead checks that the log contains the head of the chain persisted by
+// the audit device, unless a partial log is allowed.
+func (v *auditChainVerifier) verifyHead() {
+	if v.head == nil || v.allowPartial {
+		return
+	}
+
+	switch {
+	case v.sequence < v.head.Sequence:
+		v.failures = append(v.failures, fmt.Sprintf("log ends at sequence %d, but the audit device has written entries up to sequence %d; entries have been removed from the end of the log", v.sequence, v.head.Sequence))
+	case v.headChain != v.head.Chain:
+		v.failures = append(v.failures, fmt.Sprintf("the chain of the entry with sequence %d does not match the chain head of the audit device", v.head.Sequence))
+	}
+}
+
+// verifyLine verifies a single entry of the log. Problems with the entry are
+// recorded as failures, while an error is only returned if the entry could not
+// be verified at all. The chain of the entry is checked once a batch of entries
+// has been queued.
+func (v *auditChainVerifier) verifyLine(lineNum int, line []byte) error {
+	v.entries++
+
+	if !bytes.HasPrefix(line, []byte(v.prefix)) {
+		v.failf(lineNum, "entry does not start with the prefix %q", v.prefix)
+		return nil
+	}
+
+	sequenced, sequence, chain, err := audit.SplitChainedEntry(bytes.TrimPrefix(line, []byte(v.prefix)))
+	if err != nil {
+		v.failf(lineNum, "%s", err)
+		return nil
+	}
+
+	if v.head != nil && sequence == v.head.Sequence {
+		v.headChain = chain
+	}
+
+	first := v.entries == 1
+
+	switch {
+	case first && sequence == 1:
+		// The first entry of the chain is chained to an empty chain.
+	case first && v.allowPartial:
+		// Trust the first entry of a partial log.
+		v.sequence, v.chain = sequence, chain
+		return nil
+	case first:
+		v.failf(lineNum, "log starts at sequence %d, the entries before it are missing", sequence)
+		v.sequence, v.chain = sequence, chain
+		return nil
+	case sequence != v.sequence+1:
+		// The chain can't be verified without the entry before it, so carry
+		// on from this entry.
+		v.failf(lineNum, "expected sequence %d, found %d; entries have been deleted or reordered", v.sequence+1, sequence)
+		v.sequence, v.chain = sequence, chain
+		return nil
+	}
+
+	input := audit.ChainInput(v.chain, sequenced)
+	v.pending = append(v.pending, auditChainCheck{lineNum: lineNum, input: input, chain: chain})
+	v.pendingBytes += len(input)
+
+	// Carry on from this entry, so that a single problem is only reported once.
+	v.sequence, v.chain = sequence, chain
+
+	if len(v.pending) >= auditVerifyBatchSize || v.pendingBytes >= auditVerifyBatchBytes {
+		return v.flush()
+	}
+
+	return nil
+}
+
+// flush hashes the queued entries in a single request, and records the
+// failures found since the last flush.
+func (v *auditChainVerifier) flush() error {
+	var inputs []string
+	lastLine := 0
+	for _, check := range v.pending {
+		if check.failure == "" {
+			inputs = append(inputs, check.input)
+			lastLine = check.lineNum
+		}
+	}
+
+	var hashes []string
+	if len(inputs) > 0 {
+		var err error
+		hashes, err = v.hash(inputs)
+		if err != nil {
+			return fmt.Errorf("unable to hash entries up to line %d: %w", lastLine, err)
+		}
+		if len(hashes) != len(inputs) {
+			return fmt.Errorf("unable to hash entries up to line %d: expected %d hashes, got %d", lastLine, len(inputs), len(hashes))
+		}
+	}
+
+	for _, check := range v.pending {
+		switch {
+		case check.failure != "":
+			v.failures = append(v.failures, check.failure)
+		case hashes[0] != check.chain:
+			v.failures = append(v.failures, fmt.Sprintf("line %d: chain does not match; the entry, or the entry before it, has been modified", check.lineNum))
+			hashes = hashes[1:]
+		default:
+			hashes = hashes[1:]
+		}
+	}
+
+	v.pending = v.pending[:0]
+	v.pendingBytes = 0
+
+	return nil
+}
+
+func (v *auditChainVerifier) failf(lineNum int, format string, args ...any) {
+	v.pending = append(v.pending, auditChainCheck{
+		lineNum: lineNum,
+		failure: fmt.Sprintf("line %d: %s", lineNum, fmt.Sprintf(format, args...)),
+	})
+}
@@ -0,0 +1,292 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/audit"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func testAuditVerifyCommand(tb testing.TB) (*cli.MockUi, *AuditVerifyCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &AuditVerifyCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+// testAuditChainHash is a stand in for the sys/audit-hash endpoint.
+func testAuditChainHash(input string) (string, error) {
+	mac := hmac.New(sha256.New, []byte("salt"))
+	mac.Write([]byte(input))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// testAuditChainHashes is a stand in for the sys/audit-hash endpoint, when
+// hashing several inputs at once.
+func testAuditChainHashes(inputs []string) ([]string, error) {
+	hashes := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		hash, err := testAuditChainHash(input)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// testAuditChainLog returns a log of chained entries, with the supplied
+// sequence numbers.
+func testAuditChainLog(tb testing.TB, sequences ...uint64) []string {
+	tb.Helper()
+
+	var lines []string
+	var chain string
+	for _, sequence := range sequences {
+		sequenced := fmt.Sprintf(`{"type":"request","request":{"id":"%d"},"sequence":%d}`, sequence, sequence)
+		next, err := testAuditChainHash(audit.ChainInput(chain, []byte(sequenced)))
+		require.NoError(tb, err)
+
+		lines = append(lines, strings.TrimSuffix(sequenced, "}")+fmt.Sprintf(`,"chain":"%s"}`, next))
+		chain = next
+	}
+
+	return lines
+}
+
+func TestAuditVerifyCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"not_enough_args",
+			nil,
+			"Not enough arguments",
+			1,
+		},
+		{
+			"too_many_args",
+			[]string{"foo", "bar"},
+			"Too many arguments",
+			1,
+		},
+		{
+			"missing_file",
+			[]string{"/does/not/exist"},
+			"Error opening audit log",
+			2,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, closer := testVaultServer(t)
+			defer closer()
+
+			ui, cmd := testAuditVerifyCommand(t)
+			cmd.client = client
+
+			code := cmd.Run(tc.args)
+			if code != tc.code {
+				t.Errorf("expected %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestAuditChainVerifier(t *testing.T) {
+	t.Parallel()
+
+	valid := testAuditChainLog(t, 1, 2, 3, 4, 5)
+	head := func(line string) *api.AuditChainHead {
+		_, sequence, chain, err := audit.SplitChainedEntry([]byte(line))
+		require.NoError(t, err)
+		return &api.AuditChainHead{Sequence: sequence, Chain: chain}
+	}
+
+	modified := testAuditChainLog(t, 1, 2, 3)
+	modified[1] = strings.Replace(modified[1], `"id":"2"`, `"id":"X"`, 1)
+
+	reordered := testAuditChainLog(t, 1, 2, 3, 4)
+	reordered[1], reordered[2] = reordered[2], reordered[1]
+
+	restarted := append(testAuditChainLog(t, 1, 2, 3), testAuditChainLog(t, 1, 2)...)
+
+	cases := map[string]struct {
+		lines        []string
+		prefix       string
+		allowPartial bool
+		head         *api.AuditChainHead
+		entries      int
+		failures     []string
+	}{
+		"valid": {
+			lines:   valid,
+			head:    head(valid[4]),
+			entries: 5,
+		},
+		"written_after_head": {
+			lines:   valid,
+			head:    head(valid[2]),
+			entries: 5,
+		},
+		"no_head": {
+			lines:   valid,
+			entries: 5,
+		},
+		"prefixed": {
+			lines: func() []string {
+				var lines []string
+				for _, line := range testAuditChainLog(t, 1, 2) {
+					lines = append(lines, "@cee: "+line)
+				}
+				return lines
+			}(),
+			prefix:  "@cee: ",
+			entries: 2,
+		},
+		"deleted": {
+			lines:    []string{valid[0], valid[2]},
+			entries:  2,
+			failures: []string{"line 2: expected sequence 2, found 3; entries have been deleted or reordered"},
+		},
+		"truncated": {
+			lines:   valid[:3],
+			head:    head(valid[4]),
+			entries: 3,
+			failures: []string{
+				"log ends at sequence 3, but the audit device has written entries up to sequence 5; entries have been removed from the end of the log",
+			},
+		},
+		"truncated_allowed": {
+			lines:        valid[:3],
+			head:         head(valid[4]),
+			allowPartial: true,
+			entries:      3,
+		},
+		"head_mismatch": {
+			lines:   valid,
+			head:    &api.AuditChainHead{Sequence: 5, Chain: "hmac-sha256:other"},
+			entries: 5,
+			failures: []string{
+				"the chain of the entry with sequence 5 does not match the chain head of the audit device",
+			},
+		},
+		"modified": {
+			lines:   modified,
+			entries: 3,
+			failures: []string{
+				"line 2: chain does not match; the entry, or the entry before it, has been modified",
+			},
+		},
+		"reordered": {
+			lines:   reordered,
+			entries: 4,
+			failures: []string{
+				"line 2: expected sequence 2, found 3; entries have been deleted or reordered",
+				"line 3: expected sequence 4, found 2; entries have been deleted or reordered",
+				"line 4: expected sequence 3, found 4; entries have been deleted or reordered",
+			},
+		},
+		"restarted": {
+			lines:   restarted,
+			entries: 5,
+			failures: []string{
+				"line 4: expected sequence 4, found 1; entries have been deleted or reordered",
+			},
+		},
+		"partial": {
+			lines:    valid[1:],
+			entries:  4,
+			failures: []string{"line 1: log starts at sequence 2, the entries before it are missing"},
+		},
+		"partial_allowed": {
+			lines:        valid[1:],
+			allowPartial: true,
+			entries:      4,
+		},
+		"not_chained": {
+			lines:    []string{`{"type":"request"}`},
+			entries:  1,
+			failures: []string{"line 1: entry has no chain"},
+		},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := &auditChainVerifier{
+				hash:         testAuditChainHashes,
+				prefix:       tc.prefix,
+				allowPartial: tc.allowPartial,
+				head:         tc.head,
+			}
+
+			require.NoError(t, v.verify(strings.NewReader(strings.Join(tc.lines, "\n")+"\n")))
+			require.Equal(t, tc.entries, v.entries)
+			require.Equal(t, tc.failures, v.failures)
+		})
+	}
+}
+
+// TestAuditChainVerifier_Batches ensures that entries are hashed in batches,
+// and that failures are still reported in the order of the log.
+func TestAuditChainVerifier_Batches(t *testing.T) {
+	t.Parallel()
+
+	var sequences []uint64
+	for i := uint64(1); i <= auditVerifyBatchSize+10; i++ {
+		sequences = append(sequences, i)
+	}
+	lines := testAuditChainLog(t, sequences...)
+	lines[1] = strings.Replace(lines[1], `"id":"2"`, `"id":"X"`, 1)
+	lines = append(lines[:4], lines[5:]...)
+
+	var calls int
+	v := &auditChainVerifier{
+		hash: func(inputs []string) ([]string, error) {
+			calls++
+			return testAuditChainHashes(inputs)
+		},
+	}
+
+	require.NoError(t, v.verify(strings.NewReader(strings.Join(lines, "\n")+"\n")))
+	require.Equal(t, 2, calls)
+	require.Equal(t, len(lines), v.entries)
+	require.Equal(t, []string{
+		"line 2: chain does not match; the entry, or the entry before it, has been modified",
+		"line 5: expected sequence 5, found 6; entries have been deleted or reordered",
+	}, v.failures)
+}
@@ -23,6 +23,7 @@ import (
 	_ "github.com/hashicorp/vault/helper/builtinplugins"
 
 	auditFile "github.com/hashicorp/vault/builtin/audit/file"
+	auditHTTP "github.com/hashicorp/vault/builtin/audit/http"
 	auditSocket "github.com/hashicorp/vault/builtin/audit/socket"
 	auditSyslog "github.com/hashicorp/vault/builtin/audit/syslog"
 
@@ -166,6 +167,7 @@ const (
 var (
 	auditBackends = map[string]audit.Factory{
 		"file":   auditFile.Factory,
+		"http":   auditHTTP.Factory,
 		"socket": auditSocket.Factory,
 		"syslog": auditSyslog.Factory,
 	}
@@ -287,6 +289,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"audit verify": func() (cli.Command, error) {
+			return &AuditVerifyCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"auth tune": func() (cli.Command, error) {
 			return &AuthTuneCommand{
 				BaseCommand: getBaseCommand(),
@@ -760,6 +767,16 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"transit decrypt-file": func() (cli.Command, error) {
+			return &TransitDecryptFileCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
+		"transit encrypt-file": func() (cli.Command, error) {
+			return &TransitEncryptFileCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"transit import": func() (cli.Command, error) {
 			return &TransitImportCommand{
 				BaseCommand: getBaseCommand(),
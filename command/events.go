@@ -4,14 +4,19 @@
 package command
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/command/token"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"nhooyr.io/websocket"
@@ -22,11 +27,59 @@ var (
 	_ cli.CommandAutocomplete = (*EventsSubscribeCommands)(nil)
 )
 
+const (
+	eventsFormatTable = "table"
+	eventsFormatJSON  = "json"
+	eventsFormatJSONL = "jsonl"
+)
+
 type EventsSubscribeCommands struct {
 	*BaseCommand
 
 	namespaces  []string
 	bexprFilter string
+	format      string
+	exec        string
+
+	printedHeader bool
+}
+
+// eventMessage is the subset of the CloudEvents JSON for an event that is
+// used when formatting it or passing it to a command.
+type eventMessage struct {
+	ID   string `json:"id"`
+	Time string `json:"time"`
+	Data struct {
+		Event struct {
+			ID       string                 `json:"id"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"event"`
+		EventType  string `json:"event_type"`
+		Namespace  string `json:"namespace"`
+		PluginInfo struct {
+			MountClass    string `json:"mount_class"`
+			MountAccessor string `json:"mount_accessor"`
+			MountPath     string `json:"mount_path"`
+			Plugin        string `json:"plugin"`
+		} `json:"plugin_info"`
+	} `json:"data"`
+}
+
+// metadata returns the value of the metadata field as a string, or the empty
+// string if it is not set.
+func (e *eventMessage) metadata(field string) string {
+	v, ok := e.Data.Event.Metadata[field]
+	if !ok || v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
 }
 
 func (c *EventsSubscribeCommands) Synopsis() string {
@@ -35,14 +88,22 @@ func (c *EventsSubscribeCommands) Synopsis() string {
 
 func (c *EventsSubscribeCommands) Help() string {
 	helpText := `
-Usage: vault events subscribe [-namespaces=ns1] [-timeout=XYZs] [-filter=filterExpression] eventType
+Usage: vault events subscribe [-namespaces=ns1] [-timeout=XYZs] [-filter=filterExpression] [-format=jsonl] [-exec=command] eventType
 
   Subscribe to events of the given event type (topic), which may be a glob
   pattern (with "*" treated as a wildcard). The events will be sent to
   standard out.
 
-  The output will be a JSON object serialized using the default protobuf
-  JSON serialization format, with one line per event received.
+  By default, the output will be a JSON object serialized using the default
+  protobuf JSON serialization format, with one line per event received.
+
+  Print a summary of each event as a row of a table:
+
+      $ vault events subscribe -format=table 'kv*'
+
+  Run a command for each event, with the event on standard in:
+
+      $ vault events subscribe -exec='./invalidate.sh' kv-v2/data-write
 ` + c.Flags().Help()
 	return strings.TrimSpace(helpText)
 }
@@ -70,6 +131,29 @@ func (c *EventsSubscribeCommands) Flags() *FlagSets {
 		Default: []string{},
 		Target:  &c.namespaces,
 	})
+	f.StringVar(&StringVar{
+		Name:       "format",
+		Default:    eventsFormatJSONL,
+		Target:     &c.format,
+		Completion: complete.PredictSet(eventsFormatTable, eventsFormatJSON, eventsFormatJSONL),
+		Usage: `Print the events in the given format. Valid formats are "table",
+                "json" (indented JSON) and "jsonl" (one line of JSON per event).
+                This is ignored when -exec is set.`,
+	})
+	f.StringVar(&StringVar{
+		Name:    "exec",
+		Default: "",
+		Target:  &c.exec,
+		Usage: `Command to run, using the shell, for each event received instead of
+                printing it. The event JSON is written to the standard input of the
+                command, and is described by the VAULT_EVENT_ID, VAULT_EVENT_TYPE,
+                VAULT_EVENT_TIME, VAULT_EVENT_NAMESPACE, VAULT_EVENT_MOUNT_PATH,
+                VAULT_EVENT_PLUGIN and VAULT_EVENT_METADATA_<FIELD> environment
+                variables. VAULT_TOKEN, VAULT_CLIENT_CERT, VAULT_CLIENT_KEY and
+                VAULT_MFA are removed from the environment of the command. Events
+                are processed one at a time, and a command that fails does not
+                stop the subscription.`,
+	})
 	return set
 }
 
@@ -99,6 +183,13 @@ func (c *EventsSubscribeCommands) Run(args []string) int {
 		return 1
 	}
 
+	switch c.format {
+	case eventsFormatTable, eventsFormatJSON, eventsFormatJSONL:
+	default:
+		c.UI.Error(fmt.Sprintf("Invalid format %q: must be one of %q, %q or %q", c.format, eventsFormatTable, eventsFormatJSON, eventsFormatJSONL))
+		return 1
+	}
+
 	client, err := c.Client()
 	if err != nil {
 		c.UI.Error(err.Error())
@@ -189,9 +280,129 @@ func (c *EventsSubscribeCommands) subscribeRequest(client *api.Client, path stri
 		if err != nil {
 			return err
 		}
-		_, err = os.Stdout.Write(message)
+		err = c.handleMessage(message)
 		if err != nil {
 			return err
 		}
 	}
 }
+
+// handleMessage prints the event JSON in the requested format, or runs the
+// -exec command for it.
+func (c *EventsSubscribeCommands) handleMessage(message []byte) error {
+	if c.exec == "" && c.format == eventsFormatJSONL {
+		c.UI.Output(string(bytes.TrimSpace(message)))
+		return nil
+	}
+
+	var event eventMessage
+	if err := json.Unmarshal(message, &event); err != nil {
+		return fmt.Errorf("error decoding event: %w", err)
+	}
+
+	if c.exec != "" {
+		c.execEvent(message, &event)
+		return nil
+	}
+
+	switch c.format {
+	case eventsFormatJSON:
+		var out bytes.Buffer
+		if err := json.Indent(&out, message, "", "  "); err != nil {
+			return fmt.Errorf("error formatting event: %w", err)
+		}
+		c.UI.Output(out.String())
+	case eventsFormatTable:
+		if !c.printedHeader {
+			c.UI.Output(eventsTableRow("Time", "Namespace", "Event Type", "Path", "Operation"))
+			c.printedHeader = true
+		}
+		path := event.metadata("data_path")
+		if path == "" {
+			path = event.metadata("path")
+		}
+		namespace := event.Data.Namespace
+		if namespace == "" {
+			namespace = "root"
+		}
+		c.UI.Output(eventsTableRow(event.Time, namespace, event.Data.EventType, path, event.metadata("operation")))
+	}
+	return nil
+}
+
+// eventsTableRow formats a row of the table output. Since events are printed
+// as they are received, the columns have fixed widths.
+func eventsTableRow(time, namespace, eventType, path, operation string) string {
+	return strings.TrimRight(fmt.Sprintf("%-35s  %-12s  %-28s  %-30s  %s", time, namespace, eventType, path, operation), " ")
+}
+
+// execEvent runs the -exec command for an event. Errors are reported, but do
+// not end the subscription.
+func (c *EventsSubscribeCommands) execEvent(message []byte, event *eventMessage) {
+	cmd, err := token.ExecScript(c.exec)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error running command for event %s: %s", event.ID, err))
+		return
+	}
+	cmd.Env = append(eventExecBaseEnv(os.Environ()), eventExecEnv(event)...)
+	cmd.Stdin = bytes.NewReader(message)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running command for event %s: %s", event.ID, err))
+	}
+}
+
+// eventExecCredentialEnv are the environment variables holding credentials
+// for Vault, which are not passed on to the -exec command.
+var eventExecCredentialEnv = []string{
+	api.EnvVaultToken,
+	api.EnvVaultClientCert,
+	api.EnvVaultClientKey,
+	api.EnvVaultMFA,
+}
+
+// eventExecBaseEnv returns the given environment without the variables
+// holding credentials for Vault, so that the -exec command does not act with
+// the token used to subscribe.
+func eventExecBaseEnv(environ []string) []string {
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if strutil.StrListContains(eventExecCredentialEnv, name) {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// eventExecEnv returns the environment variables describing an event that are
+// passed to the -exec command.
+func eventExecEnv(event *eventMessage) []string {
+	env := []string{
+		"VAULT_EVENT_ID=" + event.ID,
+		"VAULT_EVENT_TYPE=" + event.Data.EventType,
+		"VAULT_EVENT_TIME=" + event.Time,
+		"VAULT_EVENT_NAMESPACE=" + event.Data.Namespace,
+		"VAULT_EVENT_MOUNT_PATH=" + event.Data.PluginInfo.MountPath,
+		"VAULT_EVENT_PLUGIN=" + event.Data.PluginInfo.Plugin,
+	}
+
+	fields := make([]string, 0, len(event.Data.Event.Metadata))
+	for field := range event.Data.Event.Metadata {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		name := strings.ToUpper(strings.Map(func(r rune) rune {
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return '_'
+		}, field))
+		env = append(env, "VAULT_EVENT_METADATA_"+name+"="+event.metadata(field))
+	}
+	return env
+}
@@ -4,10 +4,14 @@
 package command
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/require"
 )
 
 func testEventsSubscribeCommand(tb testing.TB) (*cli.MockUi, *EventsSubscribeCommands) {
@@ -43,6 +47,12 @@ func TestEventsSubscribeCommand_Run(t *testing.T) {
 			"Too many arguments",
 			1,
 		},
+		{
+			"invalid_format",
+			[]string{"-format=yaml", "foo"},
+			"Invalid format",
+			1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -69,3 +79,90 @@ func TestEventsSubscribeCommand_Run(t *testing.T) {
 		})
 	}
 }
+
+const testEventMessage = `{"id":"a3be9fb1-b514-519f-5b25-b6f144a8c1ce","source":"https://vaultproject.io/","specversion":"1.0","type":"*","data":{"event":{"id":"a3be9fb1-b514-519f-5b25-b6f144a8c1ce","metadata":{"current_version":"1","data_path":"secret/data/foo","modified":"true","operation":"data-write","path":"secret/data/foo"}},"event_type":"kv-v2/data-write","plugin_info":{"mount_class":"secret","mount_accessor":"kv_5dc4d18e","mount_path":"secret/","plugin":"kv"}},"datacontentype":"application/cloudevents","time":"2023-09-12T15:19:49.394915-07:00"}`
+
+// TestEventsSubscribeCommand_Format tests that events are printed in each of the output formats.
+func TestEventsSubscribeCommand_Format(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string][]string{
+		"jsonl": {testEventMessage},
+		"json":  {"{\n  \"id\": \"a3be9fb1-b514-519f-5b25-b6f144a8c1ce\",", "\"event_type\": \"kv-v2/data-write\","},
+		"table": {"Time", "Event Type", "2023-09-12T15:19:49.394915-07:00", "root", "kv-v2/data-write", "secret/data/foo", "data-write"},
+	}
+
+	for format, expected := range cases {
+		format, expected := format, expected
+
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			ui, cmd := testEventsSubscribeCommand(t)
+			cmd.format = format
+
+			require.NoError(t, cmd.handleMessage([]byte(testEventMessage)))
+			require.NoError(t, cmd.handleMessage([]byte(testEventMessage)))
+
+			out := ui.OutputWriter.String()
+			for _, e := range expected {
+				require.Contains(t, out, e)
+			}
+			if format == "table" {
+				require.Equal(t, 1, strings.Count(out, "Event Type"))
+			}
+		})
+	}
+}
+
+// TestEventsSubscribeCommand_Exec tests that the -exec command receives the event on stdin and in
+// its environment.
+func TestEventsSubscribeCommand_Exec(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("test uses a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	ui, cmd := testEventsSubscribeCommand(t)
+	cmd.exec = `cat > "` + filepath.Join(dir, "stdin") + `"; echo "$VAULT_EVENT_TYPE $VAULT_EVENT_MOUNT_PATH $VAULT_EVENT_METADATA_DATA_PATH" > "` + filepath.Join(dir, "env") + `"`
+
+	require.NoError(t, cmd.handleMessage([]byte(testEventMessage)))
+	require.Empty(t, ui.OutputWriter.String())
+	require.Empty(t, ui.ErrorWriter.String())
+
+	stdin, err := os.ReadFile(filepath.Join(dir, "stdin"))
+	require.NoError(t, err)
+	require.Equal(t, testEventMessage, string(stdin))
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	require.NoError(t, err)
+	require.Equal(t, "kv-v2/data-write secret/ secret/data/foo\n", string(env))
+
+	// A failing command is reported, but does not end the subscription.
+	cmd.exec = "exit 3"
+	require.NoError(t, cmd.handleMessage([]byte(testEventMessage)))
+	require.Contains(t, ui.ErrorWriter.String(), "Error running command for event a3be9fb1-b514-519f-5b25-b6f144a8c1ce")
+}
+
+// TestEventExecBaseEnv tests that the credentials used to subscribe are not
+// passed on to the -exec command.
+func TestEventExecBaseEnv(t *testing.T) {
+	t.Parallel()
+
+	env := eventExecBaseEnv([]string{
+		"PATH=/bin",
+		"VAULT_ADDR=https://vault:8200",
+		"VAULT_TOKEN=s.secret",
+		"VAULT_CLIENT_KEY=/etc/vault/key.pem",
+		"VAULT_CLIENT_CERT=/etc/vault/cert.pem",
+		"VAULT_MFA=totp:123456",
+		"VAULT_TOKEN_FILE=/tmp/token",
+	})
+	require.Equal(t, []string{
+		"PATH=/bin",
+		"VAULT_ADDR=https://vault:8200",
+		"VAULT_TOKEN_FILE=/tmp/token",
+	}, env)
+}
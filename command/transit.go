@@ -31,6 +31,10 @@ Usage: vault transit <subcommand> [options] [args]
 
   $ vault transit import transit/keys/newly-imported @path/to/key type=rsa-2048
 
+  To encrypt a large file with a data key generated by a Transit key:
+
+  $ vault transit encrypt-file transit/keys/my-key backup.tar backup.tar.enc
+
   Please see the individual subcommand help for detailed usage information.
 `
 
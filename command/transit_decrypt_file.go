@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*TransitDecryptFileCommand)(nil)
+	_ cli.CommandAutocomplete = (*TransitDecryptFileCommand)(nil)
+)
+
+type TransitDecryptFileCommand struct {
+	*BaseCommand
+
+	flagContext string
+
+	testStdin  io.Reader // for tests
+	testStdout io.Writer // for tests
+}
+
+func (c *TransitDecryptFileCommand) Synopsis() string {
+	return "Decrypt a file encrypted with \"vault transit encrypt-file\"."
+}
+
+func (c *TransitDecryptFileCommand) Help() string {
+	helpText := `
+Usage: vault transit decrypt-file [options] PATH INPUT OUTPUT
+
+  Decrypts the contents of INPUT, encrypted with "vault transit encrypt-file",
+  into OUTPUT. The data key stored in INPUT is decrypted by the Transit key
+  whose API path is PATH, in the form :mount:/keys/:name:, and every chunk of
+  the file is verified before it is written. Use "-" for INPUT or OUTPUT to
+  read from stdin or write to stdout respectively.
+
+  If INPUT was tampered with or truncated, decryption fails and OUTPUT is
+  removed. When writing to stdout, the chunks preceding the failure will
+  already have been written, so the exit code must be checked before using
+  the output.
+
+  Decrypt a backup archive:
+
+      $ vault transit decrypt-file transit/keys/backups backup.tar.enc backup.tar
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *TransitDecryptFileCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "context",
+		Target:     &c.flagContext,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage: "Base64 encoded context for key derivation. Required if key " +
+			"derivation is enabled on the Transit key.",
+	})
+
+	return set
+}
+
+func (c *TransitDecryptFileCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *TransitDecryptFileCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+// error codes: 1: user error, 2: internal computation error, 3: remote api call error
+func (c *TransitDecryptFileCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) != 3 {
+		c.UI.Error(fmt.Sprintf("Incorrect argument count (expected 3, got %d). Wanted PATH of the key, INPUT and OUTPUT.", len(args)))
+		return 1
+	}
+
+	mount, name, err := transitKeyMountAndName(args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	rawInput, closeInput, err := openTransitStreamInput(args[1], c.testStdin)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	defer closeInput()
+	input := bufio.NewReader(rawInput)
+
+	header, err := readTransitStreamHeader(input)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	data := map[string]interface{}{
+		"ciphertext": header.wrappedKey,
+	}
+	if c.flagContext != "" {
+		data["context"] = c.flagContext
+	}
+	secret, err := client.Logical().Write(mount+"/decrypt/"+name, data)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error decrypting data key: %s", err))
+		return 3
+	}
+	if secret == nil || secret.Data == nil {
+		c.UI.Error("Error decrypting data key: empty response")
+		return 3
+	}
+	plaintextKey, _ := secret.Data["plaintext"].(string)
+	key, err := base64.StdEncoding.DecodeString(plaintextKey)
+	if err != nil {
+		c.UI.Error("Error decrypting data key: invalid response")
+		return 3
+	}
+
+	decrypter, err := newTransitStreamDecrypter(input, key, header)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	output, closeOutput, err := createTransitStreamOutput(args[2], c.testStdout)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	_, err = io.Copy(output, decrypter)
+	if err := closeOutput(err == nil); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing output: %s", err))
+		return 2
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error decrypting: %s", err))
+		return 2
+	}
+
+	if args[2] != "-" {
+		c.UI.Output(fmt.Sprintf("Success! Decrypted %s to %s", args[1], args[2]))
+	}
+	return 0
+}
@@ -0,0 +1,239 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*TransitEncryptFileCommand)(nil)
+	_ cli.CommandAutocomplete = (*TransitEncryptFileCommand)(nil)
+)
+
+type TransitEncryptFileCommand struct {
+	*BaseCommand
+
+	flagContext   string
+	flagChunkSize int
+
+	testStdin  io.Reader // for tests
+	testStdout io.Writer // for tests
+}
+
+func (c *TransitEncryptFileCommand) Synopsis() string {
+	return "Encrypt a file of any size with a Transit data key."
+}
+
+func (c *TransitEncryptFileCommand) Help() string {
+	helpText := `
+Usage: vault transit encrypt-file [options] PATH INPUT OUTPUT
+
+  Encrypts the contents of INPUT into OUTPUT with a new data key generated by
+  the Transit key whose API path is PATH, in the form :mount:/keys/:name:.
+  The file is encrypted in chunks, using constant memory regardless of its
+  size, and the data key wrapped by the Transit key is stored in the output,
+  so that it can be decrypted with "vault transit decrypt-file". Use "-" for
+  INPUT or OUTPUT to read from stdin or write to stdout respectively.
+
+  Encrypt a backup archive:
+
+      $ vault transit encrypt-file transit/keys/backups backup.tar backup.tar.enc
+
+  Encrypt a stream with a derived key:
+
+      $ tar c data | vault transit encrypt-file -context=YmFja3Vwcw== \
+          transit/keys/backups - backup.tar.enc
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *TransitEncryptFileCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "context",
+		Target:     &c.flagContext,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage: "Base64 encoded context for key derivation. Required if key " +
+			"derivation is enabled on the Transit key.",
+	})
+
+	f.IntVar(&IntVar{
+		Name:       "chunk-size",
+		Target:     &c.flagChunkSize,
+		Default:    transitStreamDefaultChunkSize,
+		Completion: complete.PredictAnything,
+		Usage: fmt.Sprintf("Size in bytes of the plaintext chunks which are "+
+			"encrypted and authenticated individually; the memory used is "+
+			"proportional to it. Must be between %d and %d.",
+			transitStreamMinChunkSize, transitStreamMaxChunkSize),
+	})
+
+	return set
+}
+
+func (c *TransitEncryptFileCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictFiles("*")
+}
+
+func (c *TransitEncryptFileCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+// error codes: 1: user error, 2: internal computation error, 3: remote api call error
+func (c *TransitEncryptFileCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) != 3 {
+		c.UI.Error(fmt.Sprintf("Incorrect argument count (expected 3, got %d). Wanted PATH of the key, INPUT and OUTPUT.", len(args)))
+		return 1
+	}
+
+	mount, name, err := transitKeyMountAndName(args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	input, closeInput, err := openTransitStreamInput(args[1], c.testStdin)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	defer closeInput()
+
+	data := map[string]interface{}{
+		"bits": 256,
+	}
+	if c.flagContext != "" {
+		data["context"] = c.flagContext
+	}
+	secret, err := client.Logical().Write(mount+"/datakey/plaintext/"+name, data)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error generating data key: %s", err))
+		return 3
+	}
+	if secret == nil || secret.Data == nil {
+		c.UI.Error("Error generating data key: empty response")
+		return 3
+	}
+	wrappedKey, _ := secret.Data["ciphertext"].(string)
+	plaintextKey, _ := secret.Data["plaintext"].(string)
+	key, err := base64.StdEncoding.DecodeString(plaintextKey)
+	if err != nil || wrappedKey == "" {
+		c.UI.Error("Error generating data key: invalid response")
+		return 3
+	}
+
+	header, err := newTransitStreamHeader(wrappedKey, c.flagChunkSize)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	output, closeOutput, err := createTransitStreamOutput(args[2], c.testStdout)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	err = func() error {
+		encrypter, err := newTransitStreamEncrypter(output, key, header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(encrypter, input); err != nil {
+			return err
+		}
+		return encrypter.Close()
+	}()
+	if err := closeOutput(err == nil); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing output: %s", err))
+		return 2
+	}
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error encrypting: %s", err))
+		return 2
+	}
+
+	if args[2] != "-" {
+		c.UI.Output(fmt.Sprintf("Success! Encrypted %s to %s", args[1], args[2]))
+	}
+	return 0
+}
+
+// transitKeyMountAndName splits a key path of the form :mount:/keys/:name:.
+func transitKeyMountAndName(s string) (mount string, name string, err error) {
+	parts := keyPath.FindStringSubmatch(s)
+	if len(parts) != 3 || parts[2] == "" {
+		return "", "", errors.New("expected transit path and key name in the form :path:/keys/:name:")
+	}
+	return parts[1], parts[2], nil
+}
+
+// openTransitStreamInput opens the named file for reading, or stdin if the
+// name is "-".
+func openTransitStreamInput(name string, stdin io.Reader) (io.Reader, func(), error) {
+	if name == "-" {
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		return stdin, func() {}, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening input: %w", err)
+	}
+	return file, func() { file.Close() }, nil
+}
+
+// createTransitStreamOutput creates the named file for writing, or returns
+// stdout if the name is "-". The returned function closes the file, removing
+// it unless the output is complete, so that no partial output is left behind.
+func createTransitStreamOutput(name string, stdout io.Writer) (io.Writer, func(complete bool) error, error) {
+	if name == "-" {
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		return stdout, func(bool) error { return nil }, nil
+	}
+
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating output: %w", err)
+	}
+	return file, func(complete bool) error {
+		err := file.Close()
+		if !complete || err != nil {
+			os.Remove(name)
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Validate the `vault transit encrypt-file` and `vault transit decrypt-file`
+// commands work.
+func TestTransitEncryptDecryptFile(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	if err := client.Sys().Mount("transit", &api.MountInput{
+		Type: "transit",
+	}); err != nil {
+		t.Fatalf("transit mount error: %#v", err)
+	}
+	_, err := client.Logical().Write("transit/keys/backups", nil)
+	require.NoError(t, err)
+	_, err = client.Logical().Write("transit/keys/derived", map[string]interface{}{
+		"derived": true,
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	plaintext := make([]byte, 3*transitStreamMinChunkSize+100)
+	_, err = rand.Read(plaintext)
+	require.NoError(t, err)
+	plaintextPath := filepath.Join(dir, "backup.tar")
+	require.NoError(t, os.WriteFile(plaintextPath, plaintext, 0o600))
+
+	runTransit := func(args ...string) (int, string) {
+		stdout := bytes.NewBuffer(nil)
+		stderr := bytes.NewBuffer(nil)
+		code := RunCustom(append([]string{"transit"}, args...), &RunOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+			Client: client,
+		})
+		return code, stdout.String() + stderr.String()
+	}
+
+	encryptedPath := filepath.Join(dir, "backup.tar.enc")
+	code, combined := runTransit("encrypt-file", "-chunk-size=1024", "transit/keys/backups", plaintextPath, encryptedPath)
+	require.Equal(t, 0, code, combined)
+
+	decryptedPath := filepath.Join(dir, "backup.decrypted.tar")
+	code, combined = runTransit("decrypt-file", "transit/keys/backups", encryptedPath, decryptedPath)
+	require.Equal(t, 0, code, combined)
+	decrypted, err := os.ReadFile(decryptedPath)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	// Decrypting with another key fails, leaving no output behind
+	failedPath := filepath.Join(dir, "failed.tar")
+	code, _ = runTransit("decrypt-file", "-context=Zm9v", "transit/keys/derived", encryptedPath, failedPath)
+	require.NotEqual(t, 0, code)
+	require.NoFileExists(t, failedPath)
+
+	// Tampering with the encrypted file is detected
+	encrypted, err := os.ReadFile(encryptedPath)
+	require.NoError(t, err)
+	encrypted[len(encrypted)-1] ^= 1
+	require.NoError(t, os.WriteFile(encryptedPath, encrypted, 0o600))
+	code, _ = runTransit("decrypt-file", "transit/keys/backups", encryptedPath, failedPath)
+	require.NotEqual(t, 0, code)
+	require.NoFileExists(t, failedPath)
+
+	// Derived keys require the context
+	code, combined = runTransit("encrypt-file", "-context=Zm9v", "transit/keys/derived", plaintextPath, encryptedPath)
+	require.Equal(t, 0, code, combined)
+	code, _ = runTransit("decrypt-file", "-context=YmFy", "transit/keys/derived", encryptedPath, failedPath)
+	require.NotEqual(t, 0, code)
+	code, combined = runTransit("decrypt-file", "-context=Zm9v", "transit/keys/derived", encryptedPath, decryptedPath)
+	require.Equal(t, 0, code, combined)
+	decrypted, err = os.ReadFile(decryptedPath)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+
+	code, _ = runTransit("encrypt-file", "transit/backups", plaintextPath, encryptedPath)
+	require.Equal(t, 1, code)
+}
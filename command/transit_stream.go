@@ -0,0 +1,307 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The streaming envelope format used by "vault transit encrypt-file" and
+// "vault transit decrypt-file" consists of a header followed by a sequence
+// of segments.
+//
+// The header holds, in order:
+//
+//   - the magic bytes "VTSTREAM" and the format version, 1;
+//   - the plaintext chunk size, as a big-endian uint32;
+//   - a random 7 byte nonce prefix;
+//   - the length of the wrapped data key, as a big-endian uint16, followed
+//     by the wrapped data key, i.e. the transit ciphertext of the data key.
+//
+// The plaintext is split into chunks of the chunk size, the last of which
+// may be shorter or empty, and each chunk is encrypted into a segment with
+// AES-256-GCM under the data key. The nonce of each segment is the nonce
+// prefix, followed by the segment index as a big-endian uint32, followed by
+// a byte which is 1 for the last segment and 0 otherwise, as in the STREAM
+// construction of Hoang, Reyhanitabar, Rogaway and Vizár. The header is the
+// additional data of every segment, so that segments cannot be reordered,
+// dropped, truncated or moved between streams without being detected.
+const (
+	transitStreamMagic            = "VTSTREAM"
+	transitStreamVersion          = 1
+	transitStreamNoncePrefixSize  = 7
+	transitStreamDefaultChunkSize = 64 * 1024
+	transitStreamMinChunkSize     = 1024
+	transitStreamMaxChunkSize     = 16 * 1024 * 1024
+	transitStreamMaxSegments      = 1 << 32
+)
+
+var errTransitStreamTruncated = errors.New("encrypted stream is truncated")
+
+// transitStreamHeader is the header of an encrypted stream.
+type transitStreamHeader struct {
+	chunkSize   int
+	noncePrefix []byte
+	wrappedKey  string
+
+	// raw is the encoded header, used as the additional data of every
+	// segment
+	raw []byte
+}
+
+// newTransitStreamHeader returns the header of a new encrypted stream with a
+// random nonce prefix.
+func newTransitStreamHeader(wrappedKey string, chunkSize int) (*transitStreamHeader, error) {
+	if chunkSize < transitStreamMinChunkSize || chunkSize > transitStreamMaxChunkSize {
+		return nil, fmt.Errorf("chunk size must be between %d and %d bytes", transitStreamMinChunkSize, transitStreamMaxChunkSize)
+	}
+	if len(wrappedKey) == 0 || len(wrappedKey) > 0xffff {
+		return nil, fmt.Errorf("invalid wrapped key length %d", len(wrappedKey))
+	}
+
+	noncePrefix := make([]byte, transitStreamNoncePrefixSize)
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+
+	raw := []byte(transitStreamMagic)
+	raw = append(raw, transitStreamVersion)
+	raw = binary.BigEndian.AppendUint32(raw, uint32(chunkSize))
+	raw = append(raw, noncePrefix...)
+	raw = binary.BigEndian.AppendUint16(raw, uint16(len(wrappedKey)))
+	raw = append(raw, wrappedKey...)
+
+	return &transitStreamHeader{
+		chunkSize:   chunkSize,
+		noncePrefix: noncePrefix,
+		wrappedKey:  wrappedKey,
+		raw:         raw,
+	}, nil
+}
+
+// readTransitStreamHeader reads and parses the header of an encrypted stream.
+func readTransitStreamHeader(r io.Reader) (*transitStreamHeader, error) {
+	fixed := make([]byte, len(transitStreamMagic)+1+4+transitStreamNoncePrefixSize+2)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("input is not an encrypted stream: header is truncated")
+		}
+		return nil, err
+	}
+
+	if string(fixed[:len(transitStreamMagic)]) != transitStreamMagic {
+		return nil, errors.New("input is not an encrypted stream: bad magic bytes")
+	}
+	rest := fixed[len(transitStreamMagic):]
+	if rest[0] != transitStreamVersion {
+		return nil, fmt.Errorf("unsupported encrypted stream version %d", rest[0])
+	}
+	chunkSize := int(binary.BigEndian.Uint32(rest[1:5]))
+	if chunkSize < transitStreamMinChunkSize || chunkSize > transitStreamMaxChunkSize {
+		return nil, fmt.Errorf("invalid chunk size %d in encrypted stream header", chunkSize)
+	}
+	noncePrefix := rest[5 : 5+transitStreamNoncePrefixSize]
+	wrappedKeyLen := int(binary.BigEndian.Uint16(rest[5+transitStreamNoncePrefixSize:]))
+
+	wrappedKey := make([]byte, wrappedKeyLen)
+	if _, err := io.ReadFull(r, wrappedKey); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errors.New("input is not an encrypted stream: header is truncated")
+		}
+		return nil, err
+	}
+
+	return &transitStreamHeader{
+		chunkSize:   chunkSize,
+		noncePrefix: noncePrefix,
+		wrappedKey:  string(wrappedKey),
+		raw:         append(fixed, wrappedKey...),
+	}, nil
+}
+
+// nonce returns the nonce of the segment with the given index.
+func (h *transitStreamHeader) nonce(index uint64, last bool) []byte {
+	nonce := make([]byte, 0, transitStreamNoncePrefixSize+5)
+	nonce = append(nonce, h.noncePrefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, uint32(index))
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+func newTransitStreamAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("data key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// transitStreamEncrypter encrypts the plaintext written to it into an
+// encrypted stream. Close must be called to write the last segment.
+type transitStreamEncrypter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header *transitStreamHeader
+	index  uint64
+	chunk  []byte
+	out    []byte
+	closed bool
+}
+
+// newTransitStreamEncrypter writes the header to w and returns a writer
+// encrypting its input to w.
+func newTransitStreamEncrypter(w io.Writer, key []byte, header *transitStreamHeader) (io.WriteCloser, error) {
+	aead, err := newTransitStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header.raw); err != nil {
+		return nil, err
+	}
+	return &transitStreamEncrypter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		chunk:  make([]byte, 0, header.chunkSize),
+		out:    make([]byte, 0, header.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (e *transitStreamEncrypter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypted stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		// A full chunk is only written once more plaintext arrives, as the
+		// last segment must be flagged as such
+		if len(e.chunk) == e.header.chunkSize {
+			if err := e.writeSegment(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.chunk[len(e.chunk):cap(e.chunk)], p)
+		e.chunk = e.chunk[:len(e.chunk)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last segment. It does not close the underlying writer.
+func (e *transitStreamEncrypter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.writeSegment(true)
+}
+
+func (e *transitStreamEncrypter) writeSegment(last bool) error {
+	if e.index >= transitStreamMaxSegments {
+		return errors.New("plaintext is too large for the chunk size")
+	}
+	e.out = e.aead.Seal(e.out[:0], e.header.nonce(e.index, last), e.chunk, e.header.raw)
+	e.index++
+	e.chunk = e.chunk[:0]
+	_, err := e.w.Write(e.out)
+	return err
+}
+
+// transitStreamDecrypter reads and verifies the segments of an encrypted
+// stream, returning the plaintext of each segment once it has been verified.
+type transitStreamDecrypter struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  *transitStreamHeader
+	index   uint64
+	segment []byte
+	plain   []byte
+	pending []byte
+	done    bool
+}
+
+// newTransitStreamDecrypter returns a reader decrypting the segments read
+// from r, which must be positioned just after the header.
+func newTransitStreamDecrypter(r *bufio.Reader, key []byte, header *transitStreamHeader) (io.Reader, error) {
+	aead, err := newTransitStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &transitStreamDecrypter{
+		r:       r,
+		aead:    aead,
+		header:  header,
+		segment: make([]byte, header.chunkSize+aead.Overhead()),
+		plain:   make([]byte, 0, header.chunkSize),
+	}, nil
+}
+
+func (d *transitStreamDecrypter) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.readSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+func (d *transitStreamDecrypter) readSegment() error {
+	if d.index >= transitStreamMaxSegments {
+		return errors.New("encrypted stream has too many segments")
+	}
+
+	n, err := io.ReadFull(d.r, d.segment)
+	last := false
+	switch {
+	case err == io.EOF:
+		return errTransitStreamTruncated
+	case err == io.ErrUnexpectedEOF:
+		// Only the last segment may be shorter than a full segment
+		last = true
+	case err != nil:
+		return err
+	default:
+		// A full segment is the last one if nothing follows it
+		if _, err := d.r.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	plain, err := d.aead.Open(d.plain[:0], d.header.nonce(d.index, last), d.segment[:n], d.header.raw)
+	if err != nil {
+		if last {
+			// Either the segment was tampered with, or the stream was
+			// truncated just after a full segment
+			return fmt.Errorf("failed to decrypt segment %d: encrypted stream is corrupt or truncated", d.index)
+		}
+		return fmt.Errorf("failed to decrypt segment %d: encrypted stream is corrupt", d.index)
+	}
+
+	d.index++
+	d.pending = plain
+	d.done = last
+	return nil
+}
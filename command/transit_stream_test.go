@@ -0,0 +1,154 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func encryptTransitStream(t *testing.T, key, plaintext []byte, chunkSize int) []byte {
+	t.Helper()
+
+	header, err := newTransitStreamHeader("vault:v1:wrapped", chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	encrypter, err := newTransitStreamEncrypter(&buf, key, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write in uneven pieces to exercise the chunking
+	for remaining := plaintext; len(remaining) > 0; {
+		n := 1 + len(remaining)/3
+		if _, err := encrypter.Write(remaining[:n]); err != nil {
+			t.Fatal(err)
+		}
+		remaining = remaining[n:]
+	}
+	if err := encrypter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decryptTransitStream(key, ciphertext []byte) ([]byte, error) {
+	input := bufio.NewReader(bytes.NewReader(ciphertext))
+	header, err := readTransitStreamHeader(input)
+	if err != nil {
+		return nil, err
+	}
+	if header.wrappedKey != "vault:v1:wrapped" {
+		return nil, fmt.Errorf("bad wrapped key %q", header.wrappedKey)
+	}
+	decrypter, err := newTransitStreamDecrypter(input, key, header)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decrypter)
+}
+
+func TestTransitStream_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+
+	chunkSize := transitStreamMinChunkSize
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize, 5*chunkSize + 17} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			plaintext := make([]byte, size)
+			if _, err := rand.Read(plaintext); err != nil {
+				t.Fatal(err)
+			}
+
+			ciphertext := encryptTransitStream(t, key, plaintext, chunkSize)
+			decrypted, err := decryptTransitStream(key, ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Fatal("decrypted plaintext does not match")
+			}
+		})
+	}
+}
+
+func TestTransitStream_Tampering(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+
+	chunkSize := transitStreamMinChunkSize
+	plaintext := make([]byte, 3*chunkSize)
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	ciphertext := encryptTransitStream(t, key, plaintext, chunkSize)
+	header, err := readTransitStreamHeader(bytes.NewReader(ciphertext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := len(header.raw)
+	segmentLen := chunkSize + 16
+
+	segment := func(i int) []byte {
+		return ciphertext[headerLen+i*segmentLen : headerLen+(i+1)*segmentLen]
+	}
+	concat := func(parts ...[]byte) []byte {
+		var out []byte
+		for _, part := range parts {
+			out = append(out, part...)
+		}
+		return out
+	}
+
+	flipped := bytes.Clone(ciphertext)
+	flipped[headerLen+segmentLen+10] ^= 1
+
+	flippedHeader := bytes.Clone(ciphertext)
+	flippedHeader[headerLen-1] ^= 1
+
+	wrongKey := bytes.Clone(key)
+	wrongKey[0] ^= 1
+
+	tests := map[string]struct {
+		key        []byte
+		ciphertext []byte
+	}{
+		"flipped bit":              {key, flipped},
+		"modified header":          {key, flippedHeader},
+		"wrong key":                {wrongKey, ciphertext},
+		"truncated after segment":  {key, ciphertext[:headerLen+2*segmentLen]},
+		"truncated within segment": {key, ciphertext[:headerLen+2*segmentLen+5]},
+		"truncated after header":   {key, ciphertext[:headerLen]},
+		"truncated header":         {key, ciphertext[:headerLen-1]},
+		"reordered segments":       {key, concat(ciphertext[:headerLen], segment(1), segment(0), segment(2))},
+		"dropped segment":          {key, concat(ciphertext[:headerLen], segment(0), segment(2))},
+		"appended data":            {key, concat(ciphertext, []byte{0})},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			if _, err := decryptTransitStream(test.key, test.ciphertext); err == nil {
+				t.Fatal("expected error decrypting")
+			}
+		})
+	}
+
+	if _, err := newTransitStreamHeader("vault:v1:wrapped", transitStreamMinChunkSize-1); err == nil {
+		t.Fatal("expected error for too small chunk size")
+	}
+}
@@ -46,6 +46,35 @@ func TestEncrypt(t *testing.T) {
 	}
 }
 
+func TestEncryptWithAlgorithm(t *testing.T) {
+	ContentEncryptionAlgorithm = EncryptionAlgorithmDESCBC
+
+	plaintext := []byte("Hello Secret World!")
+	cert, err := createTestCertificate(x509.SHA256WithRSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := EncryptWithAlgorithm(plaintext, []*x509.Certificate{cert.Certificate}, EncryptionAlgorithmAES256CBC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p7, err := Parse(encrypted)
+	if err != nil {
+		t.Fatalf("cannot Parse encrypted result: %s", err)
+	}
+	envelope := p7.raw.(envelopedData)
+	if alg := envelope.EncryptedContentInfo.ContentEncryptionAlgorithm.Algorithm; !alg.Equal(OIDEncryptionAlgorithmAES256CBC) {
+		t.Errorf("expected content to be encrypted with AES-256-CBC, got %s", alg)
+	}
+	result, err := p7.Decrypt(cert.Certificate, *cert.PrivateKey)
+	if err != nil {
+		t.Fatalf("cannot Decrypt encrypted result: %s", err)
+	}
+	if !bytes.Equal(plaintext, result) {
+		t.Errorf("encrypted data does not match plaintext:\n\tExpected: %s\n\tActual: %s", plaintext, result)
+	}
+}
+
 func TestEncryptUsingPSK(t *testing.T) {
 	modes := []int{
 		EncryptionAlgorithmDESCBC,
@@ -38,6 +38,7 @@ type eventSubscriber struct {
 	namespacePatterns []string
 	pattern           string
 	bexprFilter       string
+	since             string
 	json              bool
 	checkCache        *cache.Cache
 	isRootToken       bool
@@ -53,7 +54,11 @@ func (sub *eventSubscriber) handleEventsSubscribeWebsocket() {
 	ctx := sub.ctx
 	logger := sub.logger
 	// subscribe before accept to avoid race conditions
-	ch, cancel, err := sub.events.SubscribeMultipleNamespaces(ctx, sub.namespacePatterns, sub.pattern, sub.bexprFilter)
+	ch, cancel, err := sub.events.SubscribeMultipleNamespacesSince(ctx, sub.namespacePatterns, sub.pattern, sub.bexprFilter, sub.since)
+	if errors.Is(err, eventbus.ErrEventNotInHistory) {
+		respondError(sub.w, http.StatusGone, fmt.Errorf("event %q is no longer in the event history", sub.since))
+		return
+	}
 	if err != nil {
 		logger.Info("Error subscribing", "error", err)
 		sub.w.WriteHeader(400)
@@ -61,7 +66,7 @@ func (sub *eventSubscriber) handleEventsSubscribeWebsocket() {
 		return
 	}
 	defer cancel()
-	logger.Debug("WebSocket is subscribed to messages", "namespaces", sub.namespacePatterns, "event_types", sub.pattern, "bexpr_filter", sub.bexprFilter)
+	logger.Debug("WebSocket is subscribed to messages", "namespaces", sub.namespacePatterns, "event_types", sub.pattern, "bexpr_filter", sub.bexprFilter, "since", sub.since)
 
 	conn, err := websocket.Accept(sub.w, sub.r, nil)
 	if err != nil {
@@ -278,6 +283,7 @@ func handleEventsSubscribe(core *vault.Core, req *logical.Request) http.Handler
 		}
 
 		bexprFilter := strings.TrimSpace(r.URL.Query().Get("filter"))
+		since := strings.TrimSpace(r.URL.Query().Get("since"))
 		namespacePatterns := r.URL.Query()["namespaces"]
 		namespacePatterns = prependNamespacePatterns(namespacePatterns, ns)
 		isRoot := entry.IsRoot()
@@ -292,6 +298,7 @@ func handleEventsSubscribe(core *vault.Core, req *logical.Request) http.Handler
 			namespacePatterns: namespacePatterns,
 			pattern:           pattern,
 			bexprFilter:       bexprFilter,
+			since:             since,
 			json:              json,
 			checkCache:        cache.New(webSocketRevalidationTime, webSocketRevalidationTime),
 			clientToken:       auth.ClientToken,
@@ -89,6 +89,7 @@ var (
 	injectDataIntoTopRoutes = []string{
 		"/v1/sys/audit",
 		"/v1/sys/audit/",
+		"/v1/sys/audit-chain/",
 		"/v1/sys/audit-hash/",
 		"/v1/sys/auth",
 		"/v1/sys/auth/",
@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
@@ -16,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/consts"
@@ -105,11 +107,12 @@ func buildLogicalRequestNoAuth(perfStandby bool, w http.ResponseWriter, r *http.
 		bufferedBody := newBufferedReader(r.Body)
 		r.Body = bufferedBody
 
-		// If we are uploading a snapshot or receiving an ocsp-request (which
-		// is der encoded) we don't want to parse it. Instead, we will simply
-		// add the HTTP request to the logical request object for later consumption.
+		// If we are uploading a snapshot or receiving an ocsp-request, an
+		// EST certificate request or a SCEP message (which are der encoded)
+		// we don't want to parse it. Instead, we will simply add the HTTP request to the
+		// logical request object for later consumption.
 		contentType := r.Header.Get("Content-Type")
-		if path == "sys/storage/raft/snapshot" || path == "sys/storage/raft/snapshot-force" || isOcspRequest(contentType) {
+		if path == "sys/storage/raft/snapshot" || path == "sys/storage/raft/snapshot-force" || isOcspRequest(contentType) || isPKCS10Request(contentType) || isSCEPRequest(contentType) {
 			passHTTPReq = true
 			origBody = r.Body
 		} else {
@@ -223,6 +226,24 @@ func isOcspRequest(contentType string) bool {
 	return contentType == "application/ocsp-request"
 }
 
+func isPKCS10Request(contentType string) bool {
+	contentType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return contentType == "application/pkcs10"
+}
+
+func isSCEPRequest(contentType string) bool {
+	contentType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return contentType == "application/x-pki-message"
+}
+
 func buildLogicalPath(r *http.Request) (string, int, error) {
 	ns, err := namespace.FromContext(r.Context())
 	if err != nil {
@@ -367,12 +388,28 @@ func handleLogicalInternal(core *vault.Core, injectDataIntoTopLevel bool, noForw
 			return
 		}
 
+		// EST clients authenticate through an auth mount instead of with a
+		// token; log them in before making the request.
+		var needsForward, ok bool
+		if req.ClientToken == "" {
+			needsForward, ok = handleESTLogin(core, w, r, req)
+			if !ok && !needsForward {
+				return
+			}
+			if req.ClientToken != "" {
+				defer core.RevokeESTClientToken(r.Context(), req.ClientToken)
+			}
+		}
+
 		// Make the internal request. We attach the connection info
 		// as well in case this is an authentication request that requires
 		// it. Vault core handles stripping this if we need to. This also
 		// handles all error cases; if we hit respondLogical, the request is a
 		// success.
-		resp, ok, needsForward := request(core, w, r, req)
+		var resp *logical.Response
+		if !needsForward {
+			resp, ok, needsForward = request(core, w, r, req)
+		}
 		switch {
 		case needsForward && noForward:
 			respondError(w, http.StatusBadRequest, vault.ErrCannotForwardLocalOnly)
@@ -396,6 +433,38 @@ func handleLogicalInternal(core *vault.Core, injectDataIntoTopLevel bool, noForw
 	})
 }
 
+// handleESTLogin logs in the client of an EST enrollment request, setting
+// the request's token on success. It returns whether the request needs to be
+// forwarded and whether it may proceed; otherwise an error has already been
+// written.
+func handleESTLogin(core *vault.Core, w http.ResponseWriter, r *http.Request, req *logical.Request) (bool, bool) {
+	username, password, _ := r.BasicAuth()
+	token, err := core.LoginESTClient(r.Context(), req, username, password)
+	switch {
+	case err == nil:
+		if token != "" {
+			req.ClientToken = token
+
+			// Keep the credentials out of the request passed on to the
+			// backend; the raw request still has them for forwarding.
+			req.Headers = r.Header.Clone()
+			delete(req.Headers, "Authorization")
+		}
+		return false, true
+	case errwrap.Contains(err, logical.ErrPerfStandbyPleaseForward.Error()):
+		return true, false
+	case errors.Is(err, vault.ErrESTCredentialsRequired):
+		w.Header().Set("WWW-Authenticate", `Basic realm="Vault EST"`)
+		respondError(w, http.StatusUnauthorized, err)
+		return false, false
+	default:
+		if !respondErrorCommon(w, req, nil, err) {
+			respondError(w, http.StatusInternalServerError, err)
+		}
+		return false, false
+	}
+}
+
 func respondLogical(core *vault.Core, w http.ResponseWriter, r *http.Request, req *logical.Request, resp *logical.Response, injectDataIntoTopLevel bool) {
 	var httpResp *logical.HTTPResponse
 	var ret interface{}
@@ -4,6 +4,7 @@
 package event
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
@@ -28,6 +29,16 @@ type options struct {
 	withSocketType  string
 	withMaxDuration time.Duration
 	withFileMode    *os.FileMode
+
+	withHeaders       map[string]string
+	withTLSConfig     *tls.Config
+	withBatchSize     int
+	withBatchInterval time.Duration
+	withMaxRetries    int
+	withRetryWaitMin  time.Duration
+	withRetryWaitMax  time.Duration
+	withSpoolPath     string
+	withSpoolMaxSize  int64
 }
 
 // getDefaultOptions returns Options with their default values.
@@ -41,6 +52,13 @@ func getDefaultOptions() options {
 		withSocketType:  "tcp",
 		withMaxDuration: 2 * time.Second,
 		withFileMode:    &fileMode,
+
+		withBatchSize:     100,
+		withBatchInterval: time.Second,
+		withMaxRetries:    3,
+		withRetryWaitMin:  500 * time.Millisecond,
+		withRetryWaitMax:  10 * time.Second,
+		withSpoolMaxSize:  100 * 1024 * 1024,
 	}
 }
 
@@ -189,3 +207,167 @@ func WithFileMode(mode string) Option {
 		return nil
 	}
 }
+
+// WithHeaders provides an Option to represent additional headers sent by a HTTP
+// sink.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *options) error {
+		if len(headers) > 0 {
+			o.withHeaders = headers
+		}
+
+		return nil
+	}
+}
+
+// WithTLSConfig provides an Option to represent the TLS configuration used by a
+// HTTP sink.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) error {
+		o.withTLSConfig = config
+
+		return nil
+	}
+}
+
+// WithBatchSize provides an Option to represent the maximum number of events
+// sent in a single batch by a HTTP sink.
+func WithBatchSize(size string) Option {
+	return func(o *options) error {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			return nil
+		}
+
+		parsed, err := strconv.Atoi(size)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse batch size: %w", err)
+		case parsed <= 0:
+			return errors.New("batch size must be greater than 0")
+		default:
+			o.withBatchSize = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithBatchInterval provides an Option to represent the maximum amount of time
+// events are held by a HTTP sink before the current batch is sent.
+func WithBatchInterval(interval string) Option {
+	return func(o *options) error {
+		interval = strings.TrimSpace(interval)
+		if interval == "" {
+			return nil
+		}
+
+		parsed, err := parseutil.ParseDurationSecond(interval)
+		switch {
+		case err != nil:
+			return err
+		case parsed <= 0:
+			return errors.New("batch interval must be greater than 0")
+		default:
+			o.withBatchInterval = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithMaxRetries provides an Option to represent the number of times a HTTP
+// sink retries sending a batch.
+func WithMaxRetries(retries string) Option {
+	return func(o *options) error {
+		retries = strings.TrimSpace(retries)
+		if retries == "" {
+			return nil
+		}
+
+		parsed, err := strconv.Atoi(retries)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse max retries: %w", err)
+		case parsed < 0:
+			return errors.New("max retries cannot be negative")
+		default:
+			o.withMaxRetries = parsed
+		}
+
+		return nil
+	}
+}
+
+// WithRetryWaitMin provides an Option to represent the initial backoff between
+// retries for a HTTP sink.
+func WithRetryWaitMin(wait string) Option {
+	return func(o *options) error {
+		wait = strings.TrimSpace(wait)
+		if wait == "" {
+			return nil
+		}
+
+		parsed, err := parseutil.ParseDurationSecond(wait)
+		if err != nil {
+			return err
+		}
+
+		o.withRetryWaitMin = parsed
+
+		return nil
+	}
+}
+
+// WithRetryWaitMax provides an Option to represent the maximum backoff between
+// retries for a HTTP sink.
+func WithRetryWaitMax(wait string) Option {
+	return func(o *options) error {
+		wait = strings.TrimSpace(wait)
+		if wait == "" {
+			return nil
+		}
+
+		parsed, err := parseutil.ParseDurationSecond(wait)
+		if err != nil {
+			return err
+		}
+
+		o.withRetryWaitMax = parsed
+
+		return nil
+	}
+}
+
+// WithSpoolPath provides an Option to represent the path of the file in which a
+// HTTP sink stores batches it was unable to deliver.
+func WithSpoolPath(path string) Option {
+	return func(o *options) error {
+		o.withSpoolPath = strings.TrimSpace(path)
+
+		return nil
+	}
+}
+
+// WithSpoolMaxSize provides an Option to represent the maximum size, in bytes,
+// of a HTTP sink's spool file.
+func WithSpoolMaxSize(size string) Option {
+	return func(o *options) error {
+		size = strings.TrimSpace(size)
+		if size == "" {
+			return nil
+		}
+
+		parsed, err := parseutil.ParseCapacityString(size)
+		switch {
+		case err != nil:
+			return fmt.Errorf("unable to parse spool max size: %w", err)
+		case parsed == 0:
+			return errors.New("spool max size must be greater than 0")
+		default:
+			o.withSpoolMaxSize = int64(parsed)
+		}
+
+		return nil
+	}
+}
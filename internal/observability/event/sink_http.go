@@ -0,0 +1,714 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+var (
+	_ eventlogger.Node   = (*HTTPSink)(nil)
+	_ eventlogger.Closer = (*HTTPSink)(nil)
+)
+
+// ErrHTTPSinkClosed is returned when an event is given to a HTTPSink which has
+// been closed.
+var ErrHTTPSinkClosed = errors.New("http sink is closed")
+
+// errSpoolFull is returned when an entry cannot be written to the spool without
+// exceeding its maximum size.
+var errSpoolFull = errors.New("spool is full")
+
+// HTTPSink is a sink node which handles delivering events to a HTTP endpoint.
+//
+// Without a spool, each event is sent in its own POST request, and the caller
+// waits for the endpoint to accept it, receiving an error if it did not, so
+// that a request is never reported as audited when its entry was lost. Such
+// requests are not retried, so that an unavailable endpoint fails requests
+// rather than stalling them.
+//
+// When a spool is configured, each event is appended to a size bounded spool
+// file on disk, and the caller returns as soon as it has been synced. A
+// background routine delivers the spooled events in batches, once the batch
+// size is reached or the batch interval elapses, retrying failed requests with
+// exponential backoff. Events are removed from the spool once the endpoint has
+// accepted them; during an outage they are kept until the endpoint recovers.
+type HTTPSink struct {
+	requiredFormat string
+	address        string
+	headers        map[string]string
+	client         *http.Client
+	maxDuration    time.Duration
+	batchSize      int
+	batchInterval  time.Duration
+	maxRetries     int
+	retryWaitMin   time.Duration
+	retryWaitMax   time.Duration
+	spool          *httpSpool
+
+	// lock protects closed, and adding to inflight.
+	lock   sync.Mutex
+	closed bool
+
+	// inflight tracks events being sent without a spool.
+	inflight sync.WaitGroup
+
+	// wake, stop and stopped coordinate the routine delivering the spool.
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewHTTPSink should be used to create a new HTTPSink.
+// Accepted options: WithMaxDuration, WithHeaders, WithTLSConfig, WithBatchSize,
+// WithBatchInterval, WithMaxRetries, WithRetryWaitMin, WithRetryWaitMax,
+// WithSpoolPath, WithSpoolMaxSize and WithFileMode. The batch and retry
+// options only apply to delivery from the spool.
+func NewHTTPSink(format string, address string, opt ...Option) (*HTTPSink, error) {
+	const op = "event.NewHTTPSink"
+
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, fmt.Errorf("%s: address is required: %w", op, ErrInvalidParameter)
+	}
+
+	// Batches are sent as newline delimited entries, which isn't a valid XML
+	// document.
+	if format == "jsonx" {
+		return nil, fmt.Errorf("%s: the jsonx format is not supported: %w", op, ErrInvalidParameter)
+	}
+
+	opts, err := getOpts(opt...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: error applying options: %w", op, err)
+	}
+
+	if opts.withRetryWaitMin > opts.withRetryWaitMax {
+		return nil, fmt.Errorf("%s: minimum retry wait %s exceeds maximum retry wait %s: %w", op, opts.withRetryWaitMin, opts.withRetryWaitMax, ErrInvalidParameter)
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+	if opts.withTLSConfig != nil {
+		transport.TLSClientConfig = opts.withTLSConfig
+	}
+
+	sink := &HTTPSink{
+		requiredFormat: format,
+		address:        address,
+		headers:        opts.withHeaders,
+		client:         &http.Client{Transport: transport},
+		maxDuration:    opts.withMaxDuration,
+		batchSize:      opts.withBatchSize,
+		batchInterval:  opts.withBatchInterval,
+		maxRetries:     opts.withMaxRetries,
+		retryWaitMin:   opts.withRetryWaitMin,
+		retryWaitMax:   opts.withRetryWaitMax,
+	}
+
+	if opts.withSpoolPath != "" {
+		sink.spool, err = newHTTPSpool(opts.withSpoolPath, opts.withSpoolMaxSize, *opts.withFileMode)
+		if err != nil {
+			return nil, fmt.Errorf("%s: unable to open spool: %w", op, err)
+		}
+
+		sink.wake = make(chan struct{}, 1)
+		sink.stop = make(chan struct{})
+		sink.stopped = make(chan struct{})
+		go sink.runSpool()
+	}
+
+	return sink, nil
+}
+
+// Process handles delivering the event to the HTTP endpoint. With a spool, it
+// returns once the event has been spooled; otherwise once the event has been
+// delivered. An error is returned if the event could not be spooled or
+// delivered.
+func (s *HTTPSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(HTTPSink).Process"
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("%s: event is nil: %w", op, ErrInvalidParameter)
+	}
+
+	formatted, found := e.Format(s.requiredFormat)
+	if !found {
+		return nil, fmt.Errorf("%s: unable to retrieve event formatted as %q", op, s.requiredFormat)
+	}
+
+	if err := s.Enqueue(ctx, formatted); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// return nil for the event to indicate the pipeline is complete.
+	return nil, nil
+}
+
+// Enqueue adds already formatted data to the spool, or sends it to the
+// endpoint and waits until it has been accepted. Entries which are not newline
+// terminated have a newline appended, so that each batch is sent as newline
+// delimited entries.
+func (s *HTTPSink) Enqueue(ctx context.Context, data []byte) error {
+	entry := make([]byte, len(data), len(data)+1)
+	copy(entry, data)
+	if !bytes.HasSuffix(entry, []byte("\n")) {
+		entry = append(entry, '\n')
+	}
+
+	if s.spool != nil {
+		return s.spoolEntry(entry)
+	}
+
+	return s.sendEntry(ctx, entry)
+}
+
+// spoolEntry appends the entry to the spool, and wakes the spool delivery
+// routine once a full batch is waiting.
+func (s *HTTPSink) spoolEntry(entry []byte) error {
+	const op = "event.(HTTPSink).spoolEntry"
+
+	s.lock.Lock()
+	closed := s.closed
+	s.lock.Unlock()
+	if closed {
+		return ErrHTTPSinkClosed
+	}
+
+	count, err := s.spool.append(entry)
+	if err != nil {
+		metrics.IncrCounter([]string{"audit", "http", "entry_dropped"}, 1)
+		return fmt.Errorf("%s: unable to spool entry: %w", op, err)
+	}
+
+	if count >= s.batchSize {
+		s.Flush()
+	}
+
+	return nil
+}
+
+// sendEntry sends a single entry to the endpoint, without retrying.
+func (s *HTTPSink) sendEntry(ctx context.Context, entry []byte) error {
+	const op = "event.(HTTPSink).sendEntry"
+
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return ErrHTTPSinkClosed
+	}
+	s.inflight.Add(1)
+	s.lock.Unlock()
+	defer s.inflight.Done()
+
+	if err := s.send(ctx, entry); err != nil {
+		metrics.IncrCounter([]string{"audit", "http", "entry_dropped"}, 1)
+		return fmt.Errorf("%s: entry was not delivered: %w", op, err)
+	}
+
+	metrics.IncrCounter([]string{"audit", "http", "entry_sent"}, 1)
+	return nil
+}
+
+// Flush wakes the routine delivering the spool, without waiting for the
+// spooled entries to be sent. It is a no-op without a spool.
+func (s *HTTPSink) Flush() {
+	if s.spool == nil {
+		return
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the HTTPSink accepting events, and waits for any events being
+// sent to be delivered. With a spool, a last attempt is made to deliver the
+// spooled entries; those which could not be delivered remain in the spool.
+func (s *HTTPSink) Close(ctx context.Context) error {
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.lock.Unlock()
+
+	if s.spool != nil {
+		close(s.stop)
+	}
+
+	delivered := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		if s.spool != nil {
+			<-s.stopped
+		}
+		close(delivered)
+	}()
+
+	select {
+	case <-delivered:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runSpool delivers the spooled entries whenever a full batch is waiting, or
+// the batch interval elapses, until the sink is closed.
+func (s *HTTPSink) runSpool() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.batchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			s.deliverSpool()
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+
+		s.deliverSpool()
+	}
+}
+
+// deliverSpool sends the spooled entries in batches, oldest first, removing
+// each batch from the spool once it has been delivered. It returns once the
+// spool is empty, or a batch could not be delivered; the remaining entries
+// are retried later.
+func (s *HTTPSink) deliverSpool() {
+	for {
+		entries, size, err := s.spool.next(s.batchSize)
+		if err != nil || len(entries) == 0 {
+			return
+		}
+
+		if err := s.sendWithRetries(bytes.Join(entries, nil)); err != nil {
+			metrics.IncrCounter([]string{"audit", "http", "batch_failed"}, 1)
+			return
+		}
+		metrics.IncrCounter([]string{"audit", "http", "batch_sent"}, 1)
+
+		if err := s.spool.remove(len(entries), size); err != nil {
+			return
+		}
+	}
+}
+
+// sendWithRetries attempts to send the body to the endpoint, retrying with
+// exponential backoff for up to the configured number of retries.
+func (s *HTTPSink) sendWithRetries(body []byte) error {
+	const op = "event.(HTTPSink).sendWithRetries"
+
+	var err error
+	wait := s.retryWaitMin
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(wait)
+			wait *= 2
+			if wait > s.retryWaitMax {
+				wait = s.retryWaitMax
+			}
+		}
+
+		if err = s.send(context.Background(), body); err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: giving up after %d attempts: %w", op, s.maxRetries+1, err)
+}
+
+// send makes a single POST request to the endpoint with the given body. Any
+// response other than a 2xx status is considered a failure.
+func (s *HTTPSink) send(ctx context.Context, body []byte) error {
+	const op = "event.(HTTPSink).send"
+
+	ctx, cancel := context.WithTimeout(ctx, s.maxDuration)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.address, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: unable to create request: %w", op, err)
+	}
+
+	req.Header.Set("Content-Type", s.contentType())
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: unable to send request: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status code %d from %q", op, resp.StatusCode, s.address)
+	}
+
+	return nil
+}
+
+// contentType returns the content type of a batch in the required format.
+func (s *HTTPSink) contentType() string {
+	switch s.requiredFormat {
+	case "cef":
+		return "text/plain"
+	default:
+		return "application/x-ndjson"
+	}
+}
+
+// Reopen is a no-op for a HTTP sink.
+func (_ *HTTPSink) Reopen() error {
+	return nil
+}
+
+// Type describes the type of this node (sink).
+func (_ *HTTPSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// httpSpoolCompactSize is the amount of delivered entries kept at the start of
+// the spool file before it is compacted.
+const httpSpoolCompactSize = 1024 * 1024
+
+// httpSpoolHeaderSize is the size of the header at the start of the spool
+// file, which holds the offset of the first entry that has not been delivered.
+const httpSpoolHeaderSize = 8
+
+// httpSpool is a size bounded file of length-prefixed entries which are
+// waiting to be delivered by a HTTPSink. Entries are appended to the end of
+// the file, and delivered from head onwards. The file is synced before an
+// entry is acknowledged, and head is stored in the file's header once entries
+// have been delivered, so that a crash neither loses acknowledged entries nor
+// sends delivered ones again, other than the last batch if the crash happens
+// before its removal is recorded.
+type httpSpool struct {
+	lock    sync.Mutex
+	path    string
+	maxSize int64
+	mode    os.FileMode
+
+	// size is the size of the file, head is the offset of the first entry
+	// which has not been delivered, and count is the number of entries from
+	// head onwards.
+	size  int64
+	head  int64
+	count int
+}
+
+// newHTTPSpool opens (or creates) the spool file at the given path, and counts
+// the entries it holds from its head onwards. A truncated entry at the end of
+// the file, left by an interrupted write, is discarded.
+func newHTTPSpool(path string, maxSize int64, mode os.FileMode) (*httpSpool, error) {
+	const op = "event.newHTTPSpool"
+
+	if maxSize <= httpSpoolHeaderSize {
+		return nil, fmt.Errorf("%s: spool max size must be greater than %d bytes: %w", op, httpSpoolHeaderSize, ErrInvalidParameter)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("%s: unable to create spool directory: %w", op, err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, mode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to open spool file %q: %w", op, path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to stat spool file %q: %w", op, path, err)
+	}
+
+	spool := &httpSpool{
+		path:    path,
+		maxSize: maxSize,
+		mode:    mode,
+		head:    httpSpoolHeaderSize,
+	}
+
+	var header [httpSpoolHeaderSize]byte
+	if _, err := io.ReadFull(f, header[:]); err == nil {
+		head := int64(binary.BigEndian.Uint64(header[:]))
+		if head >= httpSpoolHeaderSize && head <= info.Size() {
+			spool.head = head
+		}
+	}
+
+	if _, err := f.Seek(spool.head, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%s: unable to read spool file %q: %w", op, path, err)
+	}
+
+	spool.size = spool.head
+	r := bufio.NewReader(f)
+	for {
+		n, err := readSpoolEntry(r, nil)
+		if err != nil {
+			break
+		}
+		spool.size += n
+		spool.count++
+	}
+
+	if err := f.Truncate(spool.size); err != nil {
+		return nil, fmt.Errorf("%s: unable to truncate spool file %q: %w", op, path, err)
+	}
+	if err := writeSpoolHeader(f, spool.head); err != nil {
+		return nil, fmt.Errorf("%s: unable to write spool file %q: %w", op, path, err)
+	}
+
+	return spool, nil
+}
+
+// writeSpoolHeader stores head in the header of the spool file, and syncs the
+// file.
+func writeSpoolHeader(f *os.File, head int64) error {
+	var header [httpSpoolHeaderSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(head))
+	if _, err := f.WriteAt(header[:], 0); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// readSpoolEntry reads a single entry from r, appending it to entries if that
+// is not nil, and returns the number of bytes read.
+func readSpoolEntry(r *bufio.Reader, entries *[][]byte) (int64, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return 0, err
+	}
+
+	n := binary.BigEndian.Uint32(prefix[:])
+	entry := make([]byte, n)
+	if _, err := io.ReadFull(r, entry); err != nil {
+		return 0, err
+	}
+
+	if entries != nil {
+		*entries = append(*entries, entry)
+	}
+	return int64(4 + n), nil
+}
+
+func (s *httpSpool) empty() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.count == 0
+}
+
+// append adds an entry to the end of the spool, compacting the spool first if
+// the entry would not otherwise fit. The entry is synced to disk before append
+// returns the number of entries waiting to be delivered.
+func (s *httpSpool) append(entry []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	record := make([]byte, 4+len(entry))
+	binary.BigEndian.PutUint32(record, uint32(len(entry)))
+	copy(record[4:], entry)
+
+	if s.size+int64(len(record)) > s.maxSize && s.head > httpSpoolHeaderSize {
+		if err := s.compactLocked(); err != nil {
+			return 0, err
+		}
+	}
+	if s.size+int64(len(record)) > s.maxSize {
+		return 0, errSpoolFull
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY, s.mode)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n, err := f.Write(record)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		// Drop the partial record, so that later entries remain readable.
+		_ = f.Truncate(s.size)
+		return 0, err
+	}
+	s.size += int64(n)
+	s.count++
+
+	return s.count, nil
+}
+
+// next returns up to limit entries from the head of the spool, along with
+// their size in the spool. The file is read incrementally, rather than as a
+// whole.
+func (s *httpSpool) next(limit int) ([][]byte, int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.count == 0 {
+		return nil, 0, nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(s.head, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+
+	r := bufio.NewReader(f)
+	var entries [][]byte
+	var size int64
+	for len(entries) < limit && len(entries) < s.count {
+		n, err := readSpoolEntry(r, &entries)
+		if err != nil {
+			return nil, 0, err
+		}
+		size += n
+	}
+
+	return entries, size, nil
+}
+
+// remove drops count entries, totalling size bytes, from the head of the
+// spool once they have been delivered, recording the new head in the spool.
+func (s *httpSpool) remove(count int, size int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.count == count {
+		return s.resetLocked()
+	}
+	if s.head+size >= httpSpoolCompactSize {
+		s.head += size
+		s.count -= count
+		return s.compactLocked()
+	}
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY, s.mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := writeSpoolHeader(f, s.head+size); err != nil {
+		return err
+	}
+	s.head += size
+	s.count -= count
+
+	return nil
+}
+
+// resetLocked empties the spool once all of its entries have been delivered.
+// The header is written before the file is truncated, so that a crash in
+// between leaves a valid, empty spool. It must be called with the lock held.
+func (s *httpSpool) resetLocked() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY, s.mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := writeSpoolHeader(f, httpSpoolHeaderSize); err != nil {
+		return err
+	}
+	s.size, s.head, s.count = httpSpoolHeaderSize, httpSpoolHeaderSize, 0
+
+	if err := f.Truncate(httpSpoolHeaderSize); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// compactLocked rewrites the spool without the entries which have already been
+// delivered. The new file is synced before it replaces the spool. It must be
+// called with the lock held.
+func (s *httpSpool) compactLocked() error {
+	src, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if _, err := src.Seek(s.head, io.SeekStart); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, s.mode)
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	err = writeSpoolHeader(dst, httpSpoolHeaderSize)
+	if err == nil {
+		_, err = dst.Seek(httpSpoolHeaderSize, io.SeekStart)
+	}
+	if err == nil {
+		n, err = io.Copy(dst, src)
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, s.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+
+	// Sync the directory, so that the rename survives a crash.
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		_ = dir.Sync()
+		_ = dir.Close()
+	}
+
+	s.size, s.head = httpSpoolHeaderSize+n, httpSpoolHeaderSize
+	return nil
+}
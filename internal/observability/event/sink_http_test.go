@@ -0,0 +1,305 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package event
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/stretchr/testify/require"
+)
+
+// testHTTPEndpoint is a HTTP server which records the bodies of the requests
+// it receives, and can be made to fail them.
+type testHTTPEndpoint struct {
+	*httptest.Server
+
+	failing  atomic.Bool
+	requests atomic.Int32
+
+	lock    sync.Mutex
+	bodies  []string
+	headers []http.Header
+}
+
+func newTestHTTPEndpoint(t *testing.T) *testHTTPEndpoint {
+	t.Helper()
+
+	e := &testHTTPEndpoint{}
+	e.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e.requests.Add(1)
+		if e.failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		e.lock.Lock()
+		e.bodies = append(e.bodies, string(body))
+		e.headers = append(e.headers, r.Header.Clone())
+		e.lock.Unlock()
+	}))
+	t.Cleanup(e.Close)
+
+	return e
+}
+
+func (e *testHTTPEndpoint) received() []string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return append([]string(nil), e.bodies...)
+}
+
+// TestNewHTTPSink ensures that we validate the input arguments and can create
+// the HTTPSink if everything goes to plan.
+func TestNewHTTPSink(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		Address              string
+		Format               string
+		Options              []Option
+		IsErrorExpected      bool
+		ExpectedErrorMessage string
+	}{
+		"no-address": {
+			Address:              " ",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "event.NewHTTPSink: address is required: invalid parameter",
+		},
+		"bad-batch-size": {
+			Address:              "https://localhost",
+			Options:              []Option{WithBatchSize("0")},
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "event.NewHTTPSink: error applying options: batch size must be greater than 0",
+		},
+		"bad-retry-waits": {
+			Address:              "https://localhost",
+			Options:              []Option{WithRetryWaitMin("10s"), WithRetryWaitMax("1s")},
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "event.NewHTTPSink: minimum retry wait 10s exceeds maximum retry wait 1s: invalid parameter",
+		},
+		"jsonx": {
+			Address:              "https://localhost",
+			Format:               "jsonx",
+			IsErrorExpected:      true,
+			ExpectedErrorMessage: "event.NewHTTPSink: the jsonx format is not supported: invalid parameter",
+		},
+		"happy": {
+			Address: "https://localhost",
+			Options: []Option{WithBatchSize("10"), WithHeaders(map[string]string{"Authorization": "Bearer foo"})},
+		},
+	}
+
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			format := tc.Format
+			if format == "" {
+				format = "json"
+			}
+			sink, err := NewHTTPSink(format, tc.Address, tc.Options...)
+			switch {
+			case tc.IsErrorExpected:
+				require.Error(t, err)
+				require.EqualError(t, err, tc.ExpectedErrorMessage)
+				require.Nil(t, sink)
+			default:
+				require.NoError(t, err)
+				require.NotNil(t, sink)
+			}
+		})
+	}
+}
+
+// TestHTTPSink_Send ensures that, without a spool, each event is sent in its
+// own request before the caller returns.
+func TestHTTPSink_Send(t *testing.T) {
+	t.Parallel()
+
+	endpoint := newTestHTTPEndpoint(t)
+
+	sink, err := NewHTTPSink("json", endpoint.URL,
+		WithBatchSize("2"),
+		WithHeaders(map[string]string{"X-Test": "foo"}),
+	)
+	require.NoError(t, err)
+
+	e := &eventlogger.Event{Formatted: map[string][]byte{"json": []byte(`{"n":1}`)}}
+	_, err = sink.Process(context.Background(), e)
+	require.NoError(t, err)
+	require.Equal(t, []string{"{\"n\":1}\n"}, endpoint.received())
+
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("{\"n\":2}\n")))
+	require.Equal(t, []string{"{\"n\":1}\n", "{\"n\":2}\n"}, endpoint.received())
+
+	endpoint.lock.Lock()
+	defer endpoint.lock.Unlock()
+	require.Equal(t, "foo", endpoint.headers[0].Get("X-Test"))
+	require.Equal(t, "application/x-ndjson", endpoint.headers[0].Get("Content-Type"))
+}
+
+// TestHTTPSink_Dropped ensures that, without a spool, callers receive an error
+// straight away when their event is not delivered, rather than waiting for
+// retries.
+func TestHTTPSink_Dropped(t *testing.T) {
+	t.Parallel()
+
+	endpoint := newTestHTTPEndpoint(t)
+	endpoint.failing.Store(true)
+
+	sink, err := NewHTTPSink("json", endpoint.URL,
+		WithMaxRetries("3"),
+		WithRetryWaitMin("1h"),
+		WithRetryWaitMax("1h"),
+	)
+	require.NoError(t, err)
+
+	e := &eventlogger.Event{Formatted: map[string][]byte{"json": []byte("lost")}}
+	_, err = sink.Process(context.Background(), e)
+	require.ErrorContains(t, err, "entry was not delivered")
+	require.Equal(t, int32(1), endpoint.requests.Load())
+}
+
+// TestHTTPSink_Close ensures that closing the sink stops it accepting events.
+func TestHTTPSink_Close(t *testing.T) {
+	t.Parallel()
+
+	endpoint := newTestHTTPEndpoint(t)
+
+	sink, err := NewHTTPSink("json", endpoint.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("sent")))
+	require.NoError(t, sink.Close(context.Background()))
+	require.Equal(t, []string{"sent\n"}, endpoint.received())
+
+	require.ErrorIs(t, sink.Enqueue(context.Background(), []byte("late")), ErrHTTPSinkClosed)
+}
+
+// TestHTTPSink_Spool ensures that spooled entries are acknowledged without
+// waiting for delivery, kept while the endpoint is failing, and delivered in
+// order once it recovers.
+func TestHTTPSink_Spool(t *testing.T) {
+	t.Parallel()
+
+	endpoint := newTestHTTPEndpoint(t)
+	endpoint.failing.Store(true)
+
+	spoolPath := filepath.Join(t.TempDir(), "spool")
+	sink, err := NewHTTPSink("json", endpoint.URL,
+		WithBatchSize("2"),
+		WithBatchInterval("1"),
+		WithMaxRetries("1"),
+		WithRetryWaitMin("10ms"),
+		WithRetryWaitMax("10ms"),
+		WithSpoolPath(spoolPath),
+	)
+	require.NoError(t, err)
+
+	// Entries count as recorded once spooled, while the endpoint is failing.
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("first")))
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("second")))
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("third")))
+	require.False(t, sink.spool.empty())
+	require.Empty(t, endpoint.received())
+
+	endpoint.failing.Store(false)
+	require.Eventually(t, sink.spool.empty, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"first\nsecond\n", "third\n"}, endpoint.received())
+
+	require.NoError(t, sink.Close(context.Background()))
+	require.ErrorIs(t, sink.Enqueue(context.Background(), []byte("late")), ErrHTTPSinkClosed)
+}
+
+// TestHTTPSink_SpoolRestart ensures that entries which could not be delivered
+// before the sink was closed are delivered by the next sink using the spool.
+func TestHTTPSink_SpoolRestart(t *testing.T) {
+	t.Parallel()
+
+	endpoint := newTestHTTPEndpoint(t)
+	endpoint.failing.Store(true)
+
+	spoolPath := filepath.Join(t.TempDir(), "spool")
+	opts := []Option{
+		WithMaxRetries("0"),
+		WithBatchInterval("1"),
+		WithSpoolPath(spoolPath),
+	}
+	sink, err := NewHTTPSink("json", endpoint.URL, opts...)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Enqueue(context.Background(), []byte("kept")))
+	require.NoError(t, sink.Close(context.Background()))
+	require.Empty(t, endpoint.received())
+
+	endpoint.failing.Store(false)
+	sink, err = NewHTTPSink("json", endpoint.URL, opts...)
+	require.NoError(t, err)
+	require.NoError(t, sink.Close(context.Background()))
+	require.Equal(t, []string{"kept\n"}, endpoint.received())
+}
+
+// TestHTTPSpool ensures that the spool hands out entries in order, does not
+// grow beyond its maximum size, reclaims the space of delivered entries and
+// does not hand out delivered entries again once reopened.
+func TestHTTPSpool(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spool")
+	spool, err := newHTTPSpool(path, 28, 0o600)
+	require.NoError(t, err)
+
+	count, err := spool.append([]byte("12345"))
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	count, err = spool.append([]byte("6789"))
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	_, err = spool.append([]byte("12"))
+	require.ErrorIs(t, err, errSpoolFull)
+
+	entries, size, err := spool.next(1)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("12345")}, entries)
+	require.NoError(t, spool.remove(len(entries), size))
+
+	// The head is stored in the spool, so the delivered entry is skipped.
+	spool, err = newHTTPSpool(path, 28, 0o600)
+	require.NoError(t, err)
+	entries, _, err = spool.next(10)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("6789")}, entries)
+
+	// The delivered entry is compacted away to make room.
+	_, err = spool.append([]byte("12"))
+	require.NoError(t, err)
+
+	// Reopening the spool recovers the entries waiting to be delivered.
+	spool, err = newHTTPSpool(path, 28, 0o600)
+	require.NoError(t, err)
+	entries, size, err = spool.next(10)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("6789"), []byte("12")}, entries)
+	require.NoError(t, spool.remove(len(entries), size))
+	require.True(t, spool.empty())
+
+	spool, err = newHTTPSpool(path, 28, 0o600)
+	require.NoError(t, err)
+	require.True(t, spool.empty())
+}
@@ -178,6 +178,10 @@ func rollbackUser(ctx context.Context, session *gocql.Session, username string,
 }
 
 func (c *Cassandra) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
 	if req.Password == nil && req.Expiration == nil {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no changes requested")
 	}
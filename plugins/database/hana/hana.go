@@ -174,6 +174,10 @@ func (h *HANA) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (respon
 // UpdateUser allows for updating the expiration or password of the user mentioned in
 // the UpdateUserRequest
 func (h *HANA) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
 	h.Lock()
 	defer h.Unlock()
 
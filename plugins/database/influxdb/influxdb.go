@@ -209,6 +209,10 @@ func (i *Influxdb) DeleteUser(ctx context.Context, req dbplugin.DeleteUserReques
 }
 
 func (i *Influxdb) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
 	if req.Password == nil && req.Expiration == nil {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no changes requested")
 	}
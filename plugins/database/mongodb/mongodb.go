@@ -155,6 +155,10 @@ func (m *MongoDB) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (dbp
 }
 
 func (m *MongoDB) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
 	if req.Password != nil {
 		err := m.changeUserPassword(ctx, req.Username, req.Password.NewPassword)
 		return dbplugin.UpdateUserResponse{}, err
@@ -333,6 +333,10 @@ func (m *MSSQL) revokeUserDefault(ctx context.Context, username string) error {
 }
 
 func (m *MSSQL) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
 	if req.Password == nil && req.Expiration == nil {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no changes requested")
 	}
@@ -43,6 +43,14 @@ type mySQLConnectionProducer struct {
 	TLSServerName         string `json:"tls_server_name" mapstructure:"tls_server_name" structs:"tls_server_name"`
 	TLSSkipVerify         bool   `json:"tls_skip_verify" mapstructure:"tls_skip_verify" structs:"tls_skip_verify"`
 
+	// SelfManaged indicates that the connection holds no root credentials and
+	// is only used by static roles that rotate their own password.
+	SelfManaged bool `json:"self_managed" mapstructure:"self_managed" structs:"self_managed"`
+
+	// connectionURLTemplate is the connection_url as configured, before the
+	// username and password template variables are substituted.
+	connectionURLTemplate string
+
 	// tlsConfigName is a globally unique name that references the TLS config for this instance in the mysql driver
 	tlsConfigName string
 
@@ -77,15 +85,12 @@ func (c *mySQLConnectionProducer) Init(ctx context.Context, conf map[string]inte
 		return nil, fmt.Errorf("connection_url cannot be empty")
 	}
 
-	// Don't escape special characters for MySQL password
-	password := c.Password
+	if c.SelfManaged && (c.Username != "" || c.Password != "") {
+		return nil, fmt.Errorf("cannot use both self-managed and vault-managed workflows: username and password must be empty when self_managed is true")
+	}
 
-	// QueryHelper doesn't do any SQL escaping, but if it starts to do so
-	// then maybe we won't be able to use it to do URL substitution any more.
-	c.ConnectionURL = dbutil.QueryHelper(c.ConnectionURL, map[string]string{
-		"username": url.PathEscape(c.Username),
-		"password": password,
-	})
+	c.connectionURLTemplate = c.ConnectionURL
+	c.ConnectionURL = c.templateConnectionURL(c.Username, c.Password)
 
 	if c.MaxOpenConnections == 0 {
 		c.MaxOpenConnections = 4
@@ -152,7 +157,9 @@ func (c *mySQLConnectionProducer) Init(ctx context.Context, conf map[string]inte
 	// and the connection can be established at a later time.
 	c.Initialized = true
 
-	if verifyConnection {
+	// Self-managed connections have no credentials of their own, so there is
+	// nothing to verify until a static user connects.
+	if verifyConnection && !c.SelfManaged {
 		if _, err = c.Connection(ctx); err != nil {
 			return nil, fmt.Errorf("error verifying - connection: %w", err)
 		}
@@ -189,9 +196,8 @@ func (c *mySQLConnectionProducer) Connection(ctx context.Context) (interface{},
 
 	}
 
-	driverName := driverMySQL
-	if c.cloudDriverName != "" {
-		driverName = c.cloudDriverName
+	if c.SelfManaged {
+		return nil, fmt.Errorf("cannot use a self-managed connection without static user credentials")
 	}
 
 	connURL, err := c.addTLStoDSN()
@@ -204,7 +210,7 @@ func (c *mySQLConnectionProducer) Connection(ctx context.Context) (interface{},
 		return nil, err
 	}
 
-	c.db, err = sql.Open(driverName, cloudURL)
+	c.db, err = sql.Open(c.driverName(), cloudURL)
 	if err != nil {
 		return nil, err
 	}
@@ -218,6 +224,61 @@ func (c *mySQLConnectionProducer) Connection(ctx context.Context) (interface{},
 	return c.db, nil
 }
 
+// StaticConnection opens a new connection to the database authenticated as
+// the given static user rather than with the configured root credentials. The
+// caller is responsible for closing the returned connection.
+func (c *mySQLConnectionProducer) StaticConnection(ctx context.Context, username, password string) (*sql.DB, error) {
+	if !c.Initialized {
+		return nil, connutil.ErrNotInitialized
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password are required for a static connection")
+	}
+
+	connURL, err := c.dsnWithTLS(c.templateConnectionURL(username, password))
+	if err != nil {
+		return nil, err
+	}
+
+	cloudURL, err := c.rewriteProtocolForGCP(connURL)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(c.driverName(), cloudURL)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to connect as static user: %w", err)
+	}
+
+	return db, nil
+}
+
+// templateConnectionURL substitutes the given username and password into the
+// configured connection_url.
+func (c *mySQLConnectionProducer) templateConnectionURL(username, password string) string {
+	// Don't escape special characters for MySQL password
+	// QueryHelper doesn't do any SQL escaping, but if it starts to do so
+	// then maybe we won't be able to use it to do URL substitution any more.
+	return dbutil.QueryHelper(c.connectionURLTemplate, map[string]string{
+		"username": url.PathEscape(username),
+		"password": password,
+	})
+}
+
+func (c *mySQLConnectionProducer) driverName() string {
+	if c.cloudDriverName != "" {
+		return c.cloudDriverName
+	}
+	return driverMySQL
+}
+
 func (c *mySQLConnectionProducer) SecretValues() map[string]string {
 	return map[string]string{
 		c.Password: "[password]",
@@ -282,7 +343,12 @@ func (c *mySQLConnectionProducer) getTLSAuth() (tlsConfig *tls.Config, err error
 }
 
 func (c *mySQLConnectionProducer) addTLStoDSN() (connURL string, err error) {
-	config, err := mysql.ParseDSN(c.ConnectionURL)
+	return c.dsnWithTLS(c.ConnectionURL)
+}
+
+// dsnWithTLS adds the registered TLS configuration, if any, to the given DSN.
+func (c *mySQLConnectionProducer) dsnWithTLS(dsn string) (connURL string, err error) {
+	config, err := mysql.ParseDSN(dsn)
 	if err != nil {
 		return "", fmt.Errorf("unable to parse connectionURL: %s", err)
 	}
@@ -27,6 +27,12 @@ const (
 		ALTER USER '{{username}}'@'%' IDENTIFIED BY '{{password}}';
 	`
 
+	// defaultMySQLSelfManagedRotateCredentialsSQL changes the password of the
+	// connected account, which does not require any privileges.
+	defaultMySQLSelfManagedRotateCredentialsSQL = `
+		SET PASSWORD = '{{password}}';
+	`
+
 	mySQLTypeName = "mysql"
 
 	DefaultUserNameTemplate       = `{{ printf "v-%s-%s-%s-%s" (.DisplayName | truncate 10) (.RoleName | truncate 10) (random 20) (unix_time) | truncate 32 }}`
@@ -108,6 +114,7 @@ func (m *MySQL) Initialize(ctx context.Context, req dbplugin.InitializeRequest)
 	resp := dbplugin.InitializeResponse{
 		Config: req.Config,
 	}
+	resp.SetSupportsSelfManagedPassword(true)
 
 	return resp, nil
 }
@@ -196,7 +203,12 @@ func (m *MySQL) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest)
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no change requested")
 	}
 
-	if req.Password != nil {
+	if req.Password != nil && req.SelfManagedPassword != "" {
+		err := m.changeSelfManagedUserPassword(ctx, req.Username, req.SelfManagedPassword, req.Password.NewPassword, req.Password.Statements.Commands)
+		if err != nil {
+			return dbplugin.UpdateUserResponse{}, fmt.Errorf("failed to change password: %w", err)
+		}
+	} else if req.Password != nil {
 		err := m.changeUserPassword(ctx, req.Username, req.Password.NewPassword, req.Password.Statements.Commands)
 		if err != nil {
 			return dbplugin.UpdateUserResponse{}, fmt.Errorf("failed to change password: %w", err)
@@ -229,6 +241,36 @@ func (m *MySQL) changeUserPassword(ctx context.Context, username, password strin
 	return nil
 }
 
+// changeSelfManagedUserPassword changes the password of the given user by
+// connecting as that user with its current password, rather than with the
+// root credentials.
+func (m *MySQL) changeSelfManagedUserPassword(ctx context.Context, username, currentPassword, password string, rotateStatements []string) error {
+	if username == "" || password == "" {
+		return errors.New("must provide both username and password")
+	}
+
+	if len(rotateStatements) == 0 {
+		rotateStatements = []string{defaultMySQLSelfManagedRotateCredentialsSQL}
+	}
+
+	queryMap := map[string]string{
+		"name":     username,
+		"username": username,
+		"password": password,
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	db, err := m.StaticConnection(ctx, username, currentPassword)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return executePreparedStatements(ctx, db, rotateStatements, queryMap)
+}
+
 // executePreparedStatementsWithMap loops through the given templated SQL statements and
 // applies the map to them, interpolating values into the templates, returning
 // the resulting username and password
@@ -242,6 +284,13 @@ func (m *MySQL) executePreparedStatementsWithMap(ctx context.Context, statements
 	if err != nil {
 		return err
 	}
+
+	return executePreparedStatements(ctx, db, statements, queryMap)
+}
+
+// executePreparedStatements runs the given templated SQL statements in a
+// single transaction on the given connection.
+func executePreparedStatements(ctx context.Context, db *sql.DB, statements []string, queryMap map[string]string) error {
 	// Start a transaction
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
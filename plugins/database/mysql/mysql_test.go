@@ -821,6 +821,74 @@ func TestMySQL_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestMySQL_UpdateUser_SelfManaged(t *testing.T) {
+	cleanup, connURL := mysqlhelper.PrepareTestContainer(t, false, "secret")
+	defer cleanup()
+
+	// create the database user without any privileges to manage other users
+	dbUser := "vaultselfmanaged"
+	initPassword := "password"
+
+	createStatements := `
+		CREATE USER '{{name}}'@'%' IDENTIFIED BY '{{password}}';
+		GRANT SELECT ON *.* TO '{{name}}'@'%';`
+
+	createTestMySQLUser(t, connURL, dbUser, initPassword, createStatements)
+	if err := mysqlhelper.TestCredsExist(t, connURL, dbUser, initPassword); err != nil {
+		t.Fatalf("Could not connect with credentials: %s", err)
+	}
+
+	initReq := dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url": strings.Replace(connURL, "root:secret", "{{username}}:{{password}}", 1),
+			"self_managed":   true,
+		},
+		VerifyConnection: true,
+	}
+
+	// Give a timeout just in case the test decides to be problematic
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	db := newMySQL(DefaultUserNameTemplate)
+	defer db.Close()
+	_, err := db.Initialize(context.Background(), initReq)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	newPassword, err := credsutil.RandomAlphaNumeric(32, false)
+	if err != nil {
+		t.Fatalf("unable to generate password: %s", err)
+	}
+
+	// self-managed connections hold no root credentials
+	updateReq := dbplugin.UpdateUserRequest{
+		Username: dbUser,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: newPassword,
+		},
+	}
+	if _, err := db.UpdateUser(ctx, updateReq); err == nil {
+		t.Fatal("expected error changing password without the current password")
+	}
+
+	updateReq.SelfManagedPassword = initPassword
+	if _, err := db.UpdateUser(ctx, updateReq); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// verify new password works
+	if err := mysqlhelper.TestCredsExist(t, connURL, dbUser, newPassword); err != nil {
+		t.Fatalf("Could not connect with new credentials: %s", err)
+	}
+
+	// verify old password doesn't work
+	if err := mysqlhelper.TestCredsExist(t, connURL, dbUser, initPassword); err == nil {
+		t.Fatalf("Should not be able to connect with initial credentials")
+	}
+}
+
 func createTestMySQLUser(t *testing.T, connURL, username, password, query string) {
 	t.Helper()
 	db, err := sql.Open("mysql", connURL)
@@ -125,6 +125,7 @@ func (p *PostgreSQL) Initialize(ctx context.Context, req dbplugin.InitializeRequ
 	resp := dbplugin.InitializeResponse{
 		Config: newConf,
 	}
+	resp.SetSupportsSelfManagedPassword(true)
 	return resp, nil
 }
 
@@ -141,6 +142,13 @@ func (p *PostgreSQL) getConnection(ctx context.Context) (*sql.DB, error) {
 	return db.(*sql.DB), nil
 }
 
+// getStaticConnection returns a connection authenticated as the given user
+// rather than with the configured root credentials. The caller is responsible
+// for closing the connection.
+func (p *PostgreSQL) getStaticConnection(ctx context.Context, username, password string) (*sql.DB, error) {
+	return p.StaticConnection(ctx, username, password)
+}
+
 func (p *PostgreSQL) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
 	if req.Username == "" {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("missing username")
@@ -148,10 +156,13 @@ func (p *PostgreSQL) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequ
 	if req.Password == nil && req.Expiration == nil {
 		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no changes requested")
 	}
+	if req.SelfManagedPassword != "" && req.Expiration != nil {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("cannot change the expiration of a self-managed user")
+	}
 
 	merr := &multierror.Error{}
 	if req.Password != nil {
-		err := p.changeUserPassword(ctx, req.Username, req.Password)
+		err := p.changeUserPassword(ctx, req.Username, req.Password, req.SelfManagedPassword)
 		merr = multierror.Append(merr, err)
 	}
 	if req.Expiration != nil {
@@ -161,7 +172,10 @@ func (p *PostgreSQL) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequ
 	return dbplugin.UpdateUserResponse{}, merr.ErrorOrNil()
 }
 
-func (p *PostgreSQL) changeUserPassword(ctx context.Context, username string, changePass *dbplugin.ChangePassword) error {
+// changeUserPassword changes the password of the given user. If
+// selfManagedPassword is set, the statements are run as the user itself using
+// its current password instead of the root credentials.
+func (p *PostgreSQL) changeUserPassword(ctx context.Context, username string, changePass *dbplugin.ChangePassword, selfManagedPassword string) error {
 	stmts := changePass.Statements.Commands
 	if len(stmts) == 0 {
 		stmts = []string{defaultChangePasswordStatement}
@@ -175,9 +189,19 @@ func (p *PostgreSQL) changeUserPassword(ctx context.Context, username string, ch
 	p.Lock()
 	defer p.Unlock()
 
-	db, err := p.getConnection(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to get connection: %w", err)
+	var db *sql.DB
+	var err error
+	if selfManagedPassword != "" {
+		db, err = p.getStaticConnection(ctx, username, selfManagedPassword)
+		if err != nil {
+			return fmt.Errorf("unable to get connection: %w", err)
+		}
+		defer db.Close()
+	} else {
+		db, err = p.getConnection(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to get connection: %w", err)
+		}
 	}
 
 	// Check if the role exists
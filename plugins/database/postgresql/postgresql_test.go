@@ -7,6 +7,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -589,6 +590,84 @@ func TestUpdateUser_Password(t *testing.T) {
 	})
 }
 
+func TestUpdateUser_SelfManagedPassword(t *testing.T) {
+	// The root connection is only used to create the static user
+	rootDB, cleanup := getPostgreSQL(t, nil)
+	defer cleanup()
+
+	initialPass := "myreallysecurepassword"
+	createReq := dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "test",
+			RoleName:    "test",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{createAdminUser},
+		},
+		Password:   initialPass,
+		Expiration: time.Now().Add(time.Minute),
+	}
+	createResp := dbtesting.AssertNewUser(t, rootDB, createReq)
+	assertCredsExist(t, rootDB.ConnectionURL, createResp.Username, initialPass)
+
+	u, err := url.Parse(rootDB.ConnectionURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.User = nil
+	connURL := strings.Replace(u.String(), "postgres://", "postgres://{{username}}:{{password}}@", 1)
+
+	db := new()
+	dbtesting.AssertInitialize(t, db, dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url": connURL,
+			"self_managed":   true,
+		},
+		VerifyConnection: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Self-managed connections hold no root credentials
+	_, err = db.UpdateUser(ctx, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "somenewpassword",
+		},
+	})
+	if err == nil {
+		t.Fatal("err expected, got nil")
+	}
+
+	// The wrong current password cannot be used to rotate
+	_, err = db.UpdateUser(ctx, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "somenewpassword",
+		},
+		SelfManagedPassword: "wrongpassword",
+	})
+	if err == nil {
+		t.Fatal("err expected, got nil")
+	}
+	assertCredsExist(t, rootDB.ConnectionURL, createResp.Username, initialPass)
+
+	newPass := "somenewpassword"
+	_, err = db.UpdateUser(ctx, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: newPass,
+		},
+		SelfManagedPassword: initialPass,
+	})
+	if err != nil {
+		t.Fatalf("no error expected, got: %s", err)
+	}
+	assertCredsExist(t, rootDB.ConnectionURL, createResp.Username, newPass)
+	assertCredsDoNotExist(t, rootDB.ConnectionURL, createResp.Username, initialPass)
+}
+
 func TestUpdateUser_Expiration(t *testing.T) {
 	type testCase struct {
 		initialExpiration  time.Time
@@ -0,0 +1,380 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	"github.com/hashicorp/vault/sdk/database/helper/connutil"
+	"github.com/hashicorp/vault/sdk/database/helper/dbutil"
+	"github.com/hashicorp/vault/sdk/helper/dbtxn"
+	"github.com/hashicorp/vault/sdk/helper/template"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/bcrypt"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	sqliteTypeName = "sqlite"
+
+	// expirationFormat is understood by the SQLite date and time functions.
+	expirationFormat = "2006-01-02 15:04:05-07:00"
+
+	defaultUserNameTemplate = `{{ printf "v-%s-%s-%s-%s" (.DisplayName | truncate 8) (.RoleName | truncate 8) (random 20) (unix_time) | truncate 63 }}`
+)
+
+var (
+	_ dbplugin.Database       = (*SQLite)(nil)
+	_ logical.PluginVersioner = (*SQLite)(nil)
+
+	// ReportedVersion is used to report a specific version to Vault.
+	ReportedVersion = ""
+
+	errNoUsersTable = errors.New("statements must be provided when users_table is not configured")
+)
+
+// New implements builtinplugins.BuiltinFactory
+func New() (interface{}, error) {
+	db := new()
+	// Wrap the plugin with middleware to sanitize errors
+	dbType := dbplugin.NewDatabaseErrorSanitizerMiddleware(db, db.secretValues)
+	return dbType, nil
+}
+
+func new() *SQLite {
+	connProducer := &connutil.SQLConnectionProducer{}
+	connProducer.Type = sqliteTypeName
+
+	return &SQLite{
+		SQLConnectionProducer: connProducer,
+	}
+}
+
+// SQLite manages users of an SQLite database. SQLite has no users of its own,
+// so users are managed with the statements given to each operation; these may
+// target an application level users table, or the user authentication
+// extension of SQLite builds which include it. If users_table is configured,
+// users are instead managed by default in that table, whose username,
+// password and expiration columns hold text, and whose password column holds
+// a bcrypt hash of the password.
+type SQLite struct {
+	*connutil.SQLConnectionProducer
+
+	usernameProducer template.StringTemplate
+	usersTable       string
+}
+
+func (s *SQLite) Initialize(ctx context.Context, req dbplugin.InitializeRequest) (dbplugin.InitializeResponse, error) {
+	newConf, err := s.SQLConnectionProducer.Init(ctx, req.Config, req.VerifyConnection)
+	if err != nil {
+		return dbplugin.InitializeResponse{}, err
+	}
+
+	usernameTemplate, err := strutil.GetString(req.Config, "username_template")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve username_template: %w", err)
+	}
+	if usernameTemplate == "" {
+		usernameTemplate = defaultUserNameTemplate
+	}
+
+	up, err := template.NewTemplate(template.Template(usernameTemplate))
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("unable to initialize username template: %w", err)
+	}
+	s.usernameProducer = up
+
+	_, err = s.usernameProducer.Generate(dbplugin.UsernameMetadata{})
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("invalid username template: %w", err)
+	}
+
+	s.usersTable, err = strutil.GetString(req.Config, "users_table")
+	if err != nil {
+		return dbplugin.InitializeResponse{}, fmt.Errorf("failed to retrieve users_table: %w", err)
+	}
+
+	resp := dbplugin.InitializeResponse{
+		Config: newConf,
+	}
+	return resp, nil
+}
+
+func (s *SQLite) Type() (string, error) {
+	return sqliteTypeName, nil
+}
+
+func (s *SQLite) getConnection(ctx context.Context) (*sql.DB, error) {
+	db, err := s.Connection(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.(*sql.DB), nil
+}
+
+// NewUser creates a new user with the creation statements, or in the users
+// table if none are given.
+func (s *SQLite) NewUser(ctx context.Context, req dbplugin.NewUserRequest) (dbplugin.NewUserResponse, error) {
+	if len(req.Statements.Commands) == 0 && s.usersTable == "" {
+		return dbplugin.NewUserResponse{}, dbutil.ErrEmptyCreationStatement
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	username, err := s.usernameProducer.Generate(req.UsernameConfig)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	expirationStr := req.Expiration.UTC().Format(expirationFormat)
+
+	db, err := s.getConnection(ctx)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, fmt.Errorf("unable to get connection: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbplugin.NewUserResponse{}, fmt.Errorf("unable to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(req.Statements.Commands) == 0 {
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			return dbplugin.NewUserResponse{}, err
+		}
+		query := fmt.Sprintf("INSERT INTO %s (username, password, expiration) VALUES (?, ?, ?);", dbutil.QuoteIdentifier(s.usersTable))
+		if _, err := tx.ExecContext(ctx, query, username, hash, expirationStr); err != nil {
+			return dbplugin.NewUserResponse{}, fmt.Errorf("failed to create user: %w", err)
+		}
+	} else {
+		m := map[string]string{
+			"name":       username,
+			"username":   username,
+			"password":   req.Password,
+			"expiration": expirationStr,
+		}
+		if err := executeStatements(ctx, tx, m, req.Statements.Commands); err != nil {
+			return dbplugin.NewUserResponse{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dbplugin.NewUserResponse{}, err
+	}
+
+	resp := dbplugin.NewUserResponse{
+		Username: username,
+	}
+	return resp, nil
+}
+
+// UpdateUser changes the password or expiration of a user, or both. Changing
+// the password is used both for static roles and for rotating the root
+// credentials.
+func (s *SQLite) UpdateUser(ctx context.Context, req dbplugin.UpdateUserRequest) (dbplugin.UpdateUserResponse, error) {
+	if req.SelfManagedPassword != "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("self-managed passwords are not supported")
+	}
+
+	if req.Username == "" {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("missing username")
+	}
+	if req.Password == nil && req.Expiration == nil {
+		return dbplugin.UpdateUserResponse{}, fmt.Errorf("no changes requested")
+	}
+
+	merr := &multierror.Error{}
+	if req.Password != nil {
+		err := s.changeUserPassword(ctx, req.Username, req.Password)
+		merr = multierror.Append(merr, err)
+	}
+	if req.Expiration != nil {
+		err := s.changeUserExpiration(ctx, req.Username, req.Expiration)
+		merr = multierror.Append(merr, err)
+	}
+	return dbplugin.UpdateUserResponse{}, merr.ErrorOrNil()
+}
+
+func (s *SQLite) changeUserPassword(ctx context.Context, username string, changePass *dbplugin.ChangePassword) error {
+	if len(changePass.Statements.Commands) == 0 && s.usersTable == "" {
+		return errNoUsersTable
+	}
+
+	password := changePass.NewPassword
+	if password == "" {
+		return fmt.Errorf("missing password")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	db, err := s.getConnection(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get connection: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(changePass.Statements.Commands) == 0 {
+		hash, err := hashPassword(password)
+		if err != nil {
+			return err
+		}
+		query := fmt.Sprintf("UPDATE %s SET password = ? WHERE username = ?;", dbutil.QuoteIdentifier(s.usersTable))
+		result, err := tx.ExecContext(ctx, query, hash, username)
+		if err != nil {
+			return fmt.Errorf("failed to change password: %w", err)
+		}
+		// Vault requires the user to already exist
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return fmt.Errorf("cannot update password for username %q because it does not exist", username)
+		}
+	} else {
+		m := map[string]string{
+			"name":     username,
+			"username": username,
+			"password": password,
+		}
+		if err := executeStatements(ctx, tx, m, changePass.Statements.Commands); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLite) changeUserExpiration(ctx context.Context, username string, changeExp *dbplugin.ChangeExpiration) error {
+	if len(changeExp.Statements.Commands) == 0 && s.usersTable == "" {
+		// Without statements or a users table, credentials have no expiration
+		// to renew
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	db, err := s.getConnection(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	expirationStr := changeExp.NewExpiration.UTC().Format(expirationFormat)
+
+	if len(changeExp.Statements.Commands) == 0 {
+		query := fmt.Sprintf("UPDATE %s SET expiration = ? WHERE username = ?;", dbutil.QuoteIdentifier(s.usersTable))
+		if _, err := tx.ExecContext(ctx, query, expirationStr, username); err != nil {
+			return fmt.Errorf("failed to change expiration: %w", err)
+		}
+	} else {
+		m := map[string]string{
+			"name":       username,
+			"username":   username,
+			"expiration": expirationStr,
+		}
+		if err := executeStatements(ctx, tx, m, changeExp.Statements.Commands); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteUser deletes a user with the revocation statements, or from the users
+// table if none are given.
+func (s *SQLite) DeleteUser(ctx context.Context, req dbplugin.DeleteUserRequest) (dbplugin.DeleteUserResponse, error) {
+	if len(req.Statements.Commands) == 0 && s.usersTable == "" {
+		return dbplugin.DeleteUserResponse{}, errNoUsersTable
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	db, err := s.getConnection(ctx)
+	if err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return dbplugin.DeleteUserResponse{}, err
+	}
+	defer tx.Rollback()
+
+	if len(req.Statements.Commands) == 0 {
+		// No error if the user was already deleted
+		query := fmt.Sprintf("DELETE FROM %s WHERE username = ?;", dbutil.QuoteIdentifier(s.usersTable))
+		if _, err := tx.ExecContext(ctx, query, req.Username); err != nil {
+			return dbplugin.DeleteUserResponse{}, fmt.Errorf("failed to delete user: %w", err)
+		}
+	} else {
+		m := map[string]string{
+			"name":     req.Username,
+			"username": req.Username,
+		}
+		if err := executeStatements(ctx, tx, m, req.Statements.Commands); err != nil {
+			return dbplugin.DeleteUserResponse{}, err
+		}
+	}
+
+	return dbplugin.DeleteUserResponse{}, tx.Commit()
+}
+
+// hashPassword returns the bcrypt hash stored in the users table in place of
+// the password, so that the table never holds the password itself.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// executeStatements executes each of the semicolon separated queries of the
+// given statements, after substituting the templated values.
+func executeStatements(ctx context.Context, tx *sql.Tx, m map[string]string, statements []string) error {
+	for _, stmt := range statements {
+		for _, query := range strutil.ParseArbitraryStringSlice(stmt, ";") {
+			query = strings.TrimSpace(query)
+			if len(query) == 0 {
+				continue
+			}
+
+			if err := dbtxn.ExecuteTxQueryDirect(ctx, tx, m, query); err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *SQLite) secretValues() map[string]string {
+	return map[string]string{
+		s.Password: "[password]",
+	}
+}
+
+func (s *SQLite) PluginVersion() logical.PluginVersion {
+	return logical.PluginVersion{Version: ReportedVersion}
+}
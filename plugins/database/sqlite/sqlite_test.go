@@ -0,0 +1,226 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	dbplugin "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
+	dbtesting "github.com/hashicorp/vault/sdk/database/dbplugin/v5/testing"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func getSQLite(t *testing.T, options map[string]interface{}) (*SQLite, *sql.DB) {
+	t.Helper()
+
+	connURL := "file:" + filepath.Join(t.TempDir(), "vault.db")
+	conn, err := sql.Open(sqliteTypeName, connURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = conn.Exec(`CREATE TABLE users (username TEXT PRIMARY KEY, password TEXT NOT NULL, expiration TEXT);`)
+	require.NoError(t, err)
+	_, err = conn.Exec(`INSERT INTO users (username, password) VALUES ('vault', 'secret');`)
+	require.NoError(t, err)
+
+	config := map[string]interface{}{
+		"connection_url": connURL,
+		"username":       "vault",
+		"password":       "secret",
+	}
+	for k, v := range options {
+		config[k] = v
+	}
+
+	db := new()
+	dbtesting.AssertInitialize(t, db, dbplugin.InitializeRequest{
+		Config:           config,
+		VerifyConnection: true,
+	})
+	t.Cleanup(func() { dbtesting.AssertClose(t, db) })
+
+	return db, conn
+}
+
+func getUser(t *testing.T, conn *sql.DB, username string) (password string, expiration string, exists bool) {
+	t.Helper()
+
+	var exp sql.NullString
+	err := conn.QueryRow(`SELECT password, expiration FROM users WHERE username = ?;`, username).Scan(&password, &exp)
+	if err == sql.ErrNoRows {
+		return "", "", false
+	}
+	require.NoError(t, err)
+	return password, exp.String, true
+}
+
+// requirePasswordHash asserts that the stored value is a bcrypt hash of the
+// password, rather than the password itself.
+func requirePasswordHash(t *testing.T, password, stored string) {
+	t.Helper()
+	require.NotEqual(t, password, stored)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)))
+}
+
+func TestSQLite_Initialize(t *testing.T) {
+	db := new()
+	defer dbtesting.AssertClose(t, db)
+
+	_, err := dbtesting.VerifyInitialize(t, db, dbplugin.InitializeRequest{
+		Config: map[string]interface{}{
+			"connection_url":    "file:" + filepath.Join(t.TempDir(), "vault.db"),
+			"username_template": "{{.Invalid",
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestSQLite_UsersTable(t *testing.T) {
+	db, conn := getSQLite(t, map[string]interface{}{
+		"users_table": "users",
+	})
+
+	expiration := time.Now().Add(time.Hour).Truncate(time.Second)
+	createResp := dbtesting.AssertNewUser(t, db, dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "readonly",
+		},
+		Password:   "Password1",
+		Expiration: expiration,
+	})
+	password, exp, exists := getUser(t, conn, createResp.Username)
+	require.True(t, exists)
+	requirePasswordHash(t, "Password1", password)
+	require.Equal(t, expiration.UTC().Format(expirationFormat), exp)
+
+	newExpiration := expiration.Add(time.Hour)
+	dbtesting.AssertUpdateUser(t, db, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "Password2",
+		},
+		Expiration: &dbplugin.ChangeExpiration{
+			NewExpiration: newExpiration,
+		},
+	})
+	password, exp, _ = getUser(t, conn, createResp.Username)
+	requirePasswordHash(t, "Password2", password)
+	require.Equal(t, newExpiration.UTC().Format(expirationFormat), exp)
+
+	// Rotating the password of a missing user fails
+	_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "missing",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "Password3",
+		},
+	})
+	require.Error(t, err)
+
+	// Root rotation updates the configured user
+	dbtesting.AssertUpdateUser(t, db, dbplugin.UpdateUserRequest{
+		Username: "vault",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "Password4",
+		},
+	})
+	password, _, _ = getUser(t, conn, "vault")
+	requirePasswordHash(t, "Password4", password)
+
+	dbtesting.AssertDeleteUser(t, db, dbplugin.DeleteUserRequest{
+		Username: createResp.Username,
+	})
+	_, _, exists = getUser(t, conn, createResp.Username)
+	require.False(t, exists)
+
+	// Deleting again is not an error
+	dbtesting.AssertDeleteUser(t, db, dbplugin.DeleteUserRequest{
+		Username: createResp.Username,
+	})
+}
+
+func TestSQLite_Statements(t *testing.T) {
+	db, conn := getSQLite(t, nil)
+
+	// Statements are required without a users table
+	_, err := db.NewUser(context.Background(), dbplugin.NewUserRequest{
+		Password:   "Password1",
+		Expiration: time.Now().Add(time.Hour),
+	})
+	require.Error(t, err)
+
+	createResp := dbtesting.AssertNewUser(t, db, dbplugin.NewUserRequest{
+		UsernameConfig: dbplugin.UsernameMetadata{
+			DisplayName: "token",
+			RoleName:    "readonly",
+		},
+		Statements: dbplugin.Statements{
+			Commands: []string{`
+				INSERT INTO users (username, password, expiration) VALUES ('{{username}}', '{{password}}', '{{expiration}}');
+				UPDATE users SET password = password || '!' WHERE username = '{{name}}';`,
+			},
+		},
+		Password:   "Password1",
+		Expiration: time.Now().Add(time.Hour),
+	})
+	password, _, exists := getUser(t, conn, createResp.Username)
+	require.True(t, exists)
+	require.Equal(t, "Password1!", password)
+
+	dbtesting.AssertUpdateUser(t, db, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "Password2",
+			Statements: dbplugin.Statements{
+				Commands: []string{`UPDATE users SET password = '{{password}}' WHERE username = '{{username}}';`},
+			},
+		},
+	})
+	password, _, _ = getUser(t, conn, createResp.Username)
+	require.Equal(t, "Password2", password)
+
+	// Renewal is a no-op without statements
+	dbtesting.AssertUpdateUser(t, db, dbplugin.UpdateUserRequest{
+		Username: createResp.Username,
+		Expiration: &dbplugin.ChangeExpiration{
+			NewExpiration: time.Now().Add(2 * time.Hour),
+		},
+	})
+
+	// Deletion requires statements
+	_, err = db.DeleteUser(context.Background(), dbplugin.DeleteUserRequest{
+		Username: createResp.Username,
+	})
+	require.Error(t, err)
+
+	dbtesting.AssertDeleteUser(t, db, dbplugin.DeleteUserRequest{
+		Username: createResp.Username,
+		Statements: dbplugin.Statements{
+			Commands: []string{`DELETE FROM users WHERE username = '{{username}}';`},
+		},
+	})
+	_, _, exists = getUser(t, conn, createResp.Username)
+	require.False(t, exists)
+}
+
+func TestSQLite_SelfManagedPassword(t *testing.T) {
+	db, conn := getSQLite(t, nil)
+
+	_, err := db.UpdateUser(context.Background(), dbplugin.UpdateUserRequest{
+		Username: "vault",
+		Password: &dbplugin.ChangePassword{
+			NewPassword: "new-secret",
+		},
+		SelfManagedPassword: "secret",
+	})
+	require.Error(t, err)
+
+	password, _, _ := getUser(t, conn, "vault")
+	require.Equal(t, "secret", password)
+}
@@ -90,6 +90,18 @@ func (ir InitializeResponse) SetSupportedCredentialTypes(credTypes []CredentialT
 	ir.Config[SupportedCredentialTypesKey] = sct
 }
 
+// SupportsSelfManagedPasswordKey is used to get and set whether database
+// plugins support self-managed passwords in database plugins and Vault.
+const SupportsSelfManagedPasswordKey = "supports_self_managed_password"
+
+// SetSupportsSelfManagedPassword sets whether the database plugin supports
+// self-managed passwords. It can be used by database plugins to communicate
+// that they can rotate a password given in UpdateUserRequest's
+// SelfManagedPassword by connecting as the user itself.
+func (ir InitializeResponse) SetSupportsSelfManagedPassword(supported bool) {
+	ir.Config[SupportsSelfManagedPasswordKey] = supported
+}
+
 // ///////////////////////////////////////////////////////
 // NewUser()
 // ///////////////////////////////////////////////////////
@@ -192,6 +204,13 @@ type UpdateUserRequest struct {
 	// Expiration indicates the new expiration date to change to.
 	// If nil, no change is requested.
 	Expiration *ChangeExpiration
+
+	// SelfManagedPassword is the current password of a self-managed user. If
+	// set, the plugin must authenticate to the database as Username using this
+	// password and make the requested changes with that connection rather than
+	// with the connection's root credentials. Plugins that do not support
+	// self-managed users must return an error when this is set.
+	SelfManagedPassword string
 }
 
 // ChangePublicKey of a given user
@@ -41,11 +41,20 @@ type SQLConnectionProducer struct {
 	ServiceAccountJSON       string      `json:"service_account_json" mapstructure:"service_account_json" structs:"service_account_json"`
 	DisableEscaping          bool        `json:"disable_escaping" mapstructure:"disable_escaping" structs:"disable_escaping"`
 
+	// SelfManaged indicates that the connection holds no root credentials and
+	// is only used by static roles that rotate their own password. Connections
+	// are established on demand as the static user with StaticConnection.
+	SelfManaged bool `json:"self_managed" mapstructure:"self_managed" structs:"self_managed"`
+
 	// cloud options here - cloudDriverName is globally unique, but only needs to be retained for the lifetime
 	// of driver registration, not across plugin restarts.
 	cloudDriverName    string
 	cloudDialerCleanup func() error
 
+	// connectionURLTemplate is the connection_url as configured, before the
+	// username and password template variables are substituted.
+	connectionURLTemplate string
+
 	Type                  string
 	RawConfig             map[string]interface{}
 	maxConnectionLifetime time.Duration
@@ -84,24 +93,12 @@ func (c *SQLConnectionProducer) Init(ctx context.Context, conf map[string]interf
 		return nil, fmt.Errorf("username and/or password cannot contain the template variables")
 	}
 
-	// Don't escape special characters for MySQL password
-	// Also don't escape special characters for the username and password if
-	// the disable_escaping parameter is set to true
-	username := c.Username
-	password := c.Password
-	if !c.DisableEscaping {
-		username = url.PathEscape(c.Username)
-	}
-	if (c.Type != "mysql") && !c.DisableEscaping {
-		password = url.PathEscape(c.Password)
+	if c.SelfManaged && (c.Username != "" || c.Password != "") {
+		return nil, fmt.Errorf("cannot use both self-managed and vault-managed workflows: username and password must be empty when self_managed is true")
 	}
 
-	// QueryHelper doesn't do any SQL escaping, but if it starts to do so
-	// then maybe we won't be able to use it to do URL substitution any more.
-	c.ConnectionURL = dbutil.QueryHelper(c.ConnectionURL, map[string]string{
-		"username": username,
-		"password": password,
-	})
+	c.connectionURLTemplate = c.ConnectionURL
+	c.ConnectionURL = c.templateConnectionURL(c.Username, c.Password)
 
 	if c.MaxOpenConnections == 0 {
 		c.MaxOpenConnections = 4
@@ -152,7 +149,9 @@ func (c *SQLConnectionProducer) Init(ctx context.Context, conf map[string]interf
 	// and the connection can be established at a later time.
 	c.Initialized = true
 
-	if verifyConnection {
+	// Self-managed connections have no credentials of their own, so there is
+	// nothing to verify until a static user connects.
+	if verifyConnection && !c.SelfManaged {
 		if _, err := c.Connection(ctx); err != nil {
 			return nil, errwrap.Wrapf("error verifying connection: {{err}}", err)
 		}
@@ -188,6 +187,75 @@ func (c *SQLConnectionProducer) Connection(ctx context.Context) (interface{}, er
 		}
 	}
 
+	if c.SelfManaged {
+		return nil, fmt.Errorf("cannot use a self-managed connection without static user credentials")
+	}
+
+	// Otherwise, attempt to make connection
+	var err error
+	c.db, err = sql.Open(c.driverName(), connectionString(c.ConnectionURL))
+	if err != nil {
+		return nil, err
+	}
+
+	// Set some connection pool settings. We don't need much of this,
+	// since the request rate shouldn't be high.
+	c.db.SetMaxOpenConns(c.MaxOpenConnections)
+	c.db.SetMaxIdleConns(c.MaxIdleConnections)
+	c.db.SetConnMaxLifetime(c.maxConnectionLifetime)
+
+	return c.db, nil
+}
+
+// StaticConnection opens a new connection to the database authenticated as
+// the given static user rather than with the configured root credentials.
+// This is used by self-managed static roles to rotate their own password. The
+// caller is responsible for closing the returned connection.
+func (c *SQLConnectionProducer) StaticConnection(ctx context.Context, username, password string) (*sql.DB, error) {
+	if !c.Initialized {
+		return nil, ErrNotInitialized
+	}
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("username and password are required for a static connection")
+	}
+
+	db, err := sql.Open(c.driverName(), connectionString(c.templateConnectionURL(username, password)))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(0)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to connect as static user: %w", err)
+	}
+
+	return db, nil
+}
+
+// templateConnectionURL substitutes the given username and password into the
+// configured connection_url.
+func (c *SQLConnectionProducer) templateConnectionURL(username, password string) string {
+	// Don't escape special characters for MySQL password
+	// Also don't escape special characters for the username and password if
+	// the disable_escaping parameter is set to true
+	if !c.DisableEscaping {
+		username = url.PathEscape(username)
+	}
+	if (c.Type != "mysql") && !c.DisableEscaping {
+		password = url.PathEscape(password)
+	}
+
+	// QueryHelper doesn't do any SQL escaping, but if it starts to do so
+	// then maybe we won't be able to use it to do URL substitution any more.
+	return dbutil.QueryHelper(c.connectionURLTemplate, map[string]string{
+		"username": username,
+		"password": password,
+	})
+}
+
+func (c *SQLConnectionProducer) driverName() string {
 	// default non-IAM behavior
 	driverName := c.Type
 
@@ -198,9 +266,12 @@ func (c *SQLConnectionProducer) Connection(ctx context.Context) (interface{}, er
 		driverName = "sqlserver"
 	}
 
-	// Otherwise, attempt to make connection
-	conn := c.ConnectionURL
+	return driverName
+}
 
+// connectionString applies any database specific settings to the given
+// connection URL.
+func connectionString(conn string) string {
 	// PostgreSQL specific settings
 	if strings.HasPrefix(conn, "postgres://") || strings.HasPrefix(conn, "postgresql://") {
 		// Ensure timezone is set to UTC for all the connections
@@ -216,19 +287,7 @@ func (c *SQLConnectionProducer) Connection(ctx context.Context) (interface{}, er
 		}
 	}
 
-	var err error
-	c.db, err = sql.Open(driverName, conn)
-	if err != nil {
-		return nil, err
-	}
-
-	// Set some connection pool settings. We don't need much of this,
-	// since the request rate shouldn't be high.
-	c.db.SetMaxOpenConns(c.MaxOpenConnections)
-	c.db.SetMaxIdleConns(c.MaxIdleConnections)
-	c.db.SetConnMaxLifetime(c.maxConnectionLifetime)
-
-	return c.db, nil
+	return conn
 }
 
 func (c *SQLConnectionProducer) SecretValues() map[string]interface{} {
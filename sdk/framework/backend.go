@@ -739,6 +739,30 @@ func (b *Backend) SendEvent(ctx context.Context, eventType logical.EventType, ev
 	return b.events.SendEvent(ctx, eventType, event)
 }
 
+// SendOperationEvent sends a <prefix>/<operation> event describing an
+// operation performed by the backend. The operation, and the data path and
+// modified flag when set, are added to the metadata ahead of the supplied
+// metadata pairs. Events are best-effort: errors sending them are logged, and
+// ignored when the events system is not configured or enabled.
+func (b *Backend) SendOperationEvent(ctx context.Context, prefix, operation, dataPath string, modified bool, metadataPairs ...string) {
+	metadata := []string{
+		logical.EventMetadataOperation, operation,
+	}
+	if modified {
+		metadata = append(metadata, logical.EventMetadataModified, "true")
+	}
+	if dataPath != "" {
+		metadata = append(metadata, logical.EventMetadataDataPath, dataPath)
+	}
+	metadata = append(metadata, metadataPairs...)
+
+	eventType := prefix + "/" + operation
+	err := logical.SendEvent(ctx, b, eventType, metadata...)
+	if err != nil && !errors.Is(err, ErrNoEvents) {
+		b.Logger().Error("error sending event", "event_type", eventType, "error", err)
+	}
+}
+
 // FieldSchema is a basic schema to describe the format of a path field.
 type FieldSchema struct {
 	Type        FieldType
@@ -835,3 +835,37 @@ func TestFieldTypeMethods(t *testing.T) {
 		})
 	}
 }
+
+// TestBackend_SendOperationEvent ensures that operation events carry the
+// standard metadata ahead of the supplied metadata, and that backends without
+// events configured can still send them.
+func TestBackend_SendOperationEvent(t *testing.T) {
+	ctx := context.Background()
+
+	b := &Backend{}
+	b.SendOperationEvent(ctx, "test", "create", "items/foo", true)
+
+	events := &logical.TestEventSender{}
+	config := logical.TestBackendConfig()
+	config.EventsSender = events
+	require.NoError(t, b.Setup(ctx, config))
+
+	b.SendOperationEvent(ctx, "test", "create", "items/foo", true, "name", "foo")
+	b.SendOperationEvent(ctx, "test", "read", "", false)
+
+	received := events.Events()
+	require.Len(t, received, 2)
+
+	require.Equal(t, "test/create", received[0].EventType)
+	require.Equal(t, map[string]interface{}{
+		logical.EventMetadataOperation: "create",
+		logical.EventMetadataModified:  "true",
+		logical.EventMetadataDataPath:  "items/foo",
+		"name":                         "foo",
+	}, received[0].Event.Metadata.AsMap())
+
+	require.Equal(t, "test/read", received[1].EventType)
+	require.Equal(t, map[string]interface{}{
+		logical.EventMetadataOperation: "read",
+	}, received[1].Event.Metadata.AsMap())
+}
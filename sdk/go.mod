@@ -1,12 +1,16 @@
 module github.com/hashicorp/vault/sdk
 
-go 1.19
+// The go directive is the minimum required by github.com/cloudflare/circl,
+// which provides the ML-DSA and ML-KEM key types in helper/keysutil. It should
+// not otherwise be raised beyond what the SDK's importers need.
+go 1.22.0
 
 require (
 	cloud.google.com/go/cloudsqlconn v1.4.3
 	github.com/armon/go-metrics v0.4.1
 	github.com/armon/go-radix v1.0.0
 	github.com/cenkalti/backoff/v3 v3.2.2
+	github.com/cloudflare/circl v1.5.0
 	github.com/docker/docker v24.0.5+incompatible
 	github.com/docker/go-connections v0.4.0
 	github.com/evanphx/json-patch/v5 v5.6.0
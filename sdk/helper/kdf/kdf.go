@@ -12,7 +12,10 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math"
+
+	"golang.org/x/crypto/hkdf"
 )
 
 // PRF is a pseudo-random function that takes a key or seed,
@@ -83,3 +86,27 @@ func HMACSHA256PRF(key []byte, data []byte) ([]byte, error) {
 	hash.Write(data)
 	return hash.Sum(nil), nil
 }
+
+// HKDFSHA256 implements the HMAC-based extract-and-expand KDF of RFC 5869
+// using SHA-256. It derives the required number of output bits from the
+// input key material, an optional salt and an optional context (the HKDF
+// "info" parameter). Unlike CounterMode, the input key material does not
+// need to be uniformly random, which makes this suitable for shared secrets
+// such as the output of a Diffie-Hellman exchange.
+func HKDFSHA256(secret []byte, salt []byte, context []byte, bits uint32) ([]byte, error) {
+	// Ensure the bits required are byte aligned
+	if bits%8 != 0 {
+		return nil, fmt.Errorf("bits required must be byte aligned")
+	}
+
+	// HKDF can produce at most 255 blocks of output
+	if bits > 255*HMACSHA256PRFLen {
+		return nil, fmt.Errorf("too many bits required: %d", bits)
+	}
+
+	out := make([]byte, bits/8)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, context), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
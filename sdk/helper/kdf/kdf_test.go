@@ -5,6 +5,7 @@ package kdf
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
 )
 
@@ -76,3 +77,27 @@ func TestHMACSHA256PRF(t *testing.T) {
 		t.Fatalf("mis-matched output")
 	}
 }
+
+func TestHKDFSHA256(t *testing.T) {
+	// Test case 1 from RFC 5869, Appendix A.1
+	secret, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	context, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	expect, _ := hex.DecodeString("3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+
+	out, err := HKDFSHA256(secret, salt, context, 42*8)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(expect, out) {
+		t.Fatalf("mis-matched output")
+	}
+
+	if _, err := HKDFSHA256(secret, salt, context, 12); err == nil {
+		t.Fatalf("expected error for unaligned bits")
+	}
+
+	if _, err := HKDFSHA256(secret, salt, context, 256*HMACSHA256PRFLen); err == nil {
+		t.Fatalf("expected error for too many bits")
+	}
+}
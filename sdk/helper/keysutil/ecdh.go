@@ -0,0 +1,157 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+func (kt KeyType) ecdhCurve() (ecdh.Curve, error) {
+	switch kt {
+	case KeyType_ECDSA_P256:
+		return ecdh.P256(), nil
+	case KeyType_ECDSA_P384:
+		return ecdh.P384(), nil
+	case KeyType_ECDSA_P521:
+		return ecdh.P521(), nil
+	case KeyType_X25519:
+		return ecdh.X25519(), nil
+	}
+	return nil, fmt.Errorf("key agreement not supported for key type %v", kt)
+}
+
+// ECDHPrivateKey returns the private key of this key entry for use in an
+// ECDH key agreement.
+func (ke *KeyEntry) ECDHPrivateKey(kt KeyType) (*ecdh.PrivateKey, error) {
+	if ke.IsPrivateKeyMissing() {
+		return nil, errutil.UserError{Err: "key version does not contain a private part"}
+	}
+
+	switch kt {
+	case KeyType_X25519:
+		return ecdh.X25519().NewPrivateKey(ke.Key)
+	case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521:
+		var curve elliptic.Curve
+		switch kt {
+		case KeyType_ECDSA_P384:
+			curve = elliptic.P384()
+		case KeyType_ECDSA_P521:
+			curve = elliptic.P521()
+		default:
+			curve = elliptic.P256()
+		}
+
+		key := &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: curve,
+				X:     ke.EC_X,
+				Y:     ke.EC_Y,
+			},
+			D: ke.EC_D,
+		}
+		return key.ECDH()
+	}
+
+	return nil, fmt.Errorf("key agreement not supported for key type %v", kt)
+}
+
+// parsePeerPublicKey parses a peer's public key for a key agreement with a
+// key of the given type. The key may either be a PEM-encoded PKIX public key,
+// or the base64-encoded raw public key: 32 bytes for X25519, or an
+// uncompressed point for the NIST curves.
+func parsePeerPublicKey(kt KeyType, peerPublicKey []byte) (*ecdh.PublicKey, error) {
+	curve, err := kt.ecdhCurve()
+	if err != nil {
+		return nil, err
+	}
+
+	if pemBlock, _ := pem.Decode(peerPublicKey); pemBlock != nil {
+		parsedKey, err := x509.ParsePKIXPublicKey(pemBlock.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing peer public key: %w", err)
+		}
+
+		var publicKey *ecdh.PublicKey
+		switch key := parsedKey.(type) {
+		case *ecdh.PublicKey:
+			publicKey = key
+		case *ecdsa.PublicKey:
+			publicKey, err = key.ECDH()
+			if err != nil {
+				return nil, fmt.Errorf("error parsing peer public key: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("peer public key of type %T does not support key agreement", parsedKey)
+		}
+
+		if publicKey.Curve() != curve {
+			return nil, fmt.Errorf("peer public key curve does not match key type %v", kt)
+		}
+		return publicKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(string(peerPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("peer public key is neither PEM nor base64 encoded")
+	}
+
+	publicKey, err := curve.NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing peer public key: %w", err)
+	}
+	return publicKey, nil
+}
+
+// ECDH performs an elliptic curve Diffie-Hellman key agreement between the
+// private key of the given version and the peer's public key, returning the
+// raw shared secret. The shared secret is not uniformly random, and should be
+// passed through a KDF before being used as a key.
+func (p *Policy) ECDH(ver int, peerPublicKey []byte) ([]byte, error) {
+	if !p.Type.KeyAgreementSupported() {
+		return nil, errutil.UserError{Err: fmt.Sprintf("key agreement not supported for key type %v", p.Type)}
+	}
+	if err := p.checkOperationAllowed(KeyOperationKeyAgreement); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ver == 0:
+		ver = p.LatestVersion
+	case ver < 0:
+		return nil, errutil.UserError{Err: "requested version for key agreement is negative"}
+	case ver > p.LatestVersion:
+		return nil, errutil.UserError{Err: "requested version for key agreement is higher than the latest key version"}
+	case p.MinEncryptionVersion > 0 && ver < p.MinEncryptionVersion:
+		return nil, errutil.UserError{Err: "requested version for key agreement is less than the minimum encryption key version"}
+	}
+
+	keyEntry, err := p.safeGetKeyEntry(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := keyEntry.ECDHPrivateKey(p.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := parsePeerPublicKey(p.Type, peerPublicKey)
+	if err != nil {
+		return nil, errutil.UserError{Err: err.Error()}
+	}
+
+	secret, err := privateKey.ECDH(publicKey)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("key agreement failed: %v", err)}
+	}
+	return secret, nil
+}
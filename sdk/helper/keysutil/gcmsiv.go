@@ -0,0 +1,148 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	tinkaead "github.com/google/tink/go/aead/subtle"
+)
+
+const (
+	gcmSIVNonceSize = tinkaead.AESGCMSIVNonceSize
+	gcmSIVTagSize   = 16
+)
+
+// gcmSIV adapts Tink's AES-GCM-SIV, as specified in RFC 8452, to the
+// cipher.AEAD interface. Unlike AES-GCM, repeating a nonce only reveals
+// whether two messages are equal, so it can be used for deterministic
+// encryption with a nonce derived from the plaintext.
+//
+// Tink only encrypts with random nonces, so Seal follows RFC 8452 using Tink's
+// POLYVAL and the standard library's AES, while Open is Tink's decryption, so
+// that every ciphertext is authenticated by Tink.
+type gcmSIV struct {
+	aead  *tinkaead.AESGCMSIV
+	block cipher.Block
+}
+
+var _ cipher.AEAD = (*gcmSIV)(nil)
+
+// newGCMSIV returns an AES-GCM-SIV AEAD using the given 16 or 32 byte
+// key-generating key.
+func newGCMSIV(key []byte) (cipher.AEAD, error) {
+	aead, err := tinkaead.NewAESGCMSIV(key)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIV{aead: aead, block: block}, nil
+}
+
+func (g *gcmSIV) NonceSize() int { return gcmSIVNonceSize }
+
+func (g *gcmSIV) Overhead() int { return gcmSIVTagSize }
+
+func (g *gcmSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("keysutil: incorrect nonce length given to AES-GCM-SIV")
+	}
+
+	authKey, encBlock := g.deriveKeys(nonce)
+
+	p, err := tinkaead.NewPolyval(authKey)
+	if err != nil {
+		// The derived key is always a single block, so this cannot fail
+		panic(err)
+	}
+	p.Update(additionalData)
+	p.Update(plaintext)
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(additionalData))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	p.Update(lengths[:])
+
+	tag := p.Finish()
+	subtle.XORBytes(tag[:], tag[:], nonce)
+	tag[15] &= 0x7f
+	encBlock.Encrypt(tag[:], tag[:])
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	gcmSIVCTR(encBlock, tag, out[:len(plaintext)], plaintext)
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+func (g *gcmSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("keysutil: incorrect nonce length given to AES-GCM-SIV")
+	}
+
+	// Tink expects the nonce to prefix the ciphertext and tag
+	input := make([]byte, 0, len(nonce)+len(ciphertext))
+	input = append(input, nonce...)
+	input = append(input, ciphertext...)
+
+	plaintext, err := g.aead.Decrypt(input, additionalData)
+	if err != nil {
+		return nil, errors.New("cipher: message authentication failed")
+	}
+	return append(dst, plaintext...), nil
+}
+
+// deriveKeys derives the per-nonce message authentication key and message
+// encryption cipher from the key-generating key, as in RFC 8452, Section 4.
+func (g *gcmSIV) deriveKeys(nonce []byte) ([]byte, cipher.Block) {
+	keyLen := len(g.aead.Key)
+
+	var input, output [16]byte
+	copy(input[4:], nonce)
+
+	derived := make([]byte, 0, 16+keyLen)
+	for i := uint32(0); len(derived) < cap(derived); i++ {
+		binary.LittleEndian.PutUint32(input[:4], i)
+		g.block.Encrypt(output[:], input[:])
+		derived = append(derived, output[:8]...)
+	}
+
+	// The key length was already validated, so this cannot fail
+	encBlock, _ := aes.NewCipher(derived[16:])
+	return derived[:16], encBlock
+}
+
+// gcmSIVCTR applies the AES-GCM-SIV counter mode keystream, whose initial
+// counter block is derived from the tag, and whose counter is the first 32
+// bits as a little-endian integer.
+func gcmSIVCTR(block cipher.Block, tag [16]byte, dst, src []byte) {
+	counter := tag
+	counter[15] |= 0x80
+
+	var keystream [16]byte
+	for len(src) > 0 {
+		block.Encrypt(keystream[:], counter[:])
+		n := subtle.XORBytes(dst, src, keystream[:])
+		dst, src = dst[n:], src[n:]
+		binary.LittleEndian.PutUint32(counter[:4], binary.LittleEndian.Uint32(counter[:4])+1)
+	}
+}
+
+// sliceForAppend extends the input slice by n bytes, returning the extended
+// slice and the n bytes appended.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
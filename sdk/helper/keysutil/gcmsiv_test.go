@@ -0,0 +1,119 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	tinkaead "github.com/google/tink/go/aead/subtle"
+)
+
+func TestGCMSIV(t *testing.T) {
+	// Test vectors from RFC 8452, Appendix C, and a partial block with
+	// additional data
+	tests := []struct {
+		key       string
+		nonce     string
+		plaintext string
+		aad       string
+		expected  string
+	}{
+		{
+			key:      "01000000000000000000000000000000",
+			nonce:    "030000000000000000000000",
+			expected: "dc20e2d83f25705bb49e439eca56de25",
+		},
+		{
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			expected:  "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+		},
+		{
+			key:      "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:    "030000000000000000000000",
+			expected: "07f5f4169bbf55a8400cd47ea6fd400f",
+		},
+		{
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			expected:  "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+		},
+		{
+			key:       "ee8e1ed9ff2540ae8f2ba9f50bc2f27c",
+			nonce:     "752abad3e0afb5f434dc4310",
+			plaintext: "48656c6c6f20776f726c64",
+			aad:       "6578616d706c65",
+			expected:  "5d349ead175ef6b1def6fd4fbcdeb7e4793f4a1d7e4faa70100af1",
+		},
+	}
+
+	for i, test := range tests {
+		aead, err := newGCMSIV(mustDecodeHex(t, test.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonce := mustDecodeHex(t, test.nonce)
+		plaintext := mustDecodeHex(t, test.plaintext)
+		aad := mustDecodeHex(t, test.aad)
+
+		ciphertext := aead.Seal(nil, nonce, plaintext, aad)
+		if !bytes.Equal(ciphertext, mustDecodeHex(t, test.expected)) {
+			t.Fatalf("bad ciphertext for test %d: %x", i, ciphertext)
+		}
+
+		decrypted, err := aead.Open(nil, nonce, ciphertext, aad)
+		if err != nil {
+			t.Fatalf("error decrypting test %d: %s", i, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("bad plaintext for test %d: %x", i, decrypted)
+		}
+
+		ciphertext[0] ^= 1
+		if _, err := aead.Open(nil, nonce, ciphertext, aad); err == nil {
+			t.Fatalf("expected authentication failure for tampered test %d", i)
+		}
+	}
+}
+
+// TestGCMSIV_Tink ensures that sealing with a given nonce produces the same
+// ciphertext as Tink's AES-GCM-SIV encryption with that nonce.
+func TestGCMSIV_Tink(t *testing.T) {
+	for _, keySize := range []int{16, 32} {
+		key := make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatal(err)
+		}
+		tink, err := tinkaead.NewAESGCMSIV(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		aead, err := newGCMSIV(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, size := range []int{0, 1, 16, 33, 1024} {
+			plaintext := make([]byte, size)
+			if _, err := rand.Read(plaintext); err != nil {
+				t.Fatal(err)
+			}
+			aad := []byte("additional data")
+
+			expected, err := tink.Encrypt(plaintext, aad)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce := expected[:gcmSIVNonceSize]
+			ciphertext := aead.Seal(nil, nonce, plaintext, aad)
+			if !bytes.Equal(ciphertext, expected[gcmSIVNonceSize:]) {
+				t.Fatalf("ciphertext of %d bytes with a %d byte key does not match Tink's", size, keySize)
+			}
+		}
+	}
+}
@@ -63,6 +63,13 @@ type PolicyRequest struct {
 	// AllowImportedKeyRotation indicates whether an imported key may be rotated by Vault
 	AllowImportedKeyRotation bool
 
+	// After how many encryptions and signatures the key should automatically
+	// rotate
+	AutoRotateUsageCount uint64
+
+	// The operations allowed with the key; all operations if empty
+	AllowedOperations []string
+
 	// Indicates whether a private or public key is imported/upserted
 	IsPrivateKey bool
 
@@ -231,6 +238,8 @@ func (lm *LockManager) RestorePolicy(ctx context.Context, storage logical.Storag
 	}
 
 	keyData.Policy.l = new(sync.RWMutex)
+	keyData.Policy.usage = new(sync.Map)
+	keyData.Policy.versionPrefixCache = new(sync.Map)
 
 	// Update the cache to contain the restored policy
 	if lm.useCache {
@@ -362,12 +371,18 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 		// because we don't know if the parameters match.
 
 		switch req.KeyType {
-		case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305:
+		case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV:
 			if req.Convergent && !req.Derived {
 				cleanup()
 				return nil, false, fmt.Errorf("convergent encryption requires derivation to be enabled")
 			}
 
+		case KeyType_XChaCha20_Poly1305:
+			if req.Convergent {
+				cleanup()
+				return nil, false, fmt.Errorf("convergent encryption not supported for keys of type %v", req.KeyType)
+			}
+
 		case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521:
 			if req.Derived || req.Convergent {
 				cleanup()
@@ -385,6 +400,11 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 				cleanup()
 				return nil, false, fmt.Errorf("key derivation and convergent encryption not supported for keys of type %v", req.KeyType)
 			}
+		case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87, KeyType_ML_KEM_768, KeyType_ML_KEM_1024, KeyType_X25519:
+			if req.Derived || req.Convergent {
+				cleanup()
+				return nil, false, fmt.Errorf("key derivation and convergent encryption not supported for keys of type %v", req.KeyType)
+			}
 		case KeyType_HMAC:
 			if req.Derived || req.Convergent {
 				cleanup()
@@ -404,12 +424,16 @@ func (lm *LockManager) GetPolicy(ctx context.Context, req PolicyRequest, rand io
 
 		p = &Policy{
 			l:                    new(sync.RWMutex),
+			usage:                new(sync.Map),
+			versionPrefixCache:   new(sync.Map),
 			Name:                 req.Name,
 			Type:                 req.KeyType,
 			Derived:              req.Derived,
 			Exportable:           req.Exportable,
 			AllowPlaintextBackup: req.AllowPlaintextBackup,
 			AutoRotatePeriod:     req.AutoRotatePeriod,
+			AutoRotateUsageCount: req.AutoRotateUsageCount,
+			AllowedOperations:    req.AllowedOperations,
 			KeySize:              req.KeySize,
 		}
 
@@ -503,12 +527,16 @@ func (lm *LockManager) ImportPolicy(ctx context.Context, req PolicyRequest, key
 	if p == nil {
 		p = &Policy{
 			l:                        new(sync.RWMutex),
+			usage:                    new(sync.Map),
+			versionPrefixCache:       new(sync.Map),
 			Name:                     req.Name,
 			Type:                     req.KeyType,
 			Derived:                  req.Derived,
 			Exportable:               req.Exportable,
 			AllowPlaintextBackup:     req.AllowPlaintextBackup,
 			AutoRotatePeriod:         req.AutoRotatePeriod,
+			AutoRotateUsageCount:     req.AutoRotateUsageCount,
+			AllowedOperations:        req.AllowedOperations,
 			AllowImportedKeyRotation: req.AllowImportedKeyRotation,
 			Imported:                 true,
 		}
@@ -582,5 +610,15 @@ func (lm *LockManager) DeletePolicy(ctx context.Context, storage logical.Storage
 }
 
 func (lm *LockManager) getPolicyFromStorage(ctx context.Context, storage logical.Storage, name string) (*Policy, error) {
-	return LoadPolicy(ctx, storage, "policy/"+name)
+	p, err := LoadPolicy(ctx, storage, "policy/"+name)
+	if err != nil || p == nil {
+		return p, err
+	}
+
+	// Usage counted by another cached instance of the policy may not have
+	// been persisted
+	if lm.useCache {
+		p.assumeReservedUsage()
+	}
+	return p, nil
 }
@@ -0,0 +1,229 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// MACType identifies the MAC algorithm used with a key. The zero value is
+// HMAC, whose hash algorithm is chosen separately.
+type MACType uint32
+
+const (
+	MACTypeHMAC MACType = iota
+	MACTypeCMAC
+	MACTypeKMAC128
+	MACTypeKMAC256
+)
+
+var MACTypeMap = map[string]MACType{
+	"cmac":    MACTypeCMAC,
+	"kmac128": MACTypeKMAC128,
+	"kmac256": MACTypeKMAC256,
+}
+
+func (mt MACType) String() string {
+	switch mt {
+	case MACTypeHMAC:
+		return "hmac"
+	case MACTypeCMAC:
+		return "cmac"
+	case MACTypeKMAC128:
+		return "kmac128"
+	case MACTypeKMAC256:
+		return "kmac256"
+	}
+
+	return "[unknown]"
+}
+
+// MACSupported returns whether the CMAC and KMAC algorithms may be used with
+// keys of this type.
+func (kt KeyType) MACSupported() bool {
+	switch kt {
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_HMAC:
+		return true
+	}
+	return false
+}
+
+// MACOnly returns whether the policy is an AES key which may only be used
+// for MACs, as its allowed operations are limited to "hmac".
+func (p *Policy) MACOnly() bool {
+	switch p.Type {
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96:
+		return len(p.AllowedOperations) == 1 && p.AllowedOperations[0] == KeyOperationHMAC
+	}
+	return false
+}
+
+// MACKey returns the key material for the given MAC type. CMAC with AES keys
+// uses a subkey of the same size as the AES key, derived from the HMAC key
+// material with HKDF, so that the encryption key is never used for CMAC. MAC
+// only AES keys, which can never encrypt, use the AES key itself, so that tags
+// interoperate with other holders of an imported key. All other MACs use the
+// same key material as HMAC.
+func (p *Policy) MACKey(version int, macType MACType) ([]byte, error) {
+	if macType != MACTypeHMAC && !p.Type.MACSupported() {
+		return nil, fmt.Errorf("MAC type %v not supported for key type %v", macType, p.Type)
+	}
+	if err := p.checkOperationAllowed(KeyOperationHMAC); err != nil {
+		return nil, err
+	}
+	if macType != MACTypeCMAC || p.Type == KeyType_HMAC {
+		return p.HMACKey(version)
+	}
+
+	hmacKey, err := p.HMACKey(version)
+	if err != nil {
+		return nil, err
+	}
+	keyEntry, err := p.safeGetKeyEntry(version)
+	if err != nil {
+		return nil, err
+	}
+	if p.MACOnly() && !p.Derived {
+		return keyEntry.Key, nil
+	}
+	return deriveCMACKey(hmacKey, len(keyEntry.Key))
+}
+
+// deriveCMACKey derives a CMAC key of the given size from HMAC key material
+// using HKDF-SHA256.
+func deriveCMACKey(hmacKey []byte, size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, hmacKey, nil, []byte("cmac")), key); err != nil {
+		return nil, fmt.Errorf("error deriving CMAC key: %w", err)
+	}
+	return key, nil
+}
+
+// ComputeMAC computes the CMAC or KMAC of the given type over input. CMAC
+// uses AES-128, AES-192 or AES-256 depending on the length of the key.
+// KMAC128 produces a 256-bit tag and KMAC256 a 512-bit tag, both with an
+// empty customization string.
+func ComputeMAC(macType MACType, key, input []byte) ([]byte, error) {
+	switch macType {
+	case MACTypeCMAC:
+		return cmac(key, input)
+	case MACTypeKMAC128:
+		return kmac(sha3.NewCShake128, 168, key, input, nil, 32), nil
+	case MACTypeKMAC256:
+		return kmac(sha3.NewCShake256, 136, key, input, nil, 64), nil
+	default:
+		return nil, fmt.Errorf("unsupported MAC type %v", macType)
+	}
+}
+
+// cmac implements AES-CMAC as specified in NIST SP 800-38B and RFC 4493.
+func cmac(key, input []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key for CMAC: %w", err)
+	}
+
+	// Derive the subkeys from the encryption of the zero block
+	k1 := make([]byte, aes.BlockSize)
+	block.Encrypt(k1, k1)
+	cmacDouble(k1)
+	k2 := make([]byte, aes.BlockSize)
+	copy(k2, k1)
+	cmacDouble(k2)
+
+	n := (len(input) + aes.BlockSize - 1) / aes.BlockSize
+	complete := n > 0 && len(input)%aes.BlockSize == 0
+	if n == 0 {
+		n = 1
+	}
+
+	// Prepare the final block, padded and masked with the relevant subkey
+	last := make([]byte, aes.BlockSize)
+	rest := input[(n-1)*aes.BlockSize:]
+	copy(last, rest)
+	if complete {
+		subtle.XORBytes(last, last, k1)
+	} else {
+		last[len(rest)] = 0x80
+		subtle.XORBytes(last, last, k2)
+	}
+
+	x := make([]byte, aes.BlockSize)
+	for i := 0; i < n-1; i++ {
+		subtle.XORBytes(x, x, input[i*aes.BlockSize:(i+1)*aes.BlockSize])
+		block.Encrypt(x, x)
+	}
+	subtle.XORBytes(x, x, last)
+	block.Encrypt(x, x)
+
+	return x, nil
+}
+
+// cmacDouble multiplies the block by x in GF(2^128), in place.
+func cmacDouble(b []byte) {
+	msb := b[0] >> 7
+	for i := 0; i < len(b)-1; i++ {
+		b[i] = b[i]<<1 | b[i+1]>>7
+	}
+	b[len(b)-1] <<= 1
+	// Constant-time reduction by the polynomial x^128 + x^7 + x^2 + x + 1
+	b[len(b)-1] ^= byte(subtle.ConstantTimeSelect(int(msb), 0x87, 0))
+}
+
+// kmac implements KMAC128 and KMAC256 as specified in NIST SP 800-185,
+// producing a tag of outLen bytes.
+func kmac(newCShake func(N, S []byte) sha3.ShakeHash, rate int, key, input, customization []byte, outLen int) []byte {
+	h := newCShake([]byte("KMAC"), customization)
+
+	h.Write(bytepad(encodeString(key), rate))
+	h.Write(input)
+	h.Write(rightEncode(uint64(outLen * 8)))
+
+	out := make([]byte, outLen)
+	h.Read(out)
+	return out
+}
+
+func leftEncode(x uint64) []byte {
+	var buf [9]byte
+	binary.BigEndian.PutUint64(buf[1:], x)
+	// Trim leading zero bytes, keeping at least one byte of the value
+	i := 1
+	for i < 8 && buf[i] == 0 {
+		i++
+	}
+	buf[i-1] = byte(9 - i)
+	return buf[i-1:]
+}
+
+func rightEncode(x uint64) []byte {
+	var buf [9]byte
+	binary.BigEndian.PutUint64(buf[:8], x)
+	i := 0
+	for i < 7 && buf[i] == 0 {
+		i++
+	}
+	buf[8] = byte(8 - i)
+	return buf[i:]
+}
+
+func encodeString(s []byte) []byte {
+	return append(leftEncode(uint64(len(s)*8)), s...)
+}
+
+func bytepad(x []byte, w int) []byte {
+	buf := append(leftEncode(uint64(w)), x...)
+	if padLen := len(buf) % w; padLen != 0 {
+		buf = append(buf, make([]byte, w-padLen)...)
+	}
+	return buf
+}
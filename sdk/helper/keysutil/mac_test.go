@@ -0,0 +1,238 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCMAC(t *testing.T) {
+	// Test vectors from NIST SP 800-38B, Appendix D
+	const (
+		aes128Key = "2b7e151628aed2a6abf7158809cf4f3c"
+		aes256Key = "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4"
+		message   = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+	)
+
+	tests := []struct {
+		key      string
+		inputLen int
+		expected string
+	}{
+		{aes128Key, 0, "bb1d6929e95937287fa37d129b756746"},
+		{aes128Key, 16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{aes128Key, 40, "dfa66747de9ae63030ca32611497c827"},
+		{aes128Key, 64, "51f0bebf7e3b9d92fc49741779363cfe"},
+		{aes256Key, 0, "028962f61b7bf89efc6b551f4667d983"},
+		{aes256Key, 16, "28a7023f452e8f82bd4bf28d8c37c35c"},
+		{aes256Key, 40, "aaf3d8f1de5640c232f5b169b9c911e6"},
+		{aes256Key, 64, "e1992190549f6ed5696a2c056c315410"},
+	}
+
+	for _, test := range tests {
+		input := mustDecodeHex(t, message)[:test.inputLen]
+		mac, err := ComputeMAC(MACTypeCMAC, mustDecodeHex(t, test.key), input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(mac, mustDecodeHex(t, test.expected)) {
+			t.Fatalf("bad CMAC for %d byte input with %d byte key: %x", test.inputLen, len(test.key)/2, mac)
+		}
+	}
+
+	if _, err := ComputeMAC(MACTypeCMAC, []byte("short"), nil); err == nil {
+		t.Fatal("expected error computing CMAC with invalid key size")
+	}
+}
+
+func TestKMAC(t *testing.T) {
+	// Test vectors from the NIST SP 800-185 KMAC samples
+	key := mustDecodeHex(t, "404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f")
+	shortInput := mustDecodeHex(t, "00010203")
+	longInput := make([]byte, 200)
+	for i := range longInput {
+		longInput[i] = byte(i)
+	}
+
+	tests := []struct {
+		name          string
+		newCShake     func(N, S []byte) sha3.ShakeHash
+		rate          int
+		input         []byte
+		customization string
+		outLen        int
+		expected      string
+	}{
+		{
+			"kmac128 sample 1", sha3.NewCShake128, 168, shortInput, "", 32,
+			"e5780b0d3ea6f7d3a429c5706aa43a00fadbd7d49628839e3187243f456ee14e",
+		},
+		{
+			"kmac128 sample 2", sha3.NewCShake128, 168, shortInput, "My Tagged Application", 32,
+			"3b1fba963cd8b0b59e8c1a6d71888b7143651af8ba0a7070c0979e2811324aa5",
+		},
+		{
+			"kmac256 sample 4", sha3.NewCShake256, 136, shortInput, "My Tagged Application", 64,
+			"20c570c31346f703c9ac36c61c03cb64c3970d0cfc787e9b79599d273a68d2f7f69d4cc3de9d104a351689f27cf6f5951f0103f33f4f24871024d9c27773a8dd",
+		},
+		{
+			"kmac256 sample 5", sha3.NewCShake256, 136, longInput, "", 64,
+			"75358cf39e41494e949707927cee0af20a3ff553904c86b08f21cc414bcfd691589d27cf5e15369cbbff8b9a4c2eb17800855d0235ff635da82533ec6b759b69",
+		},
+	}
+
+	for _, test := range tests {
+		mac := kmac(test.newCShake, test.rate, key, test.input, []byte(test.customization), test.outLen)
+		if !bytes.Equal(mac, mustDecodeHex(t, test.expected)) {
+			t.Fatalf("bad %s: %x", test.name, mac)
+		}
+	}
+
+	// The exported entry points use an empty customization string
+	mac, err := ComputeMAC(MACTypeKMAC128, key, shortInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(mac, mustDecodeHex(t, tests[0].expected)) {
+		t.Fatalf("bad KMAC128: %x", mac)
+	}
+	mac, err = ComputeMAC(MACTypeKMAC256, key, longInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(mac, mustDecodeHex(t, tests[3].expected)) {
+		t.Fatalf("bad KMAC256: %x", mac)
+	}
+}
+
+func TestPolicy_MACKey(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	for _, keyType := range []KeyType{KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_HMAC, KeyType_ECDSA_P256} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			p := &Policy{
+				Name: keyType.String(),
+				Type: keyType,
+			}
+			if keyType == KeyType_HMAC {
+				p.KeySize = HmacMinKeySize
+			}
+			if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+				t.Fatalf("error creating key: %s", err)
+			}
+
+			hmacKey, err := p.HMACKey(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, macType := range []MACType{MACTypeCMAC, MACTypeKMAC128, MACTypeKMAC256} {
+				key, err := p.MACKey(1, macType)
+				if !keyType.MACSupported() {
+					if err == nil {
+						t.Fatalf("expected error getting %v key", macType)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expected := hmacKey
+				if macType == MACTypeCMAC && keyType != KeyType_HMAC {
+					expected = make([]byte, len(p.Keys["1"].Key))
+					if _, err := io.ReadFull(hkdf.New(sha256.New, hmacKey, nil, []byte("cmac")), expected); err != nil {
+						t.Fatal(err)
+					}
+					if bytes.Equal(key, hmacKey) || bytes.Equal(key, p.Keys["1"].Key) {
+						t.Fatal("CMAC key must not be the HMAC or encryption key")
+					}
+				}
+				if !bytes.Equal(key, expected) {
+					t.Fatalf("unexpected %v key", macType)
+				}
+				if _, err := ComputeMAC(macType, key, []byte("input")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := p.MACKey(2, MACTypeCMAC); err == nil {
+				t.Fatal("expected error getting MAC key of missing version")
+			}
+		})
+	}
+}
+
+// TestPolicy_MACKey_CMAC ensures that CMAC with MAC only AES keys uses the AES
+// key at its own size, so that tags match those computed by other holders of
+// an imported key.
+func TestPolicy_MACKey_CMAC(t *testing.T) {
+	// Test vectors from NIST SP 800-38B, Appendix D, Example 2
+	tests := []struct {
+		keyType  KeyType
+		key      string
+		expected string
+	}{
+		{KeyType_AES128_GCM96, "2b7e151628aed2a6abf7158809cf4f3c", "070a16b46b4d4144f79bdd9dd04a287c"},
+		{KeyType_AES256_GCM96, "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", "28a7023f452e8f82bd4bf28d8c37c35c"},
+		{KeyType_HMAC, "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", "28a7023f452e8f82bd4bf28d8c37c35c"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.keyType.String(), func(t *testing.T) {
+			p := &Policy{
+				Name:              test.keyType.String(),
+				Type:              test.keyType,
+				AllowedOperations: []string{KeyOperationHMAC},
+			}
+			if test.keyType == KeyType_HMAC {
+				p.KeySize = HmacMinKeySize
+			}
+			if err := p.Rotate(context.Background(), &logical.InmemStorage{}, rand.Reader); err != nil {
+				t.Fatalf("error creating key: %s", err)
+			}
+
+			// The key material of hmac keys is their HMAC key.
+			keyEntry := p.Keys["1"]
+			keyEntry.Key = mustDecodeHex(t, test.key)
+			p.Keys["1"] = keyEntry
+
+			key, err := p.MACKey(1, MACTypeCMAC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(key) != len(test.key)/2 {
+				t.Fatalf("expected a %d byte CMAC key, got %d bytes", len(test.key)/2, len(key))
+			}
+
+			mac, err := ComputeMAC(MACTypeCMAC, key, mustDecodeHex(t, "6bc1bee22e409f96e93d7e117393172a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(mac, mustDecodeHex(t, test.expected)) {
+				t.Fatalf("bad CMAC: %x", mac)
+			}
+		})
+	}
+}
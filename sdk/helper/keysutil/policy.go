@@ -9,6 +9,7 @@ import (
 	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/hmac"
@@ -70,6 +71,14 @@ const (
 	KeyType_RSA3072
 	KeyType_MANAGED_KEY
 	KeyType_HMAC
+	KeyType_ML_DSA_44
+	KeyType_ML_DSA_65
+	KeyType_ML_DSA_87
+	KeyType_ML_KEM_768
+	KeyType_ML_KEM_1024
+	KeyType_X25519
+	KeyType_AES256_GCM_SIV
+	KeyType_XChaCha20_Poly1305
 )
 
 const (
@@ -124,7 +133,7 @@ type KeyType int
 
 func (kt KeyType) EncryptionSupported() bool {
 	switch kt {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
 		return true
 	}
 	return false
@@ -132,7 +141,10 @@ func (kt KeyType) EncryptionSupported() bool {
 
 func (kt KeyType) DecryptionSupported() bool {
 	switch kt {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
+		return true
+	case KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		// Decryption of an ML-KEM ciphertext decapsulates the shared key
 		return true
 	}
 	return false
@@ -142,6 +154,29 @@ func (kt KeyType) SigningSupported() bool {
 	switch kt {
 	case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521, KeyType_ED25519, KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_MANAGED_KEY:
 		return true
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87:
+		return true
+	}
+	return false
+}
+
+// KeyAgreementSupported returns whether the key type can perform an
+// elliptic curve Diffie-Hellman key agreement with a peer's public key.
+func (kt KeyType) KeyAgreementSupported() bool {
+	switch kt {
+	case KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521, KeyType_X25519:
+		return true
+	}
+	return false
+}
+
+// KeyEncapsulationSupported returns whether the key type is a key
+// encapsulation mechanism, whose data keys are encapsulated shared secrets
+// rather than encrypted random bytes.
+func (kt KeyType) KeyEncapsulationSupported() bool {
+	switch kt {
+	case KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		return true
 	}
 	return false
 }
@@ -156,7 +191,7 @@ func (kt KeyType) HashSignatureInput() bool {
 
 func (kt KeyType) DerivationSupported() bool {
 	switch kt {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_ED25519:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_ED25519:
 		return true
 	}
 	return false
@@ -164,7 +199,7 @@ func (kt KeyType) DerivationSupported() bool {
 
 func (kt KeyType) AssociatedDataSupported() bool {
 	switch kt {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_MANAGED_KEY:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_MANAGED_KEY:
 		return true
 	}
 	return false
@@ -174,6 +209,10 @@ func (kt KeyType) ImportPublicKeySupported() bool {
 	switch kt {
 	case KeyType_RSA2048, KeyType_RSA3072, KeyType_RSA4096, KeyType_ECDSA_P256, KeyType_ECDSA_P384, KeyType_ECDSA_P521, KeyType_ED25519:
 		return true
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87, KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		return true
+	case KeyType_X25519:
+		return true
 	}
 	return false
 }
@@ -186,6 +225,10 @@ func (kt KeyType) String() string {
 		return "aes256-gcm96"
 	case KeyType_ChaCha20_Poly1305:
 		return "chacha20-poly1305"
+	case KeyType_AES256_GCM_SIV:
+		return "aes256-gcm-siv"
+	case KeyType_XChaCha20_Poly1305:
+		return "xchacha20-poly1305"
 	case KeyType_ECDSA_P256:
 		return "ecdsa-p256"
 	case KeyType_ECDSA_P384:
@@ -204,6 +247,18 @@ func (kt KeyType) String() string {
 		return "hmac"
 	case KeyType_MANAGED_KEY:
 		return "managed_key"
+	case KeyType_ML_DSA_44:
+		return "ml-dsa-44"
+	case KeyType_ML_DSA_65:
+		return "ml-dsa-65"
+	case KeyType_ML_DSA_87:
+		return "ml-dsa-87"
+	case KeyType_ML_KEM_768:
+		return "ml-kem-768"
+	case KeyType_ML_KEM_1024:
+		return "ml-kem-1024"
+	case KeyType_X25519:
+		return "x25519"
 	}
 
 	return "[unknown]"
@@ -216,7 +271,8 @@ type KeyData struct {
 
 // KeyEntry stores the key and metadata
 type KeyEntry struct {
-	// AES or some other kind that is a pure byte slice like ED25519
+	// AES or some other kind that is a pure byte slice like ED25519. For
+	// ML-DSA and ML-KEM keys this is the private key seed.
 	Key []byte `json:"key"`
 
 	// Key used for HMAC functions
@@ -248,6 +304,20 @@ type KeyEntry struct {
 	// Key entry certificate chain. If set, leaf certificate key matches the
 	// KeyEntry key
 	CertificateChain [][]byte `json:"certificate_chain"`
+
+	// Number of operations performed with this key version, as of the last
+	// time the policy was persisted
+	EncryptionCount uint64 `json:"encryption_count,omitempty"`
+	DecryptionCount uint64 `json:"decryption_count,omitempty"`
+	SignatureCount  uint64 `json:"signature_count,omitempty"`
+
+	// Number of operations which may be counted in memory before the policy
+	// must be persisted again. A policy loaded into the cache assumes these
+	// were all performed, so that usage is over-counted rather than lost if
+	// the counts held in memory are.
+	EncryptionsReserved uint64 `json:"encryptions_reserved,omitempty"`
+	DecryptionsReserved uint64 `json:"decryptions_reserved,omitempty"`
+	SignaturesReserved  uint64 `json:"signatures_reserved,omitempty"`
 }
 
 func (ke *KeyEntry) IsPrivateKeyMissing() bool {
@@ -327,6 +397,8 @@ type PolicyConfig struct {
 func NewPolicy(config PolicyConfig) *Policy {
 	return &Policy{
 		l:                    new(sync.RWMutex),
+		usage:                new(sync.Map),
+		versionPrefixCache:   new(sync.Map),
 		Name:                 config.Name,
 		Type:                 config.Type,
 		Derived:              config.Derived,
@@ -373,6 +445,8 @@ func LoadPolicy(ctx context.Context, s logical.Storage, path string) (*Policy, e
 	}
 
 	policy.l = new(sync.RWMutex)
+	policy.usage = new(sync.Map)
+	policy.versionPrefixCache = new(sync.Map)
 
 	return &policy, nil
 }
@@ -458,9 +532,27 @@ type Policy struct {
 	// rotate. Setting this to zero disables automatic rotation for the key.
 	AutoRotatePeriod time.Duration `json:"auto_rotate_period"`
 
+	// AutoRotateUsageCount is the number of encryptions and signatures after
+	// which the key should automatically rotate. Setting this to zero disables
+	// usage based rotation for the key.
+	AutoRotateUsageCount uint64 `json:"auto_rotate_usage_count,omitempty"`
+
+	// AllowedOperations restricts the operations which may be performed with
+	// the key. If empty, all operations supported by the key type are allowed.
+	AllowedOperations []string `json:"allowed_operations,omitempty"`
+
+	// usage stores, per key version, the operations counted since the policy
+	// was last persisted. usageCounted is set when usage holds any counts, so
+	// that the map is left untouched for keys which are not used, and
+	// usageUnreserved when the counts exceed the usage reserved in storage.
+	// Like l, usage is a pointer so that the policy can be copied.
+	usage           *sync.Map
+	usageCounted    uint32
+	usageUnreserved uint32
+
 	// versionPrefixCache stores caches of version prefix strings and the split
-	// version template.
-	versionPrefixCache sync.Map
+	// version template. It is a pointer so that the policy can be copied.
+	versionPrefixCache *sync.Map
 
 	// Imported indicates whether the key was generated by Vault or imported
 	// from an external source
@@ -637,10 +729,15 @@ func (p *Policy) Persist(ctx context.Context, storage logical.Storage) (retErr e
 		}
 	}
 
+	// Fold any usage counted in memory into the key entries
+	pendingUsage := p.takePendingUsage()
+	p.applyUsage(pendingUsage)
+
 	defer func() {
 		if retErr != nil {
 			p.ArchiveVersion = priorArchiveVersion
 			p.Keys = priorKeys
+			p.restorePendingUsage(pendingUsage)
 		}
 	}()
 
@@ -847,7 +944,7 @@ func (p *Policy) DeriveKey(context, salt []byte, ver int, numBytes int) ([]byte,
 		}
 
 		switch p.Type {
-		case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305:
+		case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305:
 			n, err := derBytes.ReadFrom(limReader)
 			if err != nil {
 				return nil, errutil.InternalError{Err: fmt.Sprintf("error reading returned derived bytes: %v", err)}
@@ -914,6 +1011,9 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 	if !p.Type.DecryptionSupported() {
 		return "", errutil.UserError{Err: fmt.Sprintf("message decryption not supported for key type %v", p.Type)}
 	}
+	if err := p.checkOperationAllowed(KeyOperationDecrypt); err != nil {
+		return "", err
+	}
 
 	tplParts, err := p.getTemplateParts()
 	if err != nil {
@@ -963,7 +1063,7 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 	var plain []byte
 
 	switch p.Type {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305:
 		numBytes := 32
 		if p.Type == KeyType_AES128_GCM96 {
 			numBytes = 16
@@ -1017,6 +1117,11 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 		if err != nil {
 			return "", errutil.InternalError{Err: fmt.Sprintf("failed to RSA decrypt the ciphertext: %v", err)}
 		}
+	case KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		plain, err = p.decapsulate(ver, decoded)
+		if err != nil {
+			return "", err
+		}
 	case KeyType_MANAGED_KEY:
 		keyEntry, err := p.safeGetKeyEntry(ver)
 		if err != nil {
@@ -1049,6 +1154,8 @@ func (p *Policy) DecryptWithFactory(context, nonce []byte, value string, factori
 		return "", errutil.InternalError{Err: fmt.Sprintf("unsupported key type %v", p.Type)}
 	}
 
+	p.countUsage(ver, usageDecryption)
+
 	return base64.StdEncoding.EncodeToString(plain), nil
 }
 
@@ -1100,6 +1207,9 @@ func (p *Policy) SignWithOptions(ver int, context, input []byte, options *Signin
 	if !p.Type.SigningSupported() {
 		return nil, fmt.Errorf("message signing not supported for key type %v", p.Type)
 	}
+	if err := p.checkOperationAllowed(KeyOperationSign); err != nil {
+		return nil, err
+	}
 
 	switch {
 	case ver == 0:
@@ -1247,6 +1357,19 @@ func (p *Policy) SignWithOptions(ver int, context, input []byte, options *Signin
 			return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported rsa signature algorithm %s", sigAlgorithm)}
 		}
 
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87:
+		privateKey, err := keyParams.PQCPrivateKey(p.Type)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error loading ML-DSA key: %v", err)}
+		}
+
+		// Like ed25519, ML-DSA signs the message directly rather than a
+		// pre-computed hash of it
+		sig, err = privateKey.sign(input)
+		if err != nil {
+			return nil, err
+		}
+
 	case KeyType_MANAGED_KEY:
 		keyEntry, err := p.safeGetKeyEntry(ver)
 		if err != nil {
@@ -1275,6 +1398,8 @@ func (p *Policy) SignWithOptions(ver int, context, input []byte, options *Signin
 		PublicKey: pubKey,
 	}
 
+	p.countUsage(ver, usageSignature)
+
 	return res, nil
 }
 
@@ -1291,6 +1416,9 @@ func (p *Policy) VerifySignatureWithOptions(context, input []byte, sig string, o
 	if !p.Type.SigningSupported() {
 		return false, errutil.UserError{Err: fmt.Sprintf("message verification not supported for key type %v", p.Type)}
 	}
+	if err := p.checkOperationAllowed(KeyOperationVerify); err != nil {
+		return false, err
+	}
 
 	tplParts, err := p.getTemplateParts()
 	if err != nil {
@@ -1447,6 +1575,23 @@ func (p *Policy) VerifySignatureWithOptions(context, input []byte, sig string, o
 
 		return err == nil, nil
 
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87:
+		keyEntry, err := p.safeGetKeyEntry(ver)
+		if err != nil {
+			return false, err
+		}
+
+		publicKey, err := keyEntry.PQCPublicKey(p.Type)
+		if err != nil {
+			return false, errutil.InternalError{Err: err.Error()}
+		}
+
+		verified, err := publicKey.verify(input, sigBytes)
+		if err != nil {
+			return false, errutil.InternalError{Err: err.Error()}
+		}
+		return verified, nil
+
 	case KeyType_MANAGED_KEY:
 		keyEntry, err := p.safeGetKeyEntry(ver)
 		if err != nil {
@@ -1496,12 +1641,14 @@ func (p *Policy) ImportPublicOrPrivate(ctx context.Context, storage logical.Stor
 	}
 
 	if (p.Type == KeyType_AES128_GCM96 && len(key) != 16) ||
-		((p.Type == KeyType_AES256_GCM96 || p.Type == KeyType_ChaCha20_Poly1305) && len(key) != 32) ||
+		((p.Type == KeyType_AES256_GCM96 || p.Type == KeyType_ChaCha20_Poly1305 ||
+			p.Type == KeyType_AES256_GCM_SIV || p.Type == KeyType_XChaCha20_Poly1305) && len(key) != 32) ||
 		(p.Type == KeyType_HMAC && (len(key) < HmacMinKeySize || len(key) > HmacMaxKeySize)) {
 		return fmt.Errorf("invalid key size %d bytes for key type %s", len(key), p.Type)
 	}
 
-	if p.Type == KeyType_AES128_GCM96 || p.Type == KeyType_AES256_GCM96 || p.Type == KeyType_ChaCha20_Poly1305 || p.Type == KeyType_HMAC {
+	if p.Type == KeyType_AES128_GCM96 || p.Type == KeyType_AES256_GCM96 || p.Type == KeyType_ChaCha20_Poly1305 ||
+		p.Type == KeyType_AES256_GCM_SIV || p.Type == KeyType_XChaCha20_Poly1305 || p.Type == KeyType_HMAC {
 		entry.Key = key
 		if p.Type == KeyType_HMAC {
 			p.KeySize = len(key)
@@ -1510,7 +1657,14 @@ func (p *Policy) ImportPublicOrPrivate(ctx context.Context, storage logical.Stor
 	} else {
 		var parsedKey any
 		var err error
-		if isPrivateKey {
+		if isPrivateKey && p.Type.isPQC() {
+			// crypto/x509 does not parse ML-DSA and ML-KEM keys in the
+			// toolchain we build with.
+			parsedKey, err = ParsePKCS8PQCPrivateKey(key)
+			if err != nil {
+				return fmt.Errorf("error parsing asymmetric key: %w", err)
+			}
+		} else if isPrivateKey {
 			parsedKey, err = x509.ParsePKCS8PrivateKey(key)
 			if err != nil {
 				if strings.Contains(err.Error(), "unknown elliptic curve") {
@@ -1539,7 +1693,11 @@ func (p *Policy) ImportPublicOrPrivate(ctx context.Context, storage logical.Stor
 				return fmt.Errorf("error parsing public key: not in PEM format")
 			}
 
-			parsedKey, err = x509.ParsePKIXPublicKey(pemBlock.Bytes)
+			if p.Type.isPQC() {
+				parsedKey, err = ParsePKIXPQCPublicKey(pemBlock.Bytes)
+			} else {
+				parsedKey, err = x509.ParsePKIXPublicKey(pemBlock.Bytes)
+			}
 			if err != nil {
 				return fmt.Errorf("error parsing public key: %w", err)
 			}
@@ -1620,7 +1778,7 @@ func (p *Policy) RotateInMemory(randReader io.Reader) (retErr error) {
 	entry.HMACKey = hmacKey
 
 	switch p.Type {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_HMAC:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_HMAC:
 		// Default to 256 bit key
 		numBytes := 32
 		if p.Type == KeyType_AES128_GCM96 {
@@ -1701,6 +1859,23 @@ func (p *Policy) RotateInMemory(randReader io.Reader) (retErr error) {
 		if err != nil {
 			return err
 		}
+
+	case KeyType_X25519:
+		privKey, err := ecdh.X25519().GenerateKey(randReader)
+		if err != nil {
+			return err
+		}
+		entry.Key = privKey.Bytes()
+		entry.FormattedPublicKey = base64.StdEncoding.EncodeToString(privKey.PublicKey().Bytes())
+
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87, KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		privateKey, err := generatePQCKey(p.Type, randReader)
+		if err != nil {
+			return err
+		}
+		if err := entry.parseFromKey(p.Type, privateKey); err != nil {
+			return err
+		}
 	}
 
 	if p.ConvergentEncryption {
@@ -1790,9 +1965,10 @@ func (p *Policy) Backup(ctx context.Context, storage logical.Storage) (out strin
 }
 
 func (p *Policy) getTemplateParts() ([]string, error) {
-	partsRaw, ok := p.versionPrefixCache.Load("template-parts")
-	if ok {
-		return partsRaw.([]string), nil
+	if p.versionPrefixCache != nil {
+		if partsRaw, ok := p.versionPrefixCache.Load("template-parts"); ok {
+			return partsRaw.([]string), nil
+		}
 	}
 
 	template := p.VersionTemplate
@@ -1805,14 +1981,17 @@ func (p *Policy) getTemplateParts() ([]string, error) {
 		return nil, errutil.InternalError{Err: "error parsing version template"}
 	}
 
-	p.versionPrefixCache.Store("template-parts", tplParts)
+	if p.versionPrefixCache != nil {
+		p.versionPrefixCache.Store("template-parts", tplParts)
+	}
 	return tplParts, nil
 }
 
 func (p *Policy) getVersionPrefix(ver int) string {
-	prefixRaw, ok := p.versionPrefixCache.Load(ver)
-	if ok {
-		return prefixRaw.(string)
+	if p.versionPrefixCache != nil {
+		if prefixRaw, ok := p.versionPrefixCache.Load(ver); ok {
+			return prefixRaw.(string)
+		}
 	}
 
 	template := p.VersionTemplate
@@ -1821,7 +2000,9 @@ func (p *Policy) getVersionPrefix(ver int) string {
 	}
 
 	prefix := strings.ReplaceAll(template, "{{version}}", strconv.Itoa(ver))
-	p.versionPrefixCache.Store(ver, prefix)
+	if p.versionPrefixCache != nil {
+		p.versionPrefixCache.Store(ver, prefix)
+	}
 
 	return prefix
 }
@@ -1837,8 +2018,12 @@ type SymmetricOpts struct {
 	Nonce []byte
 	// Additional data to include in AEAD authentication
 	AdditionalData []byte
-	// The HMAC key, for generating IVs in convergent encryption
+	// The HMAC key, for generating IVs in convergent encryption or when
+	// DeterministicNonce is set
 	HMACKey []byte
+	// Whether to derive the nonce from the plaintext with HMACKey without
+	// convergent encryption, as AES-GCM-SIV keys do
+	DeterministicNonce bool
 	// Allows an external provider of the AEAD, for e.g. managed keys
 	AEADFactory AEADFactory
 }
@@ -1872,6 +2057,20 @@ func (p *Policy) SymmetricEncryptRaw(ver int, encKey, plaintext []byte, opts Sym
 		}
 
 		aead = cha
+
+	case KeyType_AES256_GCM_SIV:
+		aead, err = newGCMSIV(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+	case KeyType_XChaCha20_Poly1305:
+		xcha, err := chacha20poly1305.NewX(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+		aead = xcha
 	case KeyType_MANAGED_KEY:
 		if opts.Convergent || len(opts.Nonce) != 0 {
 			return nil, errutil.UserError{Err: "cannot use convergent encryption or provide a nonce to managed-key backed encryption"}
@@ -1896,13 +2095,15 @@ func (p *Policy) SymmetricEncryptRaw(ver int, encKey, plaintext []byte, opts Sym
 			if len(opts.HMACKey) == 0 {
 				return nil, errutil.InternalError{Err: fmt.Sprintf("invalid hmac key length of zero")}
 			}
-			nonceHmac := hmac.New(sha256.New, opts.HMACKey)
-			nonceHmac.Write(plaintext)
-			nonceSum := nonceHmac.Sum(nil)
-			nonce = nonceSum[:aead.NonceSize()]
+			nonce = plaintextNonce(opts.HMACKey, plaintext, aead.NonceSize())
 		default:
 			return nil, errutil.InternalError{Err: fmt.Sprintf("unhandled convergent version %d", convergentVersion)}
 		}
+	} else if opts.DeterministicNonce {
+		if len(opts.HMACKey) == 0 {
+			return nil, errutil.InternalError{Err: "invalid hmac key length of zero"}
+		}
+		nonce = plaintextNonce(opts.HMACKey, plaintext, aead.NonceSize())
 	} else if len(nonce) == 0 {
 		// Compute random nonce
 		nonce, err = uuid.GenerateRandomBytes(aead.NonceSize())
@@ -1923,6 +2124,14 @@ func (p *Policy) SymmetricEncryptRaw(ver int, encKey, plaintext []byte, opts Sym
 	return ciphertext, nil
 }
 
+// plaintextNonce derives a nonce of the given size from the plaintext, so that
+// encrypting the same plaintext with the same keys produces the same ciphertext.
+func plaintextNonce(hmacKey, plaintext []byte, size int) []byte {
+	nonceHmac := hmac.New(sha256.New, hmacKey)
+	nonceHmac.Write(plaintext)
+	return nonceHmac.Sum(nil)[:size]
+}
+
 // Symmetrically decrypt a ciphertext given the convergence configuration and appropriate keys
 func (p *Policy) SymmetricDecryptRaw(encKey, ciphertext []byte, opts SymmetricOpts) ([]byte, error) {
 	var aead cipher.AEAD
@@ -1952,6 +2161,20 @@ func (p *Policy) SymmetricDecryptRaw(encKey, ciphertext []byte, opts SymmetricOp
 		}
 
 		aead = cha
+
+	case KeyType_AES256_GCM_SIV:
+		aead, err = newGCMSIV(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+	case KeyType_XChaCha20_Poly1305:
+		xcha, err := chacha20poly1305.NewX(encKey)
+		if err != nil {
+			return nil, errutil.InternalError{Err: err.Error()}
+		}
+
+		aead = xcha
 	case KeyType_MANAGED_KEY:
 		aead, err = opts.AEADFactory.GetAEAD(nonce)
 		if err != nil {
@@ -1965,9 +2188,10 @@ func (p *Policy) SymmetricDecryptRaw(encKey, ciphertext []byte, opts SymmetricOp
 
 	// Extract the nonce and ciphertext
 	var trueCT []byte
-	if opts.Convergent && opts.ConvergentVersion == 1 {
+	switch {
+	case opts.Convergent && opts.ConvergentVersion == 1:
 		trueCT = ciphertext
-	} else {
+	default:
 		nonce = ciphertext[:aead.NonceSize()]
 		trueCT = ciphertext[aead.NonceSize():]
 	}
@@ -1984,6 +2208,9 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 	if !p.Type.EncryptionSupported() {
 		return "", errutil.UserError{Err: fmt.Sprintf("message encryption not supported for key type %v", p.Type)}
 	}
+	if err := p.checkOperationAllowed(KeyOperationEncrypt); err != nil {
+		return "", err
+	}
 
 	// Decode the plaintext value
 	plaintext, err := base64.StdEncoding.DecodeString(value)
@@ -2005,7 +2232,7 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 	var ciphertext []byte
 
 	switch p.Type {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305:
 		hmacKey := context
 
 		var encKey []byte
@@ -2013,6 +2240,16 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 
 		encBytes := 32
 		hmacBytes := 0
+
+		// AES-GCM-SIV keys encrypt deterministically without convergent
+		// encryption, deriving the nonce from the plaintext as convergent
+		// version 3 does. A context is only required for derived keys.
+		deterministic := p.Type == KeyType_AES256_GCM_SIV && !p.ConvergentEncryption
+		if deterministic && p.Derived {
+			deriveHMAC = true
+			hmacBytes = 32
+		}
+
 		convergentVersion := p.convergentVersion(ver)
 		if convergentVersion > 2 {
 			deriveHMAC = true
@@ -2045,12 +2282,18 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 			if len(hmacKey) != hmacBytes {
 				return "", errutil.InternalError{Err: "could not derive hmac key, length not correct"}
 			}
+		} else if deterministic {
+			hmacKey, err = p.HMACKey(ver)
+			if err != nil {
+				return "", err
+			}
 		}
 
 		symopts := SymmetricOpts{
-			Convergent: p.ConvergentEncryption,
-			HMACKey:    hmacKey,
-			Nonce:      nonce,
+			Convergent:         p.ConvergentEncryption,
+			HMACKey:            hmacKey,
+			Nonce:              nonce,
+			DeterministicNonce: deterministic,
 		}
 		for index, rawFactory := range factories {
 			if rawFactory == nil {
@@ -2128,6 +2371,8 @@ func (p *Policy) EncryptWithFactory(ver int, context []byte, nonce []byte, value
 	// Prepend some information
 	encoded = p.getVersionPrefix(ver) + encoded
 
+	p.countUsage(ver, usageEncryption)
+
 	return encoded, nil
 }
 
@@ -2160,8 +2405,15 @@ func (p *Policy) ImportPrivateKeyForVersion(ctx context.Context, storage logical
 	}
 
 	// Parse key
-	parsedPrivateKey, err := x509.ParsePKCS8PrivateKey(key)
-	if err != nil {
+	var parsedPrivateKey any
+	if p.Type.isPQC() {
+		// crypto/x509 does not parse ML-DSA and ML-KEM keys in the
+		// toolchain we build with.
+		parsedPrivateKey, err = ParsePKCS8PQCPrivateKey(key)
+		if err != nil {
+			return fmt.Errorf("error parsing asymmetric key: %w", err)
+		}
+	} else if parsedPrivateKey, err = x509.ParsePKCS8PrivateKey(key); err != nil {
 		if strings.Contains(err.Error(), "unknown elliptic curve") {
 			var edErr error
 			parsedPrivateKey, edErr = ParsePKCS8Ed25519PrivateKey(key)
@@ -2211,6 +2463,23 @@ func (p *Policy) ImportPrivateKeyForVersion(ctx context.Context, storage logical
 		if !ed25519.PublicKey(publicKey).Equal(ed25519Key.Public()) {
 			return fmt.Errorf("cannot import key, key pair does not match")
 		}
+	case *ecdh.PrivateKey:
+		ecdhKey := parsedPrivateKey.(*ecdh.PrivateKey)
+		publicKey, err := base64.StdEncoding.DecodeString(keyEntry.FormattedPublicKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse key entry public key: %v", err)
+		}
+		if !bytes.Equal(ecdhKey.PublicKey().Bytes(), publicKey) {
+			return fmt.Errorf("cannot import key, key pair does not match")
+		}
+	case *PQCPrivateKey:
+		publicKey, err := keyEntry.PQCPublicKey(p.Type)
+		if err != nil {
+			return err
+		}
+		if !pqcKeyPairMatches(parsedPrivateKey.(*PQCPrivateKey), publicKey) {
+			return fmt.Errorf("cannot import key, key pair does not match")
+		}
 	}
 
 	err = keyEntry.parseFromKey(p.Type, parsedPrivateKey)
@@ -2319,6 +2588,52 @@ func (ke *KeyEntry) parseFromKey(PolKeyType KeyType, parsedKey any) error {
 			}
 			ke.RSAPublicKey = rsaKey
 		}
+	case *ecdh.PrivateKey, *ecdh.PublicKey:
+		if PolKeyType != KeyType_X25519 {
+			return fmt.Errorf("invalid key type: expected %s, got %T", PolKeyType, parsedKey)
+		}
+
+		var publicKey *ecdh.PublicKey
+		privateKey, ok := parsedKey.(*ecdh.PrivateKey)
+		if ok {
+			publicKey = privateKey.PublicKey()
+		} else {
+			publicKey = parsedKey.(*ecdh.PublicKey)
+		}
+
+		if publicKey.Curve() != ecdh.X25519() {
+			return fmt.Errorf("invalid curve: expected X25519, got %v", publicKey.Curve())
+		}
+
+		if ok {
+			ke.Key = privateKey.Bytes()
+		}
+		ke.FormattedPublicKey = base64.StdEncoding.EncodeToString(publicKey.Bytes())
+	case *PQCPrivateKey, *PQCPublicKey:
+		privateKey, ok := parsedKey.(*PQCPrivateKey)
+		var publicKey *PQCPublicKey
+		if ok {
+			var err error
+			publicKey, err = privateKey.PublicKey()
+			if err != nil {
+				return err
+			}
+		} else {
+			publicKey = parsedKey.(*PQCPublicKey)
+		}
+
+		if publicKey.KeyType != PolKeyType {
+			return fmt.Errorf("invalid key type: expected %s, got %s", PolKeyType, publicKey.KeyType)
+		}
+
+		if ok {
+			ke.Key = privateKey.Seed
+		}
+		formatted, err := formatPQCPublicKey(publicKey)
+		if err != nil {
+			return err
+		}
+		ke.FormattedPublicKey = formatted
 	default:
 		return fmt.Errorf("invalid key type: expected %s, got %T", PolKeyType, parsedKey)
 	}
@@ -2359,7 +2674,7 @@ func (ke *KeyEntry) WrapKey(targetKey interface{}, targetKeyType KeyType, hash h
 
 	var preppedTargetKey []byte
 	switch targetKeyType {
-	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_HMAC:
+	case KeyType_AES128_GCM96, KeyType_AES256_GCM96, KeyType_ChaCha20_Poly1305, KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305, KeyType_HMAC:
 		var ok bool
 		preppedTargetKey, ok = targetKey.([]byte)
 		if !ok {
@@ -2367,7 +2682,7 @@ func (ke *KeyEntry) WrapKey(targetKey interface{}, targetKeyType KeyType, hash h
 		}
 	default:
 		var err error
-		preppedTargetKey, err = x509.MarshalPKCS8PrivateKey(targetKey)
+		preppedTargetKey, err = MarshalPKCS8PrivateKey(targetKey)
 		if err != nil {
 			return "", fmt.Errorf("failed to wrap target key for import: %w", err)
 		}
@@ -6,18 +6,22 @@ package keysutil
 import (
 	"bytes"
 	"context"
+	"crypto/ecdh"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	mathrand "math/rand"
 	"reflect"
 	"strconv"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
@@ -523,7 +527,8 @@ func Test_BadUpgrade(t *testing.T) {
 	k.CreationTime = o.CreationTime
 	k.HMACKey = o.HMACKey
 	p.Keys["1"] = k
-	p.versionPrefixCache = sync.Map{}
+	p.usage = orig.(*Policy).usage
+	p.versionPrefixCache = orig.(*Policy).versionPrefixCache
 
 	if !reflect.DeepEqual(orig, p) {
 		t.Fatalf("not equal:\n%#v\n%#v", orig, p)
@@ -650,6 +655,22 @@ func Test_Import(t *testing.T) {
 			key:         testKeys[KeyType_AES256_GCM96],
 			shouldError: false,
 		},
+		"import AES-GCM-SIV key": {
+			policy: Policy{
+				Name: "test-aes-gcm-siv-key",
+				Type: KeyType_AES256_GCM_SIV,
+			},
+			key:         testKeys[KeyType_AES256_GCM96],
+			shouldError: false,
+		},
+		"import XChaCha20-Poly1305 key": {
+			policy: Policy{
+				Name: "test-xchacha20-key",
+				Type: KeyType_XChaCha20_Poly1305,
+			},
+			key:         testKeys[KeyType_AES256_GCM96],
+			shouldError: false,
+		},
 		"import RSA key": {
 			policy: Policy{
 				Name: "test-rsa-key",
@@ -674,6 +695,38 @@ func Test_Import(t *testing.T) {
 			key:         testKeys[KeyType_ED25519],
 			shouldError: false,
 		},
+		"import X25519 key": {
+			policy: Policy{
+				Name: "test-x25519-key",
+				Type: KeyType_X25519,
+			},
+			key:         testKeys[KeyType_X25519],
+			shouldError: false,
+		},
+		"import ML-DSA key": {
+			policy: Policy{
+				Name: "test-ml-dsa-key",
+				Type: KeyType_ML_DSA_65,
+			},
+			key:         testKeys[KeyType_ML_DSA_65],
+			shouldError: false,
+		},
+		"import ML-KEM key": {
+			policy: Policy{
+				Name: "test-ml-kem-key",
+				Type: KeyType_ML_KEM_768,
+			},
+			key:         testKeys[KeyType_ML_KEM_768],
+			shouldError: false,
+		},
+		"import ML-DSA key with incorrect parameters": {
+			policy: Policy{
+				Name: "test-ml-dsa-87-key",
+				Type: KeyType_ML_DSA_87,
+			},
+			key:         testKeys[KeyType_ML_DSA_65],
+			shouldError: true,
+		},
 		"import incorrect key type": {
 			policy: Policy{
 				Name: "test-ed25519-key",
@@ -746,6 +799,36 @@ func generateTestKeys() (map[KeyType][]byte, error) {
 	}
 	keyMap[KeyType_ED25519] = ed25519KeyBytes
 
+	x25519Key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	x25519KeyBytes, err := x509.MarshalPKCS8PrivateKey(x25519Key)
+	if err != nil {
+		return nil, err
+	}
+	keyMap[KeyType_X25519] = x25519KeyBytes
+
+	mldsaKey, err := generatePQCKey(KeyType_ML_DSA_65, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	mldsaKeyBytes, err := MarshalPKCS8PQCPrivateKey(mldsaKey)
+	if err != nil {
+		return nil, err
+	}
+	keyMap[KeyType_ML_DSA_65] = mldsaKeyBytes
+
+	mlkemKey, err := generatePQCKey(KeyType_ML_KEM_768, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	mlkemKeyBytes, err := MarshalPKCS8PQCPrivateKey(mlkemKey)
+	if err != nil {
+		return nil, err
+	}
+	keyMap[KeyType_ML_KEM_768] = mlkemKeyBytes
+
 	aesKey := make([]byte, 32)
 	_, err = rand.Read(aesKey)
 	if err != nil {
@@ -1067,3 +1150,391 @@ func isUnsupportedGoHashType(hashType HashType, err error) bool {
 
 	return false
 }
+
+func Test_MLDSA(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	input := []byte("the quick brown fox jumps over the lazy dog")
+
+	for _, keyType := range []KeyType{KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			p := &Policy{
+				Name: keyType.String(),
+				Type: keyType,
+			}
+			if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+				t.Fatalf("error creating key: %s", err)
+			}
+
+			for marshalingName, marshalingType := range MarshalingTypeMap {
+				sig, err := p.Sign(0, nil, input, HashTypeNone, "", marshalingType)
+				if err != nil {
+					t.Fatalf("error signing with %s marshaling: %s", marshalingName, err)
+				}
+
+				verified, err := p.VerifySignature(nil, input, HashTypeNone, "", marshalingType, sig.Signature)
+				if err != nil {
+					t.Fatalf("error verifying with %s marshaling: %s", marshalingName, err)
+				}
+				if !verified {
+					t.Fatalf("failed to verify signature with %s marshaling", marshalingName)
+				}
+
+				verified, err = p.VerifySignature(nil, []byte("some other input"), HashTypeNone, "", marshalingType, sig.Signature)
+				if err != nil {
+					t.Fatalf("error verifying with %s marshaling: %s", marshalingName, err)
+				}
+				if verified {
+					t.Fatalf("verified signature over the wrong input with %s marshaling", marshalingName)
+				}
+			}
+		})
+	}
+
+	// Import only the public key, then the matching private key
+	privateKey, err := generatePQCKey(KeyType_ML_DSA_65, rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+	publicKey, err := privateKey.PublicKey()
+	if err != nil {
+		t.Fatalf("error deriving public key: %s", err)
+	}
+	publicKeyBytes, err := MarshalPKIXPQCPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("error marshaling public key: %s", err)
+	}
+	privateKeyBytes, err := MarshalPKCS8PQCPrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("error marshaling private key: %s", err)
+	}
+
+	p := &Policy{
+		Name: "ml-dsa-imported",
+		Type: KeyType_ML_DSA_65,
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+	if err := p.ImportPublicOrPrivate(ctx, storage, publicKeyPEM, false, rand.Reader); err != nil {
+		t.Fatalf("error importing public key: %s", err)
+	}
+
+	sig, err := privateKey.sign(input)
+	if err != nil {
+		t.Fatalf("error signing: %s", err)
+	}
+	verified, err := p.VerifySignature(nil, input, HashTypeNone, "", MarshalingTypeASN1, "vault:v1:"+base64.StdEncoding.EncodeToString(sig))
+	if err != nil || !verified {
+		t.Fatalf("failed to verify signature with imported public key: %v", err)
+	}
+
+	if _, err := p.Sign(0, nil, input, HashTypeNone, "", MarshalingTypeASN1); err == nil {
+		t.Fatal("expected signing without a private key to fail")
+	}
+
+	otherKey, err := generatePQCKey(KeyType_ML_DSA_65, rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+	otherKeyBytes, err := MarshalPKCS8PQCPrivateKey(otherKey)
+	if err != nil {
+		t.Fatalf("error marshaling private key: %s", err)
+	}
+	if err := p.ImportPrivateKeyForVersion(ctx, storage, 1, otherKeyBytes); err == nil {
+		t.Fatal("expected importing a mismatched private key to fail")
+	}
+	if err := p.ImportPrivateKeyForVersion(ctx, storage, 1, privateKeyBytes); err != nil {
+		t.Fatalf("error importing private key: %s", err)
+	}
+	if _, err := p.Sign(1, nil, input, HashTypeNone, "", MarshalingTypeASN1); err != nil {
+		t.Fatalf("error signing with imported private key: %s", err)
+	}
+}
+
+func Test_MLKEM(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	for _, keyType := range []KeyType{KeyType_ML_KEM_768, KeyType_ML_KEM_1024} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			p := &Policy{
+				Name: keyType.String(),
+				Type: keyType,
+			}
+			if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+				t.Fatalf("error creating key: %s", err)
+			}
+
+			if _, err := p.Encrypt(0, nil, nil, base64.StdEncoding.EncodeToString([]byte("plaintext"))); err == nil {
+				t.Fatal("expected encryption with an ML-KEM key to fail")
+			}
+
+			sharedKey, ciphertext, err := p.Encapsulate(0)
+			if err != nil {
+				t.Fatalf("error encapsulating: %s", err)
+			}
+			if len(sharedKey) != 32 {
+				t.Fatalf("unexpected shared key length %d", len(sharedKey))
+			}
+			if !strings.HasPrefix(ciphertext, "vault:v1:") {
+				t.Fatalf("unexpected ciphertext %q", ciphertext)
+			}
+
+			decrypted, err := p.Decrypt(nil, nil, ciphertext)
+			if err != nil {
+				t.Fatalf("error decapsulating: %s", err)
+			}
+			if decrypted != base64.StdEncoding.EncodeToString(sharedKey) {
+				t.Fatal("decapsulated shared key does not match")
+			}
+
+			// The private key must round-trip through PKCS#8 for export
+			// and BYOK.
+			keyEntry, err := p.safeGetKeyEntry(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			privateKey, err := keyEntry.PQCPrivateKey(p.Type)
+			if err != nil {
+				t.Fatalf("error loading private key: %s", err)
+			}
+			der, err := MarshalPKCS8PrivateKey(privateKey)
+			if err != nil {
+				t.Fatalf("error marshaling private key: %s", err)
+			}
+			imported := &Policy{
+				Name: keyType.String() + "-imported",
+				Type: keyType,
+			}
+			if err := imported.Import(ctx, storage, der, rand.Reader); err != nil {
+				t.Fatalf("error importing private key: %s", err)
+			}
+			decrypted, err = imported.Decrypt(nil, nil, ciphertext)
+			if err != nil {
+				t.Fatalf("error decapsulating with imported key: %s", err)
+			}
+			if decrypted != base64.StdEncoding.EncodeToString(sharedKey) {
+				t.Fatal("decapsulated shared key does not match with imported key")
+			}
+		})
+	}
+}
+
+// Test_PQCSeedCompatibility checks that ML-DSA and ML-KEM keys derive the
+// FIPS 204 and FIPS 203 public keys from their stored seeds, so that keys
+// remain usable across implementations.
+func Test_PQCSeedCompatibility(t *testing.T) {
+	for keyType, expected := range map[KeyType]string{
+		KeyType_ML_DSA_44:   "9f107644c1084526af3bc8098680b05499a2325a644e388fb4f970e058d19d46",
+		KeyType_ML_DSA_65:   "d666806e11cee19a7c989f7445f90dd419cf4d2d51db8c0fdb4c0f0a542238c9",
+		KeyType_ML_DSA_87:   "91dc389cfaa01470b7f66eee45a4ae9026d154817c754dfe22298b3fa241ffcd",
+		KeyType_ML_KEM_768:  "0b7934c83125c788995e2ba6bd761e33046b3e40571be53e023309a29f398cc9",
+		KeyType_ML_KEM_1024: "c7b8fa0aa471d5ae18922d6ccad5b31e1d84f92ae723abfd13747018740a8530",
+	} {
+		t.Run(keyType.String(), func(t *testing.T) {
+			size, err := keyType.pqcSeedSize()
+			if err != nil {
+				t.Fatal(err)
+			}
+			seed := make([]byte, size)
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+
+			privateKey, err := newPQCPrivateKey(keyType, seed)
+			if err != nil {
+				t.Fatalf("error creating private key: %s", err)
+			}
+			publicKey, err := privateKey.PublicKey()
+			if err != nil {
+				t.Fatalf("error deriving public key: %s", err)
+			}
+			if actual := sha256.Sum256(publicKey.Key); hex.EncodeToString(actual[:]) != expected {
+				t.Fatalf("unexpected public key for seed: got SHA-256 %x, expected %s", actual, expected)
+			}
+
+			der, err := MarshalPKCS8PQCPrivateKey(privateKey)
+			if err != nil {
+				t.Fatalf("error marshaling private key: %s", err)
+			}
+			parsed, err := ParsePKCS8PQCPrivateKey(der)
+			if err != nil {
+				t.Fatalf("error parsing private key: %s", err)
+			}
+			if parsed.KeyType != keyType || !bytes.Equal(parsed.Seed, seed) {
+				t.Fatal("private key did not round-trip through PKCS#8")
+			}
+		})
+	}
+}
+
+func Test_ECDH(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+
+	curves := map[KeyType]ecdh.Curve{
+		KeyType_ECDSA_P256: ecdh.P256(),
+		KeyType_ECDSA_P384: ecdh.P384(),
+		KeyType_ECDSA_P521: ecdh.P521(),
+		KeyType_X25519:     ecdh.X25519(),
+	}
+
+	for keyType, curve := range curves {
+		t.Run(keyType.String(), func(t *testing.T) {
+			p := &Policy{
+				Name: keyType.String(),
+				Type: keyType,
+			}
+			if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+				t.Fatalf("error creating key: %s", err)
+			}
+
+			peerKey, err := curve.GenerateKey(rand.Reader)
+			if err != nil {
+				t.Fatalf("error generating peer key: %s", err)
+			}
+
+			keyEntry, err := p.safeGetKeyEntry(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			privateKey, err := keyEntry.ECDHPrivateKey(p.Type)
+			if err != nil {
+				t.Fatalf("error loading private key: %s", err)
+			}
+			expected, err := peerKey.ECDH(privateKey.PublicKey())
+			if err != nil {
+				t.Fatalf("error computing expected secret: %s", err)
+			}
+
+			// The raw, base64-encoded form of the peer public key
+			rawPeer := []byte(base64.StdEncoding.EncodeToString(peerKey.PublicKey().Bytes()))
+			secret, err := p.ECDH(0, rawPeer)
+			if err != nil {
+				t.Fatalf("error performing key agreement: %s", err)
+			}
+			if !bytes.Equal(secret, expected) {
+				t.Fatal("shared secret mismatch with raw peer key")
+			}
+
+			// The PEM-encoded PKIX form of the peer public key
+			der, err := x509.MarshalPKIXPublicKey(peerKey.PublicKey())
+			if err != nil {
+				t.Fatalf("error marshaling peer public key: %s", err)
+			}
+			pemPeer := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+			secret, err = p.ECDH(1, pemPeer)
+			if err != nil {
+				t.Fatalf("error performing key agreement: %s", err)
+			}
+			if !bytes.Equal(secret, expected) {
+				t.Fatal("shared secret mismatch with PEM peer key")
+			}
+		})
+	}
+
+	// A peer key on a different curve must be rejected
+	p := &Policy{
+		Name: "ecdh-mismatch",
+		Type: KeyType_ECDSA_P256,
+	}
+	if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+		t.Fatalf("error creating key: %s", err)
+	}
+	peerKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating peer key: %s", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(peerKey.PublicKey())
+	if err != nil {
+		t.Fatalf("error marshaling peer public key: %s", err)
+	}
+	if _, err := p.ECDH(0, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})); err == nil {
+		t.Fatal("expected key agreement with a mismatched curve to fail")
+	}
+
+	// Key types without key agreement must be rejected
+	p = &Policy{
+		Name: "ecdh-unsupported",
+		Type: KeyType_ED25519,
+	}
+	if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+		t.Fatalf("error creating key: %s", err)
+	}
+	if _, err := p.ECDH(0, []byte(base64.StdEncoding.EncodeToString(peerKey.PublicKey().Bytes()))); err == nil {
+		t.Fatal("expected key agreement with an ed25519 key to fail")
+	}
+}
+
+func Test_GCMSIV_XChaCha20(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+
+	for _, keyType := range []KeyType{KeyType_AES256_GCM_SIV, KeyType_XChaCha20_Poly1305} {
+		for _, derived := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/derived=%t", keyType, derived), func(t *testing.T) {
+				p := &Policy{
+					Name:    keyType.String(),
+					Type:    keyType,
+					Derived: derived,
+				}
+				var context, otherContext []byte
+				if derived {
+					p.KDF = Kdf_hkdf_sha256
+					context = []byte("context")
+					otherContext = []byte("other context")
+				}
+				if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+					t.Fatalf("error creating key: %s", err)
+				}
+
+				ciphertext, err := p.Encrypt(0, context, nil, plaintext)
+				if err != nil {
+					t.Fatalf("error encrypting: %s", err)
+				}
+				decrypted, err := p.Decrypt(context, nil, ciphertext)
+				if err != nil {
+					t.Fatalf("error decrypting: %s", err)
+				}
+				if decrypted != plaintext {
+					t.Fatalf("bad plaintext: %s", decrypted)
+				}
+
+				// AES-GCM-SIV encryption is deterministic, with a nonce derived
+				// from the plaintext, while XChaCha20-Poly1305 uses random nonces
+				again, err := p.Encrypt(0, context, nil, plaintext)
+				if err != nil {
+					t.Fatalf("error encrypting: %s", err)
+				}
+				if deterministic := keyType == KeyType_AES256_GCM_SIV; (again == ciphertext) != deterministic {
+					t.Fatalf("expected deterministic encryption to be %t", deterministic)
+				}
+				otherPlaintext := base64.StdEncoding.EncodeToString([]byte("jumps over the lazy dog"))
+				other, err := p.Encrypt(0, context, nil, otherPlaintext)
+				if err != nil {
+					t.Fatalf("error encrypting: %s", err)
+				}
+				if other[:len("vault:v1:")+16] == ciphertext[:len("vault:v1:")+16] {
+					t.Fatal("expected different plaintexts to use different nonces")
+				}
+
+				if derived {
+					other, err := p.Encrypt(0, otherContext, nil, plaintext)
+					if err != nil {
+						t.Fatalf("error encrypting: %s", err)
+					}
+					if other == ciphertext {
+						t.Fatal("expected different ciphertexts for different contexts")
+					}
+					if _, err := p.Decrypt(otherContext, nil, ciphertext); err == nil {
+						t.Fatal("expected error decrypting with the wrong context")
+					}
+				}
+
+				if _, err := p.Encrypt(0, context, []byte("nonce"), plaintext); err == nil {
+					t.Fatal("expected error encrypting with a nonce")
+				}
+			})
+		}
+	}
+}
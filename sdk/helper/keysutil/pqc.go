@@ -0,0 +1,355 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// PQCPrivateKey is an ML-DSA or ML-KEM private key. It is represented by the
+// seed the key is derived from, which is the form in which these keys are
+// stored, imported and exported.
+type PQCPrivateKey struct {
+	KeyType KeyType
+	Seed    []byte
+}
+
+// PQCPublicKey is an ML-DSA or ML-KEM public key, in its encoded form.
+type PQCPublicKey struct {
+	KeyType KeyType
+	Key     []byte
+}
+
+func (kt KeyType) isPQC() bool {
+	switch kt {
+	case KeyType_ML_DSA_44, KeyType_ML_DSA_65, KeyType_ML_DSA_87, KeyType_ML_KEM_768, KeyType_ML_KEM_1024:
+		return true
+	}
+	return false
+}
+
+func (kt KeyType) mldsaScheme() (sign.Scheme, error) {
+	switch kt {
+	case KeyType_ML_DSA_44:
+		return mldsa44.Scheme(), nil
+	case KeyType_ML_DSA_65:
+		return mldsa65.Scheme(), nil
+	case KeyType_ML_DSA_87:
+		return mldsa87.Scheme(), nil
+	}
+	return nil, fmt.Errorf("key type %v is not an ML-DSA key type", kt)
+}
+
+func (kt KeyType) mlkemScheme() (kem.Scheme, error) {
+	switch kt {
+	case KeyType_ML_KEM_768:
+		return mlkem768.Scheme(), nil
+	case KeyType_ML_KEM_1024:
+		return mlkem1024.Scheme(), nil
+	}
+	return nil, fmt.Errorf("key type %v is not an ML-KEM key type", kt)
+}
+
+// pqcSeedSize returns the size of the seed of an ML-DSA or ML-KEM key: the
+// 32-byte ξ of FIPS 204, or the 64-byte d || z of FIPS 203.
+func (kt KeyType) pqcSeedSize() (int, error) {
+	if kt.KeyEncapsulationSupported() {
+		scheme, err := kt.mlkemScheme()
+		if err != nil {
+			return 0, err
+		}
+		return scheme.SeedSize(), nil
+	}
+
+	scheme, err := kt.mldsaScheme()
+	if err != nil {
+		return 0, err
+	}
+	return scheme.SeedSize(), nil
+}
+
+// newPQCPrivateKey returns the private key of the given type derived from
+// the given seed.
+func newPQCPrivateKey(kt KeyType, seed []byte) (*PQCPrivateKey, error) {
+	size, err := kt.pqcSeedSize()
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != size {
+		return nil, fmt.Errorf("invalid %v seed length: expected %d bytes, got %d", kt, size, len(seed))
+	}
+
+	return &PQCPrivateKey{
+		KeyType: kt,
+		Seed:    append([]byte(nil), seed...),
+	}, nil
+}
+
+// newPQCPublicKey returns the public key of the given type after checking
+// that the encoded key is valid.
+func newPQCPublicKey(kt KeyType, key []byte) (*PQCPublicKey, error) {
+	var err error
+	if kt.KeyEncapsulationSupported() {
+		var scheme kem.Scheme
+		if scheme, err = kt.mlkemScheme(); err == nil {
+			_, err = scheme.UnmarshalBinaryPublicKey(key)
+		}
+	} else {
+		var scheme sign.Scheme
+		if scheme, err = kt.mldsaScheme(); err == nil {
+			_, err = scheme.UnmarshalBinaryPublicKey(key)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v public key: %w", kt, err)
+	}
+
+	return &PQCPublicKey{
+		KeyType: kt,
+		Key:     append([]byte(nil), key...),
+	}, nil
+}
+
+// generatePQCKey generates a new ML-DSA or ML-KEM private key of the given
+// type, reading its seed from randReader.
+func generatePQCKey(kt KeyType, randReader io.Reader) (*PQCPrivateKey, error) {
+	size, err := kt.pqcSeedSize()
+	if err != nil {
+		return nil, err
+	}
+
+	seed := make([]byte, size)
+	if _, err := io.ReadFull(randReader, seed); err != nil {
+		return nil, err
+	}
+	return newPQCPrivateKey(kt, seed)
+}
+
+// PublicKey returns the public key corresponding to this private key.
+func (k *PQCPrivateKey) PublicKey() (*PQCPublicKey, error) {
+	var encoded []byte
+	var err error
+	if k.KeyType.KeyEncapsulationSupported() {
+		var scheme kem.Scheme
+		if scheme, err = k.KeyType.mlkemScheme(); err == nil {
+			publicKey, _ := scheme.DeriveKeyPair(k.Seed)
+			encoded, err = publicKey.MarshalBinary()
+		}
+	} else {
+		var scheme sign.Scheme
+		if scheme, err = k.KeyType.mldsaScheme(); err == nil {
+			publicKey, _ := scheme.DeriveKey(k.Seed)
+			encoded, err = publicKey.MarshalBinary()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &PQCPublicKey{
+		KeyType: k.KeyType,
+		Key:     encoded,
+	}, nil
+}
+
+// Equal returns whether the two public keys are the same key.
+func (k *PQCPublicKey) Equal(other *PQCPublicKey) bool {
+	return other != nil && k.KeyType == other.KeyType && bytes.Equal(k.Key, other.Key)
+}
+
+// sign signs the message with this ML-DSA private key, using the hedged
+// (randomized) variant of ML-DSA.
+func (k *PQCPrivateKey) sign(message []byte) ([]byte, error) {
+	scheme, err := k.KeyType.mldsaScheme()
+	if err != nil {
+		return nil, err
+	}
+	_, privateKey := scheme.DeriveKey(k.Seed)
+
+	sig := make([]byte, scheme.SignatureSize())
+	switch key := privateKey.(type) {
+	case *mldsa44.PrivateKey:
+		err = mldsa44.SignTo(key, message, nil, true, sig)
+	case *mldsa65.PrivateKey:
+		err = mldsa65.SignTo(key, message, nil, true, sig)
+	case *mldsa87.PrivateKey:
+		err = mldsa87.SignTo(key, message, nil, true, sig)
+	default:
+		return nil, fmt.Errorf("unexpected private key type %T", privateKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// verify checks an ML-DSA signature of the message against this public key.
+func (k *PQCPublicKey) verify(message, sig []byte) (bool, error) {
+	scheme, err := k.KeyType.mldsaScheme()
+	if err != nil {
+		return false, err
+	}
+	publicKey, err := scheme.UnmarshalBinaryPublicKey(k.Key)
+	if err != nil {
+		return false, err
+	}
+
+	return scheme.Verify(publicKey, message, sig, nil), nil
+}
+
+// PQCPrivateKey returns the ML-DSA or ML-KEM private key of this key entry,
+// reconstructed from the stored seed.
+func (ke *KeyEntry) PQCPrivateKey(kt KeyType) (*PQCPrivateKey, error) {
+	if ke.IsPrivateKeyMissing() {
+		return nil, errutil.UserError{Err: "key version does not contain a private part"}
+	}
+
+	return newPQCPrivateKey(kt, ke.Key)
+}
+
+// PQCPublicKey returns the ML-DSA or ML-KEM public key of this key entry.
+func (ke *KeyEntry) PQCPublicKey(kt KeyType) (*PQCPublicKey, error) {
+	pemBlock, _ := pem.Decode([]byte(ke.FormattedPublicKey))
+	if pemBlock == nil {
+		return nil, fmt.Errorf("failed to parse key entry public key: invalid PEM blob")
+	}
+
+	publicKey, err := ParsePKIXPQCPublicKey(pemBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse key entry public key: %w", err)
+	}
+	if publicKey.KeyType != kt {
+		return nil, fmt.Errorf("failed to parse key entry public key: expected %v key, got %v", kt, publicKey.KeyType)
+	}
+
+	return publicKey, nil
+}
+
+// formatPQCPublicKey PEM-encodes an ML-DSA or ML-KEM public key in PKIX form.
+func formatPQCPublicKey(publicKey *PQCPublicKey) (string, error) {
+	derBytes, err := MarshalPKIXPQCPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("error marshaling public key: %w", err)
+	}
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: derBytes,
+	})
+	if len(pemBytes) == 0 {
+		return "", fmt.Errorf("error PEM-encoding public key")
+	}
+	return string(pemBytes), nil
+}
+
+// MarshalPKCS8PrivateKey converts a private key to PKCS #8, ASN.1 DER form,
+// including the ML-DSA and ML-KEM private keys not supported by crypto/x509.
+func MarshalPKCS8PrivateKey(key interface{}) ([]byte, error) {
+	if pqcKey, ok := key.(*PQCPrivateKey); ok {
+		return MarshalPKCS8PQCPrivateKey(pqcKey)
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// Encapsulate generates a new shared key against the ML-KEM public key of the
+// given version, returning the shared key and its encapsulation, prefixed
+// like a ciphertext so that it can be passed to Decrypt to recover the shared
+// key.
+func (p *Policy) Encapsulate(ver int) ([]byte, string, error) {
+	if !p.Type.KeyEncapsulationSupported() {
+		return nil, "", errutil.UserError{Err: fmt.Sprintf("key encapsulation not supported for key type %v", p.Type)}
+	}
+	if err := p.checkOperationAllowed(KeyOperationEncrypt); err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case ver == 0:
+		ver = p.LatestVersion
+	case ver < 0:
+		return nil, "", errutil.UserError{Err: "requested version for encapsulation is negative"}
+	case ver > p.LatestVersion:
+		return nil, "", errutil.UserError{Err: "requested version for encapsulation is higher than the latest key version"}
+	case p.MinEncryptionVersion > 0 && ver < p.MinEncryptionVersion:
+		return nil, "", errutil.UserError{Err: "requested version for encapsulation is less than the minimum encryption key version"}
+	}
+
+	keyEntry, err := p.safeGetKeyEntry(ver)
+	if err != nil {
+		return nil, "", err
+	}
+
+	publicKey, err := keyEntry.PQCPublicKey(p.Type)
+	if err != nil {
+		return nil, "", errutil.InternalError{Err: err.Error()}
+	}
+
+	scheme, err := p.Type.mlkemScheme()
+	if err != nil {
+		return nil, "", errutil.InternalError{Err: err.Error()}
+	}
+	encapsulationKey, err := scheme.UnmarshalBinaryPublicKey(publicKey.Key)
+	if err != nil {
+		return nil, "", errutil.InternalError{Err: err.Error()}
+	}
+	ciphertext, sharedKey, err := scheme.Encapsulate(encapsulationKey)
+	if err != nil {
+		return nil, "", errutil.InternalError{Err: err.Error()}
+	}
+
+	p.countUsage(ver, usageEncryption)
+
+	return sharedKey, p.getVersionPrefix(ver) + base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decapsulate recovers the shared key from an ML-KEM ciphertext.
+func (p *Policy) decapsulate(ver int, ciphertext []byte) ([]byte, error) {
+	keyEntry, err := p.safeGetKeyEntry(ver)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := keyEntry.PQCPrivateKey(p.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme, err := p.Type.mlkemScheme()
+	if err != nil {
+		return nil, errutil.InternalError{Err: err.Error()}
+	}
+	_, decapsulationKey := scheme.DeriveKeyPair(privateKey.Seed)
+
+	sharedKey, err := scheme.Decapsulate(decapsulationKey, ciphertext)
+	if err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("invalid ciphertext: %v", err)}
+	}
+
+	return sharedKey, nil
+}
+
+// pqcKeyPairMatches returns whether the given ML-DSA or ML-KEM private key
+// corresponds to the given public key.
+func pqcKeyPairMatches(privateKey *PQCPrivateKey, publicKey *PQCPublicKey) bool {
+	derived, err := privateKey.PublicKey()
+	if err != nil {
+		return false
+	}
+	return derived.Equal(publicKey)
+}
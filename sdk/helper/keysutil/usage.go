@@ -0,0 +1,277 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+)
+
+// Operations which may be restricted with a policy's AllowedOperations.
+const (
+	KeyOperationEncrypt      = "encrypt"
+	KeyOperationDecrypt      = "decrypt"
+	KeyOperationSign         = "sign"
+	KeyOperationVerify       = "verify"
+	KeyOperationHMAC         = "hmac"
+	KeyOperationKeyAgreement = "key-agreement"
+)
+
+// KeyOperations lists all operations which may be set in a policy's
+// AllowedOperations.
+var KeyOperations = []string{
+	KeyOperationEncrypt,
+	KeyOperationDecrypt,
+	KeyOperationSign,
+	KeyOperationVerify,
+	KeyOperationHMAC,
+	KeyOperationKeyAgreement,
+}
+
+// ValidateKeyOperations returns an error if any of the given operations is
+// not one of KeyOperations.
+func ValidateKeyOperations(operations []string) error {
+	for _, op := range operations {
+		if !strutil.StrListContains(KeyOperations, op) {
+			return fmt.Errorf("unknown operation %q, must be one of: %s", op, strings.Join(KeyOperations, ", "))
+		}
+	}
+	return nil
+}
+
+// OperationAllowed returns whether the policy allows the given operation. All
+// operations are allowed if AllowedOperations is empty.
+func (p *Policy) OperationAllowed(op string) bool {
+	return len(p.AllowedOperations) == 0 || strutil.StrListContains(p.AllowedOperations, op)
+}
+
+func (p *Policy) checkOperationAllowed(op string) error {
+	if !p.OperationAllowed(op) {
+		return errutil.UserError{Err: fmt.Sprintf("operation %q is not allowed for this key", op)}
+	}
+	return nil
+}
+
+// KeyUsage counts the operations performed with a key version.
+type KeyUsage struct {
+	Encryptions uint64 `json:"encryptions"`
+	Decryptions uint64 `json:"decryptions"`
+	Signatures  uint64 `json:"signatures"`
+}
+
+// usageReservationBlock is the number of operations of each type reserved in
+// storage at a time, so that the policy only needs to be persisted once per
+// block of operations for usage not to be lost.
+const usageReservationBlock = 1000
+
+type usageType int
+
+const (
+	usageEncryption usageType = iota
+	usageDecryption
+	usageSignature
+)
+
+// countUsage counts an operation performed with the given key version. The
+// count is held in memory until the policy is next persisted; it is safe to
+// call while holding only a read lock. Usage is not counted for policies which
+// were not created by NewPolicy, LoadPolicy or the lock manager.
+func (p *Policy) countUsage(ver int, usage usageType) {
+	if p.usage == nil {
+		return
+	}
+
+	raw, ok := p.usage.Load(ver)
+	if !ok {
+		raw, _ = p.usage.LoadOrStore(ver, &KeyUsage{})
+	}
+	pending := raw.(*KeyUsage)
+	keyEntry := p.Keys[strconv.Itoa(ver)]
+
+	var count, reserved uint64
+	switch usage {
+	case usageEncryption:
+		count = keyEntry.EncryptionCount + atomic.AddUint64(&pending.Encryptions, 1)
+		reserved = keyEntry.EncryptionsReserved
+	case usageDecryption:
+		count = keyEntry.DecryptionCount + atomic.AddUint64(&pending.Decryptions, 1)
+		reserved = keyEntry.DecryptionsReserved
+	case usageSignature:
+		count = keyEntry.SignatureCount + atomic.AddUint64(&pending.Signatures, 1)
+		reserved = keyEntry.SignaturesReserved
+	}
+	atomic.StoreUint32(&p.usageCounted, 1)
+	if count > reserved {
+		atomic.StoreUint32(&p.usageUnreserved, 1)
+	}
+}
+
+// UsageReservationExceeded returns whether more operations have been counted
+// in memory than were reserved in storage, in which case the policy should be
+// persisted before the operations are acknowledged.
+func (p *Policy) UsageReservationExceeded() bool {
+	return atomic.LoadUint32(&p.usageUnreserved) == 1
+}
+
+// assumeReservedUsage counts all of the usage reserved in storage as having
+// been performed, as it may have been by an instance of the policy whose
+// counts were never persisted.
+func (p *Policy) assumeReservedUsage() {
+	for keyVer, keyEntry := range p.Keys {
+		if keyEntry.EncryptionCount < keyEntry.EncryptionsReserved {
+			keyEntry.EncryptionCount = keyEntry.EncryptionsReserved
+		}
+		if keyEntry.DecryptionCount < keyEntry.DecryptionsReserved {
+			keyEntry.DecryptionCount = keyEntry.DecryptionsReserved
+		}
+		if keyEntry.SignatureCount < keyEntry.SignaturesReserved {
+			keyEntry.SignatureCount = keyEntry.SignaturesReserved
+		}
+		p.Keys[keyVer] = keyEntry
+	}
+}
+
+// usageReservationSize returns the number of operations of each type to
+// reserve at a time, which is limited to a hundredth of the auto rotate usage
+// count so that keys are not rotated much early after a restart.
+func (p *Policy) usageReservationSize() uint64 {
+	size := uint64(usageReservationBlock)
+	if p.AutoRotateUsageCount > 0 && p.AutoRotateUsageCount/100 < size {
+		size = p.AutoRotateUsageCount / 100
+	}
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+// Usage returns the operations performed with the given key version,
+// including those not yet persisted.
+func (p *Policy) Usage(ver int) KeyUsage {
+	var usage KeyUsage
+	if keyEntry, ok := p.Keys[fmt.Sprint(ver)]; ok {
+		usage.Encryptions = keyEntry.EncryptionCount
+		usage.Decryptions = keyEntry.DecryptionCount
+		usage.Signatures = keyEntry.SignatureCount
+	}
+	if atomic.LoadUint32(&p.usageCounted) == 0 {
+		return usage
+	}
+	if raw, ok := p.usage.Load(ver); ok {
+		pending := raw.(*KeyUsage)
+		usage.Encryptions += atomic.LoadUint64(&pending.Encryptions)
+		usage.Decryptions += atomic.LoadUint64(&pending.Decryptions)
+		usage.Signatures += atomic.LoadUint64(&pending.Signatures)
+	}
+	return usage
+}
+
+// HasPendingUsage returns whether operations have been counted since the
+// policy was last persisted.
+func (p *Policy) HasPendingUsage() bool {
+	if atomic.LoadUint32(&p.usageCounted) == 0 {
+		return false
+	}
+	found := false
+	p.usage.Range(func(_, raw interface{}) bool {
+		pending := raw.(*KeyUsage)
+		found = atomic.LoadUint64(&pending.Encryptions) != 0 ||
+			atomic.LoadUint64(&pending.Decryptions) != 0 ||
+			atomic.LoadUint64(&pending.Signatures) != 0
+		return !found
+	})
+	return found
+}
+
+// UsageRotationRequired returns whether the latest key version has been used
+// for at least AutoRotateUsageCount encryptions and signatures combined.
+func (p *Policy) UsageRotationRequired() bool {
+	if p.AutoRotateUsageCount == 0 {
+		return false
+	}
+	usage := p.Usage(p.LatestVersion)
+	return usage.Encryptions+usage.Signatures >= p.AutoRotateUsageCount
+}
+
+// takePendingUsage resets the usage counted in memory, returning the counts
+// per key version.
+func (p *Policy) takePendingUsage() map[int]KeyUsage {
+	atomic.StoreUint32(&p.usageUnreserved, 0)
+	if atomic.SwapUint32(&p.usageCounted, 0) == 0 {
+		return nil
+	}
+	taken := map[int]KeyUsage{}
+	p.usage.Range(func(rawVer, raw interface{}) bool {
+		pending := raw.(*KeyUsage)
+		taken[rawVer.(int)] = KeyUsage{
+			Encryptions: atomic.SwapUint64(&pending.Encryptions, 0),
+			Decryptions: atomic.SwapUint64(&pending.Decryptions, 0),
+			Signatures:  atomic.SwapUint64(&pending.Signatures, 0),
+		}
+		return true
+	})
+	return taken
+}
+
+// restorePendingUsage adds back usage taken with takePendingUsage, e.g. when
+// persisting the policy failed.
+func (p *Policy) restorePendingUsage(taken map[int]KeyUsage) {
+	if len(taken) == 0 || p.usage == nil {
+		return
+	}
+	for ver, usage := range taken {
+		raw, _ := p.usage.LoadOrStore(ver, &KeyUsage{})
+		pending := raw.(*KeyUsage)
+		atomic.AddUint64(&pending.Encryptions, usage.Encryptions)
+		atomic.AddUint64(&pending.Decryptions, usage.Decryptions)
+		atomic.AddUint64(&pending.Signatures, usage.Signatures)
+	}
+	atomic.StoreUint32(&p.usageCounted, 1)
+	atomic.StoreUint32(&p.usageUnreserved, 1)
+}
+
+// applyUsage adds the given usage to the counts stored in the key entries,
+// reserving a further block of operations of each type which has used up its
+// reservation. Usage of key versions which no longer exist is dropped.
+func (p *Policy) applyUsage(taken map[int]KeyUsage) {
+	size := p.usageReservationSize()
+	reserve := func(count uint64, reserved *uint64) {
+		if count >= *reserved {
+			*reserved = count + size
+		}
+	}
+
+	for ver, usage := range taken {
+		keyVer := fmt.Sprint(ver)
+		keyEntry, ok := p.Keys[keyVer]
+		if !ok {
+			continue
+		}
+		keyEntry.EncryptionCount += usage.Encryptions
+		keyEntry.DecryptionCount += usage.Decryptions
+		keyEntry.SignatureCount += usage.Signatures
+		if usage.Encryptions > 0 {
+			reserve(keyEntry.EncryptionCount, &keyEntry.EncryptionsReserved)
+		}
+		if usage.Decryptions > 0 {
+			reserve(keyEntry.DecryptionCount, &keyEntry.DecryptionsReserved)
+		}
+		if usage.Signatures > 0 {
+			reserve(keyEntry.SignatureCount, &keyEntry.SignaturesReserved)
+		}
+		p.Keys[keyVer] = keyEntry
+	}
+}
+
+// TransferUsage moves the usage counted in memory to another instance of the
+// policy, e.g. when this instance was evicted from the cache or is otherwise
+// stale.
+func (p *Policy) TransferUsage(to *Policy) {
+	to.restorePendingUsage(p.takePendingUsage())
+}
@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package keysutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestPolicy_Usage(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+
+	p := NewPolicy(PolicyConfig{
+		Name: "test",
+		Type: KeyType_AES256_GCM96,
+	})
+	p.AutoRotateUsageCount = 3
+	if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if p.HasPendingUsage() {
+		t.Fatal("expected no pending usage for a new key")
+	}
+
+	var ciphertext string
+	for i := 0; i < 2; i++ {
+		var err error
+		ciphertext, err = p.Encrypt(0, nil, nil, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := p.Decrypt(nil, nil, ciphertext); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Decrypt(nil, nil, "vault:v1:bad"); err == nil {
+		t.Fatal("expected error decrypting bad ciphertext")
+	}
+
+	expected := KeyUsage{Encryptions: 2, Decryptions: 1}
+	if usage := p.Usage(1); usage != expected {
+		t.Fatalf("bad usage before persisting: %#v", usage)
+	}
+	if !p.HasPendingUsage() {
+		t.Fatal("expected pending usage")
+	}
+	if p.UsageRotationRequired() {
+		t.Fatal("expected rotation not to be required yet")
+	}
+
+	if err := p.Persist(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	if p.HasPendingUsage() {
+		t.Fatal("expected no pending usage after persisting")
+	}
+	if usage := p.Usage(1); usage != expected {
+		t.Fatalf("bad usage after persisting: %#v", usage)
+	}
+
+	loaded, err := LoadPolicy(ctx, storage, "policy/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := loaded.Usage(1); usage != expected {
+		t.Fatalf("bad usage after loading: %#v", usage)
+	}
+
+	if _, err := p.Encrypt(0, nil, nil, plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if !p.UsageRotationRequired() {
+		t.Fatal("expected rotation to be required")
+	}
+	if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+	if p.UsageRotationRequired() {
+		t.Fatal("expected rotation not to be required for the new version")
+	}
+	if usage := p.Usage(1); usage.Encryptions != 3 {
+		t.Fatalf("bad usage of the previous version: %#v", usage)
+	}
+}
+
+// TestPolicy_UsageReservation ensures that usage is reserved in storage ahead
+// of use, and that a policy loaded into the cache counts the reserved usage,
+// as the counts held in memory by another instance may have been lost.
+func TestPolicy_UsageReservation(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+
+	lm, err := NewLockManager(true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _, err := lm.GetPolicy(ctx, PolicyRequest{
+		Upsert:  true,
+		Storage: storage,
+		KeyType: KeyType_AES256_GCM96,
+		Name:    "test",
+	}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.Encrypt(0, nil, nil, plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if !p.UsageReservationExceeded() {
+		t.Fatal("expected the first encryption to exceed the reserved usage")
+	}
+	if err := p.Persist(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	if p.UsageReservationExceeded() {
+		t.Fatal("expected usage to be reserved after persisting")
+	}
+	if reserved := p.Keys["1"].EncryptionsReserved; reserved != 1+usageReservationBlock {
+		t.Fatalf("bad reserved encryptions: %d", reserved)
+	}
+
+	if _, err := p.Encrypt(0, nil, nil, plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if p.UsageReservationExceeded() {
+		t.Fatal("expected the encryption to be within the reserved usage")
+	}
+
+	// Loading the policy from storage, rather than the cache, counts the
+	// reserved usage
+	loaded, err := LoadPolicy(ctx, storage, "policy/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := loaded.Usage(1); usage.Encryptions != 1 {
+		t.Fatalf("bad usage loaded without the cache: %#v", usage)
+	}
+	lm.InvalidatePolicy("test")
+	p, _, err = lm.GetPolicy(ctx, PolicyRequest{
+		Storage: storage,
+		Name:    "test",
+	}, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := p.Usage(1); usage.Encryptions != 1+usageReservationBlock {
+		t.Fatalf("bad usage loaded into the cache: %#v", usage)
+	}
+
+	// The reservation is limited by the auto rotate usage count
+	p.AutoRotateUsageCount = 500
+	if _, err := p.Encrypt(0, nil, nil, plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Persist(ctx, storage); err != nil {
+		t.Fatal(err)
+	}
+	if reserved := p.Keys["1"].EncryptionsReserved; reserved != 2+usageReservationBlock+5 {
+		t.Fatalf("bad reserved encryptions: %d", reserved)
+	}
+}
+
+func TestPolicy_AllowedOperations(t *testing.T) {
+	ctx := context.Background()
+	storage := &logical.InmemStorage{}
+	plaintext := base64.StdEncoding.EncodeToString([]byte("the quick brown fox"))
+
+	p := NewPolicy(PolicyConfig{
+		Name: "test",
+		Type: KeyType_ED25519,
+	})
+	p.AllowedOperations = []string{KeyOperationVerify}
+	if err := p.Rotate(ctx, storage, rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := p.Sign(0, nil, []byte(plaintext), HashTypeNone, "", MarshalingTypeASN1)
+	if !errors.As(err, &errutil.UserError{}) {
+		t.Fatalf("expected user error signing, got: %v", err)
+	}
+	if usage := p.Usage(1); usage != (KeyUsage{}) {
+		t.Fatalf("expected no usage to be counted: %#v", usage)
+	}
+
+	p.AllowedOperations = nil
+	sig, err := p.Sign(0, nil, []byte(plaintext), HashTypeNone, "", MarshalingTypeASN1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := p.Usage(1); usage.Signatures != 1 {
+		t.Fatalf("bad usage: %#v", usage)
+	}
+
+	p.AllowedOperations = []string{KeyOperationSign}
+	if _, err := p.VerifySignature(nil, []byte(plaintext), HashTypeNone, "", MarshalingTypeASN1, sig.Signature); !errors.As(err, &errutil.UserError{}) {
+		t.Fatalf("expected user error verifying, got: %v", err)
+	}
+
+	if err := ValidateKeyOperations([]string{KeyOperationEncrypt, KeyOperationKeyAgreement}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateKeyOperations([]string{"wrap"}); err == nil {
+		t.Fatal("expected error for unknown operation")
+	}
+}
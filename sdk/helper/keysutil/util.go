@@ -60,8 +60,26 @@ var (
 
 	// See crypto/x509/x509.go in the Go toolchain source distribution.
 	oidSignatureRSAPSS = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+
+	// The Go standard library does not marshal ML-DSA and ML-KEM keys in
+	// the toolchain we build with; see
+	// https://datatracker.ietf.org/doc/draft-ietf-lamps-dilithium-certificates/
+	// and https://datatracker.ietf.org/doc/draft-ietf-lamps-kyber-certificates/.
+	oidPublicKeyMLDSA44   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17}
+	oidPublicKeyMLDSA65   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18}
+	oidPublicKeyMLDSA87   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 19}
+	oidPublicKeyMLKEM768  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 2}
+	oidPublicKeyMLKEM1024 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 4, 3}
 )
 
+// publicKeyInfo reflects an ASN.1 SubjectPublicKeyInfo structure.
+//
+// Copied from Go: https://github.com/golang/go/blob/master/src/crypto/x509/x509.go
+type publicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
 func isEd25519OID(oid asn1.ObjectIdentifier) bool {
 	return oidNSSPKIXEd25519.Equal(oid) || oidRFC8410Ed25519.Equal(oid)
 }
@@ -149,3 +167,121 @@ func ParsePKCS8RSAPSSPrivateKey(der []byte) (key interface{}, err error) {
 
 	return key, nil
 }
+
+// pqcKeyTypeForOID returns the ML-DSA or ML-KEM key type identified by the
+// given algorithm OID.
+func pqcKeyTypeForOID(oid asn1.ObjectIdentifier) (KeyType, bool) {
+	switch {
+	case oid.Equal(oidPublicKeyMLDSA44):
+		return KeyType_ML_DSA_44, true
+	case oid.Equal(oidPublicKeyMLDSA65):
+		return KeyType_ML_DSA_65, true
+	case oid.Equal(oidPublicKeyMLDSA87):
+		return KeyType_ML_DSA_87, true
+	case oid.Equal(oidPublicKeyMLKEM768):
+		return KeyType_ML_KEM_768, true
+	case oid.Equal(oidPublicKeyMLKEM1024):
+		return KeyType_ML_KEM_1024, true
+	}
+	return 0, false
+}
+
+func pqcOIDForKeyType(kt KeyType) (asn1.ObjectIdentifier, error) {
+	switch kt {
+	case KeyType_ML_DSA_44:
+		return oidPublicKeyMLDSA44, nil
+	case KeyType_ML_DSA_65:
+		return oidPublicKeyMLDSA65, nil
+	case KeyType_ML_DSA_87:
+		return oidPublicKeyMLDSA87, nil
+	case KeyType_ML_KEM_768:
+		return oidPublicKeyMLKEM768, nil
+	case KeyType_ML_KEM_1024:
+		return oidPublicKeyMLKEM1024, nil
+	}
+	return nil, fmt.Errorf("keysutil: key type %v is not an ML-DSA or ML-KEM key type", kt)
+}
+
+// ParsePKCS8PQCPrivateKey parses an unencrypted ML-DSA or ML-KEM private key
+// in PKCS #8, ASN.1 DER form. Only the seed form of the private key is
+// supported.
+func ParsePKCS8PQCPrivateKey(der []byte) (*PQCPrivateKey, error) {
+	var privKey pkcs8
+	if _, err := asn1.Unmarshal(der, &privKey); err != nil {
+		return nil, fmt.Errorf("keysutil: failed to parse private key: %w", err)
+	}
+
+	keyType, ok := pqcKeyTypeForOID(privKey.Algo.Algorithm)
+	if !ok {
+		return nil, errors.New("keysutil: failed to parse key as ML-DSA or ML-KEM private key")
+	}
+
+	// The seed is encoded as a context-specific, implicitly tagged [0]
+	// OCTET STRING.
+	var seed asn1.RawValue
+	if rest, err := asn1.Unmarshal(privKey.PrivateKey, &seed); err != nil || len(rest) != 0 ||
+		seed.Class != asn1.ClassContextSpecific || seed.Tag != 0 || seed.IsCompound {
+		return nil, fmt.Errorf("keysutil: failed to parse %v private key: only the seed format is supported", keyType)
+	}
+
+	key, err := newPQCPrivateKey(keyType, seed.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("keysutil: failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+// MarshalPKCS8PQCPrivateKey converts an ML-DSA or ML-KEM private key to
+// PKCS #8, ASN.1 DER form, using the seed format for the private key.
+func MarshalPKCS8PQCPrivateKey(key *PQCPrivateKey) ([]byte, error) {
+	oid, err := pqcOIDForKeyType(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: key.Seed})
+	if err != nil {
+		return nil, err
+	}
+
+	var privKey pkcs8
+	privKey.Algo.Algorithm = oid
+	privKey.PrivateKey = seed
+	return asn1.Marshal(privKey)
+}
+
+// ParsePKIXPQCPublicKey parses an ML-DSA or ML-KEM public key in PKIX,
+// ASN.1 DER form.
+func ParsePKIXPQCPublicKey(der []byte) (*PQCPublicKey, error) {
+	var pki publicKeyInfo
+	if rest, err := asn1.Unmarshal(der, &pki); err != nil {
+		return nil, fmt.Errorf("keysutil: failed to parse public key: %w", err)
+	} else if len(rest) != 0 {
+		return nil, errors.New("keysutil: trailing data after ASN.1 of public key")
+	}
+
+	keyType, ok := pqcKeyTypeForOID(pki.Algorithm.Algorithm)
+	if !ok {
+		return nil, errors.New("keysutil: failed to parse key as ML-DSA or ML-KEM public key")
+	}
+
+	key, err := newPQCPublicKey(keyType, pki.PublicKey.RightAlign())
+	if err != nil {
+		return nil, fmt.Errorf("keysutil: failed to parse public key: %w", err)
+	}
+	return key, nil
+}
+
+// MarshalPKIXPQCPublicKey converts an ML-DSA or ML-KEM public key to PKIX,
+// ASN.1 DER form.
+func MarshalPKIXPQCPublicKey(key *PQCPublicKey) ([]byte, error) {
+	oid, err := pqcOIDForKeyType(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+
+	var pki publicKeyInfo
+	pki.Algorithm.Algorithm = oid
+	pki.PublicKey = asn1.BitString{Bytes: key.Key, BitLength: 8 * len(key.Key)}
+	return asn1.Marshal(pki)
+}
@@ -6,6 +6,7 @@ package logical
 import (
 	"context"
 	"reflect"
+	"sync"
 	"time"
 
 	testing "github.com/mitchellh/go-testing-interface"
@@ -89,3 +90,31 @@ func TestBackendConfig() *BackendConfig {
 
 	return bc
 }
+
+// TestEventSender is an EventSender which records the events sent through
+// it, for use in tests.
+type TestEventSender struct {
+	lock   sync.Mutex
+	events []*EventReceived
+}
+
+var _ EventSender = (*TestEventSender)(nil)
+
+func (s *TestEventSender) SendEvent(_ context.Context, eventType EventType, event *EventData) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.events = append(s.events, &EventReceived{
+		EventType: string(eventType),
+		Event:     event,
+	})
+	return nil
+}
+
+// Events returns the events sent so far, in the order they were sent.
+func (s *TestEventSender) Events() []*EventReceived {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*EventReceived(nil), s.events...)
+}
@@ -228,6 +228,36 @@ func (c *Core) disableAudit(ctx context.Context, path string, updateStorage bool
 	return true, nil
 }
 
+// auditChainHead returns the head of the hash chain of the audit device at the
+// given path, or nil if it has not written a chained entry. The head is read
+// from the registered backend where possible, as the head persisted in its
+// storage view may lag behind the entries it has written.
+func (c *Core) auditChainHead(ctx context.Context, path string) (*audit.ChainHead, error) {
+	// Ensure we end the path in a slash
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	c.auditLock.RLock()
+	defer c.auditLock.RUnlock()
+
+	entry, err := c.audit.find(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("unknown audit backend %q", path)
+	}
+
+	if c.auditBroker != nil {
+		if head, ok, err := c.auditBroker.ChainHead(ctx, entry.Path); ok {
+			return head, err
+		}
+	}
+
+	return audit.ReadChainHead(ctx, NewBarrierView(c.barrier, entry.ViewPath()))
+}
+
 // loadAudits is invoked as part of postUnseal to load the audit table
 func (c *Core) loadAudits(ctx context.Context) error {
 	auditTable := &MountTable{}
@@ -457,6 +487,14 @@ func (c *Core) teardownAudits() error {
 		for _, entry := range c.audit.Entries {
 			c.removeAuditReloadFunc(entry)
 			removeAuditPathChecker(c, entry)
+
+			// Deregistering closes the backend's sinks, allowing those which
+			// buffer entries to deliver them.
+			if c.auditBroker != nil {
+				if err := c.auditBroker.Deregister(context.Background(), entry.Path); err != nil {
+					c.logger.Error("failed to deregister audit backend", "path", entry.Path, "error", err)
+				}
+			}
 		}
 	}
 
@@ -545,6 +583,12 @@ func (c *Core) newAuditBackend(ctx context.Context, entry *MountEntry, view logi
 				auditLogger.Debug("socket backend options", "path", entry.Path, "address", entry.Options["address"], "socket type", entry.Options["socket_type"])
 			}
 		}
+	case "http":
+		if auditLogger.IsDebug() {
+			if entry.Options != nil {
+				auditLogger.Debug("http backend options", "path", entry.Path, "address", entry.Options["address"], "spool path", entry.Options["spool_path"])
+			}
+		}
 	case "syslog":
 		if auditLogger.IsDebug() {
 			if entry.Options != nil {
@@ -5,6 +5,7 @@ package vault
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
 	"sync"
@@ -85,11 +86,20 @@ func (a *AuditBroker) Deregister(ctx context.Context, name string) error {
 	a.Lock()
 	defer a.Unlock()
 
+	be, ok := a.backends[name]
+
 	// Remove the Backend from the map first, so that if an error occurs while
 	// removing the pipeline and nodes, we can quickly exit this method with
 	// the error.
 	delete(a.backends, name)
 
+	// Without the event broker, nothing else closes the backend.
+	if a.broker == nil && ok {
+		if closer, ok := be.backend.(eventlogger.Closer); ok {
+			return closer.Close(ctx)
+		}
+	}
+
 	if a.broker != nil {
 		if len(a.backends) == 0 {
 			err := a.broker.SetSuccessThresholdSinks(eventlogger.EventType(event.AuditType.String()), 0)
@@ -143,6 +153,25 @@ func (a *AuditBroker) GetHash(ctx context.Context, name string, input string) (s
 	return audit.HashString(ctx, be.backend, input)
 }
 
+// ChainHead returns the head of the hash chain of the given backend. The bool
+// is false if the backend is not registered, or cannot report its chain head.
+func (a *AuditBroker) ChainHead(ctx context.Context, name string) (*audit.ChainHead, bool, error) {
+	a.RLock()
+	defer a.RUnlock()
+	be, ok := a.backends[name]
+	if !ok {
+		return nil, false, nil
+	}
+
+	reader, ok := be.backend.(audit.ChainHeadReader)
+	if !ok {
+		return nil, false, nil
+	}
+
+	head, err := reader.ChainHead(ctx)
+	return head, true, err
+}
+
 // LogRequest is used to ensure all the audit backends have an opportunity to
 // log the given request and that *at least one* succeeds.
 func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, headersConfig *AuditedHeadersConfig) (ret error) {
@@ -217,7 +246,7 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 			e.Data = in
 
 			status, err := a.broker.Send(ctx, eventlogger.EventType(event.AuditType.String()), e)
-			if err != nil {
+			if err != nil && !filteredOut(status, err) {
 				retErr = multierror.Append(retErr, multierror.Append(err, status.Warnings...))
 			}
 		}
@@ -298,7 +327,7 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 			e.Data = in
 
 			status, err := a.broker.Send(ctx, eventlogger.EventType(event.AuditType.String()), e)
-			if err != nil {
+			if err != nil && !filteredOut(status, err) {
 				retErr = multierror.Append(retErr, multierror.Append(err, status.Warnings...))
 			}
 		}
@@ -307,6 +336,19 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 	return retErr.ErrorOrNil()
 }
 
+// filteredOut determines whether an audit event which didn't reach enough sinks
+// was deliberately filtered out by the audit devices, rather than failing to be
+// processed. Failures within a pipeline are always reported as warnings, so an
+// event which reached no sinks without any warnings was dropped by the filter
+// of every audit device.
+func filteredOut(status eventlogger.Status, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	return len(status.Warnings) == 0
+}
+
 func (a *AuditBroker) Invalidate(ctx context.Context, key string) {
 	// For now, we ignore the key as this would only apply to salts. We just
 	// sort of brute force it on each one.
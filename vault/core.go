@@ -718,6 +718,10 @@ type Core struct {
 
 	events *eventbus.EventBus
 
+	// eventSubscriptions runs the server-side event subscriptions, which
+	// forward events to external sinks. It is only set on the active node.
+	eventSubscriptions *eventSubscriptionManager
+
 	// writeForwardedPaths are a set of storage paths which are GRPC forwarded
 	// to the active node of the primary cluster, when present. This PathManager
 	// contains absolute paths that we intend to forward (and template) when
@@ -2397,6 +2401,9 @@ func (s standardUnsealStrategy) unseal(ctx context.Context, logger log.Logger, c
 		if err := c.setupAudits(ctx); err != nil {
 			return err
 		}
+		if err := c.setupEventSubscriptions(ctx); err != nil {
+			return err
+		}
 		if err := c.loadIdentityStoreArtifacts(ctx); err != nil {
 			return err
 		}
@@ -2635,6 +2642,9 @@ func (c *Core) preSeal() error {
 	}
 	c.clusterParamsLock.Unlock()
 
+	if err := c.teardownEventSubscriptions(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("error tearing down event subscriptions: %w", err))
+	}
 	if err := c.teardownAudits(); err != nil {
 		result = multierror.Append(result, fmt.Errorf("error tearing down audits: %w", err))
 	}
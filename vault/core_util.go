@@ -8,6 +8,9 @@ package vault
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/helper/namespace"
@@ -165,20 +168,96 @@ func (c *Core) initSealsForMigration() {}
 
 func (c *Core) postSealMigration(ctx context.Context) error { return nil }
 
-func (c *Core) applyLeaseCountQuota(_ context.Context, in *quotas.Request) (*quotas.Response, error) {
-	return &quotas.Response{Allowed: true}, nil
+func (c *Core) applyLeaseCountQuota(ctx context.Context, in *quotas.Request) (*quotas.Response, error) {
+	if c.quotaManager == nil {
+		return &quotas.Response{Allowed: true}, nil
+	}
+
+	in.Type = quotas.TypeLeaseCount
+	resp, err := c.quotaManager.ApplyQuota(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
 }
 
 func (c *Core) ackLeaseQuota(access quotas.Access, leaseGenerated bool) error {
-	return nil
+	if c.quotaManager == nil {
+		return nil
+	}
+
+	return c.quotaManager.AckLeaseQuota(access, leaseGenerated)
 }
 
+// quotaLeaseWalker walks all the leases held by the expiration manager and
+// invokes the callback with the quota request each lease corresponds to.
+// Leases whose request cannot be reconstructed, such as leases of a namespace
+// which is being deleted, are skipped.
 func (c *Core) quotaLeaseWalker(ctx context.Context, callback func(request *quotas.Request) bool) error {
-	return nil
+	if c.expiration == nil {
+		return nil
+	}
+
+	err := c.expiration.walkLeases(func(leaseID string, _ time.Time, loginRole string) bool {
+		req, err := c.leaseQuotaRequest(ctx, leaseID, loginRole)
+		if err != nil {
+			c.logger.Warn("failed to count lease against lease count quotas", "lease_id", leaseID, "error", err)
+			return true
+		}
+		return callback(req)
+	})
+
+	// Leases which are still being restored are accounted for as they are
+	// loaded.
+	return suppressRestoreModeError(err)
 }
 
 func (c *Core) quotasHandleLeases(ctx context.Context, action quotas.LeaseAction, leases []*quotas.QuotaLeaseInformation) error {
-	return nil
+	if c.quotaManager == nil {
+		return nil
+	}
+
+	reqs := make([]*quotas.Request, 0, len(leases))
+	for _, lease := range leases {
+		req, err := c.leaseQuotaRequest(ctx, lease.LeaseId, lease.Role)
+		if err != nil {
+			return err
+		}
+		reqs = append(reqs, req)
+	}
+
+	return c.quotaManager.HandleLeaseActions(action, reqs)
+}
+
+// leaseQuotaRequest reconstructs the quota request that generated the given
+// lease, so that the lease can be matched against lease count quotas.
+func (c *Core) leaseQuotaRequest(ctx context.Context, leaseID, role string) (*quotas.Request, error) {
+	leaseID, nsID := namespace.SplitIDFromString(leaseID)
+
+	ns := namespace.RootNamespace
+	if nsID != "" {
+		var err error
+		ns, err = NamespaceByID(ctx, nsID, c)
+		if err != nil {
+			return nil, err
+		}
+		if ns == nil {
+			return nil, namespace.ErrNoNamespace
+		}
+	}
+
+	// Lease IDs are the request path joined with a unique suffix
+	reqPath := path.Dir(leaseID)
+	mountPath := c.router.MatchingMount(namespace.ContextWithNamespace(ctx, ns), reqPath)
+
+	return &quotas.Request{
+		Type:          quotas.TypeLeaseCount,
+		Path:          reqPath,
+		Role:          role,
+		NamespacePath: ns.Path,
+		MountPath:     strings.TrimPrefix(mountPath, ns.Path),
+	}, nil
 }
 
 func (c *Core) namespaceByPath(path string) *namespace.Namespace {
@@ -54,6 +54,7 @@ type EventBus struct {
 	started         atomic.Bool
 	formatterNodeID eventlogger.NodeID
 	timeout         time.Duration
+	history         *eventHistory
 }
 
 type pluginEventBus struct {
@@ -63,7 +64,6 @@ type pluginEventBus struct {
 }
 
 type asyncChanNode struct {
-	// TODO: add bounded deque buffer of *EventReceived
 	ctx    context.Context
 	ch     chan *eventlogger.Event
 	logger hclog.Logger
@@ -129,6 +129,7 @@ func (bus *EventBus) SendEventInternal(_ context.Context, ns *namespace.Namespac
 		EventType:  string(eventType),
 		PluginInfo: pluginInfo,
 	}
+	bus.history.add(eventReceived)
 
 	// We can't easily know when the SendEvent is complete, so we can't call the cancel function.
 	// But, it is called automatically after bus.timeout, so there won't be any leak as long as bus.timeout is not too long.
@@ -197,6 +198,7 @@ func NewEventBus(logger hclog.Logger) (*EventBus, error) {
 		broker:          broker,
 		formatterNodeID: formatterNodeID,
 		timeout:         defaultTimeout,
+		history:         newEventHistory(defaultHistorySize),
 	}, nil
 }
 
@@ -209,6 +211,14 @@ func (bus *EventBus) Subscribe(ctx context.Context, ns *namespace.Namespace, pat
 // SubscribeMultipleNamespaces subscribes to events in the given namespace matching the event type
 // pattern and after applying the optional go-bexpr filter.
 func (bus *EventBus) SubscribeMultipleNamespaces(ctx context.Context, namespacePathPatterns []string, pattern string, bexprFilter string) (<-chan *eventlogger.Event, context.CancelFunc, error) {
+	return bus.SubscribeMultipleNamespacesSince(ctx, namespacePathPatterns, pattern, bexprFilter, "")
+}
+
+// SubscribeMultipleNamespacesSince is like SubscribeMultipleNamespaces, but if since is not empty,
+// the matching events sent on this node after the event with that ID are sent to the channel
+// before any new events. ErrEventNotInHistory is returned if the event is no longer in the
+// event history.
+func (bus *EventBus) SubscribeMultipleNamespacesSince(ctx context.Context, namespacePathPatterns []string, pattern string, bexprFilter string, since string) (<-chan *eventlogger.Event, context.CancelFunc, error) {
 	// subscriptions are still stored even if the bus has not been started
 	pipelineID, err := uuid.GenerateUUID()
 	if err != nil {
@@ -254,7 +264,20 @@ func (bus *EventBus) SubscribeMultipleNamespaces(ctx context.Context, namespaceP
 		EventType:  eventTypeAll,
 		NodeIDs:    nodes,
 	}
+	// The history is locked while the pipeline is registered, so that every event is either in the
+	// history we replay from or is sent to the new pipeline.
+	var missed []*logical.EventReceived
+	var sinceErr error
+	if since != "" {
+		bus.history.lock.Lock()
+	}
 	err = bus.broker.RegisterPipeline(pipeline)
+	if since != "" {
+		if err == nil {
+			missed, sinceErr = bus.history.since(since)
+		}
+		bus.history.lock.Unlock()
+	}
 	if err != nil {
 		defer cancel()
 		return nil, nil, err
@@ -264,8 +287,88 @@ func (bus *EventBus) SubscribeMultipleNamespaces(ctx context.Context, namespaceP
 	// add info needed to cancel the subscription
 	asyncNode.pipelineID = eventlogger.PipelineID(pipelineID)
 	asyncNode.cancelFunc = cancel
-	// Capture context in a closure for the cancel func
-	return asyncNode.ch, func() { asyncNode.Close(ctx) }, nil
+	if sinceErr != nil {
+		asyncNode.Close(ctx)
+		return nil, nil, sinceErr
+	}
+	if since == "" {
+		// Capture context in a closure for the cancel func
+		return asyncNode.ch, func() { asyncNode.Close(ctx) }, nil
+	}
+
+	replay, err := formatMissedEvents(ctx, filterNode, missed)
+	if err != nil {
+		asyncNode.Close(ctx)
+		return nil, nil, err
+	}
+	return replayThenForward(ctx, since, replay, asyncNode.ch), func() { asyncNode.Close(ctx) }, nil
+}
+
+// formatMissedEvents filters and formats events from the history the same way that a
+// subscription's pipeline would.
+func formatMissedEvents(ctx context.Context, filterNode *eventlogger.Filter, missed []*logical.EventReceived) ([]*eventlogger.Event, error) {
+	var events []*eventlogger.Event
+	for _, eventReceived := range missed {
+		e := &eventlogger.Event{
+			Type:      eventTypeAll,
+			CreatedAt: time.Now(),
+			Formatted: make(map[string][]byte),
+			Payload:   eventReceived,
+		}
+		ok, err := filterNode.Predicate(e)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		e, err = cloudEventsFormatterFilter.Process(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// replayThenForward returns a channel that receives the replayed events, followed by the live
+// events. Live events that were already replayed, including the since event itself, are skipped.
+func replayThenForward(ctx context.Context, since string, replay []*eventlogger.Event, live <-chan *eventlogger.Event) <-chan *eventlogger.Event {
+	ch := make(chan *eventlogger.Event)
+	go func() {
+		seen := make(map[string]struct{}, len(replay)+1)
+		seen[since] = struct{}{}
+		for _, e := range replay {
+			select {
+			case ch <- e:
+				seen[e.Payload.(*logical.EventReceived).Event.Id] = struct{}{}
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			var e *eventlogger.Event
+			select {
+			case e = <-live:
+			case <-ctx.Done():
+				return
+			}
+			id := e.Payload.(*logical.EventReceived).Event.Id
+			if _, ok := seen[id]; ok {
+				delete(seen, id)
+				continue
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
 }
 
 // SetSendTimeout sets the timeout of sending events. If the events are not accepted by the
@@ -694,3 +694,107 @@ func TestPipelineCleanedUp(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+// TestSubscribeSince tests that subscribing from an event ID first sends the matching events
+// that were sent after that event, and then new events.
+func TestSubscribeSince(t *testing.T) {
+	bus, err := NewEventBus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	bus.Start()
+
+	var events []*logical.EventData
+	for _, eventType := range []string{"someType", "someType", "otherType", "someType"} {
+		event, err := logical.NewEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bus.SendEventInternal(ctx, namespace.RootNamespace, nil, logical.EventType(eventType), event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+
+	ch, cancel, err := bus.SubscribeMultipleNamespacesSince(ctx, []string{""}, "someType", "", events[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	live, err := logical.NewEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bus.SendEventInternal(ctx, namespace.RootNamespace, nil, "someType", live)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(1 * time.Second)
+	for _, expected := range []*logical.EventData{events[1], events[3], live} {
+		select {
+		case message := <-ch:
+			if _, ok := message.Format("cloudevents-json"); !ok {
+				t.Errorf("Expected message to be formatted: %v", message)
+			}
+			if message.Payload.(*logical.EventReceived).Event.Id != expected.Id {
+				t.Errorf("Got unexpected message: %v", message)
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for event")
+		}
+	}
+}
+
+// TestSubscribeSinceNotInHistory tests that subscribing from an event that has been evicted from
+// the history fails.
+func TestSubscribeSinceNotInHistory(t *testing.T) {
+	bus, err := NewEventBus(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.history = newEventHistory(2)
+	ctx := context.Background()
+	bus.Start()
+	existing := subscriptions.Load()
+
+	var events []*logical.EventData
+	for i := 0; i < 3; i++ {
+		event, err := logical.NewEvent()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = bus.SendEventInternal(ctx, namespace.RootNamespace, nil, "someType", event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+
+	_, _, err = bus.SubscribeMultipleNamespacesSince(ctx, []string{""}, "someType", "", events[0].Id)
+	if !errors.Is(err, ErrEventNotInHistory) {
+		t.Fatalf("Expected event not in history error but got: %v", err)
+	}
+
+	ch, cancel, err := bus.SubscribeMultipleNamespacesSince(ctx, []string{""}, "someType", "", events[1].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	select {
+	case message := <-ch:
+		if message.Payload.(*logical.EventReceived).Event.Id != events[2].Id {
+			t.Errorf("Got unexpected message: %v", message)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for event")
+	}
+
+	if subscriptions.Load() != existing+1 {
+		t.Errorf("Expected only the successful subscription to be counted, got %d", subscriptions.Load()-existing)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package eventbus
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// defaultHistorySize is the number of recent events kept by each event bus,
+// so that subscribers that reconnect can be sent the events they missed.
+const defaultHistorySize = 1000
+
+// ErrEventNotInHistory is returned when subscribing from an event ID that is
+// no longer, or was never, in the event history. The subscriber may have
+// missed events, and should resynchronize its state before subscribing again
+// without an event ID.
+var ErrEventNotInHistory = errors.New("event not found in event history")
+
+// eventHistory is a bounded ring buffer of the most recent events sent on
+// this node.
+type eventHistory struct {
+	lock   sync.Mutex
+	events []*logical.EventReceived
+	// next is the index that the next event is written to
+	next int
+	full bool
+}
+
+func newEventHistory(size int) *eventHistory {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &eventHistory{
+		events: make([]*logical.EventReceived, size),
+	}
+}
+
+// add records an event, evicting the oldest event if the buffer is full.
+func (h *eventHistory) add(event *logical.EventReceived) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.events[h.next] = event
+	h.next = (h.next + 1) % len(h.events)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the events in the buffer, oldest first. The caller must
+// hold the lock.
+func (h *eventHistory) snapshot() []*logical.EventReceived {
+	if !h.full {
+		return append([]*logical.EventReceived(nil), h.events[:h.next]...)
+	}
+	events := make([]*logical.EventReceived, 0, len(h.events))
+	events = append(events, h.events[h.next:]...)
+	return append(events, h.events[:h.next]...)
+}
+
+// since returns the events that were sent after the event with the given ID,
+// oldest first. The caller must hold the lock.
+func (h *eventHistory) since(id string) ([]*logical.EventReceived, error) {
+	events := h.snapshot()
+	for i, event := range events {
+		if event.Event.GetId() == id {
+			return events[i+1:], nil
+		}
+	}
+	return nil, ErrEventNotInHistory
+}
@@ -0,0 +1,646 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// eventSubscriptionsPrefix is the storage prefix, within the system barrier
+	// view, under which subscriptions are stored. Each subscription is stored
+	// under <namespace ID>/<name>/ with its config, its delivery cursor and the
+	// queue of events that have been received for it.
+	eventSubscriptionsPrefix = "events/subscriptions/"
+
+	eventSubscriptionConfigKey = "config"
+	eventSubscriptionCursorKey = "cursor"
+	eventSubscriptionQueueKey  = "queue/"
+
+	eventSubscriptionSinkWebhook = "webhook"
+	eventSubscriptionSinkFile    = "file"
+
+	defaultEventSubscriptionRequestTimeout  = 10 * time.Second
+	defaultEventSubscriptionReplayRetention = 1000
+	defaultEventSubscriptionMaxPending      = 10000
+
+	eventSubscriptionRetryWaitMin = 1 * time.Second
+	eventSubscriptionRetryWaitMax = 1 * time.Minute
+)
+
+var (
+	errEventSubscriptionNotFound      = errors.New("event subscription not found")
+	errEventSubscriptionsNotAvailable = errors.New("event subscriptions are not available")
+	errEventSubscriptionInvalidCursor = errors.New("invalid cursor")
+)
+
+// EventSubscriptionConfig is the persisted configuration of a server-side
+// event subscription, which forwards matching events to an external sink.
+type EventSubscriptionConfig struct {
+	Name          string `json:"name"`
+	NamespaceID   string `json:"namespace_id"`
+	NamespacePath string `json:"namespace_path"`
+
+	// EventType is the event type pattern, which may contain wildcards.
+	EventType string `json:"event_type"`
+
+	// Filter is an optional go-bexpr expression evaluated against each event.
+	Filter string `json:"filter"`
+
+	// Namespaces are additional namespace patterns, relative to the
+	// subscription's namespace, whose events are also forwarded.
+	Namespaces []string `json:"namespaces"`
+
+	SinkType       string            `json:"sink_type"`
+	Address        string            `json:"address,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	RequestTimeout time.Duration     `json:"request_timeout,omitempty"`
+	FilePath       string            `json:"file_path,omitempty"`
+
+	// ReplayRetention is the number of delivered events that are kept so that
+	// the cursor can be moved back to replay them.
+	ReplayRetention int `json:"replay_retention"`
+
+	// MaxPending is the maximum number of undelivered events that are queued
+	// before new events are dropped.
+	MaxPending int `json:"max_pending"`
+}
+
+// namespacePatterns returns the namespace patterns the subscription receives
+// events from: its own namespace and any configured child namespace patterns.
+func (c *EventSubscriptionConfig) namespacePatterns() []string {
+	prefix := strings.Trim(c.NamespacePath, "/")
+	patterns := []string{prefix}
+	for _, pattern := range c.Namespaces {
+		if strings.Trim(pattern, "/") != "" {
+			patterns = append(patterns, path.Join(prefix, pattern))
+		}
+	}
+	return patterns
+}
+
+// EventSubscriptionStatus reports the delivery progress of a subscription.
+type EventSubscriptionStatus struct {
+	// Cursor is the sequence number of the last event delivered to the sink.
+	Cursor uint64
+	// LatestSequence is the sequence number of the last event received.
+	LatestSequence uint64
+	// OldestSequence is the sequence number of the oldest event still held,
+	// and therefore the earliest event which can be replayed.
+	OldestSequence uint64
+	Dropped        uint64
+	LastDelivery   time.Time
+	LastError      string
+	LastErrorTime  time.Time
+}
+
+// eventSubscriptionCursor is the persisted delivery position of a subscription.
+type eventSubscriptionCursor struct {
+	Cursor uint64 `json:"cursor"`
+}
+
+// eventSubscriptionManager runs the server-side event subscriptions on the
+// active node. Events matching a subscription are written to storage as they
+// are received from the event bus, and are removed only once they have been
+// delivered and have aged out of the replay window, which provides
+// at-least-once delivery to the sink.
+type eventSubscriptionManager struct {
+	logger hclog.Logger
+	events eventSubscriber
+	view   *BarrierView
+
+	lock          sync.Mutex
+	subscriptions map[string]*eventSubscription
+}
+
+// eventSubscriber is the part of the event bus used by subscriptions.
+type eventSubscriber interface {
+	SubscribeMultipleNamespaces(ctx context.Context, namespacePathPatterns []string, pattern string, bexprFilter string) (<-chan *eventlogger.Event, context.CancelFunc, error)
+}
+
+// eventSubscription is a single running subscription.
+type eventSubscription struct {
+	config *EventSubscriptionConfig
+	view   *BarrierView
+	sink   eventSubscriptionSink
+	logger hclog.Logger
+
+	ctx         context.Context
+	cancel      context.CancelFunc
+	unsubscribe context.CancelFunc
+	wg          sync.WaitGroup
+	notifyCh    chan struct{}
+
+	// lock protects the fields below, and serializes changes to the cursor
+	// and queue in storage.
+	lock          sync.Mutex
+	cursor        uint64
+	oldest        uint64
+	next          uint64
+	dropped       uint64
+	lastDelivery  time.Time
+	lastError     string
+	lastErrorTime time.Time
+}
+
+// setupEventSubscriptions loads the persisted event subscriptions and starts
+// delivering events to their sinks.
+func (c *Core) setupEventSubscriptions(ctx context.Context) error {
+	if c.events == nil {
+		return nil
+	}
+
+	m := &eventSubscriptionManager{
+		logger:        c.baseLogger.Named("events.subscriptions"),
+		events:        c.events,
+		view:          c.systemBarrierView.SubView(eventSubscriptionsPrefix),
+		subscriptions: make(map[string]*eventSubscription),
+	}
+	c.AddLogger(m.logger)
+
+	if err := m.load(ctx); err != nil {
+		m.stop()
+		return err
+	}
+
+	c.eventSubscriptions = m
+	return nil
+}
+
+// teardownEventSubscriptions stops all running event subscriptions.
+func (c *Core) teardownEventSubscriptions() error {
+	if c.eventSubscriptions != nil {
+		c.eventSubscriptions.stop()
+		c.eventSubscriptions = nil
+	}
+	return nil
+}
+
+// eventSubscriptionKey returns the storage key, relative to the manager's
+// view, of the subscription with the given name in the given namespace.
+func eventSubscriptionKey(nsID, name string) string {
+	return nsID + "/" + name + "/"
+}
+
+// eventSubscriptionSequenceKey returns the queue key of an event. Sequence
+// numbers are zero padded so that keys are listed in delivery order.
+func eventSubscriptionSequenceKey(seq uint64) string {
+	return fmt.Sprintf("%020d", seq)
+}
+
+// load starts every subscription found in storage.
+func (m *eventSubscriptionManager) load(ctx context.Context) error {
+	nsIDs, err := m.view.List(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list event subscription namespaces: %w", err)
+	}
+
+	for _, nsID := range nsIDs {
+		names, err := m.view.List(ctx, nsID)
+		if err != nil {
+			return fmt.Errorf("failed to list event subscriptions: %w", err)
+		}
+
+		for _, name := range names {
+			key := nsID + name
+			entry, err := m.view.Get(ctx, key+eventSubscriptionConfigKey)
+			if err != nil {
+				return fmt.Errorf("failed to read event subscription %q: %w", key, err)
+			}
+			if entry == nil {
+				continue
+			}
+
+			var config EventSubscriptionConfig
+			if err := entry.DecodeJSON(&config); err != nil {
+				return fmt.Errorf("failed to decode event subscription %q: %w", key, err)
+			}
+
+			// A subscription which can't be started shouldn't prevent Vault
+			// from unsealing; it is retried when the subscription is updated.
+			if err := m.start(ctx, &config); err != nil {
+				m.logger.Error("failed to start event subscription", "name", config.Name, "namespace", config.NamespacePath, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// stop stops all running subscriptions. Undelivered events remain in storage
+// and are delivered once the subscriptions are loaded again.
+func (m *eventSubscriptionManager) stop() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for key, sub := range m.subscriptions {
+		sub.stop()
+		delete(m.subscriptions, key)
+	}
+}
+
+// list returns the names of the subscriptions in the given namespace.
+func (m *eventSubscriptionManager) list(ctx context.Context, ns *namespace.Namespace) ([]string, error) {
+	names, err := m.view.List(ctx, ns.ID+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range names {
+		names[i] = strings.TrimSuffix(name, "/")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// get returns the configuration of a subscription, or nil if it doesn't exist.
+func (m *eventSubscriptionManager) get(ctx context.Context, ns *namespace.Namespace, name string) (*EventSubscriptionConfig, error) {
+	entry, err := m.view.Get(ctx, eventSubscriptionKey(ns.ID, name)+eventSubscriptionConfigKey)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config EventSubscriptionConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// status returns the delivery status of a running subscription.
+func (m *eventSubscriptionManager) status(ns *namespace.Namespace, name string) (*EventSubscriptionStatus, error) {
+	m.lock.Lock()
+	sub, ok := m.subscriptions[eventSubscriptionKey(ns.ID, name)]
+	m.lock.Unlock()
+	if !ok {
+		return nil, errEventSubscriptionNotFound
+	}
+
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+
+	return &EventSubscriptionStatus{
+		Cursor:         sub.cursor,
+		LatestSequence: sub.next - 1,
+		OldestSequence: sub.oldest,
+		Dropped:        sub.dropped,
+		LastDelivery:   sub.lastDelivery,
+		LastError:      sub.lastError,
+		LastErrorTime:  sub.lastErrorTime,
+	}, nil
+}
+
+// set persists the configuration of a subscription and (re)starts it. Events
+// which were queued under a previous configuration are kept. The configuration
+// is expected to have been validated by the caller.
+func (m *eventSubscriptionManager) set(ctx context.Context, config *EventSubscriptionConfig) error {
+	entry, err := logical.StorageEntryJSON(eventSubscriptionKey(config.NamespaceID, config.Name)+eventSubscriptionConfigKey, config)
+	if err != nil {
+		return err
+	}
+	if err := m.view.Put(ctx, entry); err != nil {
+		return err
+	}
+
+	return m.start(ctx, config)
+}
+
+// delete stops a subscription and removes it, along with any events queued
+// for it, from storage.
+func (m *eventSubscriptionManager) delete(ctx context.Context, ns *namespace.Namespace, name string) error {
+	key := eventSubscriptionKey(ns.ID, name)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if sub, ok := m.subscriptions[key]; ok {
+		sub.stop()
+		delete(m.subscriptions, key)
+	}
+
+	return logical.ClearView(ctx, m.view.SubView(key))
+}
+
+// replay moves the cursor of a subscription back, so that the events after
+// the cursor are delivered again.
+func (m *eventSubscriptionManager) replay(ctx context.Context, ns *namespace.Namespace, name string, cursor uint64) error {
+	m.lock.Lock()
+	sub, ok := m.subscriptions[eventSubscriptionKey(ns.ID, name)]
+	m.lock.Unlock()
+	if !ok {
+		return errEventSubscriptionNotFound
+	}
+
+	return sub.replay(ctx, cursor)
+}
+
+// start starts delivering events for the given subscription, replacing any
+// running subscription with the same name.
+func (m *eventSubscriptionManager) start(ctx context.Context, config *EventSubscriptionConfig) error {
+	key := eventSubscriptionKey(config.NamespaceID, config.Name)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if existing, ok := m.subscriptions[key]; ok {
+		existing.stop()
+		delete(m.subscriptions, key)
+	}
+
+	sink, err := newEventSubscriptionSink(config)
+	if err != nil {
+		return err
+	}
+
+	sub := &eventSubscription{
+		config:   config,
+		view:     m.view.SubView(key),
+		sink:     sink,
+		logger:   m.logger.With("name", config.Name, "namespace", config.NamespacePath),
+		notifyCh: make(chan struct{}, 1),
+	}
+	if err := sub.loadState(ctx); err != nil {
+		sink.close()
+		return err
+	}
+
+	// The subscription runs for as long as the node is active, not for as
+	// long as the request which created it.
+	sub.ctx, sub.cancel = context.WithCancel(namespace.RootContext(nil))
+	ch, unsubscribe, err := m.events.SubscribeMultipleNamespaces(sub.ctx, config.namespacePatterns(), config.EventType, config.Filter)
+	if err != nil {
+		sub.cancel()
+		sink.close()
+		return fmt.Errorf("failed to subscribe to events: %w", err)
+	}
+	sub.unsubscribe = unsubscribe
+
+	sub.wg.Add(2)
+	go sub.receive(ch)
+	go sub.deliver()
+	sub.notify()
+
+	m.subscriptions[key] = sub
+	return nil
+}
+
+// loadState restores the cursor and the bounds of the queue from storage.
+func (s *eventSubscription) loadState(ctx context.Context) error {
+	entry, err := s.view.Get(ctx, eventSubscriptionCursorKey)
+	if err != nil {
+		return fmt.Errorf("failed to read event subscription cursor: %w", err)
+	}
+	if entry != nil {
+		var cursor eventSubscriptionCursor
+		if err := entry.DecodeJSON(&cursor); err != nil {
+			return fmt.Errorf("failed to decode event subscription cursor: %w", err)
+		}
+		s.cursor = cursor.Cursor
+	}
+
+	keys, err := s.view.List(ctx, eventSubscriptionQueueKey)
+	if err != nil {
+		return fmt.Errorf("failed to list event subscription queue: %w", err)
+	}
+
+	s.oldest = s.cursor + 1
+	s.next = s.cursor + 1
+	for _, key := range keys {
+		seq, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid event subscription queue entry %q: %w", key, err)
+		}
+		if seq < s.oldest {
+			s.oldest = seq
+		}
+		if seq >= s.next {
+			s.next = seq + 1
+		}
+	}
+
+	return nil
+}
+
+// stop stops receiving and delivering events for the subscription.
+func (s *eventSubscription) stop() {
+	s.unsubscribe()
+	s.cancel()
+	s.wg.Wait()
+	s.sink.close()
+}
+
+// notify wakes up the delivery routine without blocking.
+func (s *eventSubscription) notify() {
+	select {
+	case s.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// receive queues events from the event bus until the subscription is stopped.
+func (s *eventSubscription) receive(ch <-chan *eventlogger.Event) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := s.enqueue(e); err != nil {
+				s.logger.Error("failed to queue event", "error", err)
+			}
+		}
+	}
+}
+
+// enqueue writes an event to the subscription's queue in storage.
+func (s *eventSubscription) enqueue(e *eventlogger.Event) error {
+	payload, ok := e.Format("cloudevents-json")
+	if !ok {
+		return errors.New("could not get cloudevents JSON format")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	labels := []metrics.Label{{Name: "name", Value: s.config.Name}}
+
+	// The cursor is the sequence number of the last event delivered, and next
+	// the sequence number the event will be given, so the events between them
+	// are pending delivery.
+	pending := s.next - 1 - s.cursor
+	if s.config.MaxPending > 0 && pending >= uint64(s.config.MaxPending) {
+		s.dropped++
+		metrics.IncrCounterWithLabels([]string{"events", "subscriptions", "dropped"}, 1, labels)
+		return fmt.Errorf("dropping event as %d events are pending delivery", s.config.MaxPending)
+	}
+
+	seq := s.next
+	err := s.view.Put(s.ctx, &logical.StorageEntry{
+		Key:   eventSubscriptionQueueKey + eventSubscriptionSequenceKey(seq),
+		Value: payload,
+	})
+	if err != nil {
+		return err
+	}
+	s.next++
+	metrics.IncrCounterWithLabels([]string{"events", "subscriptions", "queued"}, 1, labels)
+
+	s.notify()
+	return nil
+}
+
+// deliver sends queued events to the sink in order, retrying with an
+// exponential backoff whenever the sink fails.
+func (s *eventSubscription) deliver() {
+	defer s.wg.Done()
+
+	retryWait := eventSubscriptionRetryWaitMin
+	for {
+		err := s.deliverPending()
+		if err == nil {
+			retryWait = eventSubscriptionRetryWaitMin
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-s.notifyCh:
+			}
+			continue
+		}
+
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Warn("failed to deliver event, will retry", "retry_wait", retryWait, "error", err)
+		s.lock.Lock()
+		s.lastError = err.Error()
+		s.lastErrorTime = time.Now()
+		s.lock.Unlock()
+
+		timer := time.NewTimer(retryWait)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		retryWait *= 2
+		if retryWait > eventSubscriptionRetryWaitMax {
+			retryWait = eventSubscriptionRetryWaitMax
+		}
+	}
+}
+
+// deliverPending delivers every event after the cursor, advancing the cursor
+// as each one is accepted by the sink.
+func (s *eventSubscription) deliverPending() error {
+	labels := []metrics.Label{{Name: "name", Value: s.config.Name}}
+
+	for {
+		s.lock.Lock()
+		seq := s.cursor + 1
+		pending := seq < s.next
+		s.lock.Unlock()
+		if !pending {
+			return nil
+		}
+
+		entry, err := s.view.Get(s.ctx, eventSubscriptionQueueKey+eventSubscriptionSequenceKey(seq))
+		if err != nil {
+			return err
+		}
+
+		if entry != nil {
+			if err := s.sink.deliver(s.ctx, entry.Value); err != nil {
+				metrics.IncrCounterWithLabels([]string{"events", "subscriptions", "failed"}, 1, labels)
+				return err
+			}
+			metrics.IncrCounterWithLabels([]string{"events", "subscriptions", "delivered"}, 1, labels)
+		}
+
+		if err := s.advance(seq); err != nil {
+			return err
+		}
+	}
+}
+
+// advance moves the cursor forward to seq, which has just been delivered, and
+// removes events that have fallen out of the replay window.
+func (s *eventSubscription) advance(seq uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// The cursor may have been moved by a replay while the event was being
+	// delivered, in which case delivery resumes from the new cursor.
+	if s.cursor != seq-1 {
+		return nil
+	}
+
+	if err := s.persistCursor(s.ctx, seq); err != nil {
+		return err
+	}
+	s.cursor = seq
+	s.lastDelivery = time.Now()
+
+	for s.oldest+uint64(s.config.ReplayRetention) <= s.cursor {
+		if err := s.view.Delete(s.ctx, eventSubscriptionQueueKey+eventSubscriptionSequenceKey(s.oldest)); err != nil {
+			return err
+		}
+		s.oldest++
+	}
+
+	return nil
+}
+
+// replay moves the cursor back to the given position.
+func (s *eventSubscription) replay(ctx context.Context, cursor uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if cursor > s.cursor {
+		return fmt.Errorf("%w: %d is ahead of the delivery cursor %d", errEventSubscriptionInvalidCursor, cursor, s.cursor)
+	}
+	if cursor+1 < s.oldest {
+		return fmt.Errorf("%w: %d is before the oldest retained event %d", errEventSubscriptionInvalidCursor, cursor, s.oldest)
+	}
+
+	if err := s.persistCursor(ctx, cursor); err != nil {
+		return err
+	}
+	s.cursor = cursor
+
+	s.notify()
+	return nil
+}
+
+// persistCursor writes the cursor to storage.
+func (s *eventSubscription) persistCursor(ctx context.Context, cursor uint64) error {
+	entry, err := logical.StorageEntryJSON(eventSubscriptionCursorKey, &eventSubscriptionCursor{Cursor: cursor})
+	if err != nil {
+		return err
+	}
+	return s.view.Put(ctx, entry)
+}
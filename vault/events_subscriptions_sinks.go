@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// eventSubscriptionSink is the destination of an event subscription. A
+// delivery must only succeed once the sink has accepted the event.
+type eventSubscriptionSink interface {
+	deliver(ctx context.Context, payload []byte) error
+	close()
+}
+
+var (
+	_ eventSubscriptionSink = (*eventSubscriptionWebhook)(nil)
+	_ eventSubscriptionSink = (*eventSubscriptionFile)(nil)
+)
+
+// newEventSubscriptionSink creates the sink described by the configuration.
+func newEventSubscriptionSink(config *EventSubscriptionConfig) (eventSubscriptionSink, error) {
+	switch config.SinkType {
+	case eventSubscriptionSinkWebhook:
+		return newEventSubscriptionWebhook(config)
+	case eventSubscriptionSinkFile:
+		return newEventSubscriptionFile(config)
+	default:
+		return nil, fmt.Errorf("unsupported sink type %q", config.SinkType)
+	}
+}
+
+// eventSubscriptionWebhook POSTs each event, as a structured CloudEvent, to an
+// HTTP endpoint. Any 2xx response is treated as an acknowledgement.
+type eventSubscriptionWebhook struct {
+	address string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newEventSubscriptionWebhook(config *EventSubscriptionConfig) (*eventSubscriptionWebhook, error) {
+	u, err := url.Parse(config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("address must be an http or https URL")
+	}
+
+	timeout := config.RequestTimeout
+	if timeout <= 0 {
+		timeout = defaultEventSubscriptionRequestTimeout
+	}
+
+	client := cleanhttp.DefaultPooledClient()
+	client.Timeout = timeout
+
+	return &eventSubscriptionWebhook{
+		address: config.Address,
+		headers: config.Headers,
+		client:  client,
+	}, nil
+}
+
+func (w *eventSubscriptionWebhook) deliver(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.address, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *eventSubscriptionWebhook) close() {
+	w.client.CloseIdleConnections()
+}
+
+// eventSubscriptionFile appends each event, as a line of CloudEvents JSON, to
+// a local file. The file is synced before the delivery is acknowledged.
+type eventSubscriptionFile struct {
+	path string
+
+	lock sync.Mutex
+	f    *os.File
+}
+
+func newEventSubscriptionFile(config *EventSubscriptionConfig) (*eventSubscriptionFile, error) {
+	if !filepath.IsAbs(config.FilePath) {
+		return nil, fmt.Errorf("file_path must be an absolute path")
+	}
+
+	return &eventSubscriptionFile{
+		path: config.FilePath,
+	}, nil
+}
+
+func (s *eventSubscriptionFile) deliver(_ context.Context, payload []byte) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+
+	line := append(bytes.TrimRight(payload, "\n"), '\n')
+	_, err := s.f.Write(line)
+	if err == nil {
+		err = s.f.Sync()
+	}
+	if err != nil {
+		// Reopen the file on the next attempt, in case it was rotated or
+		// removed from under us.
+		_ = s.f.Close()
+		s.f = nil
+		return err
+	}
+
+	return nil
+}
+
+func (s *eventSubscriptionFile) close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.f != nil {
+		_ = s.f.Close()
+		s.f = nil
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+// sendTestEvent sends an event with the given type to the core's event bus.
+func sendTestEvent(t *testing.T, c *Core, eventType string) {
+	t.Helper()
+
+	event, err := logical.NewEvent()
+	require.NoError(t, err)
+	pluginInfo := &logical.EventPluginInfo{MountPath: "test/"}
+	err = c.events.SendEventInternal(namespace.RootContext(nil), namespace.RootNamespace, pluginInfo, logical.EventType(eventType), event)
+	require.NoError(t, err)
+}
+
+// readFileLines returns the lines of the file at path.
+func readFileLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) || len(b) == 0 {
+		return nil
+	}
+	require.NoError(t, err)
+
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+// TestEventSubscriptions_File ensures that events matching a subscription are
+// appended to a file sink, and that moving the cursor back replays them.
+func TestEventSubscriptions_File(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	path := filepath.Join(t.TempDir(), "events.log")
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/events/subscriptions/test")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"event_type": "kv*",
+		"sink_type":  "file",
+		"file_path":  path,
+	}
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	sendTestEvent(t, c, "kv-v2/data-write")
+	sendTestEvent(t, c, "database/creds-create")
+	sendTestEvent(t, c, "kv-v2/data-delete")
+
+	require.Eventually(t, func() bool {
+		return len(readFileLines(t, path)) == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/events/subscriptions/test")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), resp.Data["cursor"])
+	require.Equal(t, uint64(2), resp.Data["latest_sequence"])
+	require.Equal(t, uint64(1), resp.Data["oldest_sequence"])
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "sys/events/subscriptions/test/replay")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{"cursor": 1}
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	require.Eventually(t, func() bool {
+		return len(readFileLines(t, path)) == 3
+	}, 10*time.Second, 10*time.Millisecond)
+	lines := readFileLines(t, path)
+	require.Equal(t, lines[1], lines[2])
+
+	// The cursor can't be moved ahead of the events delivered so far.
+	req.Data = map[string]interface{}{"cursor": 10}
+	resp, err = c.HandleRequest(ctx, req)
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+	require.True(t, resp.IsError())
+
+	req = logical.TestRequest(t, logical.ListOperation, "sys/events/subscriptions")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test"}, resp.Data["keys"])
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "sys/events/subscriptions/test")
+	req.ClientToken = root
+	_, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	keys, err := logical.CollectKeys(context.Background(), c.systemBarrierView.SubView(eventSubscriptionsPrefix))
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+// TestEventSubscriptions_WebhookRetry ensures that events which a webhook
+// fails to accept are retried, and survive the subscription being restarted.
+func TestEventSubscriptions_WebhookRetry(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	var failing atomic.Bool
+	failing.Store(true)
+	var lock sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		bodies = append(bodies, string(body))
+		lock.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/events/subscriptions/hook")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"sink_type": "webhook",
+		"address":   server.URL,
+		"filter":    `event_type == "test/one"`,
+	}
+	_, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	sendTestEvent(t, c, "test/one")
+	sendTestEvent(t, c, "test/two")
+
+	require.Eventually(t, func() bool {
+		status, err := c.eventSubscriptions.status(namespace.RootNamespace, "hook")
+		return err == nil && status.LatestSequence == 1 && status.LastError != ""
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Restarting the subscription keeps the undelivered event.
+	require.NoError(t, c.teardownEventSubscriptions())
+	require.NoError(t, c.setupEventSubscriptions(ctx))
+	failing.Store(false)
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(bodies) == 1
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Contains(t, bodies[0], `"test/one"`)
+
+	// Invalid configuration is rejected up front.
+	req.Data = map[string]interface{}{"address": "ftp://localhost"}
+	resp, err := c.HandleRequest(ctx, req)
+	require.ErrorIs(t, err, logical.ErrInvalidRequest)
+	require.True(t, resp.IsError())
+}
+
+// TestEventSubscriptions_MaxPending ensures that no more than max_pending
+// events are queued for a sink which is not accepting them.
+func TestEventSubscriptions_MaxPending(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/events/subscriptions/hook")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"sink_type":   "webhook",
+		"address":     server.URL,
+		"max_pending": 2,
+	}
+	_, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		sendTestEvent(t, c, "test/one")
+	}
+
+	require.Eventually(t, func() bool {
+		status, err := c.eventSubscriptions.status(namespace.RootNamespace, "hook")
+		return err == nil && status.Dropped == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	status, err := c.eventSubscriptions.status(namespace.RootNamespace, "hook")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), status.Cursor)
+	require.Equal(t, uint64(2), status.LatestSequence)
+}
@@ -2393,7 +2393,7 @@ func (m *ExpirationManager) leaseAggregationMetrics(ctx context.Context, consts
 
 	rollingWindow := time.Now().Add(time.Duration(consts.NumLeaseMetricsTimeBuckets) * leaseEpsilon)
 
-	err := m.walkLeases(func(entryID string, expireTime time.Time) bool {
+	err := m.walkLeases(func(entryID string, expireTime time.Time, _ string) bool {
 		select {
 		// Abort and return empty collection if it's taking too much time, nonblocking check.
 		case <-ctx.Done():
@@ -2483,7 +2483,7 @@ func (m *ExpirationManager) WalkTokens(walkFn ExpirationWalkFunction) error {
 }
 
 // leaseWalkFunction can only be used by the core package.
-type leaseWalkFunction = func(leaseID string, expireTime time.Time) bool
+type leaseWalkFunction = func(leaseID string, expireTime time.Time, loginRole string) bool
 
 func (m *ExpirationManager) walkLeases(walkFn leaseWalkFunction) error {
 	if m.inRestoreMode() {
@@ -2497,15 +2497,15 @@ func (m *ExpirationManager) walkLeases(walkFn leaseWalkFunction) error {
 		}
 		lease := p.cachedLeaseInfo
 		expireTime := lease.ExpireTime
-		return walkFn(key.(string), expireTime)
+		return walkFn(key.(string), expireTime, lease.LoginRole)
 	}
 
-	m.pendingLock.RLock()
-	toWalk := []sync.Map{m.pending, m.nonexpiring}
-	m.pendingLock.RUnlock()
-
-	for _, m := range toWalk {
-		m.Range(callback)
+	// The maps are ranged without holding pendingLock, as they are safe for
+	// concurrent use. This allows the lease count quotas to be recomputed
+	// while the quota manager's locks are held, which the expiration manager
+	// itself acquires with pendingLock held.
+	for _, leases := range []*sync.Map{&m.pending, &m.nonexpiring} {
+		leases.Range(callback)
 	}
 
 	return nil
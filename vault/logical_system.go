@@ -121,6 +121,8 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"leases/revoke-prefix/*",
 				"leases/revoke-force/*",
 				"leases/lookup/*",
+				"events/subscriptions",
+				"events/subscriptions/*",
 				"storage/raft/snapshot-auto/config/*",
 				"leases",
 				"internal/inspect/*",
@@ -208,6 +210,7 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 	b.Backend.Paths = append(b.Backend.Paths, b.inFlightRequestPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.hostInfoPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.quotasPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.eventsPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.rootActivityPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.loginMFAPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.experimentPaths()...)
@@ -3440,12 +3443,33 @@ func (b *SystemBackend) handleAuditTable(ctx context.Context, req *logical.Reque
 func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	path := data.Get("path").(string)
 	input := data.Get("input").(string)
-	if input == "" {
+	inputs := data.Get("inputs").([]string)
+	switch {
+	case input == "" && len(inputs) == 0:
 		return logical.ErrorResponse("the \"input\" parameter is empty"), nil
+	case input != "" && len(inputs) > 0:
+		return logical.ErrorResponse("only one of \"input\" and \"inputs\" may be specified"), nil
 	}
 
 	path = sanitizePath(path)
 
+	if len(inputs) > 0 {
+		hashes := make([]string, 0, len(inputs))
+		for _, input := range inputs {
+			hash, err := b.Core.auditBroker.GetHash(ctx, path, input)
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+			hashes = append(hashes, hash)
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"hashes": hashes,
+			},
+		}, nil
+	}
+
 	hash, err := b.Core.auditBroker.GetHash(ctx, path, input)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
@@ -3458,6 +3482,30 @@ func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Reques
 	}, nil
 }
 
+// handleAuditChain is used to fetch the head of the hash chain of the
+// specified audit backend
+func (b *SystemBackend) handleAuditChain(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	path := sanitizePath(data.Get("path").(string))
+
+	head, err := b.Core.auditChainHead(ctx, path)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"sequence": uint64(0),
+			"chain":    "",
+		},
+	}
+	if head != nil {
+		resp.Data["sequence"] = head.Sequence
+		resp.Data["chain"] = head.Chain
+	}
+
+	return resp, nil
+}
+
 // handleEnableAudit is used to enable a new audit backend
 func (b *SystemBackend) handleEnableAudit(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	repState := b.Core.ReplicationState()
@@ -5950,7 +5998,20 @@ or delete a policy.
 
 	"audit-hash": {
 		"The hash of the given string via the given audit backend",
-		"",
+		`
+Returns the hash of the given input, or the hashes of the given inputs in
+order, using the salt of the given audit backend.
+		`,
+	},
+
+	"audit-chain": {
+		"The head of the hash chain of the given audit backend",
+		`
+Returns the sequence number and chain of the last entry written by the
+given audit backend when it is configured with hash_chain. Both are empty
+if the backend has not yet written a chained entry. This is used to check
+that no entries have been removed from the end of its log.
+		`,
 	},
 
 	"audit-table": {
@@ -0,0 +1,428 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-bexpr"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// eventsPaths returns paths that manage server-side event subscriptions
+func (b *SystemBackend) eventsPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "events/subscriptions/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "event-subscriptions",
+				OperationVerb:   "list",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleEventSubscriptionsList(),
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(eventsHelp["subscriptions-list"][0]),
+			HelpDescription: strings.TrimSpace(eventsHelp["subscriptions-list"][1]),
+		},
+		{
+			Pattern: "events/subscriptions/" + framework.GenericNameRegex("name") + "/replay$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "event-subscriptions",
+				OperationVerb:   "replay",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the event subscription.",
+				},
+				"cursor": {
+					Type: framework.TypeInt64,
+					Description: `Sequence number of the last event to consider delivered. Every event after
+the cursor is delivered again. Must not be before the oldest retained event.`,
+					Required: true,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleEventSubscriptionReplay(),
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(eventsHelp["subscriptions-replay"][0]),
+			HelpDescription: strings.TrimSpace(eventsHelp["subscriptions-replay"][1]),
+		},
+		{
+			Pattern: "events/subscriptions/" + framework.GenericNameRegex("name"),
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "event-subscriptions",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the event subscription.",
+				},
+				"event_type": {
+					Type:        framework.TypeString,
+					Description: "Event type pattern to subscribe to, which may contain wildcards (default '*').",
+				},
+				"filter": {
+					Type:        framework.TypeString,
+					Description: "Optional go-bexpr expression that events must match to be delivered.",
+				},
+				"namespaces": {
+					Type: framework.TypeCommaStringSlice,
+					Description: `Additional namespace patterns, relative to the subscription's namespace, to
+receive events from. Wildcards are allowed.`,
+				},
+				"sink_type": {
+					Type:          framework.TypeString,
+					Description:   "Type of sink that events are delivered to: 'webhook' or 'file'.",
+					AllowedValues: []interface{}{eventSubscriptionSinkWebhook, eventSubscriptionSinkFile},
+				},
+				"address": {
+					Type:        framework.TypeString,
+					Description: "URL that events are POSTed to. Required for the webhook sink.",
+				},
+				"headers": {
+					Type:        framework.TypeKVPairs,
+					Description: "HTTP headers added to each webhook request.",
+				},
+				"request_timeout": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Timeout of each webhook request (default '10s').",
+				},
+				"file_path": {
+					Type:        framework.TypeString,
+					Description: "Absolute path of the file that events are appended to. Required for the file sink.",
+				},
+				"replay_retention": {
+					Type:        framework.TypeInt,
+					Description: "Number of delivered events kept so that they can be replayed (default 1000).",
+				},
+				"max_pending": {
+					Type: framework.TypeInt,
+					Description: `Maximum number of undelivered events queued for the sink, after which new
+events are dropped (default 10000). Zero means no limit.`,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleEventSubscriptionWrite(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "write",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleEventSubscriptionRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleEventSubscriptionDelete(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "delete",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(eventsHelp["subscriptions"][0]),
+			HelpDescription: strings.TrimSpace(eventsHelp["subscriptions"][1]),
+		},
+	}
+}
+
+func (b *SystemBackend) handleEventSubscriptionsList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		m := b.Core.eventSubscriptions
+		if m == nil {
+			return nil, errEventSubscriptionsNotAvailable
+		}
+
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		names, err := m.list(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleEventSubscriptionWrite() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		m := b.Core.eventSubscriptions
+		if m == nil {
+			return nil, errEventSubscriptionsNotAvailable
+		}
+
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		name := d.Get("name").(string)
+		config, err := m.get(ctx, ns, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			config = &EventSubscriptionConfig{
+				Name:            name,
+				NamespaceID:     ns.ID,
+				NamespacePath:   ns.Path,
+				EventType:       "*",
+				RequestTimeout:  defaultEventSubscriptionRequestTimeout,
+				ReplayRetention: defaultEventSubscriptionReplayRetention,
+				MaxPending:      defaultEventSubscriptionMaxPending,
+			}
+		}
+
+		if v, ok := d.GetOk("event_type"); ok {
+			config.EventType = v.(string)
+		}
+		if config.EventType == "" {
+			return logical.ErrorResponse("'event_type' cannot be empty"), logical.ErrInvalidRequest
+		}
+		if v, ok := d.GetOk("filter"); ok {
+			config.Filter = v.(string)
+		}
+		if config.Filter != "" {
+			if _, err := bexpr.CreateEvaluator(config.Filter); err != nil {
+				return logical.ErrorResponse("invalid filter: %s", err), logical.ErrInvalidRequest
+			}
+		}
+		if v, ok := d.GetOk("namespaces"); ok {
+			config.Namespaces = v.([]string)
+		}
+		if v, ok := d.GetOk("sink_type"); ok {
+			config.SinkType = v.(string)
+		}
+		if v, ok := d.GetOk("address"); ok {
+			config.Address = v.(string)
+		}
+		if v, ok := d.GetOk("headers"); ok {
+			config.Headers = v.(map[string]string)
+		}
+		if v, ok := d.GetOk("request_timeout"); ok {
+			config.RequestTimeout = time.Duration(v.(int)) * time.Second
+		}
+		if v, ok := d.GetOk("file_path"); ok {
+			config.FilePath = v.(string)
+		}
+		if v, ok := d.GetOk("replay_retention"); ok {
+			config.ReplayRetention = v.(int)
+		}
+		if config.ReplayRetention < 0 {
+			return logical.ErrorResponse("'replay_retention' cannot be negative"), logical.ErrInvalidRequest
+		}
+		if v, ok := d.GetOk("max_pending"); ok {
+			config.MaxPending = v.(int)
+		}
+		if config.MaxPending < 0 {
+			return logical.ErrorResponse("'max_pending' cannot be negative"), logical.ErrInvalidRequest
+		}
+
+		switch config.SinkType {
+		case "":
+			return logical.ErrorResponse("'sink_type' is required"), logical.ErrInvalidRequest
+		case eventSubscriptionSinkWebhook:
+			if config.Address == "" {
+				return logical.ErrorResponse("'address' is required for the webhook sink"), logical.ErrInvalidRequest
+			}
+		case eventSubscriptionSinkFile:
+			if config.FilePath == "" {
+				return logical.ErrorResponse("'file_path' is required for the file sink"), logical.ErrInvalidRequest
+			}
+		}
+
+		// Create the sink up front so that configuration errors are reported
+		// to the caller rather than when the subscription is started.
+		sink, err := newEventSubscriptionSink(config)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		sink.close()
+
+		if err := m.set(ctx, config); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleEventSubscriptionRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		m := b.Core.eventSubscriptions
+		if m == nil {
+			return nil, errEventSubscriptionsNotAvailable
+		}
+
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		name := d.Get("name").(string)
+		config, err := m.get(ctx, ns, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, nil
+		}
+
+		// Header values frequently hold credentials, so only their names are
+		// returned.
+		headerNames := make([]string, 0, len(config.Headers))
+		for k := range config.Headers {
+			headerNames = append(headerNames, k)
+		}
+		sort.Strings(headerNames)
+
+		data := map[string]interface{}{
+			"name":             config.Name,
+			"event_type":       config.EventType,
+			"filter":           config.Filter,
+			"namespaces":       config.Namespaces,
+			"sink_type":        config.SinkType,
+			"address":          config.Address,
+			"header_names":     headerNames,
+			"request_timeout":  int64(config.RequestTimeout.Seconds()),
+			"file_path":        config.FilePath,
+			"replay_retention": config.ReplayRetention,
+			"max_pending":      config.MaxPending,
+		}
+
+		// The subscription may have failed to start, in which case there is
+		// no delivery status to report.
+		status, err := m.status(ns, name)
+		switch {
+		case errors.Is(err, errEventSubscriptionNotFound):
+			data["running"] = false
+		case err != nil:
+			return nil, err
+		default:
+			data["running"] = true
+			data["cursor"] = status.Cursor
+			data["latest_sequence"] = status.LatestSequence
+			data["oldest_sequence"] = status.OldestSequence
+			data["dropped"] = status.Dropped
+			data["last_error"] = status.LastError
+			if !status.LastDelivery.IsZero() {
+				data["last_delivery_time"] = status.LastDelivery.Format(time.RFC3339Nano)
+			}
+			if !status.LastErrorTime.IsZero() {
+				data["last_error_time"] = status.LastErrorTime.Format(time.RFC3339Nano)
+			}
+		}
+
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleEventSubscriptionDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		m := b.Core.eventSubscriptions
+		if m == nil {
+			return nil, errEventSubscriptionsNotAvailable
+		}
+
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.delete(ctx, ns, d.Get("name").(string)); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleEventSubscriptionReplay() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		m := b.Core.eventSubscriptions
+		if m == nil {
+			return nil, errEventSubscriptionsNotAvailable
+		}
+
+		ns, err := namespace.FromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cursor := d.Get("cursor").(int64)
+		if cursor < 0 {
+			return logical.ErrorResponse("'cursor' cannot be negative"), logical.ErrInvalidRequest
+		}
+
+		err = m.replay(ctx, ns, d.Get("name").(string), uint64(cursor))
+		switch {
+		case errors.Is(err, errEventSubscriptionNotFound), errors.Is(err, errEventSubscriptionInvalidCursor):
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		case err != nil:
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+var eventsHelp = map[string][2]string{
+	"subscriptions": {
+		"Get, create, update or delete a server-side event subscription.",
+		`An event subscription forwards the events matching its event type pattern and
+optional filter to a webhook or a local file. Events are queued in storage until
+the sink has accepted them, so each event is delivered at least once. Each event
+is given a sequence number, and the subscription's cursor records the last event
+delivered.`,
+	},
+	"subscriptions-list": {
+		"Lists the names of the event subscriptions in the namespace.",
+		"",
+	},
+	"subscriptions-replay": {
+		"Move the cursor of an event subscription back to replay events.",
+		`Events after the given cursor are delivered again. Delivered events are only
+retained up to the subscription's 'replay_retention', so the cursor cannot be
+moved before the oldest retained event.`,
+	},
+}
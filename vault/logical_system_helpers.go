@@ -238,12 +238,6 @@ var (
 			"plugins/reload/backend/status$": {operations: []logical.Operation{logical.ReadOperation}},
 		})...)
 
-		// quotas paths
-		paths = append(paths, buildEnterpriseOnlyPaths(map[string]enterprisePathStub{
-			"quotas/lease-count/?$": {operations: []logical.Operation{logical.ListOperation}},
-			"quotas/lease-count/" + framework.GenericNameRegex("name"): {parameters: []string{"name"}, operations: []logical.Operation{logical.DeleteOperation, logical.ReadOperation, logical.UpdateOperation}},
-		})...)
-
 		// raft auto-snapshot paths
 		paths = append(paths, buildEnterpriseOnlyPaths(map[string]enterprisePathStub{
 			"storage/raft/snapshot-auto/config/":                                      {operations: []logical.Operation{logical.ListOperation}},
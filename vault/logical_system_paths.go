@@ -1506,6 +1506,11 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 			"input": {
 				Type: framework.TypeString,
 			},
+
+			"inputs": {
+				Type:        framework.TypeStringSlice,
+				Description: "A list of inputs to hash, instead of a single input.",
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -1516,8 +1521,10 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 						Description: "OK",
 						Fields: map[string]*framework.FieldSchema{
 							"hash": {
-								Type:     framework.TypeString,
-								Required: true,
+								Type: framework.TypeString,
+							},
+							"hashes": {
+								Type: framework.TypeStringSlice,
 							},
 						},
 					}},
@@ -1530,9 +1537,53 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) auditChainPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit-chain/(?P<path>.+)",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "read",
+			OperationSuffix: "chain-head",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"path": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit_path"][0]),
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAuditChain,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"sequence": {
+								Type:     framework.TypeInt64,
+								Required: true,
+							},
+							"chain": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-chain"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-chain"][1]),
+	}
+}
+
 func (b *SystemBackend) auditPaths() []*framework.Path {
 	return []*framework.Path{
 		b.auditHashPath(),
+		b.auditChainPath(),
 
 		{
 			Pattern: "audit$",
@@ -215,6 +215,127 @@ from any further requests until after the 'block_interval' has elapsed.`,
 			HelpSynopsis:    strings.TrimSpace(quotasHelp["rate-limit"][0]),
 			HelpDescription: strings.TrimSpace(quotasHelp["rate-limit"][1]),
 		},
+		{
+			Pattern: "quotas/lease-count/?$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "lease-count-quotas",
+				OperationVerb:   "list",
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasList(),
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["lease-count-list"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["lease-count-list"][1]),
+		},
+		{
+			Pattern: "quotas/lease-count/" + framework.GenericNameRegex("name"),
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "lease-count-quotas",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"type": {
+					Type:        framework.TypeString,
+					Description: "Type of the quota rule.",
+				},
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the quota rule.",
+				},
+				"path": {
+					Type: framework.TypeString,
+					Description: `Path of the mount or namespace to apply the quota. A blank path configures a
+global quota. For example namespace1/ adds a quota to a full namespace,
+namespace1/auth/userpass adds a quota to userpass in namespace1.`,
+				},
+				"role": {
+					Type: framework.TypeString,
+					Description: `Login role to apply this quota to. Note that when set, path must be configured
+to a valid auth method with a concept of roles.`,
+				},
+				"inheritable": {
+					Type:        framework.TypeBool,
+					Description: `Whether all child namespaces can inherit this namespace quota.`,
+				},
+				"max_leases": {
+					Type: framework.TypeInt,
+					Description: `The maximum number of leases to be allowed by the quota rule.
+The 'max_leases' must be positive.`,
+				},
+			},
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasUpdate(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "write",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: http.StatusText(http.StatusNoContent),
+						}},
+					},
+				},
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasRead(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "read",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"name": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"path": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"role": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"max_leases": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"counter": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+								"inheritable": {
+									Type:     framework.TypeBool,
+									Required: true,
+								},
+							},
+						}},
+					},
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleLeaseCountQuotasDelete(),
+					DisplayAttrs: &framework.DisplayAttributes{
+						OperationVerb: "delete",
+					},
+					Responses: map[int][]framework.Response{
+						http.StatusNoContent: {{
+							Description: "OK",
+						}},
+					},
+				},
+			},
+			HelpSynopsis:    strings.TrimSpace(quotasHelp["lease-count"][0]),
+			HelpDescription: strings.TrimSpace(quotasHelp["lease-count"][1]),
+		},
 	}
 }
 
@@ -297,70 +418,14 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 			return logical.ErrorResponse("'block' is invalid"), nil
 		}
 
-		mountPath := sanitizePath(d.Get("path").(string))
-		ns := b.Core.namespaceByPath(mountPath)
-		if ns.ID != namespace.RootNamespaceID {
-			mountPath = strings.TrimPrefix(mountPath, ns.Path)
-		}
-
-		var pathSuffix string
-		if mountPath != "" {
-			me := b.Core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, ns), mountPath)
-			if me == nil {
-				return logical.ErrorResponse("invalid mount path %q", mountPath), nil
-			}
-
-			mountAPIPath := me.APIPathNoNamespace()
-			pathSuffix = strings.TrimSuffix(strings.TrimPrefix(mountPath, mountAPIPath), "/")
-			mountPath = mountAPIPath
-		}
-
-		role := d.Get("role").(string)
-		// If this is a quota with a role, ensure the backend supports role resolution
-		if role != "" {
-			if pathSuffix != "" {
-				return logical.ErrorResponse("Quotas cannot contain both a path suffix and a role. If a role is provided, path must be a valid auth mount with a concept of roles"), nil
-			}
-			authBackend := b.Core.router.MatchingBackend(namespace.ContextWithNamespace(ctx, ns), mountPath)
-			if authBackend == nil || authBackend.Type() != logical.TypeCredential {
-				return logical.ErrorResponse("Mount path %q is not a valid auth method and therefore unsuitable for use with role-based quotas", mountPath), nil
-			}
-			// We will always error as we aren't supplying real data, but we're looking for "unsupported operation" in particular
-			_, err := authBackend.HandleRequest(ctx, &logical.Request{
-				Path:      "login",
-				Operation: logical.ResolveRoleOperation,
-			})
-			if err != nil && (err == logical.ErrUnsupportedOperation || err == logical.ErrUnsupportedPath) {
-				return logical.ErrorResponse("Mount path %q does not support use with role-based quotas", mountPath), nil
-			}
-		}
-
-		var inheritable bool
-		// All global quotas should be inherited by default
-		if ns.Path == "" {
-			inheritable = true
-		}
-
-		if inheritableRaw, ok := d.GetOk("inheritable"); ok {
-			inheritable = inheritableRaw.(bool)
-			if inheritable {
-				if pathSuffix != "" || role != "" || mountPath != "" {
-					return logical.ErrorResponse("only namespace quotas can be configured as inheritable"), nil
-				}
-			} else if ns.Path == "" {
-				// User should not try to configure a global quota that cannot be inherited
-				return logical.ErrorResponse("all global quotas must be inheritable"), nil
-			}
-		}
-
-		// User should not try to configure a global quota to be uninheritable
-		if ns.Path == "" && !inheritable {
-			return logical.ErrorResponse("all global quotas must be inheritable"), nil
+		scope, errResp := b.parseQuotaScope(ctx, d)
+		if errResp != nil {
+			return errResp, nil
 		}
 
 		// Disallow creation of new quota that has properties similar to an
 		// existing quota.
-		quotaByFactors, err := b.Core.quotaManager.QuotaByFactors(ctx, qType, ns.Path, mountPath, pathSuffix, role)
+		quotaByFactors, err := b.Core.quotaManager.QuotaByFactors(ctx, qType, scope.ns.Path, scope.mountPath, scope.pathSuffix, scope.role)
 		if err != nil {
 			return nil, err
 		}
@@ -376,17 +441,17 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 
 		switch {
 		case quota == nil:
-			quota = quotas.NewRateLimitQuota(name, ns.Path, mountPath, pathSuffix, role, inheritable, interval, blockInterval, rate)
+			quota = quotas.NewRateLimitQuota(name, scope.ns.Path, scope.mountPath, scope.pathSuffix, scope.role, scope.inheritable, interval, blockInterval, rate)
 		default:
 			// Re-inserting the already indexed object in memdb might cause problems.
 			// So, clone the object. See https://github.com/hashicorp/go-memdb/issues/76.
 			clonedQuota := quota.Clone()
 			rlq := clonedQuota.(*quotas.RateLimitQuota)
-			rlq.NamespacePath = ns.Path
-			rlq.MountPath = mountPath
-			rlq.PathSuffix = pathSuffix
+			rlq.NamespacePath = scope.ns.Path
+			rlq.MountPath = scope.mountPath
+			rlq.PathSuffix = scope.pathSuffix
 			rlq.Rate = rate
-			rlq.Inheritable = inheritable
+			rlq.Inheritable = scope.inheritable
 			rlq.Interval = interval
 			rlq.BlockInterval = blockInterval
 			quota = rlq
@@ -409,6 +474,90 @@ func (b *SystemBackend) handleRateLimitQuotasUpdate() framework.OperationFunc {
 	}
 }
 
+// quotaScope describes where a quota rule applies, as parsed from the common
+// quota fields.
+type quotaScope struct {
+	ns          *namespace.Namespace
+	mountPath   string
+	pathSuffix  string
+	role        string
+	inheritable bool
+}
+
+// parseQuotaScope parses and validates the path, role and inheritable fields shared
+// by all the quota types. If the fields are invalid, an error response is
+// returned.
+func (b *SystemBackend) parseQuotaScope(ctx context.Context, d *framework.FieldData) (*quotaScope, *logical.Response) {
+	mountPath := sanitizePath(d.Get("path").(string))
+	ns := b.Core.namespaceByPath(mountPath)
+	if ns.ID != namespace.RootNamespaceID {
+		mountPath = strings.TrimPrefix(mountPath, ns.Path)
+	}
+
+	var pathSuffix string
+	if mountPath != "" {
+		me := b.Core.router.MatchingMountEntry(namespace.ContextWithNamespace(ctx, ns), mountPath)
+		if me == nil {
+			return nil, logical.ErrorResponse("invalid mount path %q", mountPath)
+		}
+
+		mountAPIPath := me.APIPathNoNamespace()
+		pathSuffix = strings.TrimSuffix(strings.TrimPrefix(mountPath, mountAPIPath), "/")
+		mountPath = mountAPIPath
+	}
+
+	role := d.Get("role").(string)
+	// If this is a quota with a role, ensure the backend supports role resolution
+	if role != "" {
+		if pathSuffix != "" {
+			return nil, logical.ErrorResponse("Quotas cannot contain both a path suffix and a role. If a role is provided, path must be a valid auth mount with a concept of roles")
+		}
+		authBackend := b.Core.router.MatchingBackend(namespace.ContextWithNamespace(ctx, ns), mountPath)
+		if authBackend == nil || authBackend.Type() != logical.TypeCredential {
+			return nil, logical.ErrorResponse("Mount path %q is not a valid auth method and therefore unsuitable for use with role-based quotas", mountPath)
+		}
+		// We will always error as we aren't supplying real data, but we're looking for "unsupported operation" in particular
+		_, err := authBackend.HandleRequest(ctx, &logical.Request{
+			Path:      "login",
+			Operation: logical.ResolveRoleOperation,
+		})
+		if err != nil && (err == logical.ErrUnsupportedOperation || err == logical.ErrUnsupportedPath) {
+			return nil, logical.ErrorResponse("Mount path %q does not support use with role-based quotas", mountPath)
+		}
+	}
+
+	var inheritable bool
+	// All global quotas should be inherited by default
+	if ns.Path == "" {
+		inheritable = true
+	}
+
+	if inheritableRaw, ok := d.GetOk("inheritable"); ok {
+		inheritable = inheritableRaw.(bool)
+		if inheritable {
+			if pathSuffix != "" || role != "" || mountPath != "" {
+				return nil, logical.ErrorResponse("only namespace quotas can be configured as inheritable")
+			}
+		} else if ns.Path == "" {
+			// User should not try to configure a global quota that cannot be inherited
+			return nil, logical.ErrorResponse("all global quotas must be inheritable")
+		}
+	}
+
+	// User should not try to configure a global quota to be uninheritable
+	if ns.Path == "" && !inheritable {
+		return nil, logical.ErrorResponse("all global quotas must be inheritable")
+	}
+
+	return &quotaScope{
+		ns:          ns,
+		mountPath:   mountPath,
+		pathSuffix:  pathSuffix,
+		role:        role,
+		inheritable: inheritable,
+	}, nil
+}
+
 func (b *SystemBackend) handleRateLimitQuotasRead() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 		name := d.Get("name").(string)
@@ -463,6 +612,135 @@ func (b *SystemBackend) handleRateLimitQuotasDelete() framework.OperationFunc {
 	}
 }
 
+func (b *SystemBackend) handleLeaseCountQuotasList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		names, err := b.Core.quotaManager.QuotaNames(quotas.TypeLeaseCount)
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+
+		qType := quotas.TypeLeaseCount.String()
+		maxLeases := d.Get("max_leases").(int)
+		if maxLeases <= 0 {
+			return logical.ErrorResponse("'max_leases' is invalid"), nil
+		}
+
+		scope, errResp := b.parseQuotaScope(ctx, d)
+		if errResp != nil {
+			return errResp, nil
+		}
+
+		// Disallow creation of new quota that has properties similar to an
+		// existing quota.
+		quotaByFactors, err := b.Core.quotaManager.QuotaByFactors(ctx, qType, scope.ns.Path, scope.mountPath, scope.pathSuffix, scope.role)
+		if err != nil {
+			return nil, err
+		}
+		if quotaByFactors != nil && quotaByFactors.QuotaName() != name {
+			return logical.ErrorResponse("quota rule with similar properties exists under the name %q", quotaByFactors.QuotaName()), nil
+		}
+
+		// If a quota already exists, fetch and update it.
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case quota == nil:
+			quota = quotas.NewLeaseCountQuota(name, scope.ns.Path, scope.mountPath, scope.pathSuffix, scope.role, scope.inheritable, maxLeases)
+		default:
+			// Re-inserting the already indexed object in memdb might cause problems.
+			// So, clone the object. See https://github.com/hashicorp/go-memdb/issues/76.
+			clonedQuota := quota.Clone()
+			lcq := clonedQuota.(*quotas.LeaseCountQuota)
+			lcq.NamespacePath = scope.ns.Path
+			lcq.MountPath = scope.mountPath
+			lcq.PathSuffix = scope.pathSuffix
+			lcq.Role = scope.role
+			lcq.Inheritable = scope.inheritable
+			lcq.MaxLeases = maxLeases
+			quota = lcq
+		}
+
+		entry, err := logical.StorageEntryJSON(quotas.QuotaStoragePath(qType, name), quota)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.SetQuota(ctx, qType, quota, false); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeLeaseCount.String()
+
+		quota, err := b.Core.quotaManager.QuotaByName(qType, name)
+		if err != nil {
+			return nil, err
+		}
+		if quota == nil {
+			return nil, nil
+		}
+
+		lcq := quota.(*quotas.LeaseCountQuota)
+
+		nsPath := lcq.NamespacePath
+		if lcq.NamespacePath == "root" {
+			nsPath = ""
+		}
+
+		data := map[string]interface{}{
+			"type":        qType,
+			"name":        lcq.Name,
+			"path":        nsPath + lcq.MountPath + lcq.PathSuffix,
+			"role":        lcq.Role,
+			"max_leases":  lcq.MaxLeases,
+			"counter":     lcq.LeaseCount(),
+			"inheritable": lcq.Inheritable,
+		}
+
+		return &logical.Response{
+			Data: data,
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleLeaseCountQuotasDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		qType := quotas.TypeLeaseCount.String()
+
+		if err := req.Storage.Delete(ctx, quotas.QuotaStoragePath(qType, name)); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.quotaManager.DeleteQuota(ctx, qType, name); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
 var quotasHelp = map[string][2]string{
 	"quotas-config": {
 		"Create, update and read the quota configuration.",
@@ -480,4 +758,16 @@ IP address.`,
 		"Lists the names of all the rate limit quotas.",
 		"This list contains quota definitions from all the namespaces.",
 	},
+	"lease-count": {
+		`Get, create or update lease count resource quota for an optional namespace,
+mount, path or role.`,
+		`A lease count quota will limit the number of leases that can be held at any
+given time. A lease count quota can be created at the root level or defined on
+a namespace, mount, path or login role by specifying a 'path' and optionally a
+'role'. Requests that would create a lease beyond 'max_leases' are rejected.`,
+	},
+	"lease-count-list": {
+		"Lists the names of all the lease count quotas.",
+		"This list contains quota definitions from all the namespaces.",
+	},
 }
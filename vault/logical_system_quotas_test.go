@@ -0,0 +1,138 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/vault/quotas"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSystemBackend_LeaseCountQuotas tests the lifecycle of a lease count
+// quota through the sys/quotas/lease-count endpoints.
+func TestSystemBackend_LeaseCountQuotas(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"path":       "secret/",
+		"max_leases": 0,
+	}
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	req.Data["max_leases"] = 10
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	// Another quota with the same properties is rejected.
+	dupReq := logical.TestRequest(t, logical.UpdateOperation, "sys/quotas/lease-count/dup")
+	dupReq.ClientToken = root
+	dupReq.Data = req.Data
+	resp, err = c.HandleRequest(ctx, dupReq)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, "lcq", resp.Data["name"])
+	require.Equal(t, "secret/", resp.Data["path"])
+	require.Equal(t, 10, resp.Data["max_leases"])
+	require.Equal(t, 0, resp.Data["counter"])
+
+	req = logical.TestRequest(t, logical.ListOperation, "sys/quotas/lease-count")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, []string{"lcq"}, resp.Data["keys"])
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	_, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+// TestSystemBackend_LeaseCountQuotas_Enforcement tests that lease count quotas
+// reject requests once the number of leases reaches the limit, and that
+// revoking a lease frees up a slot.
+func TestSystemBackend_LeaseCountQuotas_Enforcement(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ctx := namespace.RootContext(nil)
+
+	// Leases created in restore mode are only counted once they are restored.
+	waitForRestore(t, c.expiration)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "secret/test")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"foo":   "bar",
+		"lease": "1h",
+	}
+	_, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"path":       "secret/",
+		"max_leases": 2,
+	}
+	_, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	read := func() (*logical.Response, error) {
+		req := logical.TestRequest(t, logical.ReadOperation, "secret/test")
+		req.ClientToken = root
+		return c.HandleRequest(ctx, req)
+	}
+
+	// The first lease on the path is counted against the quota.
+	var leaseIDs []string
+	for i := 0; i < 2; i++ {
+		resp, err := read()
+		require.NoError(t, err)
+		require.NotNil(t, resp.Secret)
+		leaseIDs = append(leaseIDs, resp.Secret.LeaseID)
+	}
+
+	_, err = read()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, quotas.ErrLeaseCountQuotaExceeded))
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/quotas/lease-count/lcq")
+	req.ClientToken = root
+	resp, err := c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Data["counter"])
+
+	// Revoking a lease remains possible while the quota is exhausted.
+	req = logical.TestRequest(t, logical.UpdateOperation, "sys/leases/revoke")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"lease_id": leaseIDs[0],
+		"sync":     true,
+	}
+	_, err = c.HandleRequest(ctx, req)
+	require.NoError(t, err)
+
+	resp, err = read()
+	require.NoError(t, err)
+	require.NotNil(t, resp.Secret)
+}
@@ -2501,6 +2501,79 @@ func TestSystemBackend_auditHash(t *testing.T) {
 	if hash.(string) != "hmac-sha256:f9320baf0249169e73850cd6156ded0106e2bb6ad8cab01b7bbbebe6d1065317" {
 		t.Fatalf("bad hash back: %s", hash.(string))
 	}
+
+	// Several inputs can be hashed at once.
+	req = logical.TestRequest(t, logical.UpdateOperation, "audit-hash/foo")
+	req.Data["inputs"] = []string{"bar", "baz"}
+
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+
+	hashes := resp.Data["hashes"].([]string)
+	require.Len(t, hashes, 2)
+	require.Equal(t, hash, hashes[0])
+	require.NotEqual(t, hashes[0], hashes[1])
+
+	req.Data["input"] = "bar"
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
+}
+
+// TestSystemBackend_auditChain ensures that the head of the hash chain of an
+// audit device can be read, and advances as entries are written.
+func TestSystemBackend_auditChain(t *testing.T) {
+	c, b, root := testCoreSystemBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "audit/foo")
+	req.Data["type"] = "file"
+	req.Data["options"] = map[string]interface{}{
+		"file_path":  "discard",
+		"hash_chain": "true",
+	}
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	readHead := func() *logical.Response {
+		t.Helper()
+
+		req := logical.TestRequest(t, logical.ReadOperation, "audit-chain/foo")
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		require.NoError(t, err)
+		schema.ValidateResponse(
+			t,
+			schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+			resp,
+			true,
+		)
+		return resp
+	}
+
+	resp = readHead()
+	require.Equal(t, uint64(0), resp.Data["sequence"])
+	require.Equal(t, "", resp.Data["chain"])
+
+	req = logical.TestRequest(t, logical.ReadOperation, "sys/mounts")
+	req.ClientToken = root
+	_, err = c.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+
+	// The request and the response are each chained.
+	resp = readHead()
+	require.Equal(t, uint64(2), resp.Data["sequence"])
+	require.True(t, strings.HasPrefix(resp.Data["chain"].(string), "hmac-sha256:"))
+
+	req = logical.TestRequest(t, logical.ReadOperation, "audit-chain/missing")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	require.NoError(t, err)
+	require.True(t, resp.IsError())
 }
 
 func TestSystemBackend_enableAudit_invalid(t *testing.T) {
@@ -0,0 +1,194 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/mitchellh/mapstructure"
+)
+
+// EST (RFC 7030) clients authenticate with HTTP Basic credentials or a TLS
+// client certificate rather than a Vault token. The PKI secrets engine
+// returns the auth mounts to use for this when its config/est endpoint is
+// read; for enrollment requests without a token, we log the client in
+// through that mount and run the request with the resulting token.
+
+// ErrESTCredentialsRequired is returned when an EST enrollment request
+// carries neither credentials nor a client certificate, so that clients
+// can be challenged for HTTP Basic credentials.
+var ErrESTCredentialsRequired = errors.New("EST enrollment requires credentials")
+
+var estEnrollPathRe = regexp.MustCompile(`^est/(?:[^/]+/)?simple(?:re)?enroll$`)
+
+const (
+	estMountType  = "pki"
+	estConfigPath = "config/est"
+)
+
+// estConfig holds the fields of a mount's config/est read response that core
+// needs.
+type estConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	Authenticators map[string]*struct {
+		Accessor string `mapstructure:"accessor"`
+		CertRole string `mapstructure:"cert_role"`
+	} `mapstructure:"authenticators"`
+}
+
+// readESTConfig reads the EST configuration of the PKI mount me through its
+// config/est endpoint. The read is made by core rather than a client, so it
+// bypasses ACLs, but is audited like any other request. Nil is returned if
+// the mount does not provide one.
+func (c *Core) readESTConfig(ctx context.Context, me *MountEntry) (*estConfig, error) {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+	req := &logical.Request{
+		ID:         id,
+		Operation:  logical.ReadOperation,
+		Path:       me.Path + estConfigPath,
+		MountPoint: me.Path,
+		MountType:  me.Type,
+	}
+
+	logInput := &logical.LogInput{
+		Auth:    &logical.Auth{},
+		Request: req,
+	}
+	if err := c.auditBroker.LogRequest(ctx, logInput, c.auditedHeaders); err != nil {
+		c.logger.Error("failed to audit request", "request_path", req.Path, "error", err)
+		return nil, errors.New("failed to audit request, cannot continue")
+	}
+
+	resp, err := c.router.Route(ctx, req)
+
+	logInput.Response = resp
+	logInput.OuterErr = err
+	if auditErr := c.auditBroker.LogResponse(ctx, logInput, c.auditedHeaders); auditErr != nil {
+		c.logger.Error("failed to audit response", "request_path", req.Path, "error", auditErr)
+		return nil, errors.New("failed to audit response, cannot continue")
+	}
+
+	if errors.Is(err, logical.ErrUnsupportedPath) || errors.Is(err, logical.ErrUnsupportedOperation) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EST configuration: %w", err)
+	}
+	if resp == nil || resp.IsError() {
+		return nil, nil
+	}
+
+	var config estConfig
+	if err := mapstructure.Decode(resp.Data, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode EST configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// LoginESTClient logs in the client of an EST enrollment request through the
+// auth mount configured on the PKI mount serving it, and returns the token
+// to perform the request with. An empty token is returned for requests that
+// are not EST enrollments of a PKI mount. The auth mount must be in the PKI
+// mount's namespace. Callers should revoke the token with
+// RevokeESTClientToken once the request has been handled.
+func (c *Core) LoginESTClient(ctx context.Context, req *logical.Request, username, password string) (string, error) {
+	if req.Operation != logical.UpdateOperation || req.ClientToken != "" || c.Sealed() {
+		return "", nil
+	}
+
+	me := c.router.MatchingMountEntry(ctx, req.Path)
+	if me == nil || me.Table != mountTableType || me.Type != estMountType || !estEnrollPathRe.MatchString(strings.TrimPrefix(req.Path, me.Path)) {
+		return "", nil
+	}
+
+	config, err := c.readESTConfig(ctx, me)
+	if err != nil {
+		return "", err
+	}
+	if config == nil || !config.Enabled {
+		return "", nil
+	}
+
+	var authenticator, loginPath string
+	data := map[string]interface{}{}
+	switch {
+	case username != "" && config.Authenticators["userpass"] != nil:
+		authenticator = "userpass"
+		loginPath = "login/" + username
+		data["password"] = password
+	case req.Connection != nil && req.Connection.ConnState != nil &&
+		len(req.Connection.ConnState.PeerCertificates) > 0 && config.Authenticators["cert"] != nil:
+		authenticator = "cert"
+		loginPath = "login"
+		data["name"] = config.Authenticators["cert"].CertRole
+	case config.Authenticators["userpass"] != nil:
+		return "", ErrESTCredentialsRequired
+	default:
+		return "", nil
+	}
+
+	authMount := c.router.MatchingMountByAccessor(config.Authenticators[authenticator].Accessor)
+	if authMount == nil || authMount.Table != credentialTableType || authMount.Type != authenticator {
+		return "", fmt.Errorf("EST %s authenticator does not refer to a %s auth mount", authenticator, authenticator)
+	}
+	if authMount.NamespaceID != me.NamespaceID {
+		return "", fmt.Errorf("EST %s authenticator does not refer to an auth mount in the namespace of the PKI mount", authenticator)
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.HandleRequest(namespace.ContextWithNamespace(ctx, authMount.Namespace()), &logical.Request{
+		ID:         id,
+		Operation:  logical.UpdateOperation,
+		Path:       authMount.APIPathNoNamespace() + loginPath,
+		Data:       data,
+		Connection: req.Connection,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp == nil || resp.Auth == nil || resp.Auth.ClientToken == "" {
+		if resp != nil && resp.IsError() {
+			return "", fmt.Errorf("%w: %v", logical.ErrPermissionDenied, resp.Error())
+		}
+		return "", logical.ErrPermissionDenied
+	}
+
+	return resp.Auth.ClientToken, nil
+}
+
+// RevokeESTClientToken revokes a token returned by LoginESTClient. Batch
+// tokens can't be revoked and simply expire.
+func (c *Core) RevokeESTClientToken(ctx context.Context, token string) {
+	if strings.HasPrefix(token, consts.BatchTokenPrefix) || strings.HasPrefix(token, consts.LegacyBatchTokenPrefix) {
+		return
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		c.logger.Warn("failed to revoke EST client token", "error", err)
+		return
+	}
+	if _, err := c.HandleRequest(ctx, &logical.Request{
+		ID:          id,
+		Operation:   logical.UpdateOperation,
+		Path:        "auth/token/revoke-self",
+		ClientToken: token,
+	}); err != nil {
+		c.logger.Warn("failed to revoke EST client token", "error", err)
+	}
+}
@@ -141,6 +141,9 @@ var _ Access = (*access)(nil)
 // access implements the Access interface
 type access struct {
 	quotaID string
+
+	// path is the request path the access was issued for.
+	path string
 }
 
 // QuotaID returns the identifier of the quota rule to which this access refers
@@ -704,9 +707,9 @@ func (m *Manager) ApplyQuota(ctx context.Context, req *Request) (Response, error
 		return resp, nil
 	}
 
-	// If the quota type is lease count, and if the path is not known to
+	// If the quota type is lease count, and if the path is known not to
 	// generate leases, allow the request.
-	if req.Type == TypeLeaseCount && !m.inLeasePathCache(req.Path) {
+	if req.Type == TypeLeaseCount && !m.mayGenerateLeases(req.Path) {
 		resp.Allowed = true
 		return resp, nil
 	}
@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !enterprise
+
+package quotas
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/helper/metricsutil"
+	"github.com/hashicorp/vault/sdk/helper/cryptoutil"
+)
+
+// Ensure that LeaseCountQuota implements the Quota interface
+var _ Quota = (*LeaseCountQuota)(nil)
+
+// LeaseCountQuota represents the quota rule properties that is used to limit the
+// number of leases that can be held at any given time for a namespace, mount,
+// path or role.
+type LeaseCountQuota struct {
+	// ID is the identifier of the quota
+	ID string `json:"id"`
+
+	// Type of quota this represents
+	Type Type `json:"type"`
+
+	// Name of the quota rule
+	Name string `json:"name"`
+
+	// NamespacePath is the path of the namespace to which this quota is
+	// applicable.
+	NamespacePath string `json:"namespace_path"`
+
+	// MountPath is the path of the mount to which this quota is applicable
+	MountPath string `json:"mount_path"`
+
+	// Role is the role on an auth mount to apply the quota to upon /login requests
+	// Not applicable for use with path suffixes
+	Role string `json:"role"`
+
+	// PathSuffix is the path suffix to which this quota is applicable
+	PathSuffix string `json:"path_suffix"`
+
+	// Inheritable indicates whether the quota will be inherited by child namespaces
+	Inheritable bool `json:"inheritable"`
+
+	// MaxLeases is the maximum number of leases allowed by the quota rule.
+	MaxLeases int `json:"max_leases"`
+
+	lock       *sync.Mutex
+	logger     log.Logger
+	metricSink *metricsutil.ClusterMetricSink
+
+	// leaseCount is the number of leases currently held in the expiration
+	// manager that are counted against this quota.
+	leaseCount int
+
+	// reserved is the number of requests that have been allowed by this quota
+	// but have not yet been acknowledged. Reservations guard against concurrent
+	// requests collectively exceeding MaxLeases.
+	reserved int
+}
+
+// NewLeaseCountQuota creates a quota checker for imposing limits on the number
+// of leases that can be created for a namespace, mount, path suffix or role.
+func NewLeaseCountQuota(name, nsPath, mountPath, pathSuffix, role string, inheritable bool, maxLeases int) *LeaseCountQuota {
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		// Fall back to generating with a hash of the name, later in initialize
+		id = ""
+	}
+	return &LeaseCountQuota{
+		Name:          name,
+		ID:            id,
+		Type:          TypeLeaseCount,
+		NamespacePath: nsPath,
+		MountPath:     mountPath,
+		Role:          role,
+		PathSuffix:    pathSuffix,
+		Inheritable:   inheritable,
+		MaxLeases:     maxLeases,
+	}
+}
+
+func (lcq *LeaseCountQuota) Clone() Quota {
+	return &LeaseCountQuota{
+		ID:            lcq.ID,
+		Name:          lcq.Name,
+		MountPath:     lcq.MountPath,
+		Role:          lcq.Role,
+		Inheritable:   lcq.Inheritable,
+		Type:          lcq.Type,
+		NamespacePath: lcq.NamespacePath,
+		PathSuffix:    lcq.PathSuffix,
+		MaxLeases:     lcq.MaxLeases,
+	}
+}
+
+func (lcq *LeaseCountQuota) IsInheritable() bool {
+	return lcq.Inheritable
+}
+
+// initialize ensures the namespace and max leases are initialized and sets the
+// ID if it's currently empty. The lease counter is not touched; it is rebuilt
+// by the quota manager after the quota has been (re)inserted.
+func (lcq *LeaseCountQuota) initialize(logger log.Logger, ms *metricsutil.ClusterMetricSink) error {
+	if lcq.lock == nil {
+		lcq.lock = new(sync.Mutex)
+	}
+
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	// Memdb requires a non-empty value for indexing
+	if lcq.NamespacePath == "" {
+		lcq.NamespacePath = "root"
+	}
+
+	if lcq.MaxLeases <= 0 {
+		return fmt.Errorf("invalid max leases: %v", lcq.MaxLeases)
+	}
+
+	if logger != nil {
+		lcq.logger = logger
+	}
+
+	if lcq.metricSink == nil {
+		lcq.metricSink = ms
+	}
+
+	if lcq.ID == "" {
+		// Use a deterministic ID so that performance standbys and later
+		// invalidations refer to the same quota instance.
+		lcq.ID = hex.EncodeToString(cryptoutil.Blake2b256Hash(lcq.Name))
+	}
+
+	return nil
+}
+
+// quotaID returns the identifier of the quota rule
+func (lcq *LeaseCountQuota) quotaID() string {
+	return lcq.ID
+}
+
+// QuotaName returns the name of the quota rule
+func (lcq *LeaseCountQuota) QuotaName() string {
+	return lcq.Name
+}
+
+// LeaseCount returns the number of leases currently counted against the quota.
+func (lcq *LeaseCountQuota) LeaseCount() int {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	return lcq.leaseCount
+}
+
+// allow decides if the request is allowed by the quota. If it is, a slot is
+// reserved for the lease the request may generate. The reservation is held
+// until the request is acknowledged via the returned Access.
+func (lcq *LeaseCountQuota) allow(_ context.Context, req *Request) (Response, error) {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	if lcq.leaseCount+lcq.reserved >= lcq.MaxLeases {
+		lcq.metricSink.IncrCounterWithLabels([]string{"quota", "lease_count", "violation"}, 1, []metrics.Label{{Name: "name", Value: lcq.Name}})
+		return Response{Allowed: false}, nil
+	}
+
+	lcq.reserved++
+
+	return Response{
+		Allowed: true,
+		Access:  &access{quotaID: lcq.ID, path: req.Path},
+	}, nil
+}
+
+// release drops a reservation taken by allow. Whether or not the request
+// generated a lease, the expiration manager is responsible for accounting
+// for the lease itself through incCounter.
+func (lcq *LeaseCountQuota) release() {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	if lcq.reserved > 0 {
+		lcq.reserved--
+	}
+}
+
+// incCounter accounts for a lease that was loaded or created in the
+// expiration manager.
+func (lcq *LeaseCountQuota) incCounter() {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	lcq.leaseCount++
+	lcq.metricSink.SetGaugeWithLabels([]string{"quota", "lease_count", "counter"}, float32(lcq.leaseCount), []metrics.Label{{Name: "name", Value: lcq.Name}})
+}
+
+// decCounter accounts for a lease that was deleted from the expiration
+// manager.
+func (lcq *LeaseCountQuota) decCounter() {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	if lcq.leaseCount > 0 {
+		lcq.leaseCount--
+	}
+	lcq.metricSink.SetGaugeWithLabels([]string{"quota", "lease_count", "counter"}, float32(lcq.leaseCount), []metrics.Label{{Name: "name", Value: lcq.Name}})
+}
+
+// resetCounter clears the lease counter, prior to it being recomputed from the
+// leases held by the expiration manager.
+func (lcq *LeaseCountQuota) resetCounter() {
+	lcq.lock.Lock()
+	defer lcq.lock.Unlock()
+
+	lcq.leaseCount = 0
+}
+
+// close is a no-op for lease count quotas; there are no background routines
+// associated with them.
+func (lcq *LeaseCountQuota) close(_ context.Context) error {
+	return nil
+}
+
+func (lcq *LeaseCountQuota) handleRemount(mountpath, nspath string) {
+	lcq.MountPath = mountpath
+	lcq.NamespacePath = nspath
+}
@@ -0,0 +1,180 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+//go:build !enterprise
+
+package quotas
+
+import (
+	"context"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/helper/metricsutil"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLeaseCountQuota(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lcq       *LeaseCountQuota
+		expectErr bool
+	}{
+		{"valid max leases", NewLeaseCountQuota("test-lease-count", "qa", "/foo/bar", "", "", false, 10), false},
+		{"zero max leases", NewLeaseCountQuota("test-lease-count", "qa", "/foo/bar", "", "", false, 0), true},
+		{"negative max leases", NewLeaseCountQuota("test-lease-count", "qa", "/foo/bar", "", "", false, -1), true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.lcq.initialize(logging.NewVaultLogger(log.Trace), metricsutil.BlackholeSink())
+			require.Equal(t, tc.expectErr, err != nil, err)
+		})
+	}
+}
+
+func TestLeaseCountQuota_Allow(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), nil, metricsutil.BlackholeSink())
+	require.NoError(t, err)
+
+	lcq := NewLeaseCountQuota("tq", "", "mount1/", "", "", false, 2)
+	require.NoError(t, qm.SetQuota(context.Background(), TypeLeaseCount.String(), lcq, false))
+
+	req := &Request{
+		Type:      TypeLeaseCount,
+		Path:      "mount1/creds/role",
+		MountPath: "mount1/",
+	}
+
+	// The path has not been seen yet, so the quota is enforced on the first
+	// request to it.
+	resp, err := qm.ApplyQuota(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.NotNil(t, resp.Access)
+
+	require.NoError(t, qm.HandleLeaseActions(LeaseActionCreated, []*Request{req}))
+	require.NoError(t, qm.AckLeaseQuota(resp.Access, true))
+	require.Equal(t, 1, lcq.LeaseCount())
+
+	// One lease remains available; an outstanding reservation blocks further
+	// requests until it is acknowledged.
+	resp, err = qm.ApplyQuota(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.NotNil(t, resp.Access)
+
+	blocked, err := qm.ApplyQuota(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, blocked.Allowed)
+
+	require.NoError(t, qm.HandleLeaseActions(LeaseActionCreated, []*Request{req}))
+	require.NoError(t, qm.AckLeaseQuota(resp.Access, true))
+	require.Equal(t, 2, lcq.LeaseCount())
+
+	resp, err = qm.ApplyQuota(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, resp.Allowed)
+
+	// Revoking a lease frees up a slot.
+	require.NoError(t, qm.HandleLeaseActions(LeaseActionDeleted, []*Request{req}))
+	require.Equal(t, 1, lcq.LeaseCount())
+
+	resp, err = qm.ApplyQuota(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.NoError(t, qm.AckLeaseQuota(resp.Access, false))
+	require.Equal(t, 1, lcq.LeaseCount())
+}
+
+func TestLeaseCountQuota_NonLeasePaths(t *testing.T) {
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), nil, metricsutil.BlackholeSink())
+	require.NoError(t, err)
+
+	lcq := NewLeaseCountQuota("tq", "", "", "", "", false, 1)
+	require.NoError(t, qm.SetQuota(context.Background(), TypeLeaseCount.String(), lcq, false))
+
+	leaseReq := &Request{Type: TypeLeaseCount, Path: "mount1/creds/role", MountPath: "mount1/"}
+	configReq := &Request{Type: TypeLeaseCount, Path: "mount1/config", MountPath: "mount1/"}
+
+	// A request which does not generate a lease marks its path as lease-free.
+	resp, err := qm.ApplyQuota(context.Background(), configReq)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.NotNil(t, resp.Access)
+	require.NoError(t, qm.AckLeaseQuota(resp.Access, false))
+	require.False(t, qm.mayGenerateLeases("mount1/config"))
+
+	resp, err = qm.ApplyQuota(context.Background(), leaseReq)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.NoError(t, qm.HandleLeaseActions(LeaseActionCreated, []*Request{leaseReq}))
+	require.NoError(t, qm.AckLeaseQuota(resp.Access, true))
+	require.Equal(t, 1, lcq.LeaseCount())
+
+	// Once the quota is exhausted, lease-free and system paths remain
+	// reachable, while unknown paths are rejected.
+	resp, err = qm.ApplyQuota(context.Background(), configReq)
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+	require.Nil(t, resp.Access)
+
+	resp, err = qm.ApplyQuota(context.Background(), &Request{Type: TypeLeaseCount, Path: "sys/leases/revoke", MountPath: "sys/"})
+	require.NoError(t, err)
+	require.True(t, resp.Allowed)
+
+	resp, err = qm.ApplyQuota(context.Background(), &Request{Type: TypeLeaseCount, Path: "mount1/creds/other", MountPath: "mount1/"})
+	require.NoError(t, err)
+	require.False(t, resp.Allowed)
+
+	// A lease created on a lease-free path puts the path back under the quota.
+	require.NoError(t, qm.HandleLeaseActions(LeaseActionCreated, []*Request{configReq}))
+	require.True(t, qm.mayGenerateLeases("mount1/config"))
+}
+
+func TestLeaseCountQuota_Recompute(t *testing.T) {
+	leases := []*Request{
+		{Path: "mount1/creds/role", MountPath: "mount1/"},
+		{Path: "mount1/creds/role", MountPath: "mount1/"},
+		{Path: "mount1/creds/other", MountPath: "mount1/"},
+		{Path: "mount2/creds/role", MountPath: "mount2/"},
+	}
+	leaseWalkFunc := func(_ context.Context, cb func(request *Request) bool) error {
+		for _, lease := range leases {
+			// Hand out copies, as the manager mutates the requests it is given.
+			req := *lease
+			if !cb(&req) {
+				break
+			}
+		}
+		return nil
+	}
+
+	qm, err := NewManager(logging.NewVaultLogger(log.Trace), leaseWalkFunc, metricsutil.BlackholeSink())
+	require.NoError(t, err)
+
+	mountQuota := NewLeaseCountQuota("mount", "", "mount1/", "", "", false, 10)
+	require.NoError(t, qm.SetQuota(context.Background(), TypeLeaseCount.String(), mountQuota, false))
+	require.Equal(t, 3, mountQuota.LeaseCount())
+
+	// A more specific quota takes over the leases it matches from the mount
+	// quota.
+	pathQuota := NewLeaseCountQuota("path", "", "mount1/", "creds/role", "", false, 10)
+	require.NoError(t, qm.SetQuota(context.Background(), TypeLeaseCount.String(), pathQuota, false))
+	require.Equal(t, 1, mountQuota.LeaseCount())
+	require.Equal(t, 2, pathQuota.LeaseCount())
+
+	require.True(t, qm.inLeasePathCache("mount2/creds/role"))
+	require.False(t, qm.inLeasePathCache("mount3/creds/role"))
+	require.True(t, qm.mayGenerateLeases("mount3/creds/role"))
+
+	// Deleting the path quota hands its leases back to the mount quota.
+	require.NoError(t, qm.DeleteQuota(context.Background(), TypeLeaseCount.String(), "path"))
+	require.Equal(t, 3, mountQuota.LeaseCount())
+
+	require.NoError(t, qm.Reset())
+	require.False(t, qm.inLeasePathCache("mount1/creds/role"))
+}
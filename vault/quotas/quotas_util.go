@@ -7,70 +7,210 @@ package quotas
 
 import (
 	"context"
-
-	log "github.com/hashicorp/go-hclog"
-	"github.com/hashicorp/vault/helper/metricsutil"
+	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-memdb"
+	lru "github.com/hashicorp/golang-lru"
 )
 
+// nonLeasePathCacheSize is the number of request paths known not to generate
+// leases that are remembered by the quota manager.
+const nonLeasePathCacheSize = 4096
+
 func quotaTypes() []string {
 	return []string{
+		TypeLeaseCount.String(),
 		TypeRateLimit.String(),
 	}
 }
 
-func (m *Manager) init(walkFunc leaseWalkFunc) {}
+func (m *Manager) init(walkFunc leaseWalkFunc) {
+	m.leaseWalkFunc = walkFunc
+	m.leasePathCache = make(map[string]struct{})
+	m.leasePathCacheLock = new(sync.RWMutex)
+	m.nonLeasePathCache, _ = lru.New(nonLeasePathCacheSize)
+}
 
+// recomputeLeaseCounts resets the counters of all the lease count quotas in the
+// given transaction and recounts every lease known to the expiration manager
+// against the quota that applies to it. It must be called with the write lock
+// held.
 func (m *Manager) recomputeLeaseCounts(ctx context.Context, txn *memdb.Txn) error {
-	return nil
+	iter, err := txn.Get(TypeLeaseCount.String(), indexID)
+	if err != nil {
+		return err
+	}
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		raw.(*LeaseCountQuota).resetCounter()
+	}
+
+	if m.leaseWalkFunc == nil {
+		return nil
+	}
+
+	var walkErr error
+	err = m.leaseWalkFunc(ctx, func(req *Request) bool {
+		req.Type = TypeLeaseCount
+		m.addLeasePath(req.Path)
+
+		quota, err := m.queryQuota(txn, req)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+		if quota != nil {
+			quota.(*LeaseCountQuota).incCounter()
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return walkErr
 }
 
 func (m *Manager) setIsPerfStandby(quota Quota) {}
 
+// inLeasePathCache returns true if a lease has been seen for the given request
+// path.
 func (m *Manager) inLeasePathCache(path string) bool {
-	return false
-}
+	m.leasePathCacheLock.RLock()
+	defer m.leasePathCacheLock.RUnlock()
 
-type entManager struct {
-	isPerfStandby bool
-	isDRSecondary bool
+	_, ok := m.leasePathCache[path]
+	return ok
 }
 
-func (*entManager) Reset() error {
-	return nil
-}
+// mayGenerateLeases returns false if requests to the given path are known not
+// to generate leases. Lease count quotas are not enforced on such paths, so
+// that requests which never generate leases are not rejected when a quota is
+// exhausted. Paths which have not been seen yet are enforced, so that the
+// first lease created on a path is accounted for.
+func (m *Manager) mayGenerateLeases(path string) bool {
+	// The system backend never generates leases, and must remain reachable
+	// so that leases can be revoked once a quota is exhausted.
+	if strings.HasPrefix(path, "sys/") {
+		return false
+	}
 
-type LeaseCountQuota struct{}
+	if m.inLeasePathCache(path) {
+		return true
+	}
 
-func (l LeaseCountQuota) IsInheritable() bool {
-	panic("implement me")
+	return !m.nonLeasePathCache.Contains(path)
 }
 
-func (l LeaseCountQuota) allow(_ context.Context, _ *Request) (Response, error) {
-	panic("implement me")
-}
+func (m *Manager) addLeasePath(path string) {
+	m.leasePathCacheLock.Lock()
+	defer m.leasePathCacheLock.Unlock()
 
-func (l LeaseCountQuota) quotaID() string {
-	panic("implement me")
+	m.leasePathCache[path] = struct{}{}
+	m.nonLeasePathCache.Remove(path)
 }
 
-func (l LeaseCountQuota) QuotaName() string {
-	panic("implement me")
+func (m *Manager) addNonLeasePath(path string) {
+	if m.inLeasePathCache(path) {
+		return
+	}
+
+	m.nonLeasePathCache.Add(path, struct{}{})
 }
 
-func (l LeaseCountQuota) initialize(logger log.Logger, sink *metricsutil.ClusterMetricSink) error {
-	panic("implement me")
+// AckLeaseQuota releases the reservation taken by the lease count quota which
+// allowed a request. It must be called once the request has been handled,
+// regardless of whether a lease was generated. The paths of requests which did
+// not generate a lease are remembered, so that the quota is not enforced on
+// later requests to them.
+func (m *Manager) AckLeaseQuota(acc Access, leaseGenerated bool) error {
+	quota, err := m.QuotaByID(TypeLeaseCount.String(), acc.QuotaID())
+	if err != nil {
+		return err
+	}
+
+	// The quota may have been deleted while the request was in flight.
+	if quota == nil {
+		return nil
+	}
+
+	lcq, ok := quota.(*LeaseCountQuota)
+	if !ok {
+		return fmt.Errorf("unexpected quota type %T for lease count acknowledgement", quota)
+	}
+	lcq.release()
+
+	if a, ok := acc.(*access); ok && !leaseGenerated {
+		m.addNonLeasePath(a.path)
+	}
+
+	return nil
 }
 
-func (l LeaseCountQuota) close(_ context.Context) error {
-	panic("implement me")
+// HandleLeaseActions updates the lease path cache and the counters of the
+// applicable lease count quotas for leases which were loaded, created or
+// deleted in the expiration manager. Each request describes the request that
+// generated the corresponding lease.
+func (m *Manager) HandleLeaseActions(action LeaseAction, reqs []*Request) error {
+	m.dbAndCacheLock.RLock()
+	defer m.dbAndCacheLock.RUnlock()
+
+	txn := m.db.Txn(false)
+
+	for _, req := range reqs {
+		req.Type = TypeLeaseCount
+
+		switch action {
+		case LeaseActionLoaded, LeaseActionCreated:
+			m.addLeasePath(req.Path)
+		case LeaseActionDeleted:
+		default:
+			return fmt.Errorf("unsupported lease action %q", action)
+		}
+
+		quota, err := m.queryQuota(txn, req)
+		if err != nil {
+			return err
+		}
+		if quota == nil {
+			continue
+		}
+
+		lcq := quota.(*LeaseCountQuota)
+		if action == LeaseActionDeleted {
+			lcq.decCounter()
+		} else {
+			lcq.incCounter()
+		}
+	}
+
+	return nil
 }
 
-func (l LeaseCountQuota) Clone() Quota {
-	panic("implement me")
+type entManager struct {
+	isPerfStandby bool
+	isDRSecondary bool
+
+	// leaseWalkFunc walks all the leases held by the expiration manager. It is
+	// used to rebuild the lease count quota counters.
+	leaseWalkFunc leaseWalkFunc
+
+	// leasePathCache holds the request paths which are known to generate
+	// leases.
+	leasePathCache     map[string]struct{}
+	leasePathCacheLock *sync.RWMutex
+
+	// nonLeasePathCache holds the most recent request paths which were
+	// handled without generating a lease.
+	nonLeasePathCache *lru.Cache
 }
 
-func (l LeaseCountQuota) handleRemount(mountPath, nsPath string) {
-	panic("implement me")
+func (e *entManager) Reset() error {
+	e.leasePathCacheLock.Lock()
+	defer e.leasePathCacheLock.Unlock()
+
+	e.leasePathCache = make(map[string]struct{})
+	e.nonLeasePathCache.Purge()
+	return nil
 }